@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -14,16 +16,39 @@ import (
 	"kube-sherlock/internal/kubernetes"
 )
 
+// Exit codes for the analyze command
+const (
+	exitCodeError       = 1
+	exitCodeIssuesFound = 2
+)
+
 var analyzeCmd = &cobra.Command{
 	Use:   "analyze [error-message]",
 	Short: "Analyze a Kubernetes error and get troubleshooting suggestions",
 	Long: `Analyze a Kubernetes error message and get AI-powered troubleshooting suggestions.
-You can provide the error message as an argument or pipe it via stdin.
+You can provide the error message as an argument, pipe it via stdin or read it
+from a file with --input-file.
 
 Examples:
   kube-sherlock analyze "ImagePullBackOff"
   kubectl logs pod/failing-pod | kube-sherlock analyze
-  kube-sherlock analyze --gather-resources --namespace default "CrashLoopBackOff"`,
+  kube-sherlock analyze --input-file describe-output.txt --gather-resources
+  kube-sherlock analyze --gather-resources --namespace default "CrashLoopBackOff"
+  kube-sherlock analyze --gather-resources --namespaces frontend,backend,db "502 Bad Gateway"
+  kube-sherlock analyze --gather-resources --context staging-cluster "CrashLoopBackOff"
+  kube-sherlock analyze --dry-run "OOMKilled"
+  kube-sherlock analyze --fail-on-issues --gather-resources "CrashLoopBackOff"
+  kubectl logs deploy/api --tail=500 | kube-sherlock analyze --max-input-lines 300
+
+Piped and file input keep only the last --max-input-lines lines. Inputs longer than
+50 lines are summarized first and analyzed together with their most recent
+lines; use --summarize-input=false to analyze the raw text instead.
+
+Exit codes:
+  0  analysis completed (and, with --fail-on-issues, no issues were found)
+  1  analysis could not be completed (configuration, AI or input error)
+  2  --fail-on-issues was set and issues were found: the AI reported potential
+     causes or gathered pods are unhealthy`,
 	Args: cobra.MaximumNArgs(1),
 	Run:  runAnalyze,
 }
@@ -33,50 +58,143 @@ func init() {
 
 	analyzeCmd.Flags().String("gemini-api-key", "", "Google AI (Gemini) API key")
 	analyzeCmd.Flags().BoolP("gather-resources", "g", false, "Gather related Kubernetes resources for additional context")
-	analyzeCmd.Flags().StringP("namespace", "n", "default", "Kubernetes namespace to gather resources from")
-	analyzeCmd.Flags().StringSlice("resource-types", []string{"pods", "deployments", "services", "events"}, "Types of resources to gather")
+	analyzeCmd.Flags().StringP("namespace", "n", "", "Kubernetes namespace to gather resources from (default: the kubeconfig context's namespace)")
+	analyzeCmd.Flags().String("context", "", "Kubeconfig context to gather resources from (default: kubernetes.context, or the current context)")
+	analyzeCmd.Flags().String("kubeconfig", "", "Path to the kubeconfig file (default: kubernetes.config_path, KUBECONFIG or ~/.kube/config)")
+	analyzeCmd.Flags().StringSlice("namespaces", nil, "Gather resources from each of these namespaces instead of one, e.g. frontend,backend,db")
+	analyzeCmd.Flags().StringSlice("resource-types", []string{"pods", "deployments", "services", "events"},
+		"Types of resources to gather: "+strings.Join(kubernetes.SupportedResourceTypes, ", ")+", or group/version/resource. Asked interactively when unset and run in a terminal")
 	analyzeCmd.Flags().String("label-selector", "", "Label selector for filtering resources")
 	analyzeCmd.Flags().BoolP("verbose-output", "V", false, "Show detailed analysis steps")
+	analyzeCmd.Flags().Bool("fail-on-issues", false, "Exit with code 2 when potential causes are found or gathered pods are unhealthy")
+	analyzeCmd.Flags().Bool("dry-run", false, "Print the prompts that would be sent to Gemini without calling the model")
+	analyzeCmd.Flags().Int("max-input-lines", 500, "Analyze at most this many of the last lines of the input")
+	analyzeCmd.Flags().Bool("summarize-input", true, "Summarize large multi-line input before troubleshooting it")
+	analyzeCmd.Flags().Int("passes", 1, fmt.Sprintf("Run the troubleshoot prompt this many times (at most %d) and merge the results, ranking causes found by several passes higher", ai.MaxTroubleshootPasses))
+	analyzeCmd.Flags().StringP("input-file", "f", "", "Read the error message or resource output to analyze from this file instead of an argument or stdin")
 
 	viper.BindPFlag("gemini.api_key", analyzeCmd.Flags().Lookup("gemini-api-key"))
 	viper.BindPFlag("gather.resources", analyzeCmd.Flags().Lookup("gather-resources"))
 	viper.BindPFlag("gather.namespace", analyzeCmd.Flags().Lookup("namespace"))
+	viper.BindPFlag("gather.namespaces", analyzeCmd.Flags().Lookup("namespaces"))
+	viper.BindPFlag("kubernetes.context", analyzeCmd.Flags().Lookup("context"))
+	viper.BindPFlag("kubernetes.config_path", analyzeCmd.Flags().Lookup("kubeconfig"))
 	viper.BindPFlag("gather.resource_types", analyzeCmd.Flags().Lookup("resource-types"))
 	viper.BindPFlag("gather.label_selector", analyzeCmd.Flags().Lookup("label-selector"))
 	viper.BindPFlag("output.verbose", analyzeCmd.Flags().Lookup("verbose-output"))
+	viper.BindPFlag("gemini.dry_run", analyzeCmd.Flags().Lookup("dry-run"))
+	viper.BindPFlag("output.fail_on_issues", analyzeCmd.Flags().Lookup("fail-on-issues"))
+	viper.BindPFlag("input.max_lines", analyzeCmd.Flags().Lookup("max-input-lines"))
+	viper.BindPFlag("input.summarize", analyzeCmd.Flags().Lookup("summarize-input"))
 }
 
 func runAnalyze(cmd *cobra.Command, args []string) {
 	cfg := config.GetConfig()
 	logger := config.GetLogger()
 
-	// Validate required configuration
-	if cfg.Gemini.APIKey == "" {
-		fmt.Fprintf(os.Stderr, "Error: Gemini API key is required. Set via --gemini-api-key flag or GEMINI_API_KEY environment variable\n")
-		os.Exit(1)
+	dryRun := viper.GetBool("gemini.dry_run")
+
+	passes, _ := cmd.Flags().GetInt("passes")
+	if passes < 1 || passes > ai.MaxTroubleshootPasses {
+		fmt.Fprintf(os.Stderr, "Error: --passes must be between 1 and %d\n", ai.MaxTroubleshootPasses)
+		os.Exit(exitCodeError)
+	}
+
+	// Catch mistyped resource types before any work, instead of as <type>_error entries in the output
+	resourceTypes := viper.GetStringSlice("gather.resource_types")
+	if err := kubernetes.ValidateResourceTypes(resourceTypes); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitCodeError)
+	}
+	if viper.GetBool("gather.resources") && !viper.IsSet("gather.resource_types") && !stdinIsPiped() && stdoutIsTerminal() {
+		resourceTypes = promptResourceTypes(os.Stdin, os.Stdout, resourceTypes)
+	}
+
+	// An explicit --kubeconfig takes precedence over kubeconfig content from the config file or environment
+	kubeconfigContent := cfg.Kubernetes.ConfigContent
+	if cmd.Flags().Changed("kubeconfig") {
+		kubeconfigContent = ""
+	}
+	// Catch a mistyped context before any work, rather than gathering from the wrong cluster or none
+	if viper.GetBool("gather.resources") && kubeconfigContent == "" && (cfg.Kubernetes.Context != "" || cmd.Flags().Changed("kubeconfig")) {
+		if err := kubernetes.ValidateContext(cfg.Kubernetes.ConfigPath, cfg.Kubernetes.Context); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCodeError)
+		}
+	}
+
+	// A namespace list, usually from the config file, applies unless --namespace alone was given
+	var namespaces []string
+	if !cmd.Flags().Changed("namespace") || cmd.Flags().Changed("namespaces") {
+		namespaces = viper.GetStringSlice("gather.namespaces")
+	}
+	if len(namespaces) > 0 {
+		normalized, err := kubernetes.NormalizeNamespaces(namespaces)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCodeError)
+		}
+		namespaces = normalized
 	}
 
 	// Get error message from args or stdin
-	var errorMessage string
-	if len(args) > 0 {
-		errorMessage = args[0]
-	} else {
-		// Read from stdin
+	maxInputLines := viper.GetInt("input.max_lines")
+	inputFile, _ := cmd.Flags().GetString("input-file")
+	var source io.Reader
+	if inputFile != "" {
+		if len(args) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: Provide the error message either as an argument or with --input-file, not both\n")
+			os.Exit(exitCodeError)
+		}
+		file, err := openInputFile(inputFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCodeError)
+		}
+		defer file.Close()
+		source = file
+	} else if len(args) > 0 {
+		source = strings.NewReader(args[0])
+	} else if stdinIsPiped() {
 		fmt.Fprintf(os.Stderr, "Reading error message from stdin...\n")
-		// For now, require explicit error message
-		fmt.Fprintf(os.Stderr, "Error: Please provide an error message as an argument\n")
-		os.Exit(1)
+		source = os.Stdin
+	} else {
+		fmt.Fprintf(os.Stderr, "Error: Please provide an error message as an argument, pipe it via stdin or use --input-file\n")
+		os.Exit(exitCodeError)
 	}
 
-	if errorMessage == "" {
+	input, err := readLogInput(source, maxInputLines)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+		os.Exit(exitCodeError)
+	}
+
+	errorMessage := input.Text
+	if strings.TrimSpace(errorMessage) == "" {
 		fmt.Fprintf(os.Stderr, "Error: No error message provided\n")
-		os.Exit(1)
+		os.Exit(exitCodeError)
+	}
+	if input.Dropped > 0 {
+		fmt.Fprintf(os.Stderr, "Note: input has %d lines; only the last %d are analyzed (see --max-input-lines)\n",
+			input.Lines+input.Dropped, input.Lines)
 	}
 
 	ctx := context.Background()
 
 	// Initialize AI service
-	aiService := ai.NewService(cfg.Gemini.APIKey, cfg.Gemini.Model, logger)
+	aiOpts := cfg.AIOptions()
+	if dryRun {
+		aiOpts = append(aiOpts, ai.WithDryRun(os.Stdout))
+	}
+	aiService, err := ai.NewService(cfg.Gemini.APIKey, cfg.Gemini.Model, logger, aiOpts...)
+	if errors.Is(err, ai.ErrNoAPIKey) {
+		fmt.Fprintf(os.Stderr, "Error: Gemini API key is required. Set via --gemini-api-key flag or GEMINI_API_KEY environment variable, or configure gemini.credentials_file or gemini.use_adc\n")
+		os.Exit(exitCodeError)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing AI service: %v\n", err)
+		os.Exit(exitCodeError)
+	}
 	defer aiService.Close()
 
 	verboseOutput := viper.GetBool("output.verbose")
@@ -84,68 +202,83 @@ func runAnalyze(cmd *cobra.Command, args []string) {
 	// Print header
 	fmt.Println("🔍 Kube Sherlock Analysis")
 	fmt.Println("=" + fmt.Sprintf("%*s", 24, ""))
-	fmt.Printf("Error: %s\n\n", errorMessage)
+	if input.Lines > 1 {
+		fmt.Printf("Input: %d lines, starting with: %s\n\n", input.Lines, input.FirstLine())
+	} else {
+		fmt.Printf("Error: %s\n\n", errorMessage)
+	}
 
 	if verboseOutput {
 		fmt.Println("📋 Starting AI analysis...")
 	}
 
-	// Step 1: Troubleshoot the error
-	troubleshootResp, err := aiService.TroubleshootError(ctx, errorMessage)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error analyzing error message: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Step 2: Get resource suggestions
-	suggestResp, err := aiService.SuggestResources(ctx, errorMessage)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting resource suggestions: %v\n", err)
-		os.Exit(1)
+	// Only connect to the cluster when resources will be gathered
+	gatherResources := viper.GetBool("gather.resources")
+	var k8sService *kubernetes.Service
+	if gatherResources {
+		k8sService, err = kubernetes.NewService(cfg.Kubernetes.ConfigPath, cfg.Kubernetes.Context, logger,
+			append(cfg.KubernetesOptions(), kubernetes.WithKubeconfigContent([]byte(kubeconfigContent)))...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to connect to Kubernetes cluster: %v\n", err)
+			k8sService = nil
+		}
 	}
 
-	// Step 3: Gather resources if requested
-	var resourceContext string
-	if viper.GetBool("gather.resources") {
+	namespace := viper.GetString("gather.namespace")
+	if gatherResources && len(namespaces) > 0 && verboseOutput {
+		fmt.Printf("📋 Gathering from namespaces %s\n", strings.Join(namespaces, ", "))
+	} else if gatherResources && namespace == "" && k8sService != nil {
+		namespace = k8sService.DefaultNamespace()
 		if verboseOutput {
-			fmt.Println("📦 Gathering Kubernetes resources...")
+			fmt.Printf("📋 Using namespace %q from the kubeconfig context\n", namespace)
 		}
+	}
 
-		k8sService, err := kubernetes.NewService(cfg.Kubernetes.ConfigPath, cfg.Kubernetes.Context, logger)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: Failed to connect to Kubernetes cluster: %v\n", err)
-		} else {
-			namespace := viper.GetString("gather.namespace")
-			resourceTypes := viper.GetStringSlice("gather.resource_types")
-			labelSelector := viper.GetString("gather.label_selector")
-
-			resources, err := k8sService.GatherResources(ctx, resourceTypes, namespace, labelSelector)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: Failed to gather resources: %v\n", err)
-			} else {
-				// Summarize the gathered resources
-				resourceData := fmt.Sprintf("%+v", resources)
-				summaryResp, err := aiService.SummarizeResourceData(ctx, resourceData)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Warning: Failed to summarize resource data: %v\n", err)
-				} else {
-					resourceContext = summaryResp.Summary
-				}
+	result, err := aiService.Analyze(ctx, k8sService, ai.AnalyzeOptions{
+		ErrorMessage:        errorMessage,
+		SummarizeLargeInput: viper.GetBool("input.summarize"),
+		Passes:              passes,
+		GatherResources:     gatherResources,
+		Gather: kubernetes.GatherOptions{
+			ResourceTypes: resourceTypes,
+			Namespace:     namespace,
+			Namespaces:    namespaces,
+			LabelSelector: viper.GetString("gather.label_selector"),
+		},
+		Progress: func(message string) {
+			if verboseOutput {
+				fmt.Printf("📋 %s...\n", message)
 			}
-		}
+		},
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitCodeError)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
 	}
+	troubleshootResp := result.Troubleshoot
+	suggestResp := result.Suggestions
 
 	// Display results
 	fmt.Println("💡 Potential Causes:")
 	fmt.Println(strings.Repeat("-", 20))
 	for i, cause := range troubleshootResp.PotentialCauses {
-		fmt.Printf("%d. %s\n", i+1, cause)
+		if i < len(troubleshootResp.Causes) && troubleshootResp.Passes > 1 {
+			fmt.Printf("%d. %s (confidence: %.0f%%, found in %d of %d passes)\n", i+1, cause, troubleshootResp.Causes[i].Confidence*100,
+				troubleshootResp.Causes[i].Passes, troubleshootResp.Passes)
+		} else if i < len(troubleshootResp.Causes) {
+			fmt.Printf("%d. %s (confidence: %.0f%%)\n", i+1, cause, troubleshootResp.Causes[i].Confidence*100)
+		} else {
+			fmt.Printf("%d. %s\n", i+1, cause)
+		}
 	}
 
 	fmt.Println("\n🔧 Suggested Solutions:")
 	fmt.Println(strings.Repeat("-", 23))
 	for i, solution := range troubleshootResp.SuggestedSolutions {
-		fmt.Printf("%d. %s\n", i+1, solution)
+		fmt.Printf("%d. %s%s\n", i+1, solution, solutionHints(troubleshootResp.Solutions, i))
 	}
 
 	fmt.Println("\n📋 Recommended Resources to Check:")
@@ -155,13 +288,50 @@ func runAnalyze(cmd *cobra.Command, args []string) {
 		fmt.Printf("%d. %s\n", i+1, resource)
 	}
 
-	if resourceContext != "" {
+	if result.ResourceSummary != "" {
 		fmt.Println("\n📊 Current Cluster Context:")
 		fmt.Println(strings.Repeat("-", 28))
-		fmt.Println(resourceContext)
+		fmt.Println(result.ResourceSummary)
+	}
+
+	if len(result.UnhealthyPods) > 0 {
+		fmt.Println("\n⚠️  Unhealthy Pods:")
+		fmt.Println(strings.Repeat("-", 18))
+		for _, pod := range result.UnhealthyPods {
+			fmt.Printf("- %s\n", pod)
+		}
 	}
 
 	if verboseOutput {
 		fmt.Println("\n✅ Analysis complete!")
 	}
+
+	// Gate CI pipelines on the outcome when requested
+	if viper.GetBool("output.fail_on_issues") && !dryRun {
+		if result.HasIssues() {
+			os.Exit(exitCodeIssuesFound)
+		}
+	}
+}
+
+// solutionHints formats the effort, risk and, for merged passes, agreement of the i-th solution, or returns
+// an empty string when they weren't given
+func solutionHints(solutions []ai.SolutionAssessment, i int) string {
+	if i >= len(solutions) {
+		return ""
+	}
+	var hints []string
+	if solutions[i].Effort != "" {
+		hints = append(hints, "effort: "+solutions[i].Effort)
+	}
+	if solutions[i].Risk != "" {
+		hints = append(hints, "risk: "+solutions[i].Risk)
+	}
+	if solutions[i].Passes > 0 {
+		hints = append(hints, fmt.Sprintf("suggested by %d passes", solutions[i].Passes))
+	}
+	if len(hints) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(hints, ", ") + ")"
 }
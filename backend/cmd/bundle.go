@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"kube-sherlock/internal/config"
+	"kube-sherlock/internal/kubernetes"
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Write a diagnostic bundle of resources and logs to an archive",
+	Long: `Gather resources and the logs of unhealthy pods into a tar.gz or zip archive, for
+support tickets or offline analysis. The bundle holds one YAML file per resource
+type and namespace, the current and previous logs of every container of each
+unhealthy pod, and a metadata.json listing the files and anything that couldn't
+be collected. Credentials and Secret values are redacted as in any gather.
+
+Examples:
+  kube-sherlock bundle --namespace payments
+  kube-sherlock bundle --namespaces frontend,backend --output incident-42.zip
+  kube-sherlock bundle --all-namespaces --resource-types pods,events,nodes`,
+	Args: cobra.NoArgs,
+	Run:  runBundle,
+}
+
+func init() {
+	rootCmd.AddCommand(bundleCmd)
+
+	bundleCmd.Flags().StringP("namespace", "n", "", "Namespace to gather from (default: the kubeconfig context's namespace)")
+	bundleCmd.Flags().StringSlice("namespaces", nil, "Gather from each of these namespaces instead of one, e.g. frontend,backend,db")
+	bundleCmd.Flags().BoolP("all-namespaces", "A", false, "Gather from all permitted namespaces")
+	bundleCmd.Flags().StringSlice("resource-types", kubernetes.DefaultBundleResourceTypes,
+		"Types of resources to gather: "+strings.Join(kubernetes.SupportedResourceTypes, ", ")+", or group/version/resource. Pods are always included")
+	bundleCmd.Flags().StringP("label-selector", "l", "", "Label selector for filtering resources")
+	bundleCmd.Flags().Int64("log-lines", kubernetes.DefaultBundleLogLines, "Keep this many of the most recent lines of each container log")
+	bundleCmd.Flags().StringP("output", "o", "", "Archive to write; its extension (.tar.gz or .zip) sets the format (default: kube-sherlock-bundle-<time>.tar.gz)")
+}
+
+func runBundle(cmd *cobra.Command, args []string) {
+	cfg := config.GetConfig()
+	logger := config.GetLogger()
+
+	namespace, _ := cmd.Flags().GetString("namespace")
+	namespaces, _ := cmd.Flags().GetStringSlice("namespaces")
+	allNamespaces, _ := cmd.Flags().GetBool("all-namespaces")
+	resourceTypes, _ := cmd.Flags().GetStringSlice("resource-types")
+	labelSelector, _ := cmd.Flags().GetString("label-selector")
+	logLines, _ := cmd.Flags().GetInt64("log-lines")
+	output, _ := cmd.Flags().GetString("output")
+
+	if allNamespaces && (namespace != "" || len(namespaces) > 0) {
+		fmt.Fprintf(os.Stderr, "Error: --all-namespaces can't be combined with --namespace or --namespaces\n")
+		os.Exit(1)
+	}
+	if allNamespaces {
+		namespace = kubernetes.AllNamespaces
+	}
+	if err := kubernetes.ValidateResourceTypes(resourceTypes); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	format := kubernetes.BundleFormatTarGz
+	switch {
+	case output == "":
+	case strings.HasSuffix(output, ".zip"):
+		format = kubernetes.BundleFormatZip
+	case strings.HasSuffix(output, ".tar.gz"), strings.HasSuffix(output, ".tgz"):
+	default:
+		fmt.Fprintf(os.Stderr, "Error: --output must end in .tar.gz, .tgz or .zip\n")
+		os.Exit(1)
+	}
+
+	k8sService, err := kubernetes.NewService(cfg.Kubernetes.ConfigPath, cfg.Kubernetes.Context, logger, cfg.KubernetesOptions()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: bundle requires a reachable Kubernetes cluster: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "Collecting diagnostic bundle...\n")
+	bundle, err := k8sService.CollectBundle(context.Background(), kubernetes.BundleOptions{
+		Gather: kubernetes.GatherOptions{
+			ResourceTypes: resourceTypes,
+			Namespace:     namespace,
+			Namespaces:    namespaces,
+			LabelSelector: labelSelector,
+		},
+		LogLines: logLines,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if output == "" {
+		output = bundle.FileName(format)
+	}
+	file, err := os.Create(output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := bundle.WriteArchive(file, format); err != nil {
+		file.Close()
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", output, err)
+		os.Exit(1)
+	}
+	if err := file.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", output, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Wrote %s: %d files, logs of %d unhealthy pods\n", output, len(bundle.Manifest.Files)+1, bundle.Manifest.LogPods)
+	if bundle.Manifest.SkippedLogPods > 0 {
+		fmt.Printf("Skipped the logs of %d more unhealthy pods over the limit\n", bundle.Manifest.SkippedLogPods)
+	}
+	for _, msg := range bundle.Manifest.Errors {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", msg)
+	}
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"kube-sherlock/internal/ai"
+	"kube-sherlock/internal/config"
+	"kube-sherlock/internal/kubernetes"
+	"kube-sherlock/internal/mcp"
+)
+
+var chatCmd = &cobra.Command{
+	Use:   "chat",
+	Short: "Start an interactive troubleshooting session",
+	Long: `Start an interactive REPL for natural language questions about your cluster.
+Each question runs the MCP query flow against the live cluster, and earlier
+questions and answers are kept as context so follow-ups work naturally.
+
+Commands:
+  /context [namespace]  show or set the namespace used when a question doesn't name one
+  /tools                list the available MCP tools
+  /reset                forget the conversation so far
+  /help                 show this help
+  /exit, /quit          leave the session (Ctrl-D also works)
+
+Examples:
+  kube-sherlock chat
+  kube-sherlock chat --namespace payments`,
+	Args: cobra.NoArgs,
+	Run:  runChat,
+}
+
+func init() {
+	rootCmd.AddCommand(chatCmd)
+
+	chatCmd.Flags().String("gemini-api-key", "", "Google AI (Gemini) API key")
+	chatCmd.Flags().StringP("namespace", "n", "", "Namespace to use when a question doesn't name one")
+
+	viper.BindPFlag("gemini.api_key", chatCmd.Flags().Lookup("gemini-api-key"))
+}
+
+func runChat(cmd *cobra.Command, args []string) {
+	cfg := config.GetConfig()
+	logger := config.GetLogger()
+
+	aiService, err := ai.NewService(cfg.Gemini.APIKey, cfg.Gemini.Model, logger, cfg.AIOptions()...)
+	if errors.Is(err, ai.ErrNoAPIKey) {
+		fmt.Fprintf(os.Stderr, "Error: Gemini API key is required. Set via --gemini-api-key flag or GEMINI_API_KEY environment variable, or configure gemini.credentials_file or gemini.use_adc\n")
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing AI service: %v\n", err)
+		os.Exit(1)
+	}
+	defer aiService.Close()
+
+	// Chat answers come from live cluster data, so a cluster is required
+	k8sService, err := kubernetes.NewService(cfg.Kubernetes.ConfigPath, cfg.Kubernetes.Context, logger, cfg.KubernetesOptions()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: chat requires a reachable Kubernetes cluster: %v\n", err)
+		os.Exit(1)
+	}
+	mcpService := mcp.NewMCPService(k8sService, logger, cfg.MCPOptions()...)
+	aiService.SetMCPService(mcpService)
+
+	namespace, _ := cmd.Flags().GetString("namespace")
+	conversation := &ai.Conversation{Namespace: namespace}
+
+	fmt.Println("🔍 Kube Sherlock Chat")
+	fmt.Println(strings.Repeat("=", 20))
+	fmt.Println("Ask a question about your cluster, or type /help for commands.")
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for {
+		fmt.Print("\nsherlock> ")
+		if !scanner.Scan() {
+			fmt.Println()
+			return
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "/") {
+			if !runChatCommand(line, conversation, mcpService) {
+				return
+			}
+			continue
+		}
+
+		// Ctrl-C cancels the current question without leaving the session
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		ctx = ai.ContextWithConversation(ctx, conversation)
+		response, err := aiService.QueryWithMCPEvents(ctx, line, func(event ai.QueryEvent) {
+			if event.Type == ai.QueryEventToolExecution || event.Type == ai.QueryEventAnalysis {
+				fmt.Printf("  … %s\n", event.Message)
+			}
+		})
+		stop()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			continue
+		}
+
+		fmt.Println()
+		fmt.Println(response.Response)
+		if response.Error != "" {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", response.Error)
+		}
+		conversation.Add(line, response.Response)
+	}
+}
+
+// runChatCommand handles a slash command and reports whether the session should continue
+func runChatCommand(line string, conversation *ai.Conversation, mcpService *mcp.MCPService) bool {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case "/exit", "/quit":
+		return false
+
+	case "/reset":
+		conversation.Reset()
+		fmt.Println("Conversation cleared.")
+
+	case "/context":
+		if len(fields) > 1 {
+			conversation.Namespace = fields[1]
+		}
+		if conversation.Namespace == "" {
+			fmt.Println("No namespace set; questions that don't name one use each tool's default.")
+		} else {
+			fmt.Printf("Namespace: %s\n", conversation.Namespace)
+		}
+
+	case "/tools":
+		tools := mcpService.ListTools()
+		sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+		for _, tool := range tools {
+			fmt.Printf("- %s: %s\n", tool.Name, tool.Description)
+		}
+
+	case "/help":
+		fmt.Println("/context [namespace]  show or set the default namespace")
+		fmt.Println("/tools                list the available MCP tools")
+		fmt.Println("/reset                forget the conversation so far")
+		fmt.Println("/exit, /quit          leave the session")
+
+	default:
+		fmt.Printf("Unknown command %s; type /help for commands.\n", fields[0])
+	}
+	return true
+}
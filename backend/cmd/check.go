@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"kube-sherlock/internal/ai"
+	"kube-sherlock/internal/config"
+	"kube-sherlock/internal/kubernetes"
+	"kube-sherlock/internal/mcp"
+)
+
+var checkCmd = &cobra.Command{
+	Use:     "check",
+	Aliases: []string{"doctor"},
+	Short:   "Validate configuration, Gemini access and cluster connectivity",
+	Long: `Check that Kube Sherlock is configured correctly before running a real command.
+
+Validates the Gemini API key and model with a lightweight call, verifies that the
+kubeconfig loads and the cluster is reachable, lists the available contexts, and
+reports which MCP tools are available. Exits non-zero if any check fails.`,
+	Args: cobra.NoArgs,
+	Run:  runCheck,
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	checkCmd.Flags().String("gemini-api-key", "", "Google AI (Gemini) API key")
+
+	viper.BindPFlag("gemini.api_key", checkCmd.Flags().Lookup("gemini-api-key"))
+}
+
+func runCheck(cmd *cobra.Command, args []string) {
+	cfg := config.GetConfig()
+	logger := config.GetLogger()
+
+	failures := 0
+	pass := func(format string, a ...interface{}) {
+		fmt.Printf("✅ "+format+"\n", a...)
+	}
+	fail := func(hint, format string, a ...interface{}) {
+		failures++
+		fmt.Printf("❌ "+format+"\n", a...)
+		fmt.Printf("   → %s\n", hint)
+	}
+
+	fmt.Println("🩺 Kube Sherlock Check")
+	fmt.Println(strings.Repeat("=", 21))
+
+	// Configuration file
+	if used := viper.ConfigFileUsed(); used != "" {
+		pass("Config file: %s", used)
+	} else {
+		fmt.Println("ℹ️  No config file found, using flags, environment and defaults")
+	}
+
+	// Gemini
+	if cfg.Gemini.UsesMockProvider() {
+		fmt.Println("ℹ️  Using the mock AI provider: responses are canned and no Gemini credentials are needed")
+		if _, err := ai.NewService("", "", logger, ai.WithProvider(cfg.Gemini.Provider, cfg.Gemini.MockFixturesFile)); err != nil {
+			fail("Fix gemini.mock_fixtures_file", "Mock provider can't be used: %v", err)
+		} else if cfg.Gemini.MockFixturesFile != "" {
+			pass("Mock fixtures loaded from %s", cfg.Gemini.MockFixturesFile)
+		}
+	} else if !cfg.Gemini.HasCredentials() {
+		fail("Set via --gemini-api-key flag or GEMINI_API_KEY environment variable, or configure gemini.credentials_file or gemini.use_adc",
+			"Gemini API key is not configured")
+	} else {
+		switch {
+		case cfg.Gemini.APIKey != "":
+			pass("Gemini API key is configured")
+		case cfg.Gemini.CredentialsFile != "":
+			pass("Gemini credentials file is configured: %s", cfg.Gemini.CredentialsFile)
+		default:
+			pass("Gemini uses Application Default Credentials")
+		}
+		if cfg.Gemini.Endpoint != "" {
+			fmt.Printf("ℹ️  Using Gemini endpoint %s\n", cfg.Gemini.Endpoint)
+		}
+
+		var info *ai.ModelInfo
+		aiService, err := ai.NewService(cfg.Gemini.APIKey, cfg.Gemini.Model, logger, cfg.AIOptions()...)
+		if err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			info, err = aiService.CheckModel(ctx)
+			cancel()
+			aiService.Close()
+		}
+
+		if err != nil {
+			fail("Verify the credentials and endpoint are valid and that gemini.model names a model available to them",
+				"Gemini model %q is not reachable: %v", cfg.Gemini.Model, err)
+		} else {
+			pass("Gemini model %q is reachable (%s, input limit %d tokens)",
+				cfg.Gemini.Model, info.DisplayName, info.InputTokenLimit)
+		}
+	}
+
+	// Kubeconfig contexts
+	contexts, currentContext, err := kubernetes.ListContexts(cfg.Kubernetes.ConfigPath)
+	if cfg.Kubernetes.ConfigContent != "" {
+		fmt.Println("ℹ️  Using kubeconfig content from KUBECONFIG_CONTENT / kubernetes.config_content instead of a file")
+	} else if err != nil {
+		fmt.Printf("ℹ️  Could not read kubeconfig contexts: %v\n", err)
+	} else if len(contexts) == 0 {
+		fmt.Println("ℹ️  No kubeconfig contexts found (in-cluster config may still be used)")
+	} else {
+		pass("Kubeconfig loaded with %d context(s): %s", len(contexts), strings.Join(contexts, ", "))
+		if currentContext != "" {
+			fmt.Printf("   Current context: %s\n", currentContext)
+		}
+		if cfg.Kubernetes.Context != "" {
+			idx := sort.SearchStrings(contexts, cfg.Kubernetes.Context)
+			if idx < len(contexts) && contexts[idx] == cfg.Kubernetes.Context {
+				pass("Configured context %q exists", cfg.Kubernetes.Context)
+			} else {
+				fail("Set kubernetes.context to one of the contexts listed above",
+					"Configured context %q was not found in kubeconfig", cfg.Kubernetes.Context)
+			}
+		}
+	}
+
+	// Cluster connectivity
+	k8sService, err := kubernetes.NewService(cfg.Kubernetes.ConfigPath, cfg.Kubernetes.Context, logger, cfg.KubernetesOptions()...)
+	if err != nil {
+		fail("Check kubernetes.config_path / KUBECONFIG and that the cluster API server is reachable",
+			"Kubernetes cluster is not reachable: %v", err)
+	} else {
+		pass("Kubernetes cluster is reachable")
+	}
+
+	// MCP tools
+	mcpService := mcp.NewMCPService(k8sService, logger, cfg.MCPOptions()...)
+	toolNames := make([]string, 0)
+	for _, tool := range mcpService.ListTools() {
+		toolNames = append(toolNames, tool.Name)
+	}
+	sort.Strings(toolNames)
+	if k8sService != nil {
+		pass("%d MCP tools available: %s", len(toolNames), strings.Join(toolNames, ", "))
+	} else {
+		fmt.Printf("⚠️  MCP tools registered but unavailable without a cluster: %s\n", strings.Join(toolNames, ", "))
+	}
+
+	fmt.Println()
+	if failures > 0 {
+		fmt.Printf("%d check(s) failed\n", failures)
+		os.Exit(1)
+	}
+	fmt.Println("All checks passed")
+}
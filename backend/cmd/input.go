@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// logInput is the (possibly truncated) text given to analyze
+type logInput struct {
+	Text    string
+	Lines   int
+	Dropped int
+}
+
+// readLogInput reads r keeping only its last maxLines lines (all lines if maxLines <= 0)
+func readLogInput(r io.Reader, maxLines int) (logInput, error) {
+	var lines []string
+	dropped := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if maxLines > 0 && len(lines) > maxLines {
+			lines = lines[1:]
+			dropped++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return logInput{}, err
+	}
+
+	return logInput{
+		Text:    strings.Join(lines, "\n"),
+		Lines:   len(lines),
+		Dropped: dropped,
+	}, nil
+}
+
+// openInputFile opens a file given with --input-file, checking that it is a readable regular file
+func openInputFile(path string) (*os.File, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read input file: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("cannot read input file %s: it is a directory", path)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read input file: %w", err)
+	}
+	return file, nil
+}
+
+// FirstLine returns the first non-blank line of the input
+func (in logInput) FirstLine() string {
+	for _, line := range strings.Split(in.Text, "\n") {
+		if strings.TrimSpace(line) != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// stdinIsPiped reports whether stdin is a pipe or file rather than an interactive terminal
+func stdinIsPiped() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice == 0
+}
+
+// stdoutIsTerminal reports whether stdout is an interactive terminal rather than a pipe or file
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"kube-sherlock/internal/kubernetes"
+)
+
+// promptResourceTypes asks which resource types to gather, showing the supported types numbered with
+// the defaults marked. Types may be picked by number or name, separated by commas or spaces; an empty
+// answer or end of input keeps the defaults. Invalid answers are asked again
+func promptResourceTypes(in io.Reader, out io.Writer, defaults []string) []string {
+	fmt.Fprintln(out, "Select resource types to gather (* = default):")
+	for i, resourceType := range kubernetes.SupportedResourceTypes {
+		marker := " "
+		if slices.Contains(defaults, resourceType) {
+			marker = "*"
+		}
+		fmt.Fprintf(out, "  %s %2d) %s\n", marker, i+1, resourceType)
+	}
+
+	reader := bufio.NewReader(in)
+	for {
+		fmt.Fprint(out, "Numbers or names, or group/version/resource [Enter for defaults]: ")
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if err != nil {
+				fmt.Fprintln(out)
+			}
+			return defaults
+		}
+
+		selected, selectErr := parseResourceTypeSelection(line)
+		if selectErr == nil {
+			return selected
+		}
+		fmt.Fprintf(out, "%v\n", selectErr)
+		if err != nil {
+			return defaults
+		}
+	}
+}
+
+// parseResourceTypeSelection turns an answer to promptResourceTypes into validated resource types
+func parseResourceTypeSelection(answer string) ([]string, error) {
+	var selected []string
+	for _, field := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+		resourceType := field
+		if n, err := strconv.Atoi(field); err == nil {
+			if n < 1 || n > len(kubernetes.SupportedResourceTypes) {
+				return nil, fmt.Errorf("%d is not in the list; choose 1-%d", n, len(kubernetes.SupportedResourceTypes))
+			}
+			resourceType = kubernetes.SupportedResourceTypes[n-1]
+		}
+		if !slices.Contains(selected, resourceType) {
+			selected = append(selected, resourceType)
+		}
+	}
+	if err := kubernetes.ValidateResourceTypes(selected); err != nil {
+		return nil, err
+	}
+	return selected, nil
+}
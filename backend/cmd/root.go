@@ -58,6 +58,9 @@ func initConfig() {
 	}
 
 	viper.AutomaticEnv()
+	viper.BindEnv("kubernetes.config_content", "KUBECONFIG_CONTENT")
+	viper.BindEnv("server.admin_token", "KUBE_SHERLOCK_ADMIN_TOKEN")
+	viper.BindEnv("kubernetes.impersonation_proxy_token", "KUBE_SHERLOCK_IMPERSONATION_PROXY_TOKEN")
 
 	if err := viper.ReadInConfig(); err == nil {
 		fmt.Fprintf(os.Stderr, "Using config file: %s\n", viper.ConfigFileUsed())
@@ -65,11 +68,7 @@ func initConfig() {
 
 	// Initialize logger
 	var err error
-	if viper.GetBool("verbose") {
-		logger, err = zap.NewDevelopment()
-	} else {
-		logger, err = zap.NewProduction()
-	}
+	logger, err = config.NewLogger(viper.GetBool("verbose"))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing logger: %v\n", err)
 		os.Exit(1)
@@ -41,9 +41,9 @@ func runServer(cmd *cobra.Command, args []string) {
 	cfg := config.GetConfig()
 	logger := config.GetLogger()
 
-	// Validate required configuration
-	if cfg.Gemini.APIKey == "" {
-		logger.Fatal("Gemini API key is required. Set via --gemini-api-key flag or GEMINI_API_KEY environment variable")
+	// The server can still gather resources and run tools without AI
+	if !cfg.Gemini.HasCredentials() && !cfg.Gemini.UsesMockProvider() {
+		logger.Warn("Gemini credentials not configured; AI endpoints will return 503. Set via --gemini-api-key flag or GEMINI_API_KEY environment variable, or configure gemini.credentials_file or gemini.use_adc")
 	}
 
 	// Set gin mode based on verbosity
@@ -54,7 +54,7 @@ func runServer(cmd *cobra.Command, args []string) {
 	}
 
 	// Create router
-	router := api.NewRouter(cfg, logger)
+	router, handler := api.NewRouter(cfg, logger)
 
 	// Setup server
 	addr := fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port)
@@ -69,10 +69,21 @@ func runServer(cmd *cobra.Command, args []string) {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			logger.Fatal("Server startup failed", zap.Error(err))
 		}
-	}() // Wait for interrupt signal to gracefully shutdown the server
+	}()
+
+	// Reload the config file on SIGHUP; wait for an interrupt signal to gracefully shutdown the server
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	for running := true; running; {
+		select {
+		case <-reload:
+			reloadConfig(handler, logger)
+		case <-quit:
+			running = false
+		}
+	}
 	logger.Info("Shutting down server...")
 
 	// Give outstanding requests a deadline for completion
@@ -85,3 +96,31 @@ func runServer(cmd *cobra.Command, args []string) {
 
 	logger.Info("Server exited")
 }
+
+// reloadConfig re-reads the config file, applies the log level and the gemini and MCP query settings
+// without dropping connections, and warns about changed settings that only apply after a restart
+func reloadConfig(handler *api.Handler, logger *zap.Logger) {
+	logger.Info("Reloading configuration")
+	previous, cfg, err := config.Reload()
+	if err != nil {
+		logger.Error("Config reload failed; keeping the current configuration", zap.Error(err))
+		return
+	}
+
+	if err := config.SetLogLevel(cfg.LogLevel); err != nil {
+		logger.Warn("Keeping the current log level", zap.Error(err))
+	}
+	if err := handler.ReloadAI(cfg); err != nil {
+		logger.Error("Keeping the current AI settings", zap.Error(err))
+	}
+	for _, key := range config.RestartRequired(previous, cfg) {
+		logger.Warn("Changed setting requires a restart to take effect", zap.String("key", key))
+	}
+
+	logger.Info("Configuration reloaded",
+		zap.String("model", cfg.Gemini.Model),
+		zap.Strings("fallbackModels", cfg.Gemini.FallbackModels),
+		zap.Int("maxIterations", cfg.MCP.MaxIterations),
+		zap.Duration("queryTimeout", cfg.MCP.QueryTimeout),
+		zap.Stringer("logLevel", config.LogLevel()))
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"kube-sherlock/internal/config"
+	"kube-sherlock/internal/kubernetes"
+	"kube-sherlock/internal/version"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the Kube Sherlock and Kubernetes cluster versions",
+	Args:  cobra.NoArgs,
+	Run:   runVersion,
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+
+	versionCmd.Flags().Bool("client", false, "Only print the client version without contacting the cluster")
+}
+
+func runVersion(cmd *cobra.Command, args []string) {
+	info := version.Get()
+
+	fmt.Printf("Kube Sherlock: %s (commit %s, built %s)\n", info.Version, info.Commit, info.BuildDate)
+	fmt.Printf("Go: %s\n", info.GoVersion)
+
+	if clientOnly, _ := cmd.Flags().GetBool("client"); clientOnly {
+		return
+	}
+
+	cfg := config.GetConfig()
+	k8sService, err := kubernetes.NewService(cfg.Kubernetes.ConfigPath, cfg.Kubernetes.Context, config.GetLogger(), cfg.KubernetesOptions()...)
+	if err != nil {
+		fmt.Println("Kubernetes: unavailable (cluster not reachable)")
+		return
+	}
+
+	clusterVersion, err := k8sService.ServerVersion()
+	if err != nil {
+		fmt.Printf("Kubernetes: unavailable (%v)\n", err)
+		return
+	}
+	fmt.Printf("Kubernetes: %s\n", clusterVersion)
+}
@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"kube-sherlock/internal/ai"
+	"kube-sherlock/internal/config"
+	"kube-sherlock/internal/kubernetes"
+)
+
+// defaultWatchErrorPattern matches the log lines that trigger an analysis unless --error-pattern is given
+const defaultWatchErrorPattern = `(?i)\b(error|exception|fatal|panic|failed|failure|traceback)\b`
+
+// watchSettleDelay is how long watch keeps reading after an error line before analyzing, so the stack
+// trace or follow-up lines that usually come with it are part of the context
+const watchSettleDelay = 2 * time.Second
+
+var watchCmd = &cobra.Command{
+	Use:   "watch <pod>",
+	Short: "Follow a pod's logs and analyze errors as they appear",
+	Long: `Stream a pod's logs and, when a line matches the error pattern, send the most
+recent lines to Gemini and print the likely causes and fixes inline. Analyses
+are spaced at least --cooldown apart so a burst of errors is analyzed once;
+--interval also analyzes new output periodically, whether or not it matched.
+Lines are redacted as for any other log request before they are printed or sent.
+Press Ctrl-C to stop.
+
+Examples:
+  kube-sherlock watch api-7d9f8b-x2k4p
+  kube-sherlock watch api-7d9f8b-x2k4p -n payments -c server
+  kube-sherlock watch worker-0 --error-pattern 'OOM|timed out' --interval 5m`,
+	Args: cobra.ExactArgs(1),
+	Run:  runWatch,
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().String("gemini-api-key", "", "Google AI (Gemini) API key")
+	watchCmd.Flags().StringP("namespace", "n", "", "Namespace of the pod (default: the kubeconfig context's namespace)")
+	watchCmd.Flags().StringP("container", "c", "", "Container to follow; required when the pod has several")
+	watchCmd.Flags().String("error-pattern", defaultWatchErrorPattern, "Regular expression for log lines that trigger an analysis")
+	watchCmd.Flags().Int("context-lines", 50, "Send this many of the most recent lines with each analysis")
+	watchCmd.Flags().Int64("tail", 10, "Start with this many of the existing lines")
+	watchCmd.Flags().Duration("cooldown", 30*time.Second, "Wait at least this long between analyses")
+	watchCmd.Flags().Duration("interval", 0, "Also analyze new lines this often even when none matched (0 analyzes only on matches)")
+
+	viper.BindPFlag("gemini.api_key", watchCmd.Flags().Lookup("gemini-api-key"))
+}
+
+// logWatch holds the state of a running watch: the recent lines and when they were last analyzed
+type logWatch struct {
+	aiService    *ai.Service
+	subject      string
+	contextLines int
+	cooldown     time.Duration
+
+	recent       []string
+	newLines     int
+	lastAnalysis time.Time
+	analyzing    bool
+	results      chan watchAnalysis
+}
+
+// watchAnalysis is the outcome of one analysis run in the background
+type watchAnalysis struct {
+	lines    int
+	response *ai.TroubleshootResponse
+	err      error
+}
+
+func runWatch(cmd *cobra.Command, args []string) {
+	cfg := config.GetConfig()
+	logger := config.GetLogger()
+	podName := args[0]
+
+	namespace, _ := cmd.Flags().GetString("namespace")
+	container, _ := cmd.Flags().GetString("container")
+	pattern, _ := cmd.Flags().GetString("error-pattern")
+	contextLines, _ := cmd.Flags().GetInt("context-lines")
+	tail, _ := cmd.Flags().GetInt64("tail")
+	cooldown, _ := cmd.Flags().GetDuration("cooldown")
+	interval, _ := cmd.Flags().GetDuration("interval")
+
+	errorPattern, err := regexp.Compile(pattern)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --error-pattern: %v\n", err)
+		os.Exit(1)
+	}
+	if contextLines < 1 {
+		fmt.Fprintf(os.Stderr, "Error: --context-lines must be at least 1\n")
+		os.Exit(1)
+	}
+	if tail < 0 || cooldown < 0 || interval < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --tail, --cooldown and --interval can't be negative\n")
+		os.Exit(1)
+	}
+
+	aiService, err := ai.NewService(cfg.Gemini.APIKey, cfg.Gemini.Model, logger, cfg.AIOptions()...)
+	if errors.Is(err, ai.ErrNoAPIKey) {
+		fmt.Fprintf(os.Stderr, "Error: Gemini API key is required. Set via --gemini-api-key flag or GEMINI_API_KEY environment variable, or configure gemini.credentials_file or gemini.use_adc\n")
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing AI service: %v\n", err)
+		os.Exit(1)
+	}
+	defer aiService.Close()
+
+	k8sService, err := kubernetes.NewService(cfg.Kubernetes.ConfigPath, cfg.Kubernetes.Context, logger, cfg.KubernetesOptions()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: watch requires a reachable Kubernetes cluster: %v\n", err)
+		os.Exit(1)
+	}
+	if namespace == "" {
+		namespace = k8sService.DefaultNamespace()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	subject := fmt.Sprintf("pod %s/%s", namespace, podName)
+	if container != "" {
+		subject = fmt.Sprintf("container %s of pod %s/%s", container, namespace, podName)
+	}
+	watch := &logWatch{
+		aiService:    aiService,
+		subject:      subject,
+		contextLines: contextLines,
+		cooldown:     cooldown,
+		results:      make(chan watchAnalysis, 1),
+	}
+
+	// Lines are read in the background so following the logs never waits for an analysis
+	lines := make(chan string, 256)
+	streamDone := make(chan error, 1)
+	go func() {
+		streamDone <- k8sService.FollowPodLogs(ctx, namespace, podName, container, tail, func(line string) {
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+			}
+		})
+		close(lines)
+	}()
+
+	fmt.Fprintf(os.Stderr, "👀 Watching %s for lines matching %s (Ctrl-C to stop)\n", subject, errorPattern)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	// settle fires once an error line has had time to collect its follow-up lines; it is nil while no
+	// analysis is pending
+	var settle <-chan time.Time
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				// Errors written just before the container stopped are analyzed without waiting out the cooldown
+				if watch.analyzing {
+					watch.printResult(<-watch.results)
+				}
+				if settle != nil && ctx.Err() == nil {
+					watch.analyze(ctx)
+					watch.printResult(<-watch.results)
+				}
+				if err := <-streamDone; err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+				if ctx.Err() == nil {
+					fmt.Fprintf(os.Stderr, "Log stream of %s ended\n", subject)
+				}
+				return
+			}
+			fmt.Println(line)
+			watch.add(line)
+			if settle == nil && errorPattern.MatchString(line) {
+				settle = time.After(watchSettleDelay)
+			}
+
+		case <-settle:
+			settle = nil
+			if watch.analyzing {
+				// Checked again when the running analysis finishes
+				settle = time.After(watchSettleDelay)
+				continue
+			}
+			if wait := watch.cooldownLeft(); wait > 0 {
+				settle = time.After(wait)
+				continue
+			}
+			watch.analyze(ctx)
+
+		case <-tick:
+			if watch.newLines > 0 && !watch.analyzing && settle == nil && watch.cooldownLeft() == 0 {
+				watch.analyze(ctx)
+			}
+
+		case result := <-watch.results:
+			watch.printResult(result)
+
+		case <-ctx.Done():
+			fmt.Fprintln(os.Stderr)
+			return
+		}
+	}
+}
+
+// add keeps line among the most recent contextLines lines
+func (w *logWatch) add(line string) {
+	w.recent = append(w.recent, line)
+	if len(w.recent) > w.contextLines {
+		w.recent = w.recent[len(w.recent)-w.contextLines:]
+	}
+	w.newLines++
+}
+
+// cooldownLeft returns how long until the next analysis may start
+func (w *logWatch) cooldownLeft() time.Duration {
+	if w.lastAnalysis.IsZero() {
+		return 0
+	}
+	if wait := w.cooldown - time.Since(w.lastAnalysis); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// analyze sends the recent lines to Gemini in the background; the result arrives on w.results
+func (w *logWatch) analyze(ctx context.Context) {
+	message := fmt.Sprintf("Recent log lines of %s:\n\n%s", w.subject, strings.Join(w.recent, "\n"))
+	lines := len(w.recent)
+	w.analyzing = true
+	w.newLines = 0
+	w.lastAnalysis = time.Now()
+
+	fmt.Fprintf(os.Stderr, "🔍 Analyzing the last %d lines...\n", lines)
+	go func() {
+		response, err := w.aiService.TroubleshootError(ctx, message)
+		w.results <- watchAnalysis{lines: lines, response: response, err: err}
+	}()
+}
+
+// printResult prints a finished analysis between the log lines
+func (w *logWatch) printResult(result watchAnalysis) {
+	w.analyzing = false
+	if result.err != nil {
+		if !errors.Is(result.err, context.Canceled) {
+			fmt.Fprintf(os.Stderr, "Warning: analysis failed: %v\n", result.err)
+		}
+		return
+	}
+
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Printf("💡 Potential Causes (last %d lines):\n", result.lines)
+	for i, cause := range result.response.PotentialCauses {
+		if i < len(result.response.Causes) {
+			fmt.Printf("%d. %s (confidence: %.0f%%)\n", i+1, cause, result.response.Causes[i].Confidence*100)
+		} else {
+			fmt.Printf("%d. %s\n", i+1, cause)
+		}
+	}
+	fmt.Println("🔧 Suggested Solutions:")
+	for i, solution := range result.response.SuggestedSolutions {
+		fmt.Printf("%d. %s%s\n", i+1, solution, solutionHints(result.response.Solutions, i))
+	}
+	fmt.Println(strings.Repeat("=", 60))
+}
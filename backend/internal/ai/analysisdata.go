@@ -0,0 +1,67 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// defaultAnalysisDataBytes is the most gathered tool output embedded in a query analysis prompt when not
+// configured, roughly 50k tokens
+const defaultAnalysisDataBytes = 200 * 1024
+
+// truncatedMarker ends tool output that was cut to fit the analysis data budget
+const truncatedMarker = "\n[truncated]"
+
+// WithAnalysisDataBudget sets the most gathered tool output, in bytes, embedded verbatim in a query's analysis
+// prompt. Larger output is summarized first, or truncated when summarizing fails. Non-positive values keep the default
+func WithAnalysisDataBudget(bytes int) Option {
+	return func(s *Service) {
+		if bytes > 0 {
+			s.analysisDataBytes = bytes
+		}
+	}
+}
+
+// analysisData returns tool output that fits the analysis data budget, or the model's context window when
+// that is smaller. Output over the budget is mostly JSON, so it is summarized to keep the relevant details;
+// if that fails, or the summary is still too big, the output is truncated at a line boundary and marked
+func (s *Service) analysisData(ctx context.Context, toolOutput string) string {
+	budget := s.dataBudgetBytes(s.analysisDataBytes)
+	if len(toolOutput) <= budget {
+		return toolOutput
+	}
+
+	s.log(ctx).Info("Tool output exceeds the analysis data budget, summarizing",
+		zap.Int("bytes", len(toolOutput)),
+		zap.Int("budget", budget))
+	resp, err := s.SummarizeResourceData(ctx, toolOutput)
+	if err == nil && len(resp.Summary) <= budget {
+		return "Summary of the gathered data:\n" + resp.Summary
+	}
+	if err != nil {
+		s.log(ctx).Warn("Failed to summarize tool output, truncating", zap.Error(err))
+	} else {
+		s.log(ctx).Warn("Summary of tool output exceeds the analysis data budget, truncating", zap.Int("bytes", len(resp.Summary)))
+	}
+	return truncateData(toolOutput, budget)
+}
+
+// truncateData cuts data to at most limit bytes including the truncated marker, at the last line break
+// when there is one and otherwise at a UTF-8 boundary
+func truncateData(data string, limit int) string {
+	if len(data) <= limit {
+		return data
+	}
+	cut := max(limit-len(truncatedMarker), 0)
+	for cut > 0 && !utf8.RuneStart(data[cut]) {
+		cut--
+	}
+	head := data[:cut]
+	if line := strings.LastIndexByte(head, '\n'); line > 0 {
+		head = head[:line]
+	}
+	return head + truncatedMarker
+}
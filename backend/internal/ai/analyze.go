@@ -0,0 +1,174 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+
+	"kube-sherlock/internal/kubernetes"
+)
+
+// Inputs longer than largeInputLines are condensed before troubleshooting when SummarizeLargeInput is set;
+// the condensed form keeps the summaryTailLines most recent lines verbatim
+const (
+	largeInputLines  = 50
+	summaryTailLines = 20
+)
+
+// ResourceGatherer gathers cluster resources for the analysis pipeline. *kubernetes.Service implements it
+type ResourceGatherer interface {
+	Gather(ctx context.Context, opts kubernetes.GatherOptions) (*kubernetes.GatherResourcesResponse, error)
+}
+
+// AnalyzeOptions describes a full analysis run
+type AnalyzeOptions struct {
+	ErrorMessage string
+	// SummarizeLargeInput condenses multi-line inputs such as log excerpts before troubleshooting them
+	SummarizeLargeInput bool
+	// Passes runs the troubleshoot prompt this many times and merges the results; zero or one is a single pass
+	Passes int
+	// GatherResources enables gathering and summarizing cluster resources for context
+	GatherResources bool
+	Gather          kubernetes.GatherOptions
+	// Progress, if set, is called as each step starts
+	Progress func(message string)
+}
+
+// AnalysisResult is the combined outcome of the analysis pipeline
+type AnalysisResult struct {
+	Troubleshoot    *TroubleshootResponse      `json:"troubleshoot"`
+	Suggestions     *SuggestResourcesResponse  `json:"suggestions"`
+	ResourceSummary string                     `json:"resourceSummary,omitempty"`
+	UnhealthyPods   []string                   `json:"unhealthyPods,omitempty"`
+	GatherMetadata  *kubernetes.GatherMetadata `json:"gatherMetadata,omitempty"`
+	// Warnings describes optional steps that failed without failing the analysis
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// HasIssues reports whether the analysis found potential causes or unhealthy pods
+func (r *AnalysisResult) HasIssues() bool {
+	return len(r.Troubleshoot.PotentialCauses) > 0 || len(r.UnhealthyPods) > 0
+}
+
+// Analyze runs the troubleshoot, suggest resources, gather and summarize pipeline. Troubleshooting and
+// suggestions are required; input condensing, gathering and summarizing are best effort and reported as
+// warnings. gatherer may be nil, in which case gathering is skipped with a warning
+func (s *Service) Analyze(ctx context.Context, gatherer ResourceGatherer, opts AnalyzeOptions) (*AnalysisResult, error) {
+	progress := func(message string) {
+		if opts.Progress != nil {
+			opts.Progress(message)
+		}
+	}
+
+	result := &AnalysisResult{}
+
+	errorMessage := opts.ErrorMessage
+	if lines := strings.Split(errorMessage, "\n"); opts.SummarizeLargeInput && len(lines) > largeInputLines {
+		progress("Summarizing large input")
+		summaryResp, err := s.SummarizeResourceData(ctx, errorMessage)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Failed to summarize input, analyzed it as-is: %v", err))
+		} else {
+			errorMessage = fmt.Sprintf("Summary of a %d-line log excerpt:\n%s\n\nMost recent lines:\n%s",
+				len(lines), summaryResp.Summary, strings.Join(lines[len(lines)-summaryTailLines:], "\n"))
+		}
+	}
+
+	progress("Analyzing the error")
+	troubleshootResp, err := s.TroubleshootErrorPasses(ctx, errorMessage, opts.Passes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to troubleshoot error: %w", err)
+	}
+	if opts.Passes > 1 && troubleshootResp.Passes > 0 && troubleshootResp.Passes < opts.Passes {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("%d of %d troubleshoot passes failed; merged the other %d",
+			opts.Passes-troubleshootResp.Passes, opts.Passes, troubleshootResp.Passes))
+	}
+	result.Troubleshoot = troubleshootResp
+
+	progress("Suggesting resources to check")
+	suggestResp, err := s.SuggestResources(ctx, errorMessage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest resources: %w", err)
+	}
+	result.Suggestions = suggestResp
+
+	if !opts.GatherResources {
+		return result, nil
+	}
+	if gatherer == nil {
+		result.Warnings = append(result.Warnings, "Kubernetes cluster not available; resources were not gathered")
+		return result, nil
+	}
+
+	progress("Gathering Kubernetes resources")
+	// Gathered objects only feed the summarization prompt, so bookkeeping metadata is dropped
+	gatherOpts := opts.Gather
+	gatherOpts.Minimize = true
+	resources, err := gatherer.Gather(ctx, gatherOpts)
+	if err != nil {
+		s.log(ctx).Warn("Failed to gather resources for analysis", zap.Error(err))
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Failed to gather resources: %v", err))
+		return result, nil
+	}
+	result.GatherMetadata = &resources.Metadata
+	for namespace, group := range resources.ByNamespace() {
+		pods, ok := group["pods"].(*v1.PodList)
+		if !ok {
+			continue
+		}
+		for _, pod := range kubernetes.UnhealthyPods(pods) {
+			// Pods are named with their namespace when several namespaces were gathered
+			if len(resources.Metadata.Namespaces) > 0 {
+				pod = namespace + "/" + pod
+			}
+			result.UnhealthyPods = append(result.UnhealthyPods, pod)
+		}
+	}
+	sort.Strings(result.UnhealthyPods)
+
+	progress("Summarizing gathered resources")
+	summaryResp, err := s.SummarizeResourceSections(ctx, gatheredSections(resources))
+	if err != nil {
+		s.log(ctx).Warn("Failed to summarize resources for analysis", zap.Error(err))
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Failed to summarize resource data: %v", err))
+		return result, nil
+	}
+	result.ResourceSummary = summaryResp.Summary
+
+	return result, nil
+}
+
+// gatheredSections splits gathered resources into one JSON section per resource type so large gathers are
+// summarized in chunks that keep track of the resource type they came from. Multi-namespace gathers get a
+// section per namespace and type. The resources should already be minimized, as the MCP tools' are
+func gatheredSections(resources *kubernetes.GatherResourcesResponse) []ResourceSection {
+	sections := []ResourceSection{{Source: "gather metadata", Data: resourceJSON(resources.Metadata)}}
+	for namespace, group := range resources.ByNamespace() {
+		for key, value := range group {
+			source := key
+			if len(resources.Metadata.Namespaces) > 0 {
+				source = namespace + "/" + key
+			}
+			sections = append(sections, ResourceSection{Source: source, Data: resourceJSON(value)})
+		}
+	}
+	sort.Slice(sections[1:], func(i, j int) bool {
+		return sections[i+1].Source < sections[j+1].Source
+	})
+	return sections
+}
+
+// resourceJSON renders a gathered value as indented JSON for the model. Go's %+v form is full of
+// pointers and type noise that confuses it
+func resourceJSON(value interface{}) string {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("(could not be serialized: %v)", err)
+	}
+	return string(data)
+}
@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"kube-sherlock/internal/kubernetes"
+)
+
+func TestGatheredSectionsAreJSON(t *testing.T) {
+	pods := &v1.PodList{Items: []v1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "shop"},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "api", Image: "api:1.0"}}},
+		Status:     v1.PodStatus{Phase: v1.PodRunning},
+	}}}
+	events := &v1.EventList{Items: []v1.Event{{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-1.1", Namespace: "billing"},
+		Reason:     "BackOff",
+		Message:    "Back-off restarting failed container",
+	}}}
+
+	tests := []struct {
+		name      string
+		resources *kubernetes.GatherResourcesResponse
+		sources   []string
+	}{
+		{
+			name: "single namespace",
+			resources: &kubernetes.GatherResourcesResponse{
+				Resources: map[string]interface{}{
+					"pods":              pods,
+					"deployments_error": "deployments.apps is forbidden",
+				},
+				Metadata: kubernetes.GatherMetadata{Namespace: "shop", ClusterContext: "prod"},
+			},
+			sources: []string{"gather metadata", "deployments_error", "pods"},
+		},
+		{
+			name: "multiple namespaces",
+			resources: &kubernetes.GatherResourcesResponse{
+				Resources: map[string]interface{}{
+					"shop":    map[string]interface{}{"pods": pods},
+					"billing": map[string]interface{}{"events": events},
+				},
+				Metadata: kubernetes.GatherMetadata{Namespaces: []string{"billing", "shop"}},
+			},
+			sources: []string{"gather metadata", "billing/events", "shop/pods"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sections := gatheredSections(tt.resources)
+
+			var sources []string
+			for _, section := range sections {
+				sources = append(sources, section.Source)
+				if !json.Valid([]byte(section.Data)) {
+					t.Errorf("section %s is not valid JSON:\n%s", section.Source, section.Data)
+				}
+				if strings.HasPrefix(section.Data, "&{") || strings.HasPrefix(section.Data, "map[") {
+					t.Errorf("section %s is a Go value dump:\n%s", section.Source, section.Data)
+				}
+			}
+			if strings.Join(sources, ",") != strings.Join(tt.sources, ",") {
+				t.Errorf("sources = %v, want %v", sources, tt.sources)
+			}
+		})
+	}
+}
+
+func TestGatheredSectionsRoundTrip(t *testing.T) {
+	pods := &v1.PodList{Items: []v1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "shop"}}}}
+	sections := gatheredSections(&kubernetes.GatherResourcesResponse{
+		Resources: map[string]interface{}{"pods": pods},
+		Metadata:  kubernetes.GatherMetadata{Namespace: "shop"},
+	})
+
+	var decoded v1.PodList
+	if err := json.Unmarshal([]byte(sections[1].Data), &decoded); err != nil {
+		t.Fatalf("pods section doesn't decode as a PodList: %v", err)
+	}
+	if len(decoded.Items) != 1 || decoded.Items[0].Name != "api-1" {
+		t.Errorf("decoded pods = %+v, want api-1", decoded.Items)
+	}
+
+	var metadata kubernetes.GatherMetadata
+	if err := json.Unmarshal([]byte(sections[0].Data), &metadata); err != nil || metadata.Namespace != "shop" {
+		t.Errorf("metadata section = %s, want the gather metadata as JSON", sections[0].Data)
+	}
+}
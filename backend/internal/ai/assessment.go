@@ -0,0 +1,93 @@
+package ai
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// troubleshootAssessment is the JSON the troubleshoot prompt asks for
+type troubleshootAssessment struct {
+	PotentialCauses    []assessedItem `json:"potentialCauses"`
+	SuggestedSolutions []assessedItem `json:"suggestedSolutions"`
+}
+
+// assessedItem is a cause or solution. Models sometimes answer with plain strings as the prompt used
+// to ask, so a string is accepted as an item with no assessment
+type assessedItem struct {
+	Cause      string   `json:"cause"`
+	Solution   string   `json:"solution"`
+	Confidence *float64 `json:"confidence"`
+	Effort     string   `json:"effort"`
+	Risk       string   `json:"risk"`
+}
+
+// UnmarshalJSON accepts an assessed object or a plain string
+func (i *assessedItem) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*i = assessedItem{Cause: text, Solution: text}
+		return nil
+	}
+	type plain assessedItem
+	return json.Unmarshal(data, (*plain)(i))
+}
+
+// assessmentLevels are the accepted effort and risk hints
+var assessmentLevels = map[string]bool{"low": true, "medium": true, "high": true}
+
+// response converts the assessment to a TroubleshootResponse with causes sorted by confidence, most likely
+// first. Causes without a confidence keep their place after those with one
+func (a troubleshootAssessment) response() TroubleshootResponse {
+	result := TroubleshootResponse{
+		PotentialCauses:    []string{},
+		SuggestedSolutions: []string{},
+	}
+
+	var causes []CauseAssessment
+	var unrated []string
+	for _, item := range a.PotentialCauses {
+		cause := strings.TrimSpace(item.Cause)
+		if cause == "" {
+			continue
+		}
+		if item.Confidence == nil {
+			unrated = append(unrated, cause)
+			continue
+		}
+		causes = append(causes, CauseAssessment{Cause: cause, Confidence: min(max(*item.Confidence, 0), 1)})
+	}
+	sort.SliceStable(causes, func(i, j int) bool {
+		return causes[i].Confidence > causes[j].Confidence
+	})
+	for _, cause := range causes {
+		result.PotentialCauses = append(result.PotentialCauses, cause.Cause)
+	}
+	result.PotentialCauses = append(result.PotentialCauses, unrated...)
+	if len(unrated) == 0 {
+		result.Causes = causes
+	}
+
+	var solutions []SolutionAssessment
+	rated := false
+	for _, item := range a.SuggestedSolutions {
+		solution := strings.TrimSpace(item.Solution)
+		if solution == "" {
+			continue
+		}
+		assessment := SolutionAssessment{Solution: solution}
+		if effort := strings.ToLower(item.Effort); assessmentLevels[effort] {
+			assessment.Effort = effort
+		}
+		if risk := strings.ToLower(item.Risk); assessmentLevels[risk] {
+			assessment.Risk = risk
+		}
+		rated = rated || assessment.Effort != "" || assessment.Risk != ""
+		solutions = append(solutions, assessment)
+		result.SuggestedSolutions = append(result.SuggestedSolutions, solution)
+	}
+	if rated {
+		result.Solutions = solutions
+	}
+	return result
+}
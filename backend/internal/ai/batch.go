@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// maxBatchWorkers bounds how many errors TroubleshootErrors sends to Gemini at once
+const maxBatchWorkers = 4
+
+// BatchTroubleshootResult is the outcome of troubleshooting one error of a batch
+type BatchTroubleshootResult struct {
+	// Index is the error's position in the batch
+	Index    int                   `json:"index"`
+	Response *TroubleshootResponse `json:"response,omitempty"`
+	// Err is set instead of Response when this error could not be troubleshot
+	Err error `json:"-"`
+}
+
+// TroubleshootErrors troubleshoots several errors concurrently with a bounded worker pool and returns one
+// result per error, in input order. A failure only affects its own result; identical messages are
+// troubleshot once and share the result. Errors not started before ctx ends get ctx's error
+func (s *Service) TroubleshootErrors(ctx context.Context, errorMessages []string) []BatchTroubleshootResult {
+	results := make([]BatchTroubleshootResult, len(errorMessages))
+
+	// Each distinct message is troubleshot once; first maps it to its first index
+	first := make(map[string]int, len(errorMessages))
+	var unique []int
+	for i, message := range errorMessages {
+		results[i].Index = i
+		if _, ok := first[message]; !ok {
+			first[message] = i
+			unique = append(unique, i)
+		}
+	}
+
+	s.log(ctx).Info("Troubleshooting error batch",
+		zap.Int("errors", len(errorMessages)),
+		zap.Int("distinct", len(unique)))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	workers := min(maxBatchWorkers, len(unique))
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					results[i].Err = err
+					continue
+				}
+				results[i].Response, results[i].Err = s.TroubleshootError(ctx, errorMessages[i])
+			}
+		}()
+	}
+	for _, i := range unique {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, message := range errorMessages {
+		if j := first[message]; j != i {
+			results[i].Response, results[i].Err = results[j].Response, results[j].Err
+		}
+	}
+	return results
+}
@@ -0,0 +1,106 @@
+package ai
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultTroubleshootCacheTTL is how long cached troubleshoot responses are reused when no TTL is configured
+const defaultTroubleshootCacheTTL = time.Hour
+
+// WithTroubleshootCache caches up to size TroubleshootError responses for ttl, so repeated analyses of
+// the same error don't each call the model. A non-positive size leaves caching off; a non-positive ttl
+// uses one hour
+func WithTroubleshootCache(size int, ttl time.Duration) Option {
+	return func(s *Service) {
+		if size <= 0 {
+			return
+		}
+		if ttl <= 0 {
+			ttl = defaultTroubleshootCacheTTL
+		}
+		s.troubleshootCache = newResponseCache(size, ttl)
+	}
+}
+
+// troubleshootCacheKey identifies a troubleshoot request by its error message, with case and whitespace
+// normalized, the model and the system prompt, which all shape the response
+func (s *Service) troubleshootCacheKey(ctx context.Context, errorMessage string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(errorMessage), " "))
+	return s.model + "\x00" + s.applySystemPrompt(ctx, "") + "\x00" + normalized
+}
+
+// responseCache is a least recently used cache of troubleshoot responses whose entries expire after ttl.
+// A nil cache stores nothing
+type responseCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// cacheEntry is a cached response and when it stops being served
+type cacheEntry struct {
+	key      string
+	response TroubleshootResponse
+	expires  time.Time
+}
+
+func newResponseCache(size int, ttl time.Duration) *responseCache {
+	return &responseCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+// get returns a copy of the response cached under key, marked as cached, if it hasn't expired
+func (c *responseCache) get(key string) (*TroubleshootResponse, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	response := entry.response
+	response.Cached = true
+	return &response, true
+}
+
+// put caches response under key, evicting the least recently used entry when the cache is full
+func (c *responseCache) put(key string, response *TroubleshootResponse) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &cacheEntry{key: key, response: *response, expires: time.Now().Add(c.ttl)}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
@@ -0,0 +1,316 @@
+package ai
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxSuggestedCommands caps how many commands are extracted from a single response
+const maxSuggestedCommands = 20
+
+// ResponseProcessor derives structured fields from the text of an AI response. Processors run after
+// the model answers and must not change the text itself
+type ResponseProcessor func(text string, extras *ResponseExtras)
+
+// ResponseExtras holds the structured fields filled in by response processors
+type ResponseExtras struct {
+	// Commands are the kubectl and shell commands suggested in the response, in the order they appear
+	Commands []SuggestedCommand `json:"commands,omitempty"`
+}
+
+// SuggestedCommand is a command found in an AI response
+type SuggestedCommand struct {
+	Command string `json:"command"`
+	// ReadOnly is a verb-based guess that running the command doesn't change the cluster. Commands that
+	// aren't recognized are treated as mutating
+	ReadOnly bool `json:"readOnly"`
+}
+
+// WithResponseProcessors adds processors that run after the built-in command extraction
+func WithResponseProcessors(processors ...ResponseProcessor) Option {
+	return func(s *Service) {
+		for _, processor := range processors {
+			if processor != nil {
+				s.responseProcessors = append(s.responseProcessors, processor)
+			}
+		}
+	}
+}
+
+// processResponse runs the response processors over text
+func (s *Service) processResponse(text string, extras *ResponseExtras) {
+	for _, processor := range s.responseProcessors {
+		processor(text, extras)
+	}
+}
+
+// ExtractCommands is the built-in response processor that collects suggested commands
+func ExtractCommands(text string, extras *ResponseExtras) {
+	seen := make(map[string]bool, len(extras.Commands))
+	for _, command := range extras.Commands {
+		seen[command.Command] = true
+	}
+	for _, command := range findCommands(text) {
+		if len(extras.Commands) >= maxSuggestedCommands {
+			return
+		}
+		if seen[command] {
+			continue
+		}
+		seen[command] = true
+		extras.Commands = append(extras.Commands, SuggestedCommand{Command: command, ReadOnly: isReadOnlyCommand(command)})
+	}
+}
+
+// fencePattern matches a fenced code block and captures its language and body
+var fencePattern = regexp.MustCompile("(?s)```([A-Za-z0-9_+-]*)[^\\n]*\\n(.*?)```")
+
+// inlineCodePattern matches an inline code span
+var inlineCodePattern = regexp.MustCompile("`([^`\\n]+)`")
+
+// shellFenceLanguages are code block languages whose every line is a shell command
+var shellFenceLanguages = map[string]bool{
+	"":        true,
+	"bash":    true,
+	"sh":      true,
+	"shell":   true,
+	"console": true,
+	"zsh":     true,
+}
+
+// commandPrograms are the programs recognized in inline code and prose, where most text isn't a command
+var commandPrograms = map[string]bool{
+	"kubectl": true,
+	"helm":    true,
+}
+
+// findCommands returns the commands in fenced shell blocks, inline code spans and prose lines, in order
+func findCommands(text string) []string {
+	var commands []string
+	prose := 0
+	for _, match := range fencePattern.FindAllStringSubmatchIndex(text, -1) {
+		commands = append(commands, proseCommands(text[prose:match[0]])...)
+		commands = append(commands, fenceCommands(strings.ToLower(text[match[2]:match[3]]), text[match[4]:match[5]])...)
+		prose = match[1]
+	}
+	return append(commands, proseCommands(text[prose:])...)
+}
+
+// fenceCommands returns the commands in the body of a fenced code block
+func fenceCommands(language, body string) []string {
+	if !shellFenceLanguages[language] {
+		return nil
+	}
+	lines := joinContinuations(body)
+	// When some lines carry a prompt, the others are output
+	prompted := false
+	for _, line := range lines {
+		prompted = prompted || strings.HasPrefix(line, "$ ")
+	}
+
+	var commands []string
+	for _, line := range lines {
+		if prompted && !strings.HasPrefix(line, "$ ") {
+			continue
+		}
+		command := trimPrompt(line)
+		if command == "" || strings.HasPrefix(command, "#") {
+			continue
+		}
+		// Untagged blocks often hold YAML or output, so only known programs are taken from them
+		if language != "" || isKnownCommand(command) {
+			commands = append(commands, command)
+		}
+	}
+	return commands
+}
+
+// proseCommands returns the known commands in inline code spans, or on their own line, in prose
+func proseCommands(prose string) []string {
+	var commands []string
+	for _, line := range strings.Split(prose, "\n") {
+		spans := inlineCodePattern.FindAllStringSubmatch(line, -1)
+		for _, span := range spans {
+			if command := trimPrompt(span[1]); isKnownCommand(command) {
+				commands = append(commands, command)
+			}
+		}
+		if len(spans) > 0 {
+			continue
+		}
+		// A bare command on its own line, possibly as a list item
+		command := trimPrompt(strings.TrimLeft(line, "-*0123456789. \t"))
+		if isKnownCommand(command) {
+			commands = append(commands, command)
+		}
+	}
+	return commands
+}
+
+// joinContinuations splits a code block into lines, joining lines that end with a backslash
+func joinContinuations(block string) []string {
+	var lines []string
+	var current strings.Builder
+	for _, line := range strings.Split(block, "\n") {
+		line = strings.TrimSpace(line)
+		if continued, ok := strings.CutSuffix(line, "\\"); ok {
+			current.WriteString(strings.TrimSpace(continued) + " ")
+			continue
+		}
+		current.WriteString(line)
+		lines = append(lines, strings.TrimSpace(current.String()))
+		current.Reset()
+	}
+	if current.Len() > 0 {
+		lines = append(lines, strings.TrimSpace(current.String()))
+	}
+	return lines
+}
+
+// trimPrompt strips surrounding space and a leading shell prompt from a command
+func trimPrompt(command string) string {
+	command = strings.TrimSpace(command)
+	for _, prompt := range []string{"$ ", "# ", "> "} {
+		if rest, ok := strings.CutPrefix(command, prompt); ok && isKnownCommand(strings.TrimSpace(rest)) {
+			return strings.TrimSpace(rest)
+		}
+	}
+	return strings.TrimPrefix(command, "$ ")
+}
+
+// isKnownCommand reports whether a command runs one of commandPrograms
+func isKnownCommand(command string) bool {
+	fields := strings.Fields(command)
+	return len(fields) > 1 && commandPrograms[fields[0]]
+}
+
+// readOnlyKubectlVerbs are kubectl subcommands that only read cluster state
+var readOnlyKubectlVerbs = map[string]bool{
+	"get":           true,
+	"describe":      true,
+	"logs":          true,
+	"top":           true,
+	"explain":       true,
+	"events":        true,
+	"diff":          true,
+	"wait":          true,
+	"api-resources": true,
+	"api-versions":  true,
+	"cluster-info":  true,
+	"version":       true,
+}
+
+// readOnlyKubectlSubcommands are read-only subcommands of kubectl verbs that can also mutate
+var readOnlyKubectlSubcommands = map[string]map[string]bool{
+	"auth":    {"can-i": true, "whoami": true},
+	"config":  {"view": true, "get-contexts": true, "current-context": true, "get-clusters": true},
+	"rollout": {"status": true, "history": true},
+}
+
+// readOnlyHelmVerbs are helm subcommands that only read release state
+var readOnlyHelmVerbs = map[string]bool{
+	"list":     true,
+	"ls":       true,
+	"status":   true,
+	"get":      true,
+	"history":  true,
+	"show":     true,
+	"template": true,
+	"search":   true,
+	"version":  true,
+}
+
+// readOnlyShellPrograms are programs commonly piped after kubectl that don't change anything
+var readOnlyShellPrograms = map[string]bool{
+	"grep":   true,
+	"egrep":  true,
+	"jq":     true,
+	"yq":     true,
+	"head":   true,
+	"tail":   true,
+	"sort":   true,
+	"uniq":   true,
+	"wc":     true,
+	"cut":    true,
+	"awk":    true,
+	"less":   true,
+	"cat":    true,
+	"echo":   true,
+	"base64": true,
+}
+
+// kubectlValueFlags are global kubectl flags that take a separate value and can precede the verb
+var kubectlValueFlags = map[string]bool{
+	"-n":           true,
+	"--namespace":  true,
+	"--context":    true,
+	"--kubeconfig": true,
+	"--cluster":    true,
+	"--user":       true,
+	"-s":           true,
+	"--server":     true,
+}
+
+// commandSeparator splits a shell line into the commands of a pipeline or list
+var commandSeparator = regexp.MustCompile(`\|\||&&|[|;]`)
+
+// isReadOnlyCommand guesses from the verbs whether every part of a shell line is read-only
+func isReadOnlyCommand(command string) bool {
+	// Redirecting output writes files, and command substitution can run anything
+	if strings.ContainsAny(command, ">`") || strings.Contains(command, "$(") {
+		return false
+	}
+	for _, part := range commandSeparator.Split(command, -1) {
+		if !isReadOnlySimpleCommand(strings.Fields(part)) {
+			return false
+		}
+	}
+	return true
+}
+
+// isReadOnlySimpleCommand classifies a single command given as its words
+func isReadOnlySimpleCommand(words []string) bool {
+	if len(words) == 0 {
+		return false
+	}
+	switch words[0] {
+	case "kubectl":
+		args := positionalArgs(words[1:])
+		if len(args) == 0 {
+			return false
+		}
+		if subcommands, ok := readOnlyKubectlSubcommands[args[0]]; ok {
+			return len(args) > 1 && subcommands[args[1]]
+		}
+		return readOnlyKubectlVerbs[args[0]]
+	case "helm":
+		args := positionalArgs(words[1:])
+		return len(args) > 0 && readOnlyHelmVerbs[args[0]]
+	case "sed":
+		// sed only writes files when editing in place
+		for _, word := range words[1:] {
+			if strings.HasPrefix(word, "-i") || strings.HasPrefix(word, "--in-place") {
+				return false
+			}
+		}
+		return true
+	default:
+		return readOnlyShellPrograms[words[0]]
+	}
+}
+
+// positionalArgs drops flags, and the values of flags known to take one, from command arguments
+func positionalArgs(words []string) []string {
+	var args []string
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if strings.HasPrefix(word, "-") {
+			if kubectlValueFlags[word] {
+				i++
+			}
+			continue
+		}
+		args = append(args, word)
+	}
+	return args
+}
@@ -0,0 +1,55 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"kube-sherlock/internal/metrics"
+)
+
+// WithMaxConcurrentRequests bounds the Gemini requests the service has in flight at once; further requests
+// queue until a slot frees up or their context ends. A non-positive n leaves requests unbounded
+func WithMaxConcurrentRequests(n int) Option {
+	return func(s *Service) {
+		if n > 0 {
+			s.requestSlots = make(chan struct{}, n)
+		}
+	}
+}
+
+// acquireRequestSlot waits for a free Gemini request slot and returns the function that frees it. Waiting
+// stops with an error when ctx ends, so queued requests never outlive their request timeout
+func (s *Service) acquireRequestSlot(ctx context.Context, operation string) (func(), error) {
+	if s.requestSlots == nil {
+		return func() {}, nil
+	}
+	release := func() { <-s.requestSlots }
+
+	select {
+	case s.requestSlots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	metrics.AddAIRequestsWaiting(1)
+	defer metrics.AddAIRequestsWaiting(-1)
+	start := time.Now()
+	select {
+	case s.requestSlots <- struct{}{}:
+		s.log(ctx).Debug("Gemini request waited for a free slot",
+			zap.String("operation", operation),
+			zap.Duration("waited", time.Since(start)),
+			zap.Int("maxConcurrent", cap(s.requestSlots)))
+		return release, nil
+	case <-ctx.Done():
+		s.log(ctx).Warn("Gemini request gave up waiting for a free slot",
+			zap.String("operation", operation),
+			zap.Duration("waited", time.Since(start)),
+			zap.Int("maxConcurrent", cap(s.requestSlots)))
+		return nil, fmt.Errorf("waited %s for one of %d concurrent AI request slots: %w",
+			time.Since(start).Round(time.Millisecond), cap(s.requestSlots), ctx.Err())
+	}
+}
@@ -0,0 +1,80 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// maxConversationTurns bounds how many prior turns are replayed into query prompts
+const maxConversationTurns = 10
+
+// maxTurnResponseChars truncates long prior answers when replaying them into prompts
+const maxTurnResponseChars = 2000
+
+// ConversationTurn is one completed query and its answer
+type ConversationTurn struct {
+	Query    string
+	Response string
+}
+
+// Conversation carries prior turns and session defaults across queries in a multi-turn session
+type Conversation struct {
+	// Namespace is used for tool calls when a query doesn't name one
+	Namespace string
+	Turns     []ConversationTurn
+}
+
+// Add records a completed turn
+func (c *Conversation) Add(query, response string) {
+	c.Turns = append(c.Turns, ConversationTurn{Query: query, Response: response})
+}
+
+// Reset forgets all prior turns, keeping the session namespace
+func (c *Conversation) Reset() {
+	c.Turns = nil
+}
+
+// promptSection renders the session namespace and recent turns as prompt context
+func (c *Conversation) promptSection() string {
+	if c == nil || (c.Namespace == "" && len(c.Turns) == 0) {
+		return ""
+	}
+
+	var b strings.Builder
+	if c.Namespace != "" {
+		fmt.Fprintf(&b, "\nThe user is working in namespace %q. Use it for tool calls unless the query names another namespace.\n", c.Namespace)
+	}
+
+	turns := c.Turns
+	if len(turns) > maxConversationTurns {
+		turns = turns[len(turns)-maxConversationTurns:]
+	}
+	if len(turns) > 0 {
+		b.WriteString("\nEarlier in this conversation (use it to resolve follow-up questions):\n")
+		for _, turn := range turns {
+			response := turn.Response
+			if len(response) > maxTurnResponseChars {
+				response = response[:maxTurnResponseChars] + "... (truncated)"
+			}
+			fmt.Fprintf(&b, "User: %s\nAssistant: %s\n\n", turn.Query, response)
+		}
+	}
+	return b.String()
+}
+
+type conversationKey struct{}
+
+// ContextWithConversation returns a context that makes QueryWithMCP aware of an ongoing conversation
+func ContextWithConversation(ctx context.Context, conversation *Conversation) context.Context {
+	if conversation == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, conversationKey{}, conversation)
+}
+
+// conversationFromContext returns the conversation stored in ctx, or nil if there is none
+func conversationFromContext(ctx context.Context) *Conversation {
+	conversation, _ := ctx.Value(conversationKey{}).(*Conversation)
+	return conversation
+}
@@ -0,0 +1,65 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/option"
+)
+
+// geminiScopes are the OAuth scopes requested when authenticating with Google credentials instead of an API key
+var geminiScopes = []string{
+	"https://www.googleapis.com/auth/cloud-platform",
+	"https://www.googleapis.com/auth/generative-language",
+}
+
+// WithEndpoint sends Gemini requests to endpoint, such as a corporate proxy or gateway that exposes the
+// Gemini API, instead of the public endpoint. An empty endpoint keeps the default
+func WithEndpoint(endpoint string) Option {
+	return func(s *Service) {
+		s.endpoint = strings.TrimRight(strings.TrimSpace(endpoint), "/")
+	}
+}
+
+// WithCredentialsFile authenticates with a service account or other Google credentials JSON file instead
+// of an API key. An empty path keeps API key authentication
+func WithCredentialsFile(path string) Option {
+	return func(s *Service) {
+		s.credentialsFile = path
+	}
+}
+
+// WithApplicationDefaultCredentials authenticates with Google Application Default Credentials instead of an
+// API key when enabled and no credentials file is set
+func WithApplicationDefaultCredentials(enabled bool) Option {
+	return func(s *Service) {
+		s.useADC = enabled
+	}
+}
+
+// clientOptions builds the Gemini client options for the configured endpoint and authentication. An API key
+// takes precedence over a credentials file, which takes precedence over Application Default Credentials
+func (s *Service) clientOptions(ctx context.Context, apiKey string) ([]option.ClientOption, error) {
+	var opts []option.ClientOption
+	if s.endpoint != "" {
+		opts = append(opts, option.WithEndpoint(s.endpoint))
+	}
+
+	switch {
+	case apiKey != "":
+		opts = append(opts, option.WithAPIKey(apiKey))
+	case s.credentialsFile != "":
+		opts = append(opts, option.WithCredentialsFile(s.credentialsFile), option.WithScopes(geminiScopes...))
+	case s.useADC:
+		creds, err := google.FindDefaultCredentials(ctx, geminiScopes...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find application default credentials: %w", err)
+		}
+		opts = append(opts, option.WithTokenSource(creds.TokenSource))
+	default:
+		return nil, ErrNoAPIKey
+	}
+	return opts, nil
+}
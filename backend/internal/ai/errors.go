@@ -0,0 +1,105 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Sentinel errors returned (wrapped) by the AI service so callers can use errors.Is
+var (
+	// ErrNoAPIKey is returned by NewService when no Gemini API key is configured
+	ErrNoAPIKey = errors.New("Gemini API key is not configured")
+	// ErrMCPUnavailable is returned when queries need cluster tools but the Kubernetes service is not available
+	ErrMCPUnavailable = errors.New("MCP service not available: Kubernetes cluster is unreachable")
+	// ErrModelRateLimited means Gemini rejected the request because a quota or rate limit was exceeded
+	ErrModelRateLimited = errors.New("Gemini model is rate limited")
+	// ErrModelUnavailable means Gemini could not be reached or is temporarily not serving requests
+	ErrModelUnavailable = errors.New("Gemini model is unavailable")
+	// ErrEmptyResponse means the model returned no content
+	ErrEmptyResponse = errors.New("no response generated")
+	// ErrInvalidResponse means the model's response could not be parsed
+	ErrInvalidResponse = errors.New("failed to parse AI response")
+	// ErrContentBlocked means Gemini blocked the prompt or its response, usually because of its safety filters
+	ErrContentBlocked = errors.New("AI model blocked the content")
+	// ErrResponseTruncated means the model hit its output token limit and the partial response could not be repaired
+	ErrResponseTruncated = errors.New("AI response was truncated at the output token limit")
+	// ErrInputTooLarge means a prompt is estimated to exceed the input token limit of every model it could be sent to
+	ErrInputTooLarge = errors.New("input too large for the AI model")
+	// ErrQueryTimedOut means an MCP query reached its deadline before any cluster data was gathered
+	ErrQueryTimedOut = errors.New("AI query timed out")
+)
+
+// classifyModelError wraps a Gemini API error with the sentinel matching its status, or returns it unchanged
+func classifyModelError(err error) error {
+	var blocked *genai.BlockedError
+	if errors.As(err, &blocked) {
+		return blockedError(blocked.PromptFeedback, blocked.Candidate)
+	}
+
+	code := status.Code(err)
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Code == http.StatusTooManyRequests:
+			code = codes.ResourceExhausted
+		case apiErr.Code >= http.StatusInternalServerError:
+			code = codes.Unavailable
+		}
+	}
+
+	switch code {
+	case codes.ResourceExhausted:
+		return fmt.Errorf("%w: %w", ErrModelRateLimited, err)
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal:
+		return fmt.Errorf("%w: %w", ErrModelUnavailable, err)
+	}
+	return err
+}
+
+// blockedError explains why Gemini blocked a prompt or candidate, naming the flagged safety categories
+func blockedError(feedback *genai.PromptFeedback, candidate *genai.Candidate) error {
+	var reason string
+	var ratings []*genai.SafetyRating
+	switch {
+	case feedback != nil && feedback.BlockReason != genai.BlockReasonUnspecified:
+		ratings = feedback.SafetyRatings
+		reason = "the request was rejected by the model's content filters"
+		if feedback.BlockReason == genai.BlockReasonSafety {
+			reason = "the request was flagged by the model's safety filters"
+		}
+	case candidate != nil && candidate.FinishReason == genai.FinishReasonRecitation:
+		reason = "the response was withheld because it closely recited existing material"
+	case candidate != nil && candidate.FinishReason == genai.FinishReasonSafety:
+		ratings = candidate.SafetyRatings
+		reason = "the response was flagged by the model's safety filters"
+	default:
+		reason = "the model stopped without giving a reason"
+	}
+
+	if flagged := flaggedCategories(ratings); len(flagged) > 0 {
+		reason += " (" + strings.Join(flagged, ", ") + ")"
+	}
+	return fmt.Errorf("%w: %s; rephrase or trim the input and retry", ErrContentBlocked, reason)
+}
+
+// flaggedCategories lists the safety categories that were blocked or rated at least medium probability
+func flaggedCategories(ratings []*genai.SafetyRating) []string {
+	var flagged []string
+	for _, rating := range ratings {
+		if rating == nil || (!rating.Blocked && rating.Probability < genai.HarmProbabilityMedium) {
+			continue
+		}
+		category := strings.TrimPrefix(rating.Category.String(), "HarmCategory")
+		probability := strings.ToLower(strings.TrimPrefix(rating.Probability.String(), "HarmProbability"))
+		flagged = append(flagged, fmt.Sprintf("%s: %s probability", category, probability))
+	}
+	return flagged
+}
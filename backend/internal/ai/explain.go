@@ -0,0 +1,128 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/generative-ai-go/genai"
+	"go.uber.org/zap"
+
+	"kube-sherlock/internal/kubernetes"
+)
+
+// Health verdicts of a ResourceExplanation
+const (
+	HealthHealthy   = "healthy"
+	HealthDegraded  = "degraded"
+	HealthUnhealthy = "unhealthy"
+	HealthUnknown   = "unknown"
+)
+
+// ResourceDescriber fetches an object and the events about it for ExplainResource. *kubernetes.Service
+// implements it
+type ResourceDescriber interface {
+	GetResourceDetails(ctx context.Context, kind, namespace, name string) (*kubernetes.ResourceDetails, error)
+}
+
+// ResourceExplanation explains what an object is for and whether it is healthy
+type ResourceExplanation struct {
+	Resource kubernetes.ResourceRef `json:"resource"`
+	Purpose  string                 `json:"purpose"`
+	// Health is one of healthy, degraded, unhealthy or unknown
+	Health string `json:"health"`
+	Status string `json:"status"`
+	// Anomalies are problems or risky settings spotted in the object or its events, most serious first
+	Anomalies []string `json:"anomalies"`
+	// Model is the Gemini model that produced the response
+	Model string `json:"model,omitempty"`
+}
+
+// explainResourceSchema matches ResourceExplanation
+var explainResourceSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"purpose": {Type: genai.TypeString},
+		"health": {
+			Type:   genai.TypeString,
+			Format: "enum",
+			Enum:   []string{HealthHealthy, HealthDegraded, HealthUnhealthy, HealthUnknown},
+		},
+		"status":    {Type: genai.TypeString},
+		"anomalies": {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString}},
+	},
+	Required: []string{"purpose", "health", "status", "anomalies"},
+}
+
+// ExplainResource fetches one object with its recent events and explains in plain language what it is for,
+// its current status and anything anomalous about it. kind may be a kind, resource or short name
+func (s *Service) ExplainResource(ctx context.Context, describer ResourceDescriber, kind, namespace, name string) (*ResourceExplanation, error) {
+	if describer == nil {
+		return nil, kubernetes.ErrClusterUnavailable
+	}
+	details, err := describer.GetResourceDetails(ctx, kind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	detailsData, _ := json.MarshalIndent(details, "", "  ")
+	prompt := fmt.Sprintf(`You are a Kubernetes expert explaining a resource to someone who pasted its name and asked what it is and whether it is healthy.
+
+Resource: %s
+
+Object and its recent events:
+%s
+
+Explain what the resource is for in its application, based on its kind, name, labels, owners and spec. Describe its current status from the status fields and events, and judge its health as healthy, degraded, unhealthy or unknown. List anomalies, most serious first: failing conditions, Warning events, restarts, unavailable replicas, mismatches between spec and status, and risky settings such as missing resource requests or probes or the latest image tag. Return an empty list when nothing stands out.
+
+Provide your output in the following JSON format:
+{
+  "purpose": "What the resource does",
+  "health": "healthy",
+  "status": "Its current status",
+  "anomalies": ["Anything that looks wrong"]
+}`, details.String(), s.analysisData(ctx, string(detailsData)))
+	prompt = s.applySystemPrompt(ctx, prompt)
+
+	if s.printDryRun("explain-resource", prompt) {
+		return &ResourceExplanation{
+			Resource:  details.ResourceRef,
+			Purpose:   dryRunNotice,
+			Health:    HealthUnknown,
+			Status:    dryRunNotice,
+			Anomalies: []string{},
+		}, nil
+	}
+
+	model := s.generativeModel()
+	model.SetTemperature(0.2)
+	setResponseSchema(model, explainResourceSchema)
+
+	resp, modelName, err := s.generateContent(ctx, model, "explain_resource", prompt)
+	if err != nil {
+		s.log(ctx).Error("Failed to generate content for resource explanation", zap.Error(err))
+		return nil, fmt.Errorf("failed to explain resource: %w", err)
+	}
+
+	responseText, err := extractText(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var result ResourceExplanation
+	if err := s.parseJSONResponse(ctx, resp, responseText, &result); err != nil {
+		return nil, err
+	}
+
+	switch result.Health {
+	case HealthHealthy, HealthDegraded, HealthUnhealthy:
+	default:
+		result.Health = HealthUnknown
+	}
+	if result.Anomalies == nil {
+		result.Anomalies = []string{}
+	}
+	result.Resource = details.ResourceRef
+	result.Model = modelName
+	return &result, nil
+}
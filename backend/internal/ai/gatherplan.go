@@ -0,0 +1,154 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"kube-sherlock/internal/kubernetes"
+)
+
+// GatherPlan is a resource suggestion with the gather parameters the model chose for it
+type GatherPlan struct {
+	SuggestedResources []string `json:"suggestedResources"`
+	Reasoning          string   `json:"reasoning"`
+	// ResourceTypes, Namespace and LabelSelector are the model's gather parameters, before validation
+	ResourceTypes []string `json:"resourceTypes"`
+	Namespace     string   `json:"namespace,omitempty"`
+	LabelSelector string   `json:"labelSelector,omitempty"`
+	// Model is the Gemini model that produced the response
+	Model string `json:"model,omitempty"`
+}
+
+// resourceKeywords map words in free-text suggestions to resource types, for plans without usable types.
+// Logs and containers are read from pods
+var resourceKeywords = []struct{ keyword, resourceType string }{
+	{"pod", "pods"},
+	{"log", "pods"},
+	{"container", "pods"},
+	{"deployment", "deployments"},
+	{"replicaset", "replicasets"},
+	{"statefulset", "statefulsets"},
+	{"daemonset", "daemonsets"},
+	{"service", "services"},
+	{"endpointslice", "endpointslices"},
+	{"endpoint", "endpoints"},
+	{"configmap", "configmaps"},
+	{"secret", "secrets"},
+	{"event", "events"},
+	{"networkpolic", "networkpolicies"},
+	{"quota", "resourcequotas"},
+	{"limitrange", "limitranges"},
+	{"disruptionbudget", "poddisruptionbudgets"},
+	{"pdb", "poddisruptionbudgets"},
+}
+
+// SuggestGatherPlan asks the model which resources would help diagnose an error, as SuggestResources does,
+// and also which resource types, namespace and label selector to gather them with
+func (s *Service) SuggestGatherPlan(ctx context.Context, errorDescription string) (*GatherPlan, error) {
+	prompt := fmt.Sprintf(`You are a Kubernetes troubleshooting expert. Given the following error description, suggest which Kubernetes resources would provide helpful context for troubleshooting, and how to gather them.
+
+Error Description: %s
+
+List the suggested resources with a brief explanation of why each is relevant, focusing on the root cause. Then choose the gather parameters:
+- resourceTypes: the types to list, chosen only from: %s
+- namespace: the namespace named in the error description, or "" if none is named
+- labelSelector: a label selector such as "app=checkout" only if the error description clearly identifies the workload's labels, otherwise ""
+
+Provide your output in the following JSON format:
+{
+  "suggestedResources": ["pod/example-pod logs", "deployment/example-deployment configuration", "events in the namespace"],
+  "reasoning": "Pod logs may contain error messages. Deployment configuration can show misconfigurations. Events show scheduling and pull failures.",
+  "resourceTypes": ["pods", "deployments", "events"],
+  "namespace": "",
+  "labelSelector": ""
+}`, errorDescription, strings.Join(kubernetes.SupportedResourceTypes, ", "))
+	prompt = s.applySystemPrompt(ctx, prompt)
+
+	if s.printDryRun("suggest-gather", prompt) {
+		return &GatherPlan{
+			SuggestedResources: []string{},
+			Reasoning:          dryRunNotice,
+			ResourceTypes:      []string{},
+		}, nil
+	}
+
+	model := s.generativeModel()
+	model.SetTemperature(0.1)
+
+	resp, modelName, err := s.generateContent(ctx, model, "suggest_gather", prompt)
+	if err != nil {
+		s.log(ctx).Error("Failed to generate content for gather plan", zap.Error(err))
+		return nil, fmt.Errorf("failed to suggest resources: %w", err)
+	}
+
+	responseText, err := extractText(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan GatherPlan
+	if err := s.parseJSONResponse(ctx, resp, responseText, &plan); err != nil {
+		return nil, err
+	}
+
+	plan.Model = modelName
+	return &plan, nil
+}
+
+// GatherOptions maps the plan to gather options, dropping anything invalid with a warning. Unsupported
+// types are ignored; without any usable type, types are taken from keywords in the suggestions. namespace,
+// when set, takes precedence over the model's choice. ResourceTypes is empty when nothing could be mapped
+func (p *GatherPlan) GatherOptions(namespace string) (kubernetes.GatherOptions, []string) {
+	var opts kubernetes.GatherOptions
+	var warnings []string
+
+	for _, resourceType := range p.ResourceTypes {
+		resourceType = strings.ToLower(strings.TrimSpace(resourceType))
+		// Models sometimes answer with the singular form
+		if !slices.Contains(kubernetes.SupportedResourceTypes, resourceType) && slices.Contains(kubernetes.SupportedResourceTypes, resourceType+"s") {
+			resourceType += "s"
+		}
+		if kubernetes.ValidateResourceTypes([]string{resourceType}) != nil {
+			warnings = append(warnings, fmt.Sprintf("Ignored unsupported resource type %q suggested by the model", resourceType))
+			continue
+		}
+		if !slices.Contains(opts.ResourceTypes, resourceType) {
+			opts.ResourceTypes = append(opts.ResourceTypes, resourceType)
+		}
+	}
+	if len(opts.ResourceTypes) == 0 {
+		for _, suggestion := range p.SuggestedResources {
+			suggestion = strings.ToLower(suggestion)
+			for _, candidate := range resourceKeywords {
+				if strings.Contains(suggestion, candidate.keyword) && !slices.Contains(opts.ResourceTypes, candidate.resourceType) {
+					opts.ResourceTypes = append(opts.ResourceTypes, candidate.resourceType)
+				}
+			}
+		}
+	}
+
+	opts.Namespace = namespace
+	if suggested := strings.TrimSpace(p.Namespace); opts.Namespace == "" && suggested != "" {
+		if len(validation.IsDNS1123Label(suggested)) == 0 {
+			opts.Namespace = suggested
+		} else {
+			warnings = append(warnings, fmt.Sprintf("Ignored invalid namespace %q suggested by the model", suggested))
+		}
+	}
+
+	if selector := strings.TrimSpace(p.LabelSelector); selector != "" {
+		if _, err := labels.Parse(selector); err == nil {
+			opts.LabelSelector = selector
+		} else {
+			warnings = append(warnings, fmt.Sprintf("Ignored invalid label selector %q suggested by the model", selector))
+		}
+	}
+
+	return opts, warnings
+}
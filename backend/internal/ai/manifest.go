@@ -0,0 +1,65 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"kube-sherlock/internal/kubernetes"
+)
+
+// ManifestExplanation explains the outcome of validating a manifest
+type ManifestExplanation struct {
+	Explanation string `json:"explanation"`
+	// Model is the Gemini model that produced the response
+	Model string `json:"model,omitempty"`
+}
+
+// ExplainManifestValidation explains in plain language what a manifest would do and why any of its
+// documents failed server-side validation, with the change that fixes each failure
+func (s *Service) ExplainManifestValidation(ctx context.Context, manifest string, results []kubernetes.ManifestValidation) (*ManifestExplanation, error) {
+	resultsData, _ := json.MarshalIndent(results, "", "  ")
+	prompt := fmt.Sprintf(`You are a Kubernetes expert reviewing a manifest before it is applied. Each document was validated with a server-side dry-run apply, which checks the schema, rejects unknown fields and runs admission webhooks without changing anything.
+
+Manifest:
+%s
+
+Validation results:
+%s
+
+Explain briefly what the manifest creates or changes. For each document that failed, explain the error in plain language and give the exact change to the manifest that fixes it. If everything passed, point out risky settings a dry-run can't catch, such as missing resource requests or probes, or the latest image tag.
+
+Provide your output in the following JSON format:
+{
+  "explanation": "Markdown text with the explanation and fixes"
+}`, manifest, string(resultsData))
+	prompt = s.applySystemPrompt(ctx, prompt)
+
+	if s.printDryRun("explain-manifest", prompt) {
+		return &ManifestExplanation{Explanation: dryRunNotice}, nil
+	}
+
+	model := s.generativeModel()
+	model.SetTemperature(0.2)
+
+	resp, modelName, err := s.generateContent(ctx, model, "explain_manifest", prompt)
+	if err != nil {
+		s.log(ctx).Error("Failed to generate content for manifest explanation", zap.Error(err))
+		return nil, fmt.Errorf("failed to explain manifest validation: %w", err)
+	}
+
+	responseText, err := extractText(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var result ManifestExplanation
+	if err := s.parseJSONResponse(ctx, resp, responseText, &result); err != nil {
+		return nil, err
+	}
+
+	result.Model = modelName
+	return &result, nil
+}
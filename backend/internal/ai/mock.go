@@ -0,0 +1,244 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"sigs.k8s.io/yaml"
+)
+
+// AI providers selectable with WithProvider
+const (
+	ProviderGemini = "gemini"
+	ProviderMock   = "mock"
+)
+
+// MockModelName is the model reported for responses from the mock provider
+const MockModelName = "mock"
+
+// MockFixture is a canned model response for the mock provider. Response is returned as the model's text for
+// prompts matching the Match regular expression, for the operation named by Operation or for any operation
+// when it is empty. Operations are troubleshoot, suggest_resources, suggest_gather, summarize, summarize_chunk,
+// summarize_combine, explain_manifest, explain_resource, query (tool selection) and query_analysis
+type MockFixture struct {
+	Match     string `json:"match"`
+	Operation string `json:"operation,omitempty"`
+	Response  string `json:"response"`
+}
+
+// mockProvider answers model requests with fixtures or built-in canned responses
+type mockProvider struct {
+	fixtures []MockFixture
+	patterns []*regexp.Regexp
+}
+
+// WithProvider selects the AI provider: ProviderGemini (the default when empty) or ProviderMock, which needs
+// no credentials and answers from the fixtures in mockFixturesFile, when set, and built-in canned responses.
+// MCP tools still run against the cluster with the mock provider
+func WithProvider(provider, mockFixturesFile string) Option {
+	return func(s *Service) {
+		s.provider = provider
+		s.mockFixturesFile = mockFixturesFile
+	}
+}
+
+// newMockProvider loads and compiles the fixtures in path, if any
+func newMockProvider(path string) (*mockProvider, error) {
+	mock := &mockProvider{}
+	if path == "" {
+		return mock, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mock fixtures: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &mock.fixtures); err != nil {
+		return nil, fmt.Errorf("failed to parse mock fixtures %s: %w", path, err)
+	}
+	for i, fixture := range mock.fixtures {
+		pattern, err := regexp.Compile(fixture.Match)
+		if err != nil {
+			return nil, fmt.Errorf("invalid match pattern in mock fixture %d: %w", i+1, err)
+		}
+		mock.patterns = append(mock.patterns, pattern)
+	}
+	return mock, nil
+}
+
+// respond returns the text of the first fixture matching the operation and prompt, or a built-in response
+func (p *mockProvider) respond(operation, prompt string) string {
+	for i, fixture := range p.fixtures {
+		if (fixture.Operation == "" || fixture.Operation == operation) && p.patterns[i].MatchString(prompt) {
+			return fixture.Response
+		}
+	}
+
+	switch operation {
+	case "troubleshoot":
+		return mockTroubleshoot(prompt)
+	case "suggest_resources":
+		return `{"suggestedResources": ["pod logs of the failing pods", "events in the namespace", "deployment configuration"], "reasoning": "[mock] Logs and events usually show why a workload fails; the deployment shows how it is configured."}`
+	case "suggest_gather":
+		return `{"suggestedResources": ["pod logs of the failing pods", "events in the namespace", "deployment configuration"], "reasoning": "[mock] Logs and events usually show why a workload fails; the deployment shows how it is configured.", "resourceTypes": ["pods", "events", "deployments"], "namespace": "", "labelSelector": ""}`
+	case "summarize", "summarize_chunk", "summarize_combine":
+		data, _ := json.Marshal(map[string]string{
+			"summary": fmt.Sprintf("[mock] Summary of %d bytes of resource data. Check pods that are not Running and recent Warning events.", len(prompt)),
+		})
+		return string(data)
+	case "explain_manifest":
+		data, _ := json.Marshal(map[string]string{
+			"explanation": fmt.Sprintf("[mock] The manifest was validated with a server-side dry-run; %d documents failed. Fix the fields named in each error and validate again.", strings.Count(prompt, `"valid": false`)),
+		})
+		return string(data)
+	case "explain_resource":
+		health := HealthHealthy
+		if strings.Contains(prompt, `"type": "Warning"`) {
+			health = HealthDegraded
+		}
+		data, _ := json.Marshal(map[string]interface{}{
+			"purpose":   "[mock] The resource is part of the workload named in its labels.",
+			"health":    health,
+			"status":    "[mock] See the status fields and events of the object.",
+			"anomalies": []string{},
+		})
+		return string(data)
+	case "query":
+		return mockToolSelection(prompt)
+	case "query_analysis":
+		return mockAnalysis(prompt)
+	}
+	return fmt.Sprintf("[mock] No canned response for %s", operation)
+}
+
+// mockCause is a built-in troubleshooting answer for error messages containing Keyword
+type mockCause struct {
+	Keyword   string
+	Causes    []string
+	Solutions []string
+}
+
+// mockCauses are the built-in troubleshooting answers, checked in order
+var mockCauses = []mockCause{
+	{
+		Keyword:   "ImagePull",
+		Causes:    []string{"The image name or tag does not exist", "The registry requires credentials the pod doesn't have"},
+		Solutions: []string{"Check the image reference with `kubectl describe pod <pod>`", "Add an imagePullSecret with `kubectl create secret docker-registry`"},
+	},
+	{
+		Keyword:   "CrashLoopBackOff",
+		Causes:    []string{"The application exits on startup", "A liveness probe kills the container before it is ready"},
+		Solutions: []string{"Read the previous container's logs with `kubectl logs <pod> --previous`", "Relax the liveness probe's initialDelaySeconds"},
+	},
+	{
+		Keyword:   "OOMKilled",
+		Causes:    []string{"The container's memory limit is lower than its working set"},
+		Solutions: []string{"Compare usage with `kubectl top pod <pod>` and raise the memory limit"},
+	},
+	{
+		Keyword:   "FailedScheduling",
+		Causes:    []string{"No node has enough free CPU or memory for the pod's requests", "Node selectors, taints or affinity rules exclude every node"},
+		Solutions: []string{"Check the scheduler's reasons with `kubectl describe pod <pod>`", "Lower the requests or add capacity"},
+	},
+}
+
+// mockTroubleshoot returns a troubleshoot response for the first built-in answer whose keyword is in the prompt
+func mockTroubleshoot(prompt string) string {
+	answer := mockCause{
+		Causes:    []string{"[mock] The workload is misconfigured", "[mock] A dependency is unavailable"},
+		Solutions: []string{"Inspect the pod with `kubectl describe pod <pod>`", "Check recent events with `kubectl get events --sort-by=.lastTimestamp`"},
+	}
+	for _, candidate := range mockCauses {
+		if strings.Contains(prompt, candidate.Keyword) {
+			answer = candidate
+			break
+		}
+	}
+
+	var causes, solutions []map[string]interface{}
+	for i, cause := range answer.Causes {
+		causes = append(causes, map[string]interface{}{"cause": cause, "confidence": 0.7 / float64(i+1)})
+	}
+	for _, solution := range answer.Solutions {
+		solutions = append(solutions, map[string]interface{}{"solution": solution, "effort": "low", "risk": "low"})
+	}
+	data, _ := json.Marshal(map[string]interface{}{"potentialCauses": causes, "suggestedSolutions": solutions})
+	return string(data)
+}
+
+// mockNamespacePattern finds a namespace named in a query, as in "in the payments namespace" or "namespace payments"
+var mockNamespacePattern = regexp.MustCompile(`(?i)\b(?:in (?:the )?([a-z0-9][a-z0-9-]*) namespace|namespace ([a-z0-9][a-z0-9-]*))\b`)
+
+// mockTools maps query keywords to the tool the mock provider calls, checked in order
+var mockTools = []struct{ keyword, tool string }{
+	{"event", "get_recent_events"},
+	{"deployment", "get_deployment_status"},
+	{"service", "get_service_endpoints"},
+	{"namespaces", "get_namespaces"},
+}
+
+// mockToolSelection picks a tool by keyword for the first step of a query and answers once data was gathered
+func mockToolSelection(prompt string) string {
+	if strings.Contains(prompt, "Cluster data gathered so far:") {
+		return `{"action": "answer"}`
+	}
+
+	query := strings.ToLower(promptLine(prompt, "Query: "))
+	tool := "get_pod_health"
+	for _, candidate := range mockTools {
+		if strings.Contains(query, candidate.keyword) {
+			tool = candidate.tool
+			break
+		}
+	}
+	arguments := map[string]interface{}{}
+	if match := mockNamespacePattern.FindStringSubmatch(query); match != nil && tool != "get_namespaces" {
+		arguments["namespace"] = match[1] + match[2]
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{"action": "use_tool", "tool": tool, "arguments": arguments})
+	return string(data)
+}
+
+// mockAnalysis describes the gathered cluster data without interpreting it
+func mockAnalysis(prompt string) string {
+	data := prompt
+	if _, after, ok := strings.Cut(prompt, "Cluster Data:\n"); ok {
+		data = after
+	}
+	return fmt.Sprintf(`## Mock analysis
+
+This answer comes from the **mock AI provider**; the cluster data was gathered by real tool calls.
+
+- **Query:** %s
+- **Cluster data:** %d lines gathered
+
+## Next steps
+
+- Review the raw data in `+"`rawData`"+`
+- Run `+"`kubectl get pods`"+` to compare with the live state`, promptLine(prompt, "Original Query: "), strings.Count(data, "\n")+1)
+}
+
+// promptLine returns the rest of the first prompt line starting with prefix
+func promptLine(prompt, prefix string) string {
+	for _, line := range strings.Split(prompt, "\n") {
+		if rest, ok := strings.CutPrefix(line, prefix); ok {
+			return strings.TrimSpace(rest)
+		}
+	}
+	return ""
+}
+
+// mockResponse wraps text as a finished model response
+func mockResponse(text string) *genai.GenerateContentResponse {
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content:      &genai.Content{Parts: []genai.Part{genai.Text(text)}, Role: "model"},
+			FinishReason: genai.FinishReasonStop,
+		}},
+	}
+}
@@ -0,0 +1,214 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// MaxTroubleshootPasses bounds the passes TroubleshootErrorPasses runs for one error
+const MaxTroubleshootPasses = 5
+
+// Multi-pass troubleshooting samples at multiPassTemperature so the passes can differ, and treats causes
+// or solutions whose word overlap is at least itemSimilarity as the same item
+const (
+	multiPassTemperature = 0.5
+	itemSimilarity       = 0.6
+)
+
+// TroubleshootErrorPasses troubleshoots an error with passes independent model calls and merges their
+// causes and solutions. Items found by several passes are merged into one that records how many passes
+// found it and ranks above items fewer passes agree on; a cause's confidence is its average over all
+// passes, counting passes that missed it as zero. One pass, or fewer, is the same as TroubleshootError.
+// Passes that fail are left out as long as one succeeds, and the response's Passes counts those that did
+func (s *Service) TroubleshootErrorPasses(ctx context.Context, errorMessage string, passes int) (*TroubleshootResponse, error) {
+	if passes <= 1 {
+		return s.TroubleshootError(ctx, errorMessage)
+	}
+	if passes > MaxTroubleshootPasses {
+		return nil, fmt.Errorf("at most %d troubleshoot passes are allowed, got %d", MaxTroubleshootPasses, passes)
+	}
+
+	prompt := s.troubleshootPrompt(ctx, errorMessage)
+	if s.printDryRun(fmt.Sprintf("troubleshoot (%d passes)", passes), prompt) {
+		return &TroubleshootResponse{
+			PotentialCauses:    []string{dryRunNotice},
+			SuggestedSolutions: []string{dryRunNotice},
+		}, nil
+	}
+
+	responses := make([]*TroubleshootResponse, passes)
+	errs := make([]error, passes)
+	var wg sync.WaitGroup
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], errs[i] = s.troubleshootPass(ctx, prompt, multiPassTemperature)
+		}(i)
+	}
+	wg.Wait()
+
+	var succeeded []*TroubleshootResponse
+	var firstErr error
+	for i, response := range responses {
+		if errs[i] != nil {
+			s.log(ctx).Warn("Troubleshoot pass failed", zap.Int("pass", i+1), zap.Error(errs[i]))
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			continue
+		}
+		succeeded = append(succeeded, response)
+	}
+	if len(succeeded) == 0 {
+		return nil, firstErr
+	}
+
+	result := mergeTroubleshootPasses(succeeded)
+	s.processResponse(strings.Join(append(append([]string{}, result.PotentialCauses...), result.SuggestedSolutions...), "\n"), &result.ResponseExtras)
+	s.log(ctx).Info("Merged troubleshoot passes",
+		zap.Int("passes", result.Passes),
+		zap.Int("failed", passes-result.Passes),
+		zap.Int("causes", len(result.PotentialCauses)),
+		zap.Int("solutions", len(result.SuggestedSolutions)))
+	return result, nil
+}
+
+// mergedItem is a cause or solution merged across passes, keeping the wording of the pass that found it first
+type mergedItem struct {
+	text       string
+	words      map[string]bool
+	passes     int
+	confidence float64
+	effort     string
+	risk       string
+}
+
+// mergeTroubleshootPasses merges the causes and solutions of several troubleshoot responses, ranking items by
+// how many passes found them and, for causes, then by average confidence
+func mergeTroubleshootPasses(responses []*TroubleshootResponse) *TroubleshootResponse {
+	var causes, solutions []*mergedItem
+	rated, assessed := false, false
+	for _, response := range responses {
+		matched := map[*mergedItem]bool{}
+		for i, cause := range response.PotentialCauses {
+			item := mergeItem(&causes, matched, cause)
+			if i < len(response.Causes) {
+				item.confidence += response.Causes[i].Confidence
+				rated = true
+			}
+		}
+		matched = map[*mergedItem]bool{}
+		for i, solution := range response.SuggestedSolutions {
+			item := mergeItem(&solutions, matched, solution)
+			if i < len(response.Solutions) {
+				if item.effort == "" {
+					item.effort = response.Solutions[i].Effort
+				}
+				if item.risk == "" {
+					item.risk = response.Solutions[i].Risk
+				}
+				assessed = true
+			}
+		}
+	}
+
+	sort.SliceStable(causes, func(i, j int) bool {
+		if causes[i].passes != causes[j].passes {
+			return causes[i].passes > causes[j].passes
+		}
+		return causes[i].confidence > causes[j].confidence
+	})
+	sort.SliceStable(solutions, func(i, j int) bool {
+		return solutions[i].passes > solutions[j].passes
+	})
+
+	result := &TroubleshootResponse{
+		PotentialCauses:    []string{},
+		SuggestedSolutions: []string{},
+		Model:              responses[0].Model,
+		Passes:             len(responses),
+	}
+	for _, item := range causes {
+		result.PotentialCauses = append(result.PotentialCauses, item.text)
+		if rated {
+			result.Causes = append(result.Causes, CauseAssessment{
+				Cause:      item.text,
+				Confidence: roundConfidence(item.confidence / float64(len(responses))),
+				Passes:     item.passes,
+			})
+		}
+	}
+	for _, item := range solutions {
+		result.SuggestedSolutions = append(result.SuggestedSolutions, item.text)
+		if assessed {
+			result.Solutions = append(result.Solutions, SolutionAssessment{
+				Solution: item.text,
+				Effort:   item.effort,
+				Risk:     item.risk,
+				Passes:   item.passes,
+			})
+		}
+	}
+	return result
+}
+
+// mergeItem finds the item in items most similar to text that this pass hasn't matched yet, or appends a
+// new one, and counts the pass for it
+func mergeItem(items *[]*mergedItem, matched map[*mergedItem]bool, text string) *mergedItem {
+	words := itemWords(text)
+	var best *mergedItem
+	bestSimilarity := itemSimilarity
+	for _, item := range *items {
+		if matched[item] {
+			continue
+		}
+		if similarity := jaccard(words, item.words); similarity >= bestSimilarity {
+			best, bestSimilarity = item, similarity
+		}
+	}
+	if best == nil {
+		best = &mergedItem{text: text, words: words}
+		*items = append(*items, best)
+	}
+	matched[best] = true
+	best.passes++
+	return best
+}
+
+// itemWords is the set of lowercased words in text, ignoring punctuation
+func itemWords(text string) map[string]bool {
+	words := map[string]bool{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '/' || r == '.')
+	}) {
+		if word = strings.Trim(word, "."); word != "" {
+			words[word] = true
+		}
+	}
+	return words
+}
+
+// jaccard is the share of words two sets have in common
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	common := 0
+	for word := range a {
+		if b[word] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// roundConfidence rounds a merged confidence to two decimals
+func roundConfidence(confidence float64) float64 {
+	return float64(int(confidence*100+0.5)) / 100
+}
@@ -0,0 +1,542 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+	"go.uber.org/zap"
+
+	"kube-sherlock/internal/mcp"
+)
+
+// maxToolCallsPerStep caps how many tools the model may request in a single decision
+const maxToolCallsPerStep = 5
+
+// defaultMaxToolIterations is the number of decide/execute rounds allowed when not configured
+const defaultMaxToolIterations = 5
+
+// analysisReserveDivisor holds back 1/n of a query's remaining time for the final analysis, so tool
+// selection and execution can't use up the whole deadline
+const analysisReserveDivisor = 3
+
+// toolCall is a single tool invocation requested by the model
+type toolCall struct {
+	Tool      string                 `json:"tool"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// queryAction is the model's decision on how to answer a query
+type queryAction struct {
+	Action    string                 `json:"action"`
+	Tool      string                 `json:"tool"`
+	Arguments map[string]interface{} `json:"arguments"`
+	Tools     []toolCall             `json:"tools"`
+	Response  string                 `json:"response"`
+}
+
+// key identifies a call by tool name and arguments for duplicate detection
+func (c toolCall) key() string {
+	args, _ := json.Marshal(c.Arguments)
+	return c.Tool + string(args)
+}
+
+// toolCalls returns the requested tool invocations, accepting both the single and multi-tool forms
+func (a *queryAction) toolCalls() []toolCall {
+	calls := make([]toolCall, 0, len(a.Tools)+1)
+	if a.Tool != "" {
+		calls = append(calls, toolCall{Tool: a.Tool, Arguments: a.Arguments})
+	}
+	for _, call := range a.Tools {
+		if call.Tool != "" {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+type explainKey struct{}
+
+// ContextWithExplain returns a context that makes MCP queries include each tool-selection decision
+// and the raw tool output in their response
+func ContextWithExplain(ctx context.Context, explain bool) context.Context {
+	if !explain {
+		return ctx
+	}
+	return context.WithValue(ctx, explainKey{}, true)
+}
+
+// explainFromContext reports whether ctx asks for explained MCP queries
+func explainFromContext(ctx context.Context) bool {
+	explain, _ := ctx.Value(explainKey{}).(bool)
+	return explain
+}
+
+// QueryWithMCP handles natural language queries with MCP tool support
+func (s *Service) QueryWithMCP(ctx context.Context, query string) (*QueryResponse, error) {
+	return s.QueryWithMCPEvents(ctx, query, nil)
+}
+
+// QueryWithMCPEvents runs the MCP query flow, reporting progress to onEvent as each step starts.
+// The model may call tools repeatedly, seeing all previous results each round, until it chooses
+// to answer or the iteration cap is reached.
+func (s *Service) QueryWithMCPEvents(ctx context.Context, query string, onEvent QueryEventFunc) (*QueryResponse, error) {
+	return s.processQueryResponse(s.queryWithMCP(ctx, query, onEvent, false))
+}
+
+// QueryWithMCPStream runs the MCP query flow like QueryWithMCPEvents and also streams the final analysis
+// to onEvent as QueryEventDelta events while Gemini generates it. The returned response holds the full
+// text, which replaces the streamed text if the analysis failed part way
+func (s *Service) QueryWithMCPStream(ctx context.Context, query string, onEvent QueryEventFunc) (*QueryResponse, error) {
+	return s.processQueryResponse(s.queryWithMCP(ctx, query, onEvent, true))
+}
+
+// processQueryResponse runs the response processors over a query's final response
+func (s *Service) processQueryResponse(response *QueryResponse, err error) (*QueryResponse, error) {
+	if response != nil {
+		s.processResponse(response.Response, &response.ResponseExtras)
+	}
+	return response, err
+}
+
+// queryWithMCP implements QueryWithMCPEvents and QueryWithMCPStream
+func (s *Service) queryWithMCP(ctx context.Context, query string, onEvent QueryEventFunc, stream bool) (*QueryResponse, error) {
+	notify := func(event QueryEvent) {
+		if onEvent != nil {
+			onEvent(event)
+		}
+	}
+
+	if s.mcpService == nil {
+		return nil, ErrMCPUnavailable
+	}
+
+	// The prompt segment describing the tools this request may run is marshaled once by the MCP service
+	toolsJSON := s.mcpService.ToolsJSON(ctx)
+	conversation := conversationFromContext(ctx).promptSection()
+
+	firstPrompt := s.applySystemPrompt(ctx, buildQueryPrompt(query, toolsJSON, conversation, ""))
+	if s.printDryRun("query", firstPrompt) {
+		return &QueryResponse{
+			Response: dryRunNotice,
+			UsedTool: false,
+		}, nil
+	}
+
+	if s.queryTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.queryTimeout)
+		defer cancel()
+	}
+	// Tool selection and execution run under gatherCtx, which expires early enough to leave time for the analysis
+	gatherCtx, cancelGather := withAnalysisReserve(ctx)
+	defer cancelGather()
+
+	model := s.generativeModel()
+	model.SetTemperature(0.1)
+
+	var gathered strings.Builder
+	var toolNames []string
+	var steps []QueryStep
+	explain := explainFromContext(ctx)
+	executed := make(map[string]bool)
+	totalCalls, failedCalls := 0, 0
+
+	for iteration := 1; iteration <= s.maxToolIterations; iteration++ {
+		prompt := s.applySystemPrompt(ctx, buildQueryPrompt(query, toolsJSON, conversation, gathered.String()))
+
+		notify(QueryEvent{
+			Type:    QueryEventToolSelection,
+			Message: fmt.Sprintf("Choosing how to answer the query (step %d of %d)", iteration, s.maxToolIterations),
+		})
+
+		resp, modelName, err := s.generateContent(gatherCtx, model, "query", prompt)
+		if err != nil {
+			s.log(ctx).Error("Failed to generate MCP response", zap.Error(err), zap.Int("iteration", iteration))
+			if totalCalls == 0 {
+				if timedOut(gatherCtx) {
+					return nil, fmt.Errorf("%w before any cluster data was gathered: %w", ErrQueryTimedOut, err)
+				}
+				return nil, fmt.Errorf("failed to process query: %w", err)
+			}
+			// Fall through to analysis with the data gathered so far
+			break
+		}
+
+		responseText, err := extractText(resp)
+		if err != nil {
+			s.log(ctx).Warn("Unusable MCP response", zap.Error(err), zap.Int("iteration", iteration))
+			if totalCalls == 0 {
+				return nil, err
+			}
+			break
+		}
+		if explain {
+			steps = append(steps, QueryStep{Step: iteration, Model: modelName, ToolSelection: responseText})
+		}
+
+		// Parse the AI response to see if it wants to use tools
+		aiAction, ok := parseQueryAction(responseText)
+		if !ok && hitTokenLimit(resp) {
+			// A truncated decision is not a direct answer; close the JSON if possible
+			if repaired, repairedOK := repairTruncatedJSON(responseText); repairedOK {
+				aiAction, ok = parseQueryAction(repaired)
+			}
+			if !ok {
+				s.logParseFailure(ctx, ErrResponseTruncated, responseText)
+				if totalCalls == 0 {
+					return nil, ErrResponseTruncated
+				}
+				break
+			}
+			s.log(ctx).Warn("Repaired tool selection truncated at the output token limit", zap.Int("iteration", iteration))
+		}
+		if !ok {
+			if totalCalls == 0 {
+				// If all parsing fails, treat it as a direct response
+				return &QueryResponse{
+					Response: responseText,
+					UsedTool: false,
+					Model:    modelName,
+					Steps:    steps,
+				}, nil
+			}
+			break
+		}
+
+		calls := aiAction.toolCalls()
+		if (aiAction.Action != "use_tool" && aiAction.Action != "use_tools") || len(calls) == 0 {
+			if totalCalls == 0 {
+				// Direct answer without tools
+				return &QueryResponse{
+					Response: aiAction.Response,
+					UsedTool: false,
+					Model:    modelName,
+					Steps:    steps,
+				}, nil
+			}
+			break
+		}
+
+		// Skip calls that were already made so the model can't loop on the same request
+		newCalls := make([]toolCall, 0, len(calls))
+		for _, call := range calls {
+			key := call.key()
+			if !executed[key] {
+				executed[key] = true
+				newCalls = append(newCalls, call)
+			}
+		}
+		if len(newCalls) == 0 {
+			s.log(ctx).Debug("Model repeated previous tool calls, moving to analysis", zap.Int("iteration", iteration))
+			break
+		}
+
+		if len(newCalls) > maxToolCallsPerStep {
+			s.log(ctx).Warn("Model requested too many tools, truncating",
+				zap.Int("requested", len(newCalls)),
+				zap.Int("max", maxToolCallsPerStep))
+			newCalls = newCalls[:maxToolCallsPerStep]
+		}
+
+		output, failed := s.executeToolCalls(gatherCtx, newCalls, notify)
+		fmt.Fprintf(&gathered, "## Step %d\n%s\n", iteration, output)
+		if explain {
+			step := &steps[len(steps)-1]
+			for _, call := range newCalls {
+				step.Tools = append(step.Tools, call.Tool)
+			}
+			step.ToolOutput = output
+		}
+		for _, call := range newCalls {
+			toolNames = append(toolNames, call.Tool)
+		}
+		totalCalls += len(newCalls)
+		failedCalls += failed
+
+		if iteration == s.maxToolIterations {
+			s.log(ctx).Warn("Reached maximum tool iterations, analyzing gathered data",
+				zap.Int("maxIterations", s.maxToolIterations))
+		}
+		if timedOut(gatherCtx) {
+			s.log(ctx).Warn("Query time for tool calls used up, analyzing gathered data", zap.Int("iteration", iteration))
+			break
+		}
+	}
+
+	toolOutput := gathered.String()
+	toolsUsed := strings.Join(toolNames, ", ")
+
+	if failedCalls == totalCalls {
+		errMsg := "all tool executions failed"
+		if timedOut(gatherCtx) {
+			errMsg = "tool execution timed out"
+		}
+		return &QueryResponse{
+			Response:  fmt.Sprintf("Error executing tools %s:\n%s", toolsUsed, toolOutput),
+			UsedTool:  true,
+			ToolUsed:  toolsUsed,
+			ToolsUsed: toolNames,
+			Error:     errMsg,
+			Steps:     steps,
+		}, nil
+	}
+
+	// Now ask AI to analyze the tool results
+	analysisPrompt := fmt.Sprintf(`Based on the following Kubernetes cluster data, provide a comprehensive answer to the user's query.
+
+Format your response using markdown for better readability:
+- Use headers (## ) for main sections
+- Use bullet points for lists
+- Use **bold** for important information
+- Use code blocks for kubectl commands or resource names
+- Include specific recommendations and next steps
+
+Original Query: %s
+%s
+Cluster Data:
+%s
+
+Provide a well-structured markdown response analyzing this data with clear sections for current state, findings, and recommendations.`, query, conversation, s.analysisData(ctx, toolOutput))
+	analysisPrompt = s.applySystemPrompt(ctx, analysisPrompt)
+
+	notify(QueryEvent{
+		Type:    QueryEventAnalysis,
+		Message: "Analyzing results",
+		Tool:    toolsUsed,
+	})
+
+	var analysisResp *genai.GenerateContentResponse
+	var analysisModel string
+	var err error
+	if stream {
+		analysisResp, analysisModel, err = s.generateContentStream(ctx, model, "query_analysis", analysisPrompt, func(text string) {
+			notify(QueryEvent{Type: QueryEventDelta, Message: text})
+		})
+	} else {
+		analysisResp, analysisModel, err = s.generateContent(ctx, model, "query_analysis", analysisPrompt)
+	}
+	if err != nil && timedOut(ctx) {
+		s.log(ctx).Warn("Query analysis timed out, returning gathered data", zap.Error(err))
+		return &QueryResponse{
+			Response:  fmt.Sprintf("Analysis timed out; here is the raw data gathered so far:\n\n%s", toolOutput),
+			UsedTool:  true,
+			ToolUsed:  toolsUsed,
+			ToolsUsed: toolNames,
+			RawData:   toolOutput,
+			Error:     "analysis timed out",
+			Steps:     steps,
+		}, nil
+	}
+	if err != nil {
+		s.log(ctx).Warn("Query analysis failed, returning gathered data", zap.Error(err))
+		return &QueryResponse{
+			Response:  fmt.Sprintf("Gathered data but failed to analyze: %s", toolOutput),
+			UsedTool:  true,
+			ToolUsed:  toolsUsed,
+			ToolsUsed: toolNames,
+			RawData:   toolOutput,
+			Error:     err.Error(),
+			Steps:     steps,
+		}, nil
+	}
+
+	analysisText, err := extractText(analysisResp)
+	if errors.Is(err, ErrContentBlocked) {
+		s.log(ctx).Warn("Query analysis was blocked, returning gathered data", zap.Error(err))
+		return &QueryResponse{
+			Response:  fmt.Sprintf("Gathered data but the analysis was blocked: %v\n\n%s", err, toolOutput),
+			UsedTool:  true,
+			ToolUsed:  toolsUsed,
+			ToolsUsed: toolNames,
+			RawData:   toolOutput,
+			Error:     err.Error(),
+			Steps:     steps,
+		}, nil
+	}
+	if hitTokenLimit(analysisResp) {
+		s.log(ctx).Warn("Query analysis truncated at the output token limit")
+		analysisText += "\n\n---\n*This response was truncated at the model's output limit. Try a narrower query for a complete answer.*"
+	}
+
+	return &QueryResponse{
+		Response:  analysisText,
+		UsedTool:  true,
+		ToolUsed:  toolsUsed,
+		ToolsUsed: toolNames,
+		RawData:   toolOutput,
+		Model:     analysisModel,
+		Steps:     steps,
+	}, nil
+}
+
+// withAnalysisReserve returns a context that expires 1/analysisReserveDivisor of the remaining time before
+// ctx's deadline, or one that is only cancelled with ctx when it has no deadline
+func withAnalysisReserve(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	reserve := time.Until(deadline) / analysisReserveDivisor
+	return context.WithDeadline(ctx, deadline.Add(-reserve))
+}
+
+// timedOut reports whether ctx has passed its deadline, as opposed to being cancelled by the caller
+func timedOut(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// buildQueryPrompt renders the tool-selection prompt, including any conversation context and data gathered in earlier steps
+func buildQueryPrompt(query, toolsJSON, conversation, gathered string) string {
+	history := ""
+	if gathered != "" {
+		history = fmt.Sprintf(`
+Cluster data gathered so far:
+%s
+Use this data to decide whether you need more information. Do not repeat tool calls you have already made.
+If you have enough data, respond with {"action": "answer"}.
+`, gathered)
+	}
+
+	return fmt.Sprintf(`You are a Kubernetes expert assistant. Answer the user's query using available tools when needed.
+
+Query: %s
+%s
+Available Tools:
+%s
+%s
+CRITICAL: Respond ONLY with valid JSON. NO explanations, NO markdown, NO code blocks.
+
+If you need cluster data from a single tool:
+{"action": "use_tool", "tool": "tool_name", "arguments": {"param": "value"}}
+
+If you need cluster data from several tools (at most %d):
+{"action": "use_tools", "tools": [{"tool": "tool_name", "arguments": {"param": "value"}}, {"tool": "other_tool", "arguments": {}}]}
+
+If you can answer directly:
+{"action": "answer", "response": "## Your markdown-formatted answer here\n\nUse proper markdown formatting with headers, bullet points, and **bold** text for better readability."}
+
+Choose the most appropriate tools for the query and respond immediately.`, query, conversation, toolsJSON, history, maxToolCallsPerStep)
+}
+
+// executeToolCalls runs the requested tools concurrently and returns their combined output in request order
+// along with the number of calls that failed
+func (s *Service) executeToolCalls(ctx context.Context, calls []toolCall, notify QueryEventFunc) (string, int) {
+	requests := make([]mcp.ToolRequest, len(calls))
+	for i, call := range calls {
+		notify(QueryEvent{
+			Type:    QueryEventToolExecution,
+			Message: fmt.Sprintf("Calling tool %s", call.Tool),
+			Tool:    call.Tool,
+		})
+		requests[i] = mcp.ToolRequest{Name: call.Tool, Arguments: call.Arguments}
+	}
+	results := s.mcpService.ExecuteTools(ctx, requests)
+
+	var combined strings.Builder
+	failed := 0
+	for _, result := range results {
+		if len(calls) > 1 {
+			fmt.Fprintf(&combined, "### Tool: %s\n", result.Name)
+		}
+		if result.Err != nil {
+			failed++
+			fmt.Fprintf(&combined, "Error executing tool %s: %v\n", result.Name, result.Err)
+		} else if result.Result != nil {
+			for _, content := range result.Result.Content {
+				combined.WriteString(content.Text + "\n")
+			}
+		}
+		if len(calls) > 1 {
+			combined.WriteString("\n")
+		}
+	}
+
+	return combined.String(), failed
+}
+
+// parseQueryAction extracts the model's JSON decision from its response text
+func parseQueryAction(responseText string) (*queryAction, bool) {
+	var aiAction queryAction
+
+	// Extract JSON from markdown code blocks if present
+	jsonText := responseText
+	if strings.Contains(responseText, "```json") {
+		// Find the JSON block
+		start := strings.Index(responseText, "```json") + 7
+		end := strings.Index(responseText[start:], "```")
+		if end != -1 {
+			jsonText = strings.TrimSpace(responseText[start : start+end])
+		}
+	}
+
+	// First try to parse as direct action
+	if err := json.Unmarshal([]byte(jsonText), &aiAction); err == nil {
+		return &aiAction, true
+	}
+
+	// If that fails, try to extract any JSON object embedded in the response text
+	start := strings.Index(responseText, "{")
+	end := strings.LastIndex(responseText, "}")
+	if start != -1 && end != -1 && end > start {
+		if err := json.Unmarshal([]byte(responseText[start:end+1]), &aiAction); err == nil {
+			return &aiAction, true
+		}
+	}
+
+	return nil, false
+}
+
+// QueryEvent reports progress of an MCP query to streaming clients
+type QueryEvent struct {
+	Type     string         `json:"type"`
+	Message  string         `json:"message,omitempty"`
+	Tool     string         `json:"tool,omitempty"`
+	Response *QueryResponse `json:"response,omitempty"`
+}
+
+// QueryEventFunc receives progress events from QueryWithMCPEvents
+type QueryEventFunc func(QueryEvent)
+
+// Query event types
+const (
+	QueryEventToolSelection = "tool_selection"
+	QueryEventToolExecution = "tool_execution"
+	QueryEventAnalysis      = "analysis"
+	// QueryEventDelta carries the next piece of a streamed analysis in Message
+	QueryEventDelta  = "delta"
+	QueryEventAnswer = "answer"
+	QueryEventError  = "error"
+)
+
+// QueryResponse represents the response from an MCP-enabled query
+type QueryResponse struct {
+	Response  string   `json:"response"`
+	UsedTool  bool     `json:"usedTool"`
+	ToolUsed  string   `json:"toolUsed,omitempty"`
+	ToolsUsed []string `json:"toolsUsed,omitempty"`
+	RawData   string   `json:"rawData,omitempty"`
+	Error     string   `json:"error,omitempty"`
+	// Model is the Gemini model that produced the final response
+	Model string `json:"model,omitempty"`
+	// Steps records each tool-selection round; it is only set for queries run with ContextWithExplain
+	Steps []QueryStep `json:"steps,omitempty"`
+	ResponseExtras
+}
+
+// QueryStep is one tool-selection round of an explained MCP query
+type QueryStep struct {
+	Step int `json:"step"`
+	// Model is the Gemini model that made the decision
+	Model string `json:"model,omitempty"`
+	// ToolSelection is the model's raw decision, normally the JSON naming the tools to call
+	ToolSelection string `json:"toolSelection"`
+	// Tools lists the tools executed for this step, after duplicates of earlier calls were dropped
+	Tools []string `json:"tools,omitempty"`
+	// ToolOutput is the raw output of those tools
+	ToolOutput string `json:"toolOutput,omitempty"`
+}
@@ -0,0 +1,120 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"go.uber.org/zap"
+)
+
+// maxRepairAttempts bounds how many cut points repairTruncatedJSON tries before giving up
+const maxRepairAttempts = 20
+
+// parseJSONResponse unmarshals the model's JSON answer into v, falling back to the JSON object embedded in
+// a free-form answer. When the model stopped at its output token limit, the truncated JSON is closed and parsed; if that fails too the error wraps ErrResponseTruncated
+func (s *Service) parseJSONResponse(ctx context.Context, resp *genai.GenerateContentResponse, responseText string, v interface{}) error {
+	err := json.Unmarshal([]byte(responseText), v)
+	if err == nil {
+		return nil
+	}
+	// Models answering without a response schema may wrap the JSON in a code fence or prose
+	if object, ok := extractJSONObject(responseText); ok && json.Unmarshal([]byte(object), v) == nil {
+		return nil
+	}
+
+	if !hitTokenLimit(resp) {
+		s.logParseFailure(ctx, err, responseText)
+		return fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	}
+
+	if repaired, ok := repairTruncatedJSON(responseText); ok && json.Unmarshal([]byte(repaired), v) == nil {
+		s.log(ctx).Warn("Repaired JSON response truncated at the output token limit",
+			zap.Int("responseLength", len(responseText)))
+		return nil
+	}
+
+	s.logParseFailure(ctx, err, responseText)
+	return fmt.Errorf("%w: %w", ErrResponseTruncated, err)
+}
+
+// hitTokenLimit reports whether the model stopped generating because it reached its output token limit
+func hitTokenLimit(resp *genai.GenerateContentResponse) bool {
+	return resp != nil && len(resp.Candidates) > 0 && resp.Candidates[0].FinishReason == genai.FinishReasonMaxTokens
+}
+
+// repairTruncatedJSON closes JSON that was cut off mid-document: an open string is terminated and open
+// objects and arrays are closed. If that doesn't produce valid JSON, the text is cut back to each
+// earlier comma in turn, dropping the incomplete element, until it does
+func repairTruncatedJSON(text string) (string, bool) {
+	text = strings.TrimSpace(text)
+	if start := strings.IndexAny(text, "{["); start > 0 {
+		text = text[start:]
+	}
+
+	type cutPoint struct {
+		index int
+		open  []byte
+	}
+
+	var open []byte
+	var cuts []cutPoint
+	inString, escaped := false, false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			open = append(open, c)
+		case c == '}' || c == ']':
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+		case c == ',':
+			cuts = append(cuts, cutPoint{index: i, open: append([]byte(nil), open...)})
+		}
+	}
+	if len(open) == 0 {
+		return "", false
+	}
+
+	// First try closing everything where the text ends
+	candidate := text
+	if escaped {
+		candidate = candidate[:len(candidate)-1]
+	}
+	if inString {
+		candidate += `"`
+	}
+	if repaired := candidate + closers(open); json.Valid([]byte(repaired)) {
+		return repaired, true
+	}
+
+	for i, attempts := len(cuts)-1, 0; i >= 0 && attempts < maxRepairAttempts; i, attempts = i-1, attempts+1 {
+		if repaired := text[:cuts[i].index] + closers(cuts[i].open); json.Valid([]byte(repaired)) {
+			return repaired, true
+		}
+	}
+	return "", false
+}
+
+// closers returns the brackets that close the open objects and arrays, innermost first
+func closers(open []byte) string {
+	var b strings.Builder
+	for i := len(open) - 1; i >= 0; i-- {
+		if open[i] == '{' {
+			b.WriteByte('}')
+		} else {
+			b.WriteByte(']')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,120 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// jsonMIMEType asks the model for a JSON response
+const jsonMIMEType = "application/json"
+
+// assessmentLevelSchema is an effort or risk hint
+var assessmentLevelSchema = &genai.Schema{
+	Type:   genai.TypeString,
+	Format: "enum",
+	Enum:   []string{"low", "medium", "high"},
+}
+
+// troubleshootSchema matches troubleshootAssessment
+var troubleshootSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"potentialCauses": {
+			Type: genai.TypeArray,
+			Items: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"cause":      {Type: genai.TypeString},
+					"confidence": {Type: genai.TypeNumber, Description: "Estimate from 0 to 1 that this is the root cause"},
+				},
+				Required: []string{"cause", "confidence"},
+			},
+		},
+		"suggestedSolutions": {
+			Type: genai.TypeArray,
+			Items: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"solution": {Type: genai.TypeString},
+					"effort":   assessmentLevelSchema,
+					"risk":     assessmentLevelSchema,
+				},
+				Required: []string{"solution"},
+			},
+		},
+	},
+	Required: []string{"potentialCauses", "suggestedSolutions"},
+}
+
+// suggestResourcesSchema matches SuggestResourcesResponse
+var suggestResourcesSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"suggestedResources": {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString}},
+		"reasoning":          {Type: genai.TypeString},
+	},
+	Required: []string{"suggestedResources", "reasoning"},
+}
+
+// summarizeSchema matches SummarizeResponse
+var summarizeSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"summary": {Type: genai.TypeString},
+	},
+	Required: []string{"summary"},
+}
+
+// setResponseSchema makes the model answer with JSON matching schema instead of free-form text
+func setResponseSchema(model *genai.GenerativeModel, schema *genai.Schema) {
+	model.ResponseMIMEType = jsonMIMEType
+	model.ResponseSchema = schema
+}
+
+// withoutResponseSchema returns a copy of model that answers with free-form text, for models that reject
+// a response schema
+func withoutResponseSchema(model *genai.GenerativeModel) *genai.GenerativeModel {
+	plain := *model
+	plain.ResponseMIMEType = ""
+	plain.ResponseSchema = nil
+	return &plain
+}
+
+// schemaRejected reports whether the model refused a request because it doesn't support JSON responses or
+// response schemas
+func schemaRejected(err error) bool {
+	code := status.Code(err)
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusBadRequest {
+		code = codes.InvalidArgument
+	}
+	if code != codes.InvalidArgument {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	for _, hint := range []string{"response_schema", "response_mime_type", "responseschema", "responsemimetype", "json mode"} {
+		if strings.Contains(message, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractJSONObject returns the outermost JSON object in text, dropping Markdown code fences and any prose
+// around it, for models that answer without a response schema
+func extractJSONObject(text string) (string, bool) {
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return "", false
+	}
+	candidate := text[start : end+1]
+	return candidate, json.Valid([]byte(candidate))
+}
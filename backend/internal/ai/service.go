@@ -2,15 +2,20 @@ package ai
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"regexp"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/generative-ai-go/genai"
 	"go.uber.org/zap"
-	"google.golang.org/api/option"
 
+	"kube-sherlock/internal/logging"
 	"kube-sherlock/internal/mcp"
+	"kube-sherlock/internal/metrics"
 )
 
 // Service handles AI-powered analysis using Google Gemini
@@ -19,39 +24,207 @@ type Service struct {
 	model      string
 	logger     *zap.Logger
 	mcpService *mcp.MCPService
+	dryRunOut  io.Writer
+	// systemPrompt is prepended to every prompt unless overridden per request
+	systemPrompt string
+	// maxToolIterations bounds the decide/execute cycles in QueryWithMCP
+	maxToolIterations int
+	// queryTimeout bounds the whole QueryWithMCP flow; zero leaves it to the caller's context
+	queryTimeout time.Duration
+	// fallbackModels are tried in order when the primary model is overloaded
+	fallbackModels []string
+	// summarizeChunkBytes is the largest resource data summarized in a single request
+	summarizeChunkBytes int
+	// analysisDataBytes is the most tool output embedded verbatim in a query analysis prompt
+	analysisDataBytes int
+	// modelTokenLimits overrides the built-in input token limits by model name
+	modelTokenLimits map[string]int
+	// endpoint overrides the public Gemini API endpoint, e.g. for a proxy
+	endpoint string
+	// credentialsFile and useADC authenticate with Google credentials when no API key is set
+	credentialsFile string
+	useADC          bool
+	// responseProcessors derive structured fields such as suggested commands from responses
+	responseProcessors []ResponseProcessor
+	// provider selects Gemini or the mock provider; mock is set when the mock provider is used
+	provider         string
+	mockFixturesFile string
+	mock             *mockProvider
+	// troubleshootCache reuses TroubleshootError responses for repeated errors; nil when caching is off
+	troubleshootCache *responseCache
+	// requestSlots bounds the Gemini requests in flight; nil when unbounded
+	requestSlots chan struct{}
 }
 
+// Option configures optional behavior of the AI service
+type Option func(*Service)
+
+// WithDryRun makes the service print rendered prompts to out instead of calling Gemini
+func WithDryRun(out io.Writer) Option {
+	return func(s *Service) {
+		s.dryRunOut = out
+	}
+}
+
+// parseFailurePreviewLen bounds how much of an unparseable response is logged at error level
+const parseFailurePreviewLen = 200
+
+// base64BlobPattern matches long base64-looking runs such as secret data or tokens
+var base64BlobPattern = regexp.MustCompile(`[A-Za-z0-9+/_-]{40,}={0,2}`)
+
+// WithSystemPrompt sets a default system prompt prepended to every prompt
+func WithSystemPrompt(prompt string) Option {
+	return func(s *Service) {
+		s.systemPrompt = prompt
+	}
+}
+
+// WithMaxToolIterations bounds how many tool-calling rounds a single query may run
+func WithMaxToolIterations(n int) Option {
+	return func(s *Service) {
+		if n > 0 {
+			s.maxToolIterations = n
+		}
+	}
+}
+
+// WithQueryTimeout bounds the whole MCP query flow, including tool execution and the final analysis.
+// Non-positive values leave the deadline to the caller's context
+func WithQueryTimeout(timeout time.Duration) Option {
+	return func(s *Service) {
+		if timeout > 0 {
+			s.queryTimeout = timeout
+		}
+	}
+}
+
+// WithFallbackModels sets models to try, in order, when the primary model is overloaded or rate limited
+func WithFallbackModels(models ...string) Option {
+	return func(s *Service) {
+		for _, model := range models {
+			if model != "" && model != s.model {
+				s.fallbackModels = append(s.fallbackModels, model)
+			}
+		}
+	}
+}
+
+type systemPromptKey struct{}
+
+// ContextWithSystemPrompt returns a context that overrides the configured system prompt for one request
+func ContextWithSystemPrompt(ctx context.Context, prompt string) context.Context {
+	if prompt == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, systemPromptKey{}, prompt)
+}
+
+// dryRunNotice is the placeholder text returned by stub responses in dry-run mode
+const dryRunNotice = "[dry-run] prompt was not sent to the model"
+
 // TroubleshootResponse represents the response from troubleshooting
 type TroubleshootResponse struct {
+	// PotentialCauses is ordered by confidence, most likely first
 	PotentialCauses    []string `json:"potentialCauses"`
 	SuggestedSolutions []string `json:"suggestedSolutions"`
+	// Causes and Solutions repeat PotentialCauses and SuggestedSolutions, in the same order, with the
+	// model's confidence and effort and risk hints. Each is omitted when the model didn't assess its items
+	Causes    []CauseAssessment    `json:"causes,omitempty"`
+	Solutions []SolutionAssessment `json:"solutions,omitempty"`
+	// Model is the Gemini model that produced the response
+	Model string `json:"model,omitempty"`
+	// Cached is set when the response was reused from an earlier analysis of the same error
+	Cached bool `json:"cached,omitempty"`
+	// Passes is the number of model passes merged into the response when several were run
+	Passes int `json:"passes,omitempty"`
+	ResponseExtras
+}
+
+// CauseAssessment is a potential cause with the model's confidence, from 0 to 1, that it is the root cause
+type CauseAssessment struct {
+	Cause      string  `json:"cause"`
+	Confidence float64 `json:"confidence"`
+	// Passes is how many of several merged passes found the cause
+	Passes int `json:"passes,omitempty"`
+}
+
+// SolutionAssessment is a suggested solution with hints of the effort it takes and the risk of applying it,
+// each low, medium or high when given
+type SolutionAssessment struct {
+	Solution string `json:"solution"`
+	Effort   string `json:"effort,omitempty"`
+	Risk     string `json:"risk,omitempty"`
+	// Passes is how many of several merged passes suggested the solution
+	Passes int `json:"passes,omitempty"`
 }
 
 // SuggestResourcesResponse represents the response with suggested resources
 type SuggestResourcesResponse struct {
 	SuggestedResources []string `json:"suggestedResources"`
 	Reasoning          string   `json:"reasoning"`
+	// Model is the Gemini model that produced the response
+	Model string `json:"model,omitempty"`
 }
 
 // SummarizeResponse represents the response with summarized data
 type SummarizeResponse struct {
 	Summary string `json:"summary"`
+	// Model is the Gemini model that produced the response
+	Model string `json:"model,omitempty"`
 }
 
-// NewService creates a new AI service
-func NewService(apiKey, model string, logger *zap.Logger) *Service {
-	ctx := context.Background()
-	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
-	if err != nil {
-		logger.Fatal("Failed to create Gemini client", zap.Error(err))
-	}
-
-	return &Service{
-		client:     client,
+// NewService creates a new AI service. It returns ErrNoAPIKey when apiKey is empty and no credentials
+// file or Application Default Credentials are configured (unless in dry-run mode) and an error if the
+// Gemini client cannot be created; it never exits the process.
+func NewService(apiKey, model string, logger *zap.Logger, opts ...Option) (*Service, error) {
+	s := &Service{
 		model:      model,
 		logger:     logger,
 		mcpService: nil, // Will be set later when needed
+
+		maxToolIterations:   defaultMaxToolIterations,
+		summarizeChunkBytes: defaultSummarizeChunkBytes,
+		analysisDataBytes:   defaultAnalysisDataBytes,
+		responseProcessors:  []ResponseProcessor{ExtractCommands},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	// No client is needed when prompts are only printed
+	if s.dryRunOut != nil {
+		return s, nil
 	}
+
+	switch s.provider {
+	case "", ProviderGemini:
+	case ProviderMock:
+		mock, err := newMockProvider(s.mockFixturesFile)
+		if err != nil {
+			return nil, err
+		}
+		s.mock = mock
+		s.model = MockModelName
+		s.fallbackModels = nil
+		logger.Warn("Using the mock AI provider; responses are canned, not generated", zap.String("fixtures", s.mockFixturesFile))
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unknown AI provider %q: use %s or %s", s.provider, ProviderGemini, ProviderMock)
+	}
+
+	ctx := context.Background()
+	clientOpts, err := s.clientOptions(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	client, err := genai.NewClient(ctx, clientOpts...)
+	if err != nil {
+		logger.Error("Failed to create Gemini client", zap.Error(err))
+		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+	}
+	s.client = client
+
+	return s, nil
 }
 
 // SetMCPService sets the MCP service for tool execution
@@ -59,53 +232,146 @@ func (s *Service) SetMCPService(mcpService *mcp.MCPService) {
 	s.mcpService = mcpService
 }
 
+// log returns the request-scoped logger from ctx, falling back to the service logger
+func (s *Service) log(ctx context.Context) *zap.Logger {
+	return logging.FromContext(ctx, s.logger)
+}
+
 // Close closes the AI service client
 func (s *Service) Close() error {
+	if s.client == nil {
+		return nil
+	}
 	return s.client.Close()
 }
 
+// IsDryRun reports whether the service prints prompts instead of calling Gemini
+func (s *Service) IsDryRun() bool {
+	return s.dryRunOut != nil
+}
+
+// applySystemPrompt prepends the request or configured system prompt to a prompt
+func (s *Service) applySystemPrompt(ctx context.Context, prompt string) string {
+	systemPrompt := s.systemPrompt
+	if override, ok := ctx.Value(systemPromptKey{}).(string); ok {
+		systemPrompt = override
+	}
+	if systemPrompt == "" {
+		return prompt
+	}
+	return strings.TrimSpace(systemPrompt) + "\n\n" + prompt
+}
+
+// printDryRun writes the rendered prompt when in dry-run mode and reports whether the model call should be skipped
+func (s *Service) printDryRun(name, prompt string) bool {
+	if s.dryRunOut == nil {
+		return false
+	}
+	fmt.Fprintf(s.dryRunOut, "----- %s prompt (model: %s) -----\n%s\n----- end %s prompt -----\n\n", name, s.model, prompt, name)
+	return true
+}
+
+// ModelInfo describes the configured Gemini model
+type ModelInfo struct {
+	Name             string `json:"name"`
+	DisplayName      string `json:"displayName"`
+	InputTokenLimit  int32  `json:"inputTokenLimit"`
+	OutputTokenLimit int32  `json:"outputTokenLimit"`
+}
+
+// CheckModel verifies the API key and model name with a lightweight model lookup
+func (s *Service) CheckModel(ctx context.Context) (*ModelInfo, error) {
+	if s.mock != nil {
+		return &ModelInfo{Name: MockModelName, DisplayName: "Mock provider (canned responses)"}, nil
+	}
+	if s.client == nil {
+		return nil, fmt.Errorf("Gemini client not initialized")
+	}
+
+	info, err := s.client.GenerativeModel(s.model).Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model info for %s: %w", s.model, classifyModelError(err))
+	}
+
+	return &ModelInfo{
+		Name:             info.Name,
+		DisplayName:      info.DisplayName,
+		InputTokenLimit:  info.InputTokenLimit,
+		OutputTokenLimit: info.OutputTokenLimit,
+	}, nil
+}
+
 // TroubleshootError analyzes a Kubernetes error and provides troubleshooting guidance
 func (s *Service) TroubleshootError(ctx context.Context, errorMessage string) (*TroubleshootResponse, error) {
+	prompt := s.troubleshootPrompt(ctx, errorMessage)
+
+	if s.printDryRun("troubleshoot", prompt) {
+		return &TroubleshootResponse{
+			PotentialCauses:    []string{dryRunNotice},
+			SuggestedSolutions: []string{dryRunNotice},
+		}, nil
+	}
+
+	cacheKey := s.troubleshootCacheKey(ctx, errorMessage)
+	if cached, ok := s.troubleshootCache.get(cacheKey); ok {
+		s.log(ctx).Debug("Reusing cached troubleshoot response", zap.String("model", cached.Model))
+		return cached, nil
+	}
+
+	// Lower temperature for more consistent technical responses
+	result, err := s.troubleshootPass(ctx, prompt, 0.1)
+	if err != nil {
+		return nil, err
+	}
+	s.processResponse(strings.Join(append(append([]string{}, result.PotentialCauses...), result.SuggestedSolutions...), "\n"), &result.ResponseExtras)
+	s.troubleshootCache.put(cacheKey, result)
+	return result, nil
+}
+
+// troubleshootPrompt renders the troubleshoot prompt for an error message, with the system prompt applied
+func (s *Service) troubleshootPrompt(ctx context.Context, errorMessage string) string {
 	prompt := fmt.Sprintf(`You are a Kubernetes expert specializing in troubleshooting errors. Analyze the provided error message or event description to determine potential causes and suggest solutions.
 
 Error Message/Event Description: %s
 
 Provide your output in the following JSON format:
 {
-  "potentialCauses": ["cause1", "cause2", "cause3"],
-  "suggestedSolutions": ["solution1", "solution2", "solution3"]
+  "potentialCauses": [{"cause": "cause1", "confidence": 0.7}, {"cause": "cause2", "confidence": 0.2}],
+  "suggestedSolutions": [{"solution": "solution1", "effort": "low", "risk": "low"}, {"solution": "solution2", "effort": "medium", "risk": "high"}]
 }
 
+"confidence" is your estimate, from 0 to 1, that the cause is the root cause; list the most likely cause first. "effort" and "risk" are "low", "medium" or "high": how much work the solution takes and how likely applying it is to disrupt running workloads.
+
 Focus on practical, actionable solutions. Be specific about kubectl commands, configuration changes, or diagnostic steps.`, errorMessage)
+	return s.applySystemPrompt(ctx, prompt)
+}
 
-	model := s.client.GenerativeModel(s.model)
-	model.SetTemperature(0.1) // Lower temperature for more consistent technical responses
+// troubleshootPass sends a rendered troubleshoot prompt to the model at temperature and parses its causes
+// and solutions. Response processors are left to the caller
+func (s *Service) troubleshootPass(ctx context.Context, prompt string, temperature float32) (*TroubleshootResponse, error) {
+	model := s.generativeModel()
+	model.SetTemperature(temperature)
+	setResponseSchema(model, troubleshootSchema)
 
-	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
+	resp, modelName, err := s.generateContent(ctx, model, "troubleshoot", prompt)
 	if err != nil {
-		s.logger.Error("Failed to generate content for troubleshooting", zap.Error(err))
+		s.log(ctx).Error("Failed to generate content for troubleshooting", zap.Error(err))
 		return nil, fmt.Errorf("failed to analyze error: %w", err)
 	}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("no response generated")
-	}
-
-	// Extract text from response
-	responseText := ""
-	for _, part := range resp.Candidates[0].Content.Parts {
-		if text, ok := part.(genai.Text); ok {
-			responseText += string(text)
-		}
+	responseText, err := extractText(resp)
+	if err != nil {
+		return nil, err
 	}
 
 	// Parse JSON response
-	var result TroubleshootResponse
-	if err := json.Unmarshal([]byte(responseText), &result); err != nil {
-		s.logger.Error("Failed to parse AI response", zap.Error(err), zap.String("response", responseText))
-		return nil, fmt.Errorf("failed to parse AI response: %w", err)
+	var assessed troubleshootAssessment
+	if err := s.parseJSONResponse(ctx, resp, responseText, &assessed); err != nil {
+		return nil, err
 	}
 
+	result := assessed.response()
+	result.Model = modelName
 	return &result, nil
 }
 
@@ -122,40 +388,47 @@ Provide your output in the following JSON format:
   "suggestedResources": ["pod/example-pod logs", "deployment/example-deployment configuration", "service/example-service description"],
   "reasoning": "Pod logs may contain error messages. Deployment configuration can show misconfigurations. Service description can show service unavailable issues."
 }`, errorDescription)
+	prompt = s.applySystemPrompt(ctx, prompt)
 
-	model := s.client.GenerativeModel(s.model)
+	if s.printDryRun("suggest-resources", prompt) {
+		return &SuggestResourcesResponse{
+			SuggestedResources: []string{},
+			Reasoning:          dryRunNotice,
+		}, nil
+	}
+
+	model := s.generativeModel()
 	model.SetTemperature(0.1)
+	setResponseSchema(model, suggestResourcesSchema)
 
-	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
+	resp, modelName, err := s.generateContent(ctx, model, "suggest_resources", prompt)
 	if err != nil {
-		s.logger.Error("Failed to generate content for resource suggestions", zap.Error(err))
+		s.log(ctx).Error("Failed to generate content for resource suggestions", zap.Error(err))
 		return nil, fmt.Errorf("failed to suggest resources: %w", err)
 	}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("no response generated")
-	}
-
-	// Extract text from response
-	responseText := ""
-	for _, part := range resp.Candidates[0].Content.Parts {
-		if text, ok := part.(genai.Text); ok {
-			responseText += string(text)
-		}
+	responseText, err := extractText(resp)
+	if err != nil {
+		return nil, err
 	}
 
 	// Parse JSON response
 	var result SuggestResourcesResponse
-	if err := json.Unmarshal([]byte(responseText), &result); err != nil {
-		s.logger.Error("Failed to parse AI response", zap.Error(err), zap.String("response", responseText))
-		return nil, fmt.Errorf("failed to parse AI response: %w", err)
+	if err := s.parseJSONResponse(ctx, resp, responseText, &result); err != nil {
+		return nil, err
 	}
 
+	result.Model = modelName
 	return &result, nil
 }
 
 // SummarizeResourceData summarizes Kubernetes resource data for diagnosis
+// Data larger than the summarize chunk size is summarized in parts that are then combined
 func (s *Service) SummarizeResourceData(ctx context.Context, resourceData string) (*SummarizeResponse, error) {
+	if len(resourceData) > s.summarizeChunkSize() {
+		return s.SummarizeResourceSections(ctx, []ResourceSection{{Source: "resource data", Data: resourceData}})
+	}
+
 	prompt := fmt.Sprintf(`You are an expert Kubernetes troubleshooter. Your task is to summarize the provided data from Kubernetes resources, highlighting only the relevant information for diagnosing issues. Ignore any irrelevant details.
 
 Resource Data:
@@ -165,218 +438,218 @@ Provide your output in the following JSON format:
 {
   "summary": "A summarized version of the input resource data, highlighting the relevant information for diagnosing issues."
 }`, resourceData)
+	return s.summarize(ctx, "summarize", prompt)
+}
+
+// summarize sends a summarization prompt and parses the JSON summary, recording metrics under operation
+func (s *Service) summarize(ctx context.Context, operation, prompt string) (*SummarizeResponse, error) {
+	prompt = s.applySystemPrompt(ctx, prompt)
 
-	model := s.client.GenerativeModel(s.model)
+	if s.printDryRun(operation, prompt) {
+		return &SummarizeResponse{Summary: dryRunNotice}, nil
+	}
+
+	model := s.generativeModel()
 	model.SetTemperature(0.1)
+	setResponseSchema(model, summarizeSchema)
 
-	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
+	resp, modelName, err := s.generateContent(ctx, model, operation, prompt)
 	if err != nil {
-		s.logger.Error("Failed to generate content for summarization", zap.Error(err))
+		s.log(ctx).Error("Failed to generate content for summarization", zap.Error(err))
 		return nil, fmt.Errorf("failed to summarize data: %w", err)
 	}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("no response generated")
-	}
-
-	// Extract text from response
-	responseText := ""
-	for _, part := range resp.Candidates[0].Content.Parts {
-		if text, ok := part.(genai.Text); ok {
-			responseText += string(text)
-		}
+	responseText, err := extractText(resp)
+	if err != nil {
+		return nil, err
 	}
 
 	// Parse JSON response
 	var result SummarizeResponse
-	if err := json.Unmarshal([]byte(responseText), &result); err != nil {
-		s.logger.Error("Failed to parse AI response", zap.Error(err), zap.String("response", responseText))
-		return nil, fmt.Errorf("failed to parse AI response: %w", err)
+	if err := s.parseJSONResponse(ctx, resp, responseText, &result); err != nil {
+		return nil, err
 	}
 
+	result.Model = modelName
 	return &result, nil
 }
 
-// QueryWithMCP handles natural language queries with MCP tool support
-func (s *Service) QueryWithMCP(ctx context.Context, query string) (*QueryResponse, error) {
-	if s.mcpService == nil {
-		return nil, fmt.Errorf("MCP service not available")
+// extractText returns the text of the first candidate. Blocked prompts and candidates that stopped for
+// safety or recitation reasons return a wrapped ErrContentBlocked; other empty responses ErrEmptyResponse
+func extractText(resp *genai.GenerateContentResponse) (string, error) {
+	if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != genai.BlockReasonUnspecified {
+		return "", blockedError(resp.PromptFeedback, nil)
 	}
-
-	// Create a prompt that includes available tools
-	tools := s.mcpService.ListTools()
-	toolsJSON, _ := json.MarshalIndent(tools, "", "  ")
-
-	prompt := fmt.Sprintf(`You are a Kubernetes expert assistant. Answer the user's query using available tools when needed.
-
-Query: %s
-
-Available Tools:
-%s
-
-CRITICAL: Respond ONLY with valid JSON. NO explanations, NO markdown, NO code blocks.
-
-If you need cluster data:
-{"action": "use_tool", "tool": "tool_name", "arguments": {"param": "value"}}
-
-If you can answer directly:
-{"action": "answer", "response": "## Your markdown-formatted answer here\n\nUse proper markdown formatting with headers, bullet points, and **bold** text for better readability."}
-
-Choose the most appropriate tool for the query and respond immediately.`, query, string(toolsJSON))
-
-	model := s.client.GenerativeModel(s.model)
-	model.SetTemperature(0.1)
-
-	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
-	if err != nil {
-		s.logger.Error("Failed to generate MCP response", zap.Error(err))
-		return nil, fmt.Errorf("failed to process query: %w", err)
-	}
-
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("no response generated")
-	}
-
-	// Extract text from response
-	responseText := ""
-	for _, part := range resp.Candidates[0].Content.Parts {
-		if text, ok := part.(genai.Text); ok {
-			responseText += string(text)
-		}
+	if len(resp.Candidates) == 0 {
+		return "", ErrEmptyResponse
 	}
 
-	// Parse the AI response to see if it wants to use a tool
-	var aiAction struct {
-		Action    string                 `json:"action"`
-		Tool      string                 `json:"tool"`
-		Arguments map[string]interface{} `json:"arguments"`
-		Response  string                 `json:"response"`
+	candidate := resp.Candidates[0]
+	switch candidate.FinishReason {
+	case genai.FinishReasonSafety, genai.FinishReasonRecitation:
+		return "", blockedError(nil, candidate)
 	}
-
-	// Extract JSON from markdown code blocks if present
-	jsonText := responseText
-	if strings.Contains(responseText, "```json") {
-		// Find the JSON block
-		start := strings.Index(responseText, "```json") + 7
-		end := strings.Index(responseText[start:], "```")
-		if end != -1 {
-			jsonText = strings.TrimSpace(responseText[start : start+end])
+	if candidate.Content == nil || len(candidate.Content.Parts) == 0 {
+		if candidate.FinishReason == genai.FinishReasonOther {
+			return "", blockedError(nil, candidate)
 		}
+		return "", ErrEmptyResponse
 	}
 
-	// First try to parse as direct action
-	if err := json.Unmarshal([]byte(jsonText), &aiAction); err != nil {
-		// If that fails, check if there's a JSON block within the response text
-		if strings.Contains(responseText, "{") && strings.Contains(responseText, "}") {
-			// Try to extract any JSON from the response
-			start := strings.Index(responseText, "{")
-			end := strings.LastIndex(responseText, "}")
-			if start != -1 && end != -1 && end > start {
-				possibleJSON := responseText[start : end+1]
-				if err := json.Unmarshal([]byte(possibleJSON), &aiAction); err == nil {
-					// Successfully parsed JSON from within the response
-					jsonText = possibleJSON
-				} else {
-					// If all parsing fails, treat it as a direct response
-					return &QueryResponse{
-						Response: responseText,
-						UsedTool: false,
-					}, nil
-				}
-			} else {
-				// If parsing fails, treat it as a direct response
-				return &QueryResponse{
-					Response: responseText,
-					UsedTool: false,
-				}, nil
-			}
-		} else {
-			// If parsing fails, treat it as a direct response
-			return &QueryResponse{
-				Response: responseText,
-				UsedTool: false,
-			}, nil
+	text := ""
+	for _, part := range candidate.Content.Parts {
+		if t, ok := part.(genai.Text); ok {
+			text += string(t)
 		}
 	}
+	return text, nil
+}
 
-	if aiAction.Action == "use_tool" {
-		// Execute the requested tool
-		toolRequest := mcp.ToolRequest{
-			Name:      aiAction.Tool,
-			Arguments: aiAction.Arguments,
-		}
-
-		toolResult, err := s.mcpService.ExecuteTool(ctx, toolRequest)
-		if err != nil {
-			return &QueryResponse{
-				Response: fmt.Sprintf("Error executing tool %s: %v", aiAction.Tool, err),
-				UsedTool: true,
-				ToolUsed: aiAction.Tool,
-				Error:    err.Error(),
-			}, nil
-		}
+// logParseFailure logs a short preview of an unparseable response at error level and the full text at debug level
+func (s *Service) logParseFailure(ctx context.Context, err error, responseText string) {
+	redacted := redactBlobs(responseText)
 
-		// Now ask AI to analyze the tool results
-		var toolOutput string
-		for _, content := range toolResult.Content {
-			toolOutput += content.Text + "\n"
+	preview := redacted
+	if len(preview) > parseFailurePreviewLen {
+		// Cut at a rune boundary so the logged preview stays valid UTF-8
+		cut := parseFailurePreviewLen
+		for cut > 0 && !utf8.RuneStart(preview[cut]) {
+			cut--
 		}
+		preview = preview[:cut] + "..."
+	}
 
-		analysisPrompt := fmt.Sprintf(`Based on the following Kubernetes cluster data, provide a comprehensive answer to the user's query. 
-
-Format your response using markdown for better readability:
-- Use headers (## ) for main sections
-- Use bullet points for lists
-- Use **bold** for important information
-- Use code blocks for kubectl commands or resource names
-- Include specific recommendations and next steps
+	s.log(ctx).Error("Failed to parse AI response",
+		zap.Error(err),
+		zap.Int("responseLength", len(responseText)),
+		zap.String("preview", preview))
+	s.log(ctx).Debug("Raw AI response", zap.String("response", redacted))
+}
 
-Original Query: %s
+// redactBlobs replaces anything resembling a base64-encoded secret with a placeholder
+func redactBlobs(text string) string {
+	return base64BlobPattern.ReplaceAllString(text, "[REDACTED]")
+}
 
-Cluster Data:
-%s
+// Retry policy for transient model failures, applied to the primary model and each fallback
+const (
+	modelAttempts     = 2
+	modelRetryBackoff = time.Second
+)
 
-Provide a well-structured markdown response analyzing this data with clear sections for current state, findings, and recommendations.`, query, toolOutput)
+// generateContent calls the model and records request, latency and token metrics under operation.
+// When the model is overloaded or rate limited it retries, then tries each fallback model with the same
+// generation settings. It returns the name of the model that answered and a classified error
+func (s *Service) generateContent(ctx context.Context, model *genai.GenerativeModel, operation, prompt string) (*genai.GenerateContentResponse, string, error) {
+	if s.mock != nil {
+		metrics.ObserveAIRequest(operation, false, 0)
+		return mockResponse(s.mock.respond(operation, prompt)), MockModelName, nil
+	}
+	return s.generateWithFallback(ctx, model, operation, prompt, func(ctx context.Context, model *genai.GenerativeModel) (*genai.GenerateContentResponse, bool, error) {
+		resp, err := model.GenerateContent(ctx, genai.Text(prompt))
+		return resp, true, err
+	})
+}
 
-		analysisResp, err := model.GenerateContent(ctx, genai.Text(analysisPrompt))
-		if err != nil {
-			return &QueryResponse{
-				Response: fmt.Sprintf("Gathered data but failed to analyze: %s", toolOutput),
-				UsedTool: true,
-				ToolUsed: aiAction.Tool,
-			}, nil
+// modelRequest sends one request to model. retryable reports whether a failed request may be sent again,
+// to the same model or a fallback
+type modelRequest func(ctx context.Context, model *genai.GenerativeModel) (resp *genai.GenerateContentResponse, retryable bool, err error)
+
+// generateWithFallback sends request to model, then to each fallback model with the same generation
+// settings, skipping models the prompt is too large for. Each request waits for a request slot and is
+// recorded under operation. An overloaded or rate-limited model is retried with backoff, and a model that
+// rejects the response schema is asked again without one, as long as request reports the failure retryable.
+// It returns the name of the model that answered and a classified error
+func (s *Service) generateWithFallback(ctx context.Context, model *genai.GenerativeModel, operation, prompt string, request modelRequest) (*genai.GenerateContentResponse, string, error) {
+	candidates := append([]string{s.model}, s.fallbackModels...)
+
+	var lastErr error
+	for i, name := range candidates {
+		if err := s.checkPromptSize(name, prompt); err != nil {
+			s.log(ctx).Warn("Prompt too large for model",
+				zap.String("operation", operation),
+				zap.String("model", name),
+				zap.Int("estimatedTokens", EstimateTokens(prompt)))
+			lastErr = err
+			continue
+		}
+		current := model
+		if i > 0 {
+			current = s.fallbackModel(model, name)
 		}
 
-		// Extract analysis text
-		analysisText := ""
-		if len(analysisResp.Candidates) > 0 && len(analysisResp.Candidates[0].Content.Parts) > 0 {
-			for _, part := range analysisResp.Candidates[0].Content.Parts {
-				if text, ok := part.(genai.Text); ok {
-					analysisText += string(text)
+		for attempt := 1; attempt <= modelAttempts; attempt++ {
+			release, err := s.acquireRequestSlot(ctx, operation)
+			if err != nil {
+				return nil, name, err
+			}
+			start := time.Now()
+			resp, retryable, err := request(ctx, current)
+			metrics.ObserveAIRequest(operation, err != nil, time.Since(start))
+			if err != nil && retryable && current.ResponseSchema != nil && schemaRejected(err) {
+				// The prompts spell out the JSON shape, so the answer can still be parsed without the schema
+				s.log(ctx).Warn("Model doesn't support response schemas; retrying without one",
+					zap.String("operation", operation),
+					zap.String("model", name),
+					zap.Error(err))
+				current = withoutResponseSchema(current)
+				start = time.Now()
+				resp, retryable, err = request(ctx, current)
+				metrics.ObserveAIRequest(operation, err != nil, time.Since(start))
+			}
+			release()
+			if err == nil {
+				if resp.UsageMetadata != nil {
+					metrics.AddAITokens(operation, resp.UsageMetadata.PromptTokenCount, resp.UsageMetadata.CandidatesTokenCount)
 				}
+				logFn := s.log(ctx).Debug
+				if i > 0 || attempt > 1 {
+					logFn = s.log(ctx).Info
+				}
+				logFn("Gemini request answered",
+					zap.String("operation", operation),
+					zap.String("model", name),
+					zap.Int("attempt", attempt))
+				return resp, name, nil
 			}
-		}
 
-		return &QueryResponse{
-			Response: analysisText,
-			UsedTool: true,
-			ToolUsed: aiAction.Tool,
-			RawData:  toolOutput,
-		}, nil
+			lastErr = classifyModelError(err)
+			if !retryable || ctx.Err() != nil || (!errors.Is(lastErr, ErrModelUnavailable) && !errors.Is(lastErr, ErrModelRateLimited)) {
+				return nil, name, lastErr
+			}
+			s.log(ctx).Warn("Gemini model overloaded",
+				zap.String("operation", operation),
+				zap.String("model", name),
+				zap.Int("attempt", attempt),
+				zap.Error(err))
+
+			if attempt < modelAttempts {
+				select {
+				case <-ctx.Done():
+					return nil, name, ctx.Err()
+				case <-time.After(modelRetryBackoff * time.Duration(attempt)):
+				}
+			}
+		}
 	}
 
-	// Direct answer without tools
-	return &QueryResponse{
-		Response: aiAction.Response,
-		UsedTool: false,
-	}, nil
+	return nil, candidates[len(candidates)-1], lastErr
+}
+
+// generativeModel returns the configured model. The mock provider has no client, so it gets an unbound
+// model that only carries settings
+func (s *Service) generativeModel() *genai.GenerativeModel {
+	if s.client == nil {
+		return &genai.GenerativeModel{}
+	}
+	return s.client.GenerativeModel(s.model)
 }
 
-// QueryResponse represents the response from an MCP-enabled query
-type QueryResponse struct {
-	Response string `json:"response"`
-	UsedTool bool   `json:"usedTool"`
-	ToolUsed string `json:"toolUsed,omitempty"`
-	RawData  string `json:"rawData,omitempty"`
-	Error    string `json:"error,omitempty"`
+// fallbackModel returns the named model configured like model
+func (s *Service) fallbackModel(model *genai.GenerativeModel, name string) *genai.GenerativeModel {
+	fallback := s.client.GenerativeModel(name)
+	fallback.GenerationConfig = model.GenerationConfig
+	fallback.SafetySettings = model.SafetySettings
+	fallback.SystemInstruction = model.SystemInstruction
+	return fallback
 }
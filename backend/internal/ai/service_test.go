@@ -0,0 +1,105 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/google/generative-ai-go/genai"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGenerateWithFallback(t *testing.T) {
+	answer := &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []genai.Part{genai.Text("ok")}}}}}
+	overloaded := status.Error(codes.Unavailable, "model is overloaded")
+	schemaRefused := status.Error(codes.InvalidArgument, "response_schema is not supported by this model")
+
+	type reply struct {
+		retryable bool
+		err       error
+	}
+	tests := []struct {
+		name      string
+		schema    bool
+		replies   []reply
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "answered", replies: []reply{{true, nil}}, wantCalls: 1},
+		{name: "overloaded then answered", replies: []reply{{true, overloaded}, {true, nil}}, wantCalls: 2},
+		{name: "overloaded after streaming", replies: []reply{{false, overloaded}}, wantCalls: 1, wantErr: ErrModelUnavailable},
+		{name: "overloaded on every attempt", replies: []reply{{true, overloaded}, {true, overloaded}}, wantCalls: modelAttempts, wantErr: ErrModelUnavailable},
+		{name: "schema refused", schema: true, replies: []reply{{true, schemaRefused}, {true, nil}}, wantCalls: 2},
+		{name: "schema refused after streaming", schema: true, replies: []reply{{false, schemaRefused}}, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Service{model: "test-model", logger: zap.NewNop()}
+			model := &genai.GenerativeModel{}
+			if tt.schema {
+				model.ResponseMIMEType = "application/json"
+				model.ResponseSchema = &genai.Schema{Type: genai.TypeObject}
+			}
+
+			calls := 0
+			resp, name, err := s.generateWithFallback(context.Background(), model, "test", "prompt", func(ctx context.Context, model *genai.GenerativeModel) (*genai.GenerateContentResponse, bool, error) {
+				reply := tt.replies[min(calls, len(tt.replies)-1)]
+				calls++
+				if calls > 1 && tt.schema && model.ResponseSchema != nil {
+					t.Error("retried with the response schema the model refused")
+				}
+				if reply.err != nil {
+					return nil, reply.retryable, reply.err
+				}
+				return answer, true, nil
+			})
+
+			if calls != tt.wantCalls {
+				t.Errorf("made %d requests, want %d", calls, tt.wantCalls)
+			}
+			if name != "test-model" {
+				t.Errorf("model = %q, want test-model", name)
+			}
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("err = %v, want %v", err, tt.wantErr)
+				}
+			case tt.replies[len(tt.replies)-1].err == nil:
+				if err != nil || resp != answer {
+					t.Errorf("resp, err = %v, %v, want the answer", resp, err)
+				}
+			default:
+				if err == nil {
+					t.Error("err = nil, want the request's failure")
+				}
+			}
+		})
+	}
+}
+
+func TestLogParseFailurePreviewIsValidUTF8(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+	s := &Service{logger: zap.New(core)}
+
+	// One byte of ASCII puts every later two-byte rune across the preview's byte limit
+	s.logParseFailure(context.Background(), ErrInvalidResponse, "x"+strings.Repeat("é", parseFailurePreviewLen))
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("got %d error logs, want 1", len(entries))
+	}
+	preview, _ := entries[0].ContextMap()["preview"].(string)
+	if !utf8.ValidString(preview) {
+		t.Errorf("preview %q is not valid UTF-8", preview)
+	}
+	if !strings.HasSuffix(preview, "...") || len(preview) > parseFailurePreviewLen+len("...") {
+		t.Errorf("preview is %d bytes, want a cut one of at most %d", len(preview), parseFailurePreviewLen+len("..."))
+	}
+}
@@ -0,0 +1,57 @@
+package ai
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/iterator"
+)
+
+// generateContentStream is generateContent for streamed responses: onText receives each piece of text as
+// Gemini produces it, and the merged response is returned at the end. Streamed text can't be taken back,
+// so only a request that fails before its first piece is retried or passed to a fallback model
+func (s *Service) generateContentStream(ctx context.Context, model *genai.GenerativeModel, operation, prompt string, onText func(string)) (*genai.GenerateContentResponse, string, error) {
+	if s.mock != nil {
+		resp, modelName, err := s.generateContent(ctx, model, operation, prompt)
+		if err == nil {
+			text, _ := extractText(resp)
+			onText(text)
+		}
+		return resp, modelName, err
+	}
+	return s.generateWithFallback(ctx, model, operation, prompt, func(ctx context.Context, model *genai.GenerativeModel) (*genai.GenerateContentResponse, bool, error) {
+		resp, streamed, err := streamContent(ctx, model, prompt, onText)
+		return resp, !streamed, err
+	})
+}
+
+// streamContent runs one streamed request, passing each piece of text to onText. It reports whether any
+// text was passed on, so callers know whether the request can still be retried
+func streamContent(ctx context.Context, model *genai.GenerativeModel, prompt string, onText func(string)) (*genai.GenerateContentResponse, bool, error) {
+	iter := model.GenerateContentStream(ctx, genai.Text(prompt))
+	streamed := false
+	for {
+		resp, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			merged := iter.MergedResponse()
+			if merged == nil {
+				return nil, streamed, ErrEmptyResponse
+			}
+			return merged, streamed, nil
+		}
+		if err != nil {
+			return nil, streamed, err
+		}
+
+		if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+			continue
+		}
+		for _, part := range resp.Candidates[0].Content.Parts {
+			if text, ok := part.(genai.Text); ok && text != "" {
+				streamed = true
+				onText(string(text))
+			}
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// defaultSummarizeChunkBytes is the largest resource data summarized in one request when not configured,
+// roughly 25k tokens
+const defaultSummarizeChunkBytes = 100 * 1024
+
+// maxSummarizeRounds bounds how many times partial summaries are themselves summarized in parts
+const maxSummarizeRounds = 3
+
+// WithSummarizeChunkSize sets the largest resource data, in bytes, summarized in a single request.
+// Larger data is split into chunks that are summarized separately and then combined. Non-positive values keep the default
+func WithSummarizeChunkSize(bytes int) Option {
+	return func(s *Service) {
+		if bytes > 0 {
+			s.summarizeChunkBytes = bytes
+		}
+	}
+}
+
+// summarizeChunkSize returns the configured chunk size, reduced when the model's context window is smaller
+func (s *Service) summarizeChunkSize() int {
+	return s.dataBudgetBytes(s.summarizeChunkBytes)
+}
+
+// ResourceSection is resource data from a single source, such as one resource type
+type ResourceSection struct {
+	Source string
+	Data   string
+}
+
+// summaryChunk is the data sent in one map-step request, labeled with the sources it covers
+type summaryChunk struct {
+	sources []string
+	data    strings.Builder
+}
+
+// SummarizeResourceSections summarizes resource data from several sources. Data that fits within the chunk
+// size is summarized in one request; otherwise each chunk is summarized on its own, labeled with the sources
+// it came from, and the partial summaries are combined (map-reduce)
+func (s *Service) SummarizeResourceSections(ctx context.Context, sections []ResourceSection) (*SummarizeResponse, error) {
+	chunkBytes := s.summarizeChunkSize()
+	if sectionsSize(sections) <= chunkBytes {
+		return s.SummarizeResourceData(ctx, joinSections(sections))
+	}
+
+	for round := 1; ; round++ {
+		chunks := buildSummaryChunks(sections, chunkBytes)
+		s.log(ctx).Info("Summarizing resource data in chunks",
+			zap.Int("round", round),
+			zap.Int("chunks", len(chunks)),
+			zap.Int("bytes", sectionsSize(sections)))
+
+		partials := make([]ResourceSection, 0, len(chunks))
+		for i, chunk := range chunks {
+			sources := strings.Join(chunk.sources, ", ")
+			resp, err := s.summarize(ctx, "summarize_chunk", chunkSummaryPrompt(i+1, len(chunks), sources, chunk.data.String()))
+			if err != nil {
+				return nil, fmt.Errorf("failed to summarize part %d of %d (%s): %w", i+1, len(chunks), sources, err)
+			}
+			partials = append(partials, ResourceSection{Source: sources, Data: resp.Summary})
+		}
+		sections = partials
+
+		if sectionsSize(partials) <= chunkBytes || round >= maxSummarizeRounds {
+			return s.summarize(ctx, "summarize_combine", combineSummariesPrompt(joinSections(partials)))
+		}
+	}
+}
+
+// chunkSummaryPrompt asks for a summary of one part of a larger data set
+func chunkSummaryPrompt(part, parts int, sources, data string) string {
+	return fmt.Sprintf(`You are an expert Kubernetes troubleshooter. Your task is to summarize the provided data from Kubernetes resources, highlighting only the relevant information for diagnosing issues. Ignore any irrelevant details.
+
+This is part %d of %d of a larger data set and covers: %s. Summarize only this part and name the resources each finding comes from; the summaries of all parts will be combined afterwards.
+
+Resource Data:
+%s
+
+Provide your output in the following JSON format:
+{
+  "summary": "A summarized version of this part of the resource data, highlighting the relevant information for diagnosing issues."
+}`, part, parts, sources, data)
+}
+
+// combineSummariesPrompt asks for one summary from the labeled summaries of each part
+func combineSummariesPrompt(summaries string) string {
+	return fmt.Sprintf(`You are an expert Kubernetes troubleshooter. The Kubernetes resource data below was too large to summarize at once, so each part was summarized separately. Each summary is headed by the resources it covers.
+
+Partial Summaries:
+%s
+
+Combine them into a single summary that highlights only the information relevant to diagnosing issues, keeps track of which resources each finding comes from, and drops duplicates.
+
+Provide your output in the following JSON format:
+{
+  "summary": "A combined summary of the resource data, highlighting the relevant information for diagnosing issues."
+}`, summaries)
+}
+
+// buildSummaryChunks packs sections into chunks of at most limit bytes, splitting large sections at line
+// boundaries. Each piece is headed by its source, with the part number when a section was split
+func buildSummaryChunks(sections []ResourceSection, limit int) []*summaryChunk {
+	var chunks []*summaryChunk
+	current := &summaryChunk{}
+	for _, section := range sections {
+		pieces := splitAtLines(section.Data, limit-len(section.Source)-64)
+		for i, piece := range pieces {
+			label := section.Source
+			if len(pieces) > 1 {
+				label = fmt.Sprintf("%s (part %d of %d)", section.Source, i+1, len(pieces))
+			}
+			entry := fmt.Sprintf("## %s\n%s\n\n", label, piece)
+			if current.data.Len() > 0 && current.data.Len()+len(entry) > limit {
+				chunks = append(chunks, current)
+				current = &summaryChunk{}
+			}
+			current.sources = append(current.sources, label)
+			current.data.WriteString(entry)
+		}
+	}
+	if current.data.Len() > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// splitAtLines splits text into pieces of at most limit bytes, preferring to break after a newline and
+// never splitting a UTF-8 character
+func splitAtLines(text string, limit int) []string {
+	if limit < 1024 {
+		limit = 1024
+	}
+	var pieces []string
+	for len(text) > limit {
+		cut := strings.LastIndexByte(text[:limit], '\n') + 1
+		if cut < limit/2 {
+			cut = limit
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+		}
+		pieces = append(pieces, text[:cut])
+		text = text[cut:]
+	}
+	return append(pieces, text)
+}
+
+// joinSections renders sections as one document with a heading per source
+func joinSections(sections []ResourceSection) string {
+	if len(sections) == 1 {
+		return sections[0].Data
+	}
+	var b strings.Builder
+	for _, section := range sections {
+		fmt.Fprintf(&b, "## %s\n%s\n\n", section.Source, section.Data)
+	}
+	return b.String()
+}
+
+// sectionsSize returns the combined size of the sections' data
+func sectionsSize(sections []ResourceSection) int {
+	size := 0
+	for _, section := range sections {
+		size += len(section.Data)
+	}
+	return size
+}
@@ -0,0 +1,87 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// bytesPerToken is the rough size of a token in English text and JSON, used to estimate prompt sizes
+const bytesPerToken = 4
+
+// promptDataShare is the part of a model's context window given to embedded data; the rest is left
+// for instructions, conversation history and the response
+const promptDataShare = 0.75
+
+// defaultModelTokenLimits are the input token limits of known Gemini models, matched by name prefix.
+// Longer prefixes are listed first so versioned names match the most specific entry
+var defaultModelTokenLimits = []struct {
+	prefix string
+	tokens int
+}{
+	{"gemini-1.5-pro", 2097152},
+	{"gemini-1.5-flash", 1048576},
+	{"gemini-1.0-pro", 30720},
+	{"gemini-2.0-flash", 1048576},
+	{"gemini-2.5-pro", 1048576},
+	{"gemini-2.5-flash", 1048576},
+	{"gemini-pro", 30720},
+}
+
+// WithModelTokenLimits sets the input token limits of models by exact name, overriding the built-in limits.
+// Non-positive limits disable the check for that model
+func WithModelTokenLimits(limits map[string]int) Option {
+	return func(s *Service) {
+		if len(limits) == 0 {
+			return
+		}
+		if s.modelTokenLimits == nil {
+			s.modelTokenLimits = make(map[string]int, len(limits))
+		}
+		for model, tokens := range limits {
+			s.modelTokenLimits[model] = tokens
+		}
+	}
+}
+
+// EstimateTokens returns a rough token count for text, at about four bytes per token
+func EstimateTokens(text string) int {
+	return (len(text) + bytesPerToken - 1) / bytesPerToken
+}
+
+// ModelTokenLimit returns the input token limit of a model: its configured limit, or the built-in limit for
+// the longest matching name prefix. It returns 0 when the limit is unknown, and prompts are then not checked
+func (s *Service) ModelTokenLimit(model string) int {
+	if tokens, ok := s.modelTokenLimits[model]; ok {
+		return max(tokens, 0)
+	}
+	name := strings.TrimPrefix(model, "models/")
+	for _, known := range defaultModelTokenLimits {
+		if strings.HasPrefix(name, known.prefix) {
+			return known.tokens
+		}
+	}
+	return 0
+}
+
+// checkPromptSize returns ErrInputTooLarge when the estimated size of prompt exceeds the model's token limit
+func (s *Service) checkPromptSize(model, prompt string) error {
+	limit := s.ModelTokenLimit(model)
+	if limit == 0 {
+		return nil
+	}
+	if tokens := EstimateTokens(prompt); tokens > limit {
+		return fmt.Errorf("%w: about %d tokens for model %s, which accepts %d; narrow the request or shorten the input",
+			ErrInputTooLarge, tokens, model, limit)
+	}
+	return nil
+}
+
+// dataBudgetBytes returns the most data, in bytes, to embed in a prompt for the configured model, or
+// limit when that is smaller or the model's token limit is unknown
+func (s *Service) dataBudgetBytes(limit int) int {
+	tokens := s.ModelTokenLimit(s.model)
+	if tokens == 0 {
+		return limit
+	}
+	return min(limit, int(float64(tokens)*bytesPerToken*promptDataShare))
+}
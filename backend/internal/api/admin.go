@@ -0,0 +1,110 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"kube-sherlock/internal/config"
+	"kube-sherlock/internal/mcp"
+)
+
+// LogLevelRequest changes the server's log level
+type LogLevelRequest struct {
+	Level string `json:"level" binding:"required"`
+}
+
+// LogLevelResponse reports the server's current log level
+type LogLevelResponse struct {
+	Level string `json:"level"`
+}
+
+// adminAuthMiddleware only lets requests through that carry token as a bearer token. Admin endpoints
+// are disabled, with 403, when no token is configured
+func adminAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if requireAdminToken(c, token, "Admin endpoints are disabled: set server.admin_token to enable them") {
+			c.Next()
+		}
+	}
+}
+
+// adminContextMiddleware marks the context of requests carrying the admin token with mcp.WithAdmin, so
+// queries they make may use the admin-only MCP tools. Other requests pass through unmarked
+func adminContextMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if hasAdminToken(c, token) {
+			c.Request = c.Request.WithContext(mcp.WithAdmin(c.Request.Context()))
+		}
+		c.Next()
+	}
+}
+
+// adminToolsMiddleware requires the admin token for running admin-only MCP tools directly; other tools
+// pass through. Without a configured token those tools can't be run directly at all
+func adminToolsMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		if !mcp.IsAdminOnly(name) {
+			c.Next()
+			return
+		}
+		if requireAdminToken(c, token, fmt.Sprintf("%s can only be run directly with the admin token: set server.admin_token to enable it", name)) {
+			c.Next()
+		}
+	}
+}
+
+// requireAdminToken reports whether the request carries token as a bearer token, aborting it otherwise:
+// with 403 and disabledMessage when no token is configured, and with 401 when the token is missing or wrong
+func requireAdminToken(c *gin.Context, token, disabledMessage string) bool {
+	if token == "" {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": disabledMessage})
+		return false
+	}
+	if !hasAdminToken(c, token) {
+		c.Header("WWW-Authenticate", `Bearer realm="kube-sherlock admin"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing or invalid admin token"})
+		return false
+	}
+	return true
+}
+
+// hasAdminToken reports whether token is configured and the request carries it as a bearer token
+func hasAdminToken(c *gin.Context, token string) bool {
+	if token == "" {
+		return false
+	}
+	supplied, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(supplied), []byte(token)) == 1
+}
+
+// getLogLevel returns the current log level
+func (h *Handler) getLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, LogLevelResponse{Level: config.LogLevel().String()})
+}
+
+// setLogLevel changes the level of every logger at runtime, including request-scoped ones
+func (h *Handler) setLogLevel(c *gin.Context) {
+	var req LogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	previous := config.LogLevel()
+	if err := config.SetLogLevel(req.Level); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Logged at warn so the change is recorded whatever the new level
+	h.log(c).Warn("Log level changed",
+		zap.Stringer("from", previous),
+		zap.Stringer("to", config.LogLevel()))
+
+	c.JSON(http.StatusOK, LogLevelResponse{Level: config.LogLevel().String()})
+}
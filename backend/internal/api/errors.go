@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"kube-sherlock/internal/ai"
+	"kube-sherlock/internal/kubernetes"
+	"kube-sherlock/internal/mcp"
+)
+
+// errorStatus maps service errors to an HTTP status code and client-facing message.
+// Errors without a known sentinel get a 500 with the fallback message
+func errorStatus(err error, fallback string) (int, string) {
+	switch {
+	case errors.Is(err, ai.ErrModelRateLimited):
+		return http.StatusTooManyRequests, "AI model is rate limited; retry later"
+	case errors.Is(err, ai.ErrModelUnavailable):
+		return http.StatusServiceUnavailable, "AI model is temporarily unavailable; retry later"
+	case errors.Is(err, ai.ErrMCPUnavailable), errors.Is(err, kubernetes.ErrClusterUnavailable):
+		return http.StatusServiceUnavailable, "Kubernetes cluster is unavailable; cluster queries cannot be answered right now"
+	case errors.Is(err, kubernetes.ErrMetricsUnavailable):
+		return http.StatusServiceUnavailable, "Resource metrics are unavailable; check that metrics-server is installed"
+	case errors.Is(err, kubernetes.ErrNotFound):
+		return http.StatusNotFound, "Requested Kubernetes resource was not found"
+	case errors.Is(err, kubernetes.ErrNamespaceNotPermitted):
+		return http.StatusForbidden, "Namespace not permitted: " + err.Error()
+	case errors.Is(err, kubernetes.ErrForbidden):
+		return http.StatusForbidden, "Access to the requested Kubernetes resource is forbidden"
+	case errors.Is(err, mcp.ErrToolNotFound):
+		return http.StatusNotFound, "Tool not found"
+	case errors.Is(err, mcp.ErrToolNotImplemented):
+		return http.StatusNotImplemented, "Tool is not implemented"
+	case errors.Is(err, mcp.ErrCommandNotAllowed), errors.Is(err, mcp.ErrAdminRequired):
+		return http.StatusForbidden, err.Error()
+	case errors.Is(err, mcp.ErrInvalidArguments), errors.Is(err, kubernetes.ErrInvalidNamespaces),
+		errors.Is(err, kubernetes.ErrInvalidAgeFilter), errors.Is(err, kubernetes.ErrInvalidManifest),
+		errors.Is(err, kubernetes.ErrInvalidBundle), errors.Is(err, kubernetes.ErrUnsupportedResourceType):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, ai.ErrInputTooLarge):
+		return http.StatusRequestEntityTooLarge, err.Error()
+	case errors.Is(err, ai.ErrContentBlocked):
+		return http.StatusUnprocessableEntity, err.Error()
+	case errors.Is(err, ai.ErrResponseTruncated):
+		return http.StatusBadGateway, fallback + ": the AI response was truncated; try a narrower query or shorter input"
+	case errors.Is(err, mcp.ErrToolTimeout):
+		return http.StatusGatewayTimeout, err.Error()
+	case errors.Is(err, ai.ErrQueryTimedOut):
+		return http.StatusGatewayTimeout, "Query timed out before any cluster data was gathered; try a narrower query"
+	case errors.Is(err, ai.ErrEmptyResponse), errors.Is(err, ai.ErrInvalidResponse):
+		return http.StatusBadGateway, fallback + ": the AI model returned an unusable response"
+	}
+	return http.StatusInternalServerError, fallback
+}
+
+// requestTimedOutMessage is returned with 504 when a request exceeds its timeout
+const requestTimedOutMessage = "Request timed out before the cluster or AI model responded"
+
+// respondError writes the status and message errorStatus chooses for err, or 504 when the request's
+// deadline has passed, since downstream errors then only reflect the cancellation
+func respondError(c *gin.Context, err error, fallback string) {
+	if errors.Is(c.Request.Context().Err(), context.DeadlineExceeded) {
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": requestTimedOutMessage})
+		return
+	}
+	code, message := errorStatus(err, fallback)
+	c.JSON(code, gin.H{"error": message})
+}
+
+// respondBindError writes 413 for request bodies over the size limit and 400 with a readable
+// message for malformed or invalid requests
+func respondBindError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": fmt.Sprintf("Request body exceeds the %d byte limit", maxBytesErr.Limit),
+		})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": validationMessage(err)})
+}
+
+// validationMessage describes binding validation failures by JSON field name, or returns err's text
+func validationMessage(err error) string {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err.Error()
+	}
+
+	messages := make([]string, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		// JSON field names are the lower camel case form of the struct field names
+		field := strings.ToLower(fieldErr.Field()[:1]) + fieldErr.Field()[1:]
+		switch fieldErr.Tag() {
+		case "required":
+			messages = append(messages, fmt.Sprintf("%s is required", field))
+		case "max":
+			messages = append(messages, fmt.Sprintf("%s must be at most %s characters", field, fieldErr.Param()))
+		default:
+			messages = append(messages, fmt.Sprintf("%s is invalid", field))
+		}
+	}
+	return strings.Join(messages, "; ")
+}
@@ -1,25 +1,44 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 
 	"kube-sherlock/internal/ai"
 	"kube-sherlock/internal/kubernetes"
+	"kube-sherlock/internal/logging"
+	"kube-sherlock/internal/mcp"
+	"kube-sherlock/internal/version"
 )
 
 // Handler contains the API handlers and dependencies
 type Handler struct {
-	aiService  *ai.Service
+	// aiService is swapped when the configuration is reloaded; nil when AI is not configured
+	aiService  atomic.Pointer[ai.Service]
 	k8sService *kubernetes.Service
+	mcpService *mcp.MCPService
 	logger     *zap.Logger
+	// maxBodyBytes also bounds messages read from WebSocket clients
+	maxBodyBytes int64
+	// queryTimeout bounds each query received over a WebSocket
+	queryTimeout time.Duration
+	// wsUpgrader upgrades connections for the interactive query endpoint, checking their origin
+	wsUpgrader *websocket.Upgrader
 }
 
 // TroubleshootRequest represents the request to troubleshoot a Kubernetes error
 type TroubleshootRequest struct {
-	ErrorMessage string `json:"errorMessage" binding:"required"`
+	ErrorMessage string `json:"errorMessage" binding:"required,max=65536"`
+	SystemPrompt string `json:"systemPrompt" binding:"max=4000"`
 }
 
 // TroubleshootResponse represents the response from troubleshooting
@@ -28,9 +47,32 @@ type TroubleshootResponse struct {
 	SuggestedSolutions []string `json:"suggestedSolutions"`
 }
 
+// TroubleshootBatchRequest represents a request to troubleshoot several errors at once
+type TroubleshootBatchRequest struct {
+	ErrorMessages []string `json:"errorMessages" binding:"required,min=1,max=50,dive,required,max=65536"`
+	SystemPrompt  string   `json:"systemPrompt" binding:"max=4000"`
+}
+
+// TroubleshootBatchItem is the result for one error of a batch: the troubleshooting fields on success,
+// or Error with the status a single troubleshoot request would have returned
+type TroubleshootBatchItem struct {
+	Index int `json:"index"`
+	*ai.TroubleshootResponse
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// TroubleshootBatchResponse represents the results of a batch, in request order
+type TroubleshootBatchResponse struct {
+	Results   []TroubleshootBatchItem `json:"results"`
+	Succeeded int                     `json:"succeeded"`
+	Failed    int                     `json:"failed"`
+}
+
 // SuggestResourcesRequest represents the request to suggest Kubernetes resources
 type SuggestResourcesRequest struct {
-	ErrorDescription string `json:"errorDescription" binding:"required"`
+	ErrorDescription string `json:"errorDescription" binding:"required,max=65536"`
+	SystemPrompt     string `json:"systemPrompt" binding:"max=4000"`
 }
 
 // SuggestResourcesResponse represents the response with suggested resources
@@ -39,9 +81,63 @@ type SuggestResourcesResponse struct {
 	Reasoning          string   `json:"reasoning"`
 }
 
+// SuggestAndGatherRequest represents the request to suggest resources and gather them in one step
+type SuggestAndGatherRequest struct {
+	ErrorDescription string `json:"errorDescription" binding:"required,max=65536"`
+	// Namespace and AllNamespaces override the namespace the model picks from the description
+	Namespace     string `json:"namespace"`
+	AllNamespaces bool   `json:"allNamespaces"`
+	SystemPrompt  string `json:"systemPrompt" binding:"max=4000"`
+	Minimize      bool   `json:"minimize"`
+}
+
+// SuggestAndGatherResponse combines the model's suggestions with the resources gathered for them
+type SuggestAndGatherResponse struct {
+	Suggestions *ai.GatherPlan `json:"suggestions"`
+	// Gather holds the parameters actually used, after validating the model's choices
+	Gather    GatherParameters          `json:"gather"`
+	Resources map[string]interface{}    `json:"resources"`
+	Metadata  kubernetes.GatherMetadata `json:"metadata"`
+	// Warnings lists suggested parameters that were dropped or replaced
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// GatherParameters are the resource types, namespace and label selector a gather ran with
+type GatherParameters struct {
+	ResourceTypes []string `json:"resourceTypes"`
+	Namespace     string   `json:"namespace"`
+	LabelSelector string   `json:"labelSelector,omitempty"`
+}
+
+// ValidateManifestRequest represents the request to validate a manifest before it is applied
+type ValidateManifestRequest struct {
+	Manifest string `json:"manifest" binding:"required,max=262144"`
+	// Explain asks the AI model to explain the results; it is skipped with a warning when AI is not configured
+	Explain      bool   `json:"explain"`
+	SystemPrompt string `json:"systemPrompt" binding:"max=4000"`
+}
+
+// ValidateManifestResponse represents the per-document validation results of a manifest
+type ValidateManifestResponse struct {
+	Valid       bool                            `json:"valid"`
+	Documents   []kubernetes.ManifestValidation `json:"documents"`
+	Explanation string                          `json:"explanation,omitempty"`
+	Model       string                          `json:"model,omitempty"`
+	Warnings    []string                        `json:"warnings,omitempty"`
+}
+
+// ExplainResourceRequest names the resource to explain; Kind may be a kind, resource or short name
+type ExplainResourceRequest struct {
+	Kind         string `json:"kind" binding:"required,max=253"`
+	Name         string `json:"name" binding:"required,max=253"`
+	Namespace    string `json:"namespace" binding:"max=63"`
+	SystemPrompt string `json:"systemPrompt" binding:"max=4000"`
+}
+
 // SummarizeRequest represents the request to summarize resource data
 type SummarizeRequest struct {
-	ResourceData string `json:"resourceData" binding:"required"`
+	ResourceData string `json:"resourceData" binding:"required,max=262144"`
+	SystemPrompt string `json:"systemPrompt" binding:"max=4000"`
 }
 
 // SummarizeResponse represents the response with summarized data
@@ -54,79 +150,412 @@ type GatherResourcesRequest struct {
 	ResourceTypes []string `json:"resourceTypes" binding:"required"`
 	Namespace     string   `json:"namespace"`
 	LabelSelector string   `json:"labelSelector"`
+	AllNamespaces bool     `json:"allNamespaces"`
+	// Namespaces gathers from each listed namespace instead of Namespace, keying resources by namespace
+	Namespaces []string `json:"namespaces"`
+	// LabelSelectors overrides LabelSelector for individual resource types
+	LabelSelectors map[string]string `json:"labelSelectors"`
+	// FieldSelectors sets a field selector for individual resource types, such as spec.nodeName=node-1 for pods
+	FieldSelectors map[string]string `json:"fieldSelectors"`
+	// Minimize strips managedFields and other bookkeeping metadata from returned objects
+	Minimize bool `json:"minimize"`
+	// NewerThan and OlderThan, durations such as "10m", keep only objects created within or before them.
+	// They filter after listing, since the API server can't select on age
+	NewerThan string `json:"newerThan"`
+	OlderThan string `json:"olderThan"`
 }
 
-// GatherResourcesResponse represents the response with gathered resource data
-type GatherResourcesResponse struct {
-	Resources map[string]interface{} `json:"resources"`
-	Metadata  GatherMetadata         `json:"metadata"`
-}
-
-// GatherMetadata contains metadata about the gathering operation
-type GatherMetadata struct {
-	Timestamp      string `json:"timestamp"`
-	ClusterContext string `json:"clusterContext"`
-	Namespace      string `json:"namespace"`
+// BundleRequest represents a request for a diagnostic bundle of resources and unhealthy pods' logs
+type BundleRequest struct {
+	// ResourceTypes defaults to kubernetes.DefaultBundleResourceTypes; pods are always included
+	ResourceTypes []string `json:"resourceTypes"`
+	Namespace     string   `json:"namespace"`
+	AllNamespaces bool     `json:"allNamespaces"`
+	Namespaces    []string `json:"namespaces"`
+	LabelSelector string   `json:"labelSelector"`
+	// Format is tar.gz (the default) or zip
+	Format string `json:"format"`
+	// LogLines is how many of the most recent lines to keep from each container log
+	LogLines int64 `json:"logLines" binding:"min=0"`
 }
 
 // MCPQueryRequest represents a natural language query request
 type MCPQueryRequest struct {
-	Query string `json:"query" binding:"required"`
+	Query        string `json:"query" binding:"required,max=4000"`
+	SystemPrompt string `json:"systemPrompt" binding:"max=4000"`
+	// Explain includes the model's tool-selection decisions and raw tool output in the response
+	Explain bool `json:"explain"`
 }
 
 // MCPQueryResponse represents the response from an MCP query
 type MCPQueryResponse struct {
-	Response string `json:"response"`
-	UsedTool bool   `json:"usedTool"`
-	ToolUsed string `json:"toolUsed,omitempty"`
-	RawData  string `json:"rawData,omitempty"`
-	Error    string `json:"error,omitempty"`
+	Response  string   `json:"response"`
+	UsedTool  bool     `json:"usedTool"`
+	ToolUsed  string   `json:"toolUsed,omitempty"`
+	ToolsUsed []string `json:"toolsUsed,omitempty"`
+	RawData   string   `json:"rawData,omitempty"`
+	Error     string   `json:"error,omitempty"`
+	// Steps is only set when the request asked to explain the query
+	Steps []ai.QueryStep `json:"steps,omitempty"`
+}
+
+// AnalyzeRequest represents the request to run the full analysis pipeline
+type AnalyzeRequest struct {
+	ErrorMessage    string            `json:"errorMessage" binding:"required,max=65536"`
+	SummarizeInput  bool              `json:"summarizeInput"`
+	Passes          int               `json:"passes" binding:"min=0,max=5"`
+	GatherResources bool              `json:"gatherResources"`
+	ResourceTypes   []string          `json:"resourceTypes"`
+	Namespace       string            `json:"namespace"`
+	AllNamespaces   bool              `json:"allNamespaces"`
+	Namespaces      []string          `json:"namespaces"`
+	LabelSelector   string            `json:"labelSelector"`
+	LabelSelectors  map[string]string `json:"labelSelectors"`
+	SystemPrompt    string            `json:"systemPrompt" binding:"max=4000"`
+}
+
+// defaultAnalyzeResourceTypes mirrors the CLI's default --resource-types
+var defaultAnalyzeResourceTypes = []string{"pods", "deployments", "services", "events"}
+
+// ExecuteToolRequest represents a request to run a single MCP tool directly
+type ExecuteToolRequest struct {
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// log returns the request-scoped logger carrying the request's correlation ID
+func (h *Handler) log(c *gin.Context) *zap.Logger {
+	return logging.FromContext(c.Request.Context(), h.logger)
 }
 
-// health is a simple health check endpoint
+// requireAI returns the current AI service, or writes a 503 and returns false when it is not configured
+func (h *Handler) requireAI(c *gin.Context) (*ai.Service, bool) {
+	aiService := h.aiService.Load()
+	if aiService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "AI service not configured: set a Gemini API key to enable this endpoint"})
+		return nil, false
+	}
+	return aiService, true
+}
+
+// VersionResponse represents the build and cluster version information
+type VersionResponse struct {
+	version.Info
+	ClusterVersion string `json:"clusterVersion,omitempty"`
+	ClusterNote    string `json:"clusterNote,omitempty"`
+}
+
+// healthCheckTimeout bounds the cluster connectivity check in the readiness endpoint
+const healthCheckTimeout = 3 * time.Second
+
+// HealthResponse reports overall readiness and the status of each dependency
+type HealthResponse struct {
+	Status  string                     `json:"status"`
+	Service string                     `json:"service"`
+	Checks  map[string]DependencyCheck `json:"checks"`
+}
+
+// DependencyCheck is the status of a single dependency in the health response
+type DependencyCheck struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// health is a readiness check reporting Kubernetes connectivity and AI availability.
+// It returns 503 when either dependency is down
 func (h *Handler) health(c *gin.Context) {
+	response := HealthResponse{
+		Status:  "healthy",
+		Service: "kube-sherlock",
+		Checks:  make(map[string]DependencyCheck),
+	}
+
+	if h.aiService.Load() == nil {
+		response.Checks["ai"] = DependencyCheck{Status: "unavailable", Error: "AI service not configured"}
+	} else {
+		response.Checks["ai"] = DependencyCheck{Status: "ok"}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+	if h.k8sService == nil {
+		response.Checks["kubernetes"] = DependencyCheck{Status: "unavailable", Error: "Kubernetes service not configured"}
+	} else if err := h.k8sService.Ping(ctx); err != nil {
+		h.log(c).Warn("Health check could not reach the cluster", zap.Error(err))
+		response.Checks["kubernetes"] = DependencyCheck{Status: "unavailable", Error: "Kubernetes cluster is unreachable"}
+	} else {
+		response.Checks["kubernetes"] = DependencyCheck{Status: "ok"}
+	}
+
+	code := http.StatusOK
+	for _, check := range response.Checks {
+		if check.Status != "ok" {
+			response.Status = "unhealthy"
+			code = http.StatusServiceUnavailable
+		}
+	}
+	c.JSON(code, response)
+}
+
+// live is a lightweight liveness check that only reports the process is serving requests
+func (h *Handler) live(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
-		"status":  "healthy",
+		"status":  "alive",
 		"service": "kube-sherlock",
 	})
 }
 
+// version reports the application build and connected cluster versions
+func (h *Handler) version(c *gin.Context) {
+	response := VersionResponse{Info: version.Get()}
+
+	if h.k8sService == nil {
+		response.ClusterNote = "Kubernetes service not configured"
+	} else if clusterVersion, err := h.k8sService.ServerVersion(); err != nil {
+		response.ClusterNote = "Failed to get cluster version"
+	} else {
+		response.ClusterVersion = clusterVersion
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // troubleshoot handles Kubernetes error troubleshooting requests
 func (h *Handler) troubleshoot(c *gin.Context) {
+	aiService, ok := h.requireAI(c)
+	if !ok {
+		return
+	}
+
 	var req TroubleshootRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Invalid troubleshoot request", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log(c).Error("Invalid troubleshoot request", zap.Error(err))
+		respondBindError(c, err)
 		return
 	}
 
-	h.logger.Info("Processing troubleshoot request", zap.String("error", req.ErrorMessage))
+	h.log(c).Info("Processing troubleshoot request", zap.String("error", req.ErrorMessage))
 
-	response, err := h.aiService.TroubleshootError(c.Request.Context(), req.ErrorMessage)
+	response, err := aiService.TroubleshootError(ai.ContextWithSystemPrompt(c.Request.Context(), req.SystemPrompt), req.ErrorMessage)
 	if err != nil {
-		h.logger.Error("Failed to troubleshoot error", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to analyze error"})
+		h.log(c).Error("Failed to troubleshoot error", zap.Error(err))
+		respondError(c, err, "Failed to analyze error")
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// troubleshootBatch handles requests to troubleshoot several errors; one error failing doesn't fail the batch
+func (h *Handler) troubleshootBatch(c *gin.Context) {
+	aiService, ok := h.requireAI(c)
+	if !ok {
+		return
+	}
+
+	var req TroubleshootBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log(c).Error("Invalid troubleshoot batch request", zap.Error(err))
+		respondBindError(c, err)
+		return
+	}
+
+	h.log(c).Info("Processing troubleshoot batch request", zap.Int("errors", len(req.ErrorMessages)))
+
+	results := aiService.TroubleshootErrors(ai.ContextWithSystemPrompt(c.Request.Context(), req.SystemPrompt), req.ErrorMessages)
+	response := TroubleshootBatchResponse{Results: make([]TroubleshootBatchItem, 0, len(results))}
+	for _, result := range results {
+		item := TroubleshootBatchItem{Index: result.Index, TroubleshootResponse: result.Response, Status: http.StatusOK}
+		switch {
+		case errors.Is(result.Err, context.DeadlineExceeded):
+			item.Status, item.Error = http.StatusGatewayTimeout, requestTimedOutMessage
+		case result.Err != nil:
+			h.log(c).Warn("Failed to troubleshoot batch item", zap.Int("index", result.Index), zap.Error(result.Err))
+			item.Status, item.Error = errorStatus(result.Err, "Failed to analyze error")
+		}
+		if item.Error != "" {
+			response.Failed++
+		} else {
+			response.Succeeded++
+		}
+		response.Results = append(response.Results, item)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // suggestResources handles resource suggestion requests
 func (h *Handler) suggestResources(c *gin.Context) {
+	aiService, ok := h.requireAI(c)
+	if !ok {
+		return
+	}
+
 	var req SuggestResourcesRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Invalid suggest resources request", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log(c).Error("Invalid suggest resources request", zap.Error(err))
+		respondBindError(c, err)
+		return
+	}
+
+	h.log(c).Info("Processing suggest resources request", zap.String("description", req.ErrorDescription))
+
+	response, err := aiService.SuggestResources(ai.ContextWithSystemPrompt(c.Request.Context(), req.SystemPrompt), req.ErrorDescription)
+	if err != nil {
+		h.log(c).Error("Failed to suggest resources", zap.Error(err))
+		respondError(c, err, "Failed to suggest resources")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// suggestAndGather asks the model which resources are relevant to an error, maps its suggestions to gather
+// parameters and gathers them, replacing a suggest-resources call followed by a gather-resources call
+func (h *Handler) suggestAndGather(c *gin.Context) {
+	aiService, ok := h.requireAI(c)
+	if !ok {
+		return
+	}
+
+	var req SuggestAndGatherRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log(c).Error("Invalid suggest and gather request", zap.Error(err))
+		respondBindError(c, err)
+		return
+	}
+
+	if h.k8sService == nil {
+		h.log(c).Error("Kubernetes service not available")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kubernetes service not configured"})
+		return
+	}
+
+	h.log(c).Info("Processing suggest and gather request", zap.String("description", req.ErrorDescription))
+
+	plan, err := aiService.SuggestGatherPlan(ai.ContextWithSystemPrompt(c.Request.Context(), req.SystemPrompt), req.ErrorDescription)
+	if err != nil {
+		h.log(c).Error("Failed to suggest resources", zap.Error(err))
+		respondError(c, err, "Failed to suggest resources")
+		return
+	}
+
+	namespace := req.Namespace
+	if req.AllNamespaces {
+		namespace = kubernetes.AllNamespaces
+	}
+	gatherOpts, warnings := plan.GatherOptions(namespace)
+	if len(gatherOpts.ResourceTypes) == 0 {
+		gatherOpts.ResourceTypes = defaultAnalyzeResourceTypes
+		warnings = append(warnings, "No usable resource types were suggested; gathered the default types")
+	}
+	gatherOpts.Minimize = req.Minimize
+
+	response, err := h.k8sService.Gather(c.Request.Context(), gatherOpts)
+	if err != nil {
+		h.log(c).Error("Failed to gather suggested resources", zap.Error(err))
+		respondError(c, err, "Failed to gather resources")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuggestAndGatherResponse{
+		Suggestions: plan,
+		Gather: GatherParameters{
+			ResourceTypes: gatherOpts.ResourceTypes,
+			Namespace:     response.Metadata.Namespace,
+			LabelSelector: gatherOpts.LabelSelector,
+		},
+		Resources: response.Resources,
+		Metadata:  response.Metadata,
+		Warnings:  warnings,
+	})
+}
+
+// validateManifest dry-runs each document of a manifest server-side and optionally has the AI model explain
+// the results. Nothing is changed in the cluster
+func (h *Handler) validateManifest(c *gin.Context) {
+	var req ValidateManifestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log(c).Error("Invalid validate manifest request", zap.Error(err))
+		respondBindError(c, err)
+		return
+	}
+
+	if h.k8sService == nil {
+		h.log(c).Error("Kubernetes service not available")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kubernetes service not configured"})
+		return
+	}
+
+	h.log(c).Info("Processing validate manifest request", zap.Int("bytes", len(req.Manifest)), zap.Bool("explain", req.Explain))
+
+	documents, err := h.k8sService.ValidateManifest(c.Request.Context(), req.Manifest)
+	if err != nil {
+		h.log(c).Error("Failed to validate manifest", zap.Error(err))
+		respondError(c, err, "Failed to validate manifest")
+		return
+	}
+
+	response := ValidateManifestResponse{Valid: true, Documents: documents}
+	for _, document := range documents {
+		response.Valid = response.Valid && document.Valid
+	}
+
+	if req.Explain {
+		aiService := h.aiService.Load()
+		if aiService == nil {
+			response.Warnings = append(response.Warnings, "AI service not configured; the results were not explained")
+		} else {
+			// The model sees the manifest with Secret values and credentials removed
+			explanation, err := aiService.ExplainManifestValidation(ai.ContextWithSystemPrompt(c.Request.Context(), req.SystemPrompt),
+				h.k8sService.RedactManifest(req.Manifest), documents)
+			if err != nil {
+				h.log(c).Warn("Failed to explain manifest validation", zap.Error(err))
+				response.Warnings = append(response.Warnings, fmt.Sprintf("Failed to explain the results: %v", err))
+			} else {
+				response.Explanation = explanation.Explanation
+				response.Model = explanation.Model
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// explainResource fetches one resource with its events and has the AI model explain what it is for,
+// its status and any anomalies
+func (h *Handler) explainResource(c *gin.Context) {
+	aiService, ok := h.requireAI(c)
+	if !ok {
+		return
+	}
+
+	var req ExplainResourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log(c).Error("Invalid explain resource request", zap.Error(err))
+		respondBindError(c, err)
 		return
 	}
 
-	h.logger.Info("Processing suggest resources request", zap.String("description", req.ErrorDescription))
+	if h.k8sService == nil {
+		h.log(c).Error("Kubernetes service not available")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kubernetes service not configured"})
+		return
+	}
+
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = h.k8sService.DefaultNamespace()
+	}
+	h.log(c).Info("Processing explain resource request",
+		zap.String("kind", req.Kind),
+		zap.String("namespace", namespace),
+		zap.String("name", req.Name))
 
-	response, err := h.aiService.SuggestResources(c.Request.Context(), req.ErrorDescription)
+	response, err := aiService.ExplainResource(ai.ContextWithSystemPrompt(c.Request.Context(), req.SystemPrompt),
+		h.k8sService, req.Kind, namespace, req.Name)
 	if err != nil {
-		h.logger.Error("Failed to suggest resources", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suggest resources"})
+		h.log(c).Error("Failed to explain resource", zap.Error(err))
+		respondError(c, err, "Failed to explain resource")
 		return
 	}
 
@@ -135,71 +564,307 @@ func (h *Handler) suggestResources(c *gin.Context) {
 
 // summarize handles resource data summarization requests
 func (h *Handler) summarize(c *gin.Context) {
+	aiService, ok := h.requireAI(c)
+	if !ok {
+		return
+	}
+
 	var req SummarizeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Invalid summarize request", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log(c).Error("Invalid summarize request", zap.Error(err))
+		respondBindError(c, err)
 		return
 	}
 
-	h.logger.Info("Processing summarize request")
+	h.log(c).Info("Processing summarize request")
 
-	response, err := h.aiService.SummarizeResourceData(c.Request.Context(), req.ResourceData)
+	response, err := aiService.SummarizeResourceData(ai.ContextWithSystemPrompt(c.Request.Context(), req.SystemPrompt), req.ResourceData)
 	if err != nil {
-		h.logger.Error("Failed to summarize resource data", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to summarize data"})
+		h.log(c).Error("Failed to summarize resource data", zap.Error(err))
+		respondError(c, err, "Failed to summarize data")
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// analyze runs the full troubleshoot, suggest, gather and summarize pipeline, mirroring the CLI analyze command
+func (h *Handler) analyze(c *gin.Context) {
+	aiService, ok := h.requireAI(c)
+	if !ok {
+		return
+	}
+
+	var req AnalyzeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log(c).Error("Invalid analyze request", zap.Error(err))
+		respondBindError(c, err)
+		return
+	}
+
+	resourceTypes := req.ResourceTypes
+	if len(resourceTypes) == 0 {
+		resourceTypes = defaultAnalyzeResourceTypes
+	}
+	if req.AllNamespaces && len(req.Namespaces) > 0 {
+		respondError(c, fmt.Errorf("%w: allNamespaces can't be combined with namespaces", kubernetes.ErrInvalidNamespaces), "Invalid namespaces")
+		return
+	}
+	// Gather failures only become warnings in the analysis, so a bad list is rejected up front
+	if len(req.Namespaces) > 0 {
+		if _, err := kubernetes.NormalizeNamespaces(req.Namespaces); err != nil {
+			respondError(c, err, "Invalid namespaces")
+			return
+		}
+	}
+	namespace := req.Namespace
+	if req.AllNamespaces {
+		namespace = kubernetes.AllNamespaces
+	}
+
+	h.log(c).Info("Processing analyze request",
+		zap.String("error", req.ErrorMessage),
+		zap.Bool("gatherResources", req.GatherResources))
+
+	response, err := aiService.Analyze(ai.ContextWithSystemPrompt(c.Request.Context(), req.SystemPrompt), h.k8sService, ai.AnalyzeOptions{
+		ErrorMessage:        req.ErrorMessage,
+		SummarizeLargeInput: req.SummarizeInput,
+		Passes:              req.Passes,
+		GatherResources:     req.GatherResources,
+		Gather: kubernetes.GatherOptions{
+			ResourceTypes:  resourceTypes,
+			Namespace:      namespace,
+			Namespaces:     req.Namespaces,
+			LabelSelector:  req.LabelSelector,
+			LabelSelectors: req.LabelSelectors,
+		},
+	})
+	if err != nil {
+		h.log(c).Error("Failed to analyze error", zap.Error(err))
+		respondError(c, err, "Failed to analyze error")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// yamlContentType is the media type for YAML responses
+const yamlContentType = "application/yaml"
+
+// wantsYAML reports whether the client asked for YAML with ?format=yaml or an Accept header
+func wantsYAML(c *gin.Context) bool {
+	if format := c.Query("format"); format != "" {
+		return strings.EqualFold(format, "yaml")
+	}
+	accept := c.GetHeader("Accept")
+	return strings.Contains(accept, "application/yaml") || strings.Contains(accept, "application/x-yaml") ||
+		strings.Contains(accept, "text/yaml")
+}
+
 // gatherResources handles Kubernetes resource gathering requests
 func (h *Handler) gatherResources(c *gin.Context) {
 	var req GatherResourcesRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Invalid gather resources request", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log(c).Error("Invalid gather resources request", zap.Error(err))
+		respondBindError(c, err)
 		return
 	}
 
 	if h.k8sService == nil {
-		h.logger.Error("Kubernetes service not available")
+		h.log(c).Error("Kubernetes service not available")
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kubernetes service not configured"})
 		return
 	}
 
-	h.logger.Info("Processing gather resources request",
-		zap.Strings("types", req.ResourceTypes),
-		zap.String("namespace", req.Namespace))
+	if req.AllNamespaces && len(req.Namespaces) > 0 {
+		respondError(c, fmt.Errorf("%w: allNamespaces can't be combined with namespaces", kubernetes.ErrInvalidNamespaces), "Invalid namespaces")
+		return
+	}
+	newerThan, olderThan, err := kubernetes.ParseAgeFilter(req.NewerThan, req.OlderThan)
+	if err != nil {
+		respondError(c, err, "Invalid age filter")
+		return
+	}
+	namespace := req.Namespace
+	if req.AllNamespaces {
+		namespace = kubernetes.AllNamespaces
+	}
 
-	response, err := h.k8sService.GatherResources(c.Request.Context(), req.ResourceTypes, req.Namespace, req.LabelSelector)
+	h.log(c).Info("Processing gather resources request",
+		zap.Strings("types", req.ResourceTypes),
+		zap.String("namespace", namespace),
+		zap.Strings("namespaces", req.Namespaces))
+
+	// YAML is meant to be applied, so it's always minimized
+	yamlOutput := wantsYAML(c)
+	response, err := h.k8sService.Gather(c.Request.Context(), kubernetes.GatherOptions{
+		ResourceTypes:  req.ResourceTypes,
+		Namespace:      namespace,
+		Namespaces:     req.Namespaces,
+		LabelSelector:  req.LabelSelector,
+		LabelSelectors: req.LabelSelectors,
+		FieldSelectors: req.FieldSelectors,
+		Minimize:       req.Minimize || yamlOutput,
+		NewerThan:      newerThan,
+		OlderThan:      olderThan,
+	})
 	if err != nil {
-		h.logger.Error("Failed to gather resources", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to gather resources"})
+		h.log(c).Error("Failed to gather resources", zap.Error(err))
+		respondError(c, err, "Failed to gather resources")
+		return
+	}
+
+	if yamlOutput {
+		data, err := kubernetes.ManifestYAML(response)
+		if err != nil {
+			h.log(c).Error("Failed to render gathered resources as YAML", zap.Error(err))
+			respondError(c, err, "Failed to render resources as YAML")
+			return
+		}
+		c.Data(http.StatusOK, yamlContentType, data)
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// bundleContentTypes are the media types of the bundle archive formats
+var bundleContentTypes = map[string]string{
+	kubernetes.BundleFormatTarGz: "application/gzip",
+	kubernetes.BundleFormatZip:   "application/zip",
+}
+
+// bundle handles requests for a diagnostic bundle, returned as a tar.gz or zip download
+func (h *Handler) bundle(c *gin.Context) {
+	var req BundleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log(c).Error("Invalid bundle request", zap.Error(err))
+		respondBindError(c, err)
+		return
+	}
+
+	if h.k8sService == nil {
+		h.log(c).Error("Kubernetes service not available")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kubernetes service not configured"})
+		return
+	}
+
+	if req.AllNamespaces && len(req.Namespaces) > 0 {
+		respondError(c, fmt.Errorf("%w: allNamespaces can't be combined with namespaces", kubernetes.ErrInvalidNamespaces), "Invalid namespaces")
+		return
+	}
+	format := req.Format
+	if format == "" {
+		format = kubernetes.BundleFormatTarGz
+	}
+	if err := kubernetes.ValidateBundleFormat(format); err != nil {
+		respondError(c, err, "Invalid bundle format")
+		return
+	}
+	namespace := req.Namespace
+	if req.AllNamespaces {
+		namespace = kubernetes.AllNamespaces
+	}
+
+	h.log(c).Info("Processing bundle request",
+		zap.Strings("types", req.ResourceTypes),
+		zap.String("namespace", namespace),
+		zap.Strings("namespaces", req.Namespaces),
+		zap.String("format", format))
+
+	bundle, err := h.k8sService.CollectBundle(c.Request.Context(), kubernetes.BundleOptions{
+		Gather: kubernetes.GatherOptions{
+			ResourceTypes: req.ResourceTypes,
+			Namespace:     namespace,
+			Namespaces:    req.Namespaces,
+			LabelSelector: req.LabelSelector,
+		},
+		LogLines: req.LogLines,
+	})
+	if err != nil {
+		h.log(c).Error("Failed to collect bundle", zap.Error(err))
+		respondError(c, err, "Failed to collect diagnostic bundle")
+		return
+	}
+
+	c.Header("Content-Type", bundleContentTypes[format])
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", bundle.FileName(format)))
+	c.Status(http.StatusOK)
+	if err := bundle.WriteArchive(c.Writer, format); err != nil {
+		// The status is already sent, so the client sees a truncated archive
+		h.log(c).Error("Failed to write bundle", zap.Error(err))
+	}
+}
+
 // mcpQuery handles natural language queries with MCP tool support
 func (h *Handler) mcpQuery(c *gin.Context) {
+	aiService, ok := h.requireAI(c)
+	if !ok {
+		return
+	}
+
 	var req MCPQueryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Invalid MCP query request", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log(c).Error("Invalid MCP query request", zap.Error(err))
+		respondBindError(c, err)
 		return
 	}
 
-	h.logger.Info("Processing MCP query", zap.String("query", req.Query))
+	h.log(c).Info("Processing MCP query", zap.String("query", req.Query))
 
-	response, err := h.aiService.QueryWithMCP(c.Request.Context(), req.Query)
+	ctx := ai.ContextWithExplain(ai.ContextWithSystemPrompt(c.Request.Context(), req.SystemPrompt), req.Explain)
+	response, err := aiService.QueryWithMCP(ctx, req.Query)
 	if err != nil {
-		h.logger.Error("Failed to process MCP query", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process query"})
+		h.log(c).Error("Failed to process MCP query", zap.Error(err))
+		respondError(c, err, "Failed to process query: the AI model could not generate a response")
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
+
+// listTools returns the available MCP tools
+func (h *Handler) listTools(c *gin.Context) {
+	if h.mcpService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kubernetes service not configured"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tools": h.mcpService.ListTools()})
+}
+
+// executeTool runs a single MCP tool directly, without AI involvement
+func (h *Handler) executeTool(c *gin.Context) {
+	var req ExecuteToolRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log(c).Error("Invalid execute tool request", zap.Error(err))
+		respondBindError(c, err)
+		return
+	}
+
+	if h.mcpService == nil {
+		h.log(c).Error("MCP service not available")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kubernetes service not configured"})
+		return
+	}
+
+	name := c.Param("name")
+	h.log(c).Info("Processing execute tool request", zap.String("tool", name))
+
+	result, err := h.mcpService.ExecuteTool(c.Request.Context(), mcp.ToolRequest{
+		Name:      name,
+		Arguments: req.Arguments,
+	})
+	if err != nil {
+		h.log(c).Error("Failed to execute tool", zap.String("tool", name), zap.Error(err))
+		if result != nil {
+			code, _ := errorStatus(err, "")
+			c.JSON(code, result)
+			return
+		}
+		respondError(c, err, "Failed to execute tool")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -1,29 +1,44 @@
 package api
 
 import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"kube-sherlock/internal/ai"
 	"kube-sherlock/internal/config"
 	"kube-sherlock/internal/kubernetes"
+	"kube-sherlock/internal/logging"
 	"kube-sherlock/internal/mcp"
+	"kube-sherlock/internal/metrics"
 )
 
-// NewRouter creates and configures the API router
-func NewRouter(cfg *config.Config, logger *zap.Logger) *gin.Engine {
+// NewRouter creates and configures the API router. The returned handler can reload the AI service
+func NewRouter(cfg *config.Config, logger *zap.Logger) (*gin.Engine, *Handler) {
 	router := gin.New()
 
 	// Middleware
+	router.Use(requestIDMiddleware(logger))
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
 	router.Use(corsMiddleware())
+	router.Use(metricsMiddleware())
+	router.Use(bodySizeLimitMiddleware(cfg.Server.MaxBodyBytes))
 
 	// Initialize services
-	aiService := ai.NewService(cfg.Gemini.APIKey, cfg.Gemini.Model, logger)
-	k8sService, err := kubernetes.NewService(cfg.Kubernetes.ConfigPath, cfg.Kubernetes.Context, logger)
+	aiService, err := newAIService(cfg, logger)
+	if err != nil {
+		logger.Warn("AI service unavailable, AI endpoints will return 503", zap.Error(err))
+		aiService = nil
+	}
+	k8sService, err := kubernetes.NewService(cfg.Kubernetes.ConfigPath, cfg.Kubernetes.Context, logger, cfg.KubernetesOptions()...)
 	if err != nil {
 		logger.Warn("Failed to initialize Kubernetes service", zap.Error(err))
 		k8sService = nil // Service will handle nil gracefully
@@ -32,31 +47,94 @@ func NewRouter(cfg *config.Config, logger *zap.Logger) *gin.Engine {
 	// Initialize MCP service if Kubernetes is available
 	var mcpService *mcp.MCPService
 	if k8sService != nil {
-		mcpService = mcp.NewMCPService(k8sService, logger)
-		aiService.SetMCPService(mcpService)
+		// Anyone who can reach the server can ask a query, so admin-only tools need the admin token
+		mcpService = mcp.NewMCPService(k8sService, logger, append(cfg.MCPOptions(), mcp.WithAdminOnlyTools(true))...)
+		if aiService != nil {
+			aiService.SetMCPService(mcpService)
+		}
 	}
 
 	// API handlers
 	handler := &Handler{
-		aiService:  aiService,
-		k8sService: k8sService,
-		logger:     logger,
+		k8sService:   k8sService,
+		mcpService:   mcpService,
+		logger:       logger,
+		maxBodyBytes: cfg.Server.MaxBodyBytes,
+		queryTimeout: cfg.Server.AIRequestTimeout,
+		wsUpgrader:   newWSUpgrader(cfg.Server.WebSocketAllowedOrigins),
+	}
+	if aiService != nil {
+		handler.aiService.Store(aiService)
 	}
 
-	// Health check
+	// Health checks: readiness with dependency status, and a lightweight liveness probe
 	router.GET("/health", handler.health)
+	router.GET("/health/live", handler.live)
+
+	// Prometheus metrics
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	// API routes
-	api := router.Group("/api")
+	api := router.Group("/api",
+		impersonationMiddleware(k8sService, cfg.Kubernetes.AllowRequestImpersonation, cfg.Kubernetes.ImpersonationProxyToken, logger),
+		adminContextMiddleware(cfg.Server.AdminToken))
 	{
-		api.POST("/troubleshoot", handler.troubleshoot)
-		api.POST("/suggest-resources", handler.suggestResources)
-		api.POST("/summarize", handler.summarize)
-		api.POST("/gather-resources", handler.gatherResources)
-		api.POST("/query", handler.mcpQuery) // New MCP endpoint
+		// Cluster and tool endpoints
+		clusterRoutes := api.Group("", timeoutMiddleware(cfg.Server.RequestTimeout))
+		clusterRoutes.GET("/version", handler.version)
+		clusterRoutes.POST("/gather-resources", handler.gatherResources)
+		clusterRoutes.POST("/bundle", handler.bundle)
+		clusterRoutes.GET("/tools", handler.listTools)
+		clusterRoutes.POST("/tools/:name", adminToolsMiddleware(cfg.Server.AdminToken), handler.executeTool)
+
+		// AI endpoints wait on Gemini and get a longer timeout
+		aiRoutes := api.Group("", timeoutMiddleware(cfg.Server.AIRequestTimeout))
+		aiRoutes.POST("/troubleshoot", handler.troubleshoot)
+		aiRoutes.POST("/troubleshoot/batch", handler.troubleshootBatch)
+		aiRoutes.POST("/analyze", handler.analyze)
+		aiRoutes.POST("/suggest-resources", handler.suggestResources)
+		aiRoutes.POST("/suggest-and-gather", handler.suggestAndGather)
+		aiRoutes.POST("/validate-manifest", handler.validateManifest)
+		aiRoutes.POST("/explain-resource", handler.explainResource)
+		aiRoutes.POST("/summarize", handler.summarize)
+		aiRoutes.POST("/query", handler.mcpQuery) // New MCP endpoint
+		aiRoutes.POST("/query/stream", handler.mcpQueryStream)
+
+		// WebSocket connections are long-lived; each query gets the AI timeout instead
+		api.GET("/query/ws", handler.mcpQueryWebSocket)
+
+		// Operational endpoints, only available with the admin token
+		adminRoutes := api.Group("/admin", adminAuthMiddleware(cfg.Server.AdminToken))
+		adminRoutes.GET("/loglevel", handler.getLogLevel)
+		adminRoutes.PUT("/loglevel", handler.setLogLevel)
+	}
+
+	return router, handler
+}
+
+// newAIService creates the AI service from the gemini settings and the MCP query limits
+func newAIService(cfg *config.Config, logger *zap.Logger) (*ai.Service, error) {
+	return ai.NewService(cfg.Gemini.APIKey, cfg.Gemini.Model, logger, cfg.AIOptions()...)
+}
+
+// ReloadAI replaces the AI service with one built from cfg, so gemini settings and MCP query limits change
+// without a restart. Requests in flight finish on the previous service, which is closed once they have
+// timed out. If the new service can't be created the current one is kept
+func (h *Handler) ReloadAI(cfg *config.Config) error {
+	aiService, err := newAIService(cfg, h.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create AI service: %w", err)
+	}
+	if h.mcpService != nil {
+		aiService.SetMCPService(h.mcpService)
 	}
 
-	return router
+	if previous := h.aiService.Swap(aiService); previous != nil {
+		time.AfterFunc(h.queryTimeout, func() {
+			previous.Close()
+		})
+	}
+	return nil
 }
 
 // corsMiddleware adds CORS headers for frontend communication
@@ -64,7 +142,8 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, "+logging.RequestIDHeader)
+		c.Header("Access-Control-Expose-Headers", logging.RequestIDHeader)
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
@@ -74,3 +153,140 @@ func corsMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// metricsMiddleware records request counts and latency per route
+func metricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		// Use the route pattern so path parameters don't create unbounded label values
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// timeoutMiddleware cancels the request context after timeout so in-flight Kubernetes and Gemini calls
+// stop, and answers 504 if the handler hasn't responded by then
+func timeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			c.JSON(http.StatusGatewayTimeout, gin.H{"error": requestTimedOutMessage})
+		}
+	}
+}
+
+// bodySizeLimitMiddleware rejects request bodies larger than maxBytes with 413
+func bodySizeLimitMiddleware(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": fmt.Sprintf("Request body exceeds the %d byte limit", maxBytes),
+			})
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
+
+// maxRequestIDLength bounds caller-supplied request IDs so they can't bloat logs
+const maxRequestIDLength = 128
+
+// requestIDMiddleware assigns each request a correlation ID, reusing a valid incoming X-Request-ID,
+// and stores it with a request-scoped logger in the request context
+func requestIDMiddleware(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(logging.RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Header(logging.RequestIDHeader, requestID)
+
+		ctx := logging.WithRequestID(c.Request.Context(), requestID)
+		ctx = logging.WithLogger(ctx, logger.With(zap.String("requestId", requestID)))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// validRequestID reports whether an incoming request ID is non-empty, bounded and printable ASCII
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// Headers an authenticating proxy sets to choose the identity cluster reads act as, and to prove that
+// it set them
+const (
+	impersonateUserHeader         = "Impersonate-User"
+	impersonateGroupHeader        = "Impersonate-Group"
+	impersonationProxyTokenHeader = "X-Impersonation-Proxy-Token"
+)
+
+// impersonationMiddleware makes a request's cluster reads act as the identity in its impersonation
+// headers. Requests carrying the headers are refused unless per-request impersonation is enabled and
+// they also carry proxyToken, so clients that reach the server directly can't choose an identity
+func impersonationMiddleware(k8sService *kubernetes.Service, allowed bool, proxyToken string, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := c.GetHeader(impersonateUserHeader)
+		groups := c.Request.Header.Values(impersonateGroupHeader)
+		if user == "" && len(groups) == 0 {
+			c.Next()
+			return
+		}
+
+		if !allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Per-request impersonation is disabled"})
+			return
+		}
+		if proxyToken == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Per-request impersonation is disabled: set kubernetes.impersonation_proxy_token to enable it"})
+			return
+		}
+		supplied := c.GetHeader(impersonationProxyTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(supplied), []byte(proxyToken)) != 1 {
+			logging.FromContext(c.Request.Context(), logger).Warn("Refusing impersonation headers without a valid proxy token",
+				zap.String("user", user), zap.Strings("groups", groups))
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing or invalid impersonation proxy token"})
+			return
+		}
+		if k8sService == nil {
+			c.Next()
+			return
+		}
+
+		ctx, err := k8sService.ContextWithImpersonation(c.Request.Context(), user, groups)
+		if errors.Is(err, kubernetes.ErrInvalidImpersonation) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Impersonate-Group requires Impersonate-User"})
+			return
+		}
+		if err != nil {
+			logging.FromContext(c.Request.Context(), logger).Error("Failed to set up impersonation", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to set up impersonation"})
+			return
+		}
+
+		logging.FromContext(ctx, logger).Info("Impersonating for request",
+			zap.String("user", user), zap.Strings("groups", groups))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"kube-sherlock/internal/ai"
+)
+
+// mcpQueryStream answers an MCP query with server-sent events: progress events while tools are chosen
+// and run, delta events carrying the analysis markdown as Gemini generates it, then one answer or error event
+func (h *Handler) mcpQueryStream(c *gin.Context) {
+	aiService, ok := h.requireAI(c)
+	if !ok {
+		return
+	}
+
+	var req MCPQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log(c).Error("Invalid streaming MCP query request", zap.Error(err))
+		respondBindError(c, err)
+		return
+	}
+
+	h.log(c).Info("Processing streaming MCP query", zap.String("query", req.Query))
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Stop reverse proxies such as nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	ctx := ai.ContextWithExplain(ai.ContextWithSystemPrompt(c.Request.Context(), req.SystemPrompt), req.Explain)
+	response, err := aiService.QueryWithMCPStream(ctx, req.Query, func(event ai.QueryEvent) {
+		writeSSEvent(c, event)
+	})
+	if err != nil {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return
+		}
+		message := requestTimedOutMessage
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			_, message = errorStatus(err, "Failed to process query: the AI model could not generate a response")
+		}
+		h.log(c).Error("Failed to process streaming MCP query", zap.Error(err))
+		writeSSEvent(c, ai.QueryEvent{Type: ai.QueryEventError, Message: message})
+		return
+	}
+
+	writeSSEvent(c, ai.QueryEvent{Type: ai.QueryEventAnswer, Response: response})
+}
+
+// writeSSEvent sends a query event named after its type and flushes it to the client
+func writeSSEvent(c *gin.Context, event ai.QueryEvent) {
+	c.SSEvent(event.Type, event)
+	c.Writer.Flush()
+}
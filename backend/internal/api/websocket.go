@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"kube-sherlock/internal/ai"
+)
+
+// newWSUpgrader returns the upgrader for the interactive query endpoint. Browsers may only connect from
+// the server's own origin or one of allowedOrigins, so other web pages can't drive queries with the
+// server's cluster credentials; "*" allows every origin
+func newWSUpgrader(allowedOrigins []string) *websocket.Upgrader {
+	return &websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     checkWebSocketOrigin(allowedOrigins),
+	}
+}
+
+// checkWebSocketOrigin accepts requests without an Origin header, which only non-browser clients omit, requests
+// from the server's own host and requests from an allowed origin
+func checkWebSocketOrigin(allowedOrigins []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+			return true
+		}
+		for _, allowed := range allowedOrigins {
+			if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// mcpQueryWebSocket handles interactive MCP queries over a WebSocket, streaming progress events
+func (h *Handler) mcpQueryWebSocket(c *gin.Context) {
+	if _, ok := h.requireAI(c); !ok {
+		return
+	}
+
+	conn, err := h.wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		h.log(c).Warn("Failed to upgrade WebSocket connection", zap.Error(err))
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(h.maxBodyBytes)
+
+	// Cancel in-flight queries as soon as the client goes away
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	queries := make(chan MCPQueryRequest)
+	go func() {
+		defer cancel()
+		defer close(queries)
+		for {
+			var req MCPQueryRequest
+			if err := conn.ReadJSON(&req); err != nil {
+				if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+					h.log(c).Debug("WebSocket read ended", zap.Error(err))
+				}
+				return
+			}
+			select {
+			case queries <- req:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for req := range queries {
+		if err := binding.Validator.ValidateStruct(&req); err != nil {
+			h.writeQueryEvent(c, conn, ai.QueryEvent{Type: ai.QueryEventError, Message: validationMessage(err)})
+			continue
+		}
+
+		h.log(c).Info("Processing WebSocket MCP query", zap.String("query", req.Query))
+
+		// Each query uses the current AI service, so a config reload applies to open connections too
+		aiService := h.aiService.Load()
+		queryCtx, cancelQuery := context.WithTimeout(ai.ContextWithExplain(ai.ContextWithSystemPrompt(ctx, req.SystemPrompt), req.Explain), h.queryTimeout)
+		response, err := aiService.QueryWithMCPEvents(queryCtx, req.Query, func(event ai.QueryEvent) {
+			h.writeQueryEvent(c, conn, event)
+		})
+		timedOut := errors.Is(queryCtx.Err(), context.DeadlineExceeded)
+		cancelQuery()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if timedOut {
+				h.writeQueryEvent(c, conn, ai.QueryEvent{Type: ai.QueryEventError, Message: requestTimedOutMessage})
+				continue
+			}
+			_, message := errorStatus(err, "Failed to process query: the AI model could not generate a response")
+			h.log(c).Error("Failed to process WebSocket MCP query", zap.Error(err))
+			h.writeQueryEvent(c, conn, ai.QueryEvent{Type: ai.QueryEventError, Message: message})
+			continue
+		}
+
+		h.writeQueryEvent(c, conn, ai.QueryEvent{Type: ai.QueryEventAnswer, Response: response})
+	}
+}
+
+// writeQueryEvent sends a single query event to the client
+func (h *Handler) writeQueryEvent(c *gin.Context, conn *websocket.Conn, event ai.QueryEvent) {
+	if err := conn.WriteJSON(event); err != nil {
+		h.log(c).Debug("Failed to write WebSocket event", zap.Error(err))
+	}
+}
@@ -1,51 +1,203 @@
 package config
 
 import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"kube-sherlock/internal/ai"
+	"kube-sherlock/internal/kubernetes"
+	"kube-sherlock/internal/mcp"
 )
 
 type Config struct {
 	Server     ServerConfig     `mapstructure:"server"`
 	Gemini     GeminiConfig     `mapstructure:"gemini"`
 	Kubernetes KubernetesConfig `mapstructure:"kubernetes"`
+	MCP        MCPConfig        `mapstructure:"mcp"`
+	// LogLevel is debug, info, warn or error; empty uses debug with --verbose and info otherwise
+	LogLevel string `mapstructure:"log_level"`
 }
 
 type ServerConfig struct {
 	Host string `mapstructure:"host"`
 	Port string `mapstructure:"port"`
+	// MaxBodyBytes caps API request bodies; larger requests are rejected with 413
+	MaxBodyBytes int64 `mapstructure:"max_body_bytes"`
+	// RequestTimeout bounds cluster and tool requests; AIRequestTimeout bounds requests that call Gemini
+	RequestTimeout   time.Duration `mapstructure:"request_timeout"`
+	AIRequestTimeout time.Duration `mapstructure:"ai_request_timeout"`
+	// AdminToken is the bearer token required by /api/admin endpoints, which are disabled when it is empty.
+	// It is also read from the KUBE_SHERLOCK_ADMIN_TOKEN environment variable
+	AdminToken string `mapstructure:"admin_token"`
+	// WebSocketAllowedOrigins are the browser origins, besides the server's own, that may open /api/query/ws.
+	// "*" allows any origin
+	WebSocketAllowedOrigins []string `mapstructure:"websocket_allowed_origins"`
 }
 
 type GeminiConfig struct {
-	APIKey string `mapstructure:"api_key"`
-	Model  string `mapstructure:"model"`
+	APIKey       string `mapstructure:"api_key"`
+	Model        string `mapstructure:"model"`
+	SystemPrompt string `mapstructure:"system_prompt"`
+	// FallbackModels are tried in order when the primary model is overloaded or unavailable
+	FallbackModels []string `mapstructure:"fallback_models"`
+	// SummarizeChunkBytes is the largest resource data summarized in one request; larger data is
+	// summarized in chunks whose summaries are then combined
+	SummarizeChunkBytes int `mapstructure:"summarize_chunk_bytes"`
+	// AnalysisDataBytes is the most gathered tool output embedded in a query's analysis prompt; larger
+	// output is summarized, or truncated when summarizing fails
+	AnalysisDataBytes int `mapstructure:"analysis_data_bytes"`
+	// ModelTokenLimits overrides the built-in input token limits of models by name; prompts estimated to
+	// exceed a model's limit are not sent to it
+	ModelTokenLimits map[string]int `mapstructure:"model_token_limits"`
+	// Endpoint overrides the public Gemini API endpoint, e.g. a corporate proxy that exposes the Gemini API
+	Endpoint string `mapstructure:"endpoint"`
+	// CredentialsFile and UseADC authenticate with Google credentials instead of an API key
+	CredentialsFile string `mapstructure:"credentials_file"`
+	UseADC          bool   `mapstructure:"use_adc"`
+	// Provider is "gemini" or "mock"; the mock provider returns canned responses without credentials,
+	// optionally from the fixtures in MockFixturesFile
+	Provider         string `mapstructure:"provider"`
+	MockFixturesFile string `mapstructure:"mock_fixtures_file"`
+	// TroubleshootCacheSize is how many troubleshoot responses are cached for TroubleshootCacheTTL,
+	// keyed by the normalized error message, model and system prompt; zero disables the cache
+	TroubleshootCacheSize int           `mapstructure:"troubleshoot_cache_size"`
+	TroubleshootCacheTTL  time.Duration `mapstructure:"troubleshoot_cache_ttl"`
+	// MaxConcurrentRequests bounds the Gemini requests in flight at once; further requests queue until
+	// their request times out. Zero uses the default of 5 and a negative value removes the limit
+	MaxConcurrentRequests int `mapstructure:"max_concurrent_requests"`
+}
+
+// HasCredentials reports whether any way of authenticating to Gemini is configured
+func (g GeminiConfig) HasCredentials() bool {
+	return g.APIKey != "" || g.CredentialsFile != "" || g.UseADC
+}
+
+// UsesMockProvider reports whether the mock AI provider is selected, which needs no credentials
+func (g GeminiConfig) UsesMockProvider() bool {
+	return g.Provider == ai.ProviderMock
 }
 
 type KubernetesConfig struct {
 	ConfigPath string `mapstructure:"config_path"`
 	Context    string `mapstructure:"context"`
+	// ConfigContent is kubeconfig YAML, optionally base64-encoded, used instead of ConfigPath when set.
+	// It is also read from the KUBECONFIG_CONTENT environment variable
+	ConfigContent     string   `mapstructure:"config_content"`
+	AllowedNamespaces []string `mapstructure:"allowed_namespaces"`
+	DeniedNamespaces  []string `mapstructure:"denied_namespaces"`
+	// ImpersonateUser and ImpersonateGroups make all cluster reads act as this identity
+	ImpersonateUser   string   `mapstructure:"impersonate_user"`
+	ImpersonateGroups []string `mapstructure:"impersonate_groups"`
+	// AllowRequestImpersonation honors Impersonate-User/Impersonate-Group headers on API requests that
+	// carry ImpersonationProxyToken. Only enable it behind an authenticating proxy that sets these headers
+	AllowRequestImpersonation bool `mapstructure:"allow_request_impersonation"`
+	// ImpersonationProxyToken is the secret the proxy sends in the X-Impersonation-Proxy-Token header;
+	// impersonation headers are refused without it. It is also read from the
+	// KUBE_SHERLOCK_IMPERSONATION_PROXY_TOKEN environment variable
+	ImpersonationProxyToken string `mapstructure:"impersonation_proxy_token"`
+	// Redaction removes credentials from gathered objects, pod logs and exec output. It is on by default
+	Redaction kubernetes.RedactionPolicy `mapstructure:"redaction"`
+	// MaxLogReadBytes caps the log output a single read takes from the API server, whatever is returned
+	MaxLogReadBytes int64 `mapstructure:"max_log_read_bytes"`
+}
+
+type MCPConfig struct {
+	MaxIterations int   `mapstructure:"max_iterations"`
+	MaxLogLines   int64 `mapstructure:"max_log_lines"`
+	MaxLogBytes   int64 `mapstructure:"max_log_bytes"`
+	// QueryTimeout bounds a whole natural language query: tool selection, tool execution and analysis
+	QueryTimeout time.Duration `mapstructure:"query_timeout"`
+	// ToolTimeout bounds a single tool call; ToolTimeouts overrides it, and the built-in limits, per tool name
+	ToolTimeout  time.Duration            `mapstructure:"tool_timeout"`
+	ToolTimeouts map[string]time.Duration `mapstructure:"tool_timeouts"`
+	// HealthScoreWeights overrides the relative weights of the namespace health score components by name
+	HealthScoreWeights map[string]float64 `mapstructure:"health_score_weights"`
+	// MaxItems caps the items list tools return; ToolMaxItems overrides it, and the built-in caps, per tool name
+	MaxItems     int            `mapstructure:"max_items"`
+	ToolMaxItems map[string]int `mapstructure:"tool_max_items"`
+	// ExecEnabled offers the exec_in_pod tool, limited to ExecAllowedCommands (a read-only default list when empty)
+	ExecEnabled         bool     `mapstructure:"exec_enabled"`
+	ExecAllowedCommands []string `mapstructure:"exec_allowed_commands"`
+	// CustomTools are read-only gather tools defined in configuration
+	CustomTools []mcp.CustomTool `mapstructure:"custom_tools"`
 }
 
 var (
 	globalConfig *Config
 	globalLogger *zap.Logger
+
+	// logLevel is shared by every logger NewLogger builds, so changing it affects all derived loggers
+	logLevel = zap.NewAtomicLevel()
+	// defaultLogLevel is the level used when log_level is empty
+	defaultLogLevel = zapcore.InfoLevel
 )
 
+// liveKeys are the settings a config reload applies without a restart; gemini covers every key under it
+var liveKeys = map[string]bool{
+	"gemini":             true,
+	"mcp.max_iterations": true,
+	"mcp.query_timeout":  true,
+	"log_level":          true,
+}
+
 // GetConfig returns the global configuration
 func GetConfig() *Config {
 	if globalConfig == nil {
 		globalConfig = &Config{
 			Server: ServerConfig{
-				Host: viper.GetString("server.host"),
-				Port: viper.GetString("server.port"),
+				Host:                    viper.GetString("server.host"),
+				Port:                    viper.GetString("server.port"),
+				MaxBodyBytes:            viper.GetInt64("server.max_body_bytes"),
+				RequestTimeout:          viper.GetDuration("server.request_timeout"),
+				AIRequestTimeout:        viper.GetDuration("server.ai_request_timeout"),
+				AdminToken:              viper.GetString("server.admin_token"),
+				WebSocketAllowedOrigins: viper.GetStringSlice("server.websocket_allowed_origins"),
 			},
 			Gemini: GeminiConfig{
-				APIKey: viper.GetString("gemini.api_key"),
-				Model:  viper.GetString("gemini.model"),
+				APIKey:              viper.GetString("gemini.api_key"),
+				Model:               viper.GetString("gemini.model"),
+				SystemPrompt:        viper.GetString("gemini.system_prompt"),
+				FallbackModels:      viper.GetStringSlice("gemini.fallback_models"),
+				SummarizeChunkBytes: viper.GetInt("gemini.summarize_chunk_bytes"),
+				AnalysisDataBytes:   viper.GetInt("gemini.analysis_data_bytes"),
+				Endpoint:            viper.GetString("gemini.endpoint"),
+				CredentialsFile:     viper.GetString("gemini.credentials_file"),
+				UseADC:              viper.GetBool("gemini.use_adc"),
+				Provider:            viper.GetString("gemini.provider"),
+				MockFixturesFile:    viper.GetString("gemini.mock_fixtures_file"),
+
+				TroubleshootCacheSize: viper.GetInt("gemini.troubleshoot_cache_size"),
+				TroubleshootCacheTTL:  viper.GetDuration("gemini.troubleshoot_cache_ttl"),
+				MaxConcurrentRequests: viper.GetInt("gemini.max_concurrent_requests"),
 			},
 			Kubernetes: KubernetesConfig{
-				ConfigPath: viper.GetString("kubernetes.config_path"),
-				Context:    viper.GetString("kubernetes.context"),
+				ConfigPath:                viper.GetString("kubernetes.config_path"),
+				Context:                   viper.GetString("kubernetes.context"),
+				ConfigContent:             viper.GetString("kubernetes.config_content"),
+				AllowedNamespaces:         viper.GetStringSlice("kubernetes.allowed_namespaces"),
+				DeniedNamespaces:          viper.GetStringSlice("kubernetes.denied_namespaces"),
+				ImpersonateUser:           viper.GetString("kubernetes.impersonate_user"),
+				ImpersonateGroups:         viper.GetStringSlice("kubernetes.impersonate_groups"),
+				AllowRequestImpersonation: viper.GetBool("kubernetes.allow_request_impersonation"),
+				ImpersonationProxyToken:   viper.GetString("kubernetes.impersonation_proxy_token"),
+				MaxLogReadBytes:           viper.GetInt64("kubernetes.max_log_read_bytes"),
+			},
+			LogLevel: viper.GetString("log_level"),
+			MCP: MCPConfig{
+				MaxIterations:       viper.GetInt("mcp.max_iterations"),
+				MaxLogLines:         viper.GetInt64("mcp.max_log_lines"),
+				MaxLogBytes:         viper.GetInt64("mcp.max_log_bytes"),
+				QueryTimeout:        viper.GetDuration("mcp.query_timeout"),
+				ToolTimeout:         viper.GetDuration("mcp.tool_timeout"),
+				MaxItems:            viper.GetInt("mcp.max_items"),
+				ExecEnabled:         viper.GetBool("mcp.exec_enabled"),
+				ExecAllowedCommands: viper.GetStringSlice("mcp.exec_allowed_commands"),
 			},
 		}
 
@@ -56,13 +208,143 @@ func GetConfig() *Config {
 		if globalConfig.Server.Port == "" {
 			globalConfig.Server.Port = "8080"
 		}
+		if globalConfig.Server.MaxBodyBytes <= 0 {
+			globalConfig.Server.MaxBodyBytes = 1024 * 1024
+		}
+		if globalConfig.Server.RequestTimeout <= 0 {
+			globalConfig.Server.RequestTimeout = 60 * time.Second
+		}
+		if globalConfig.Server.AIRequestTimeout <= 0 {
+			globalConfig.Server.AIRequestTimeout = 120 * time.Second
+		}
+		if globalConfig.Gemini.Provider == "" {
+			globalConfig.Gemini.Provider = ai.ProviderGemini
+		}
 		if globalConfig.Gemini.Model == "" {
 			globalConfig.Gemini.Model = "gemini-2.0-flash"
 		}
+		if globalConfig.Gemini.SummarizeChunkBytes <= 0 {
+			globalConfig.Gemini.SummarizeChunkBytes = 100 * 1024
+		}
+		if globalConfig.Gemini.AnalysisDataBytes <= 0 {
+			globalConfig.Gemini.AnalysisDataBytes = 200 * 1024
+		}
+		if globalConfig.Gemini.TroubleshootCacheTTL <= 0 {
+			globalConfig.Gemini.TroubleshootCacheTTL = time.Hour
+		}
+		if globalConfig.Gemini.MaxConcurrentRequests == 0 {
+			globalConfig.Gemini.MaxConcurrentRequests = 5
+		}
+		if globalConfig.MCP.MaxIterations <= 0 {
+			globalConfig.MCP.MaxIterations = 5
+		}
+		if globalConfig.MCP.MaxLogLines <= 0 {
+			globalConfig.MCP.MaxLogLines = 1000
+		}
+		if globalConfig.MCP.MaxLogBytes <= 0 {
+			globalConfig.MCP.MaxLogBytes = 256 * 1024
+		}
+		if globalConfig.MCP.QueryTimeout <= 0 {
+			globalConfig.MCP.QueryTimeout = 90 * time.Second
+		}
+		if globalConfig.MCP.ToolTimeout <= 0 {
+			globalConfig.MCP.ToolTimeout = 30 * time.Second
+		}
+		if err := viper.UnmarshalKey("gemini.model_token_limits", &globalConfig.Gemini.ModelTokenLimits); err != nil {
+			GetLogger().Warn("Ignoring invalid gemini.model_token_limits", zap.Error(err))
+		}
+		if err := viper.UnmarshalKey("mcp.custom_tools", &globalConfig.MCP.CustomTools); err != nil {
+			GetLogger().Warn("Ignoring invalid mcp.custom_tools", zap.Error(err))
+		}
+		if err := viper.UnmarshalKey("mcp.tool_timeouts", &globalConfig.MCP.ToolTimeouts); err != nil {
+			GetLogger().Warn("Ignoring invalid mcp.tool_timeouts", zap.Error(err))
+		}
+		if err := viper.UnmarshalKey("mcp.health_score_weights", &globalConfig.MCP.HealthScoreWeights); err != nil {
+			GetLogger().Warn("Ignoring invalid mcp.health_score_weights", zap.Error(err))
+		}
+		if err := viper.UnmarshalKey("mcp.tool_max_items", &globalConfig.MCP.ToolMaxItems); err != nil {
+			GetLogger().Warn("Ignoring invalid mcp.tool_max_items", zap.Error(err))
+		}
+		if err := viper.UnmarshalKey("kubernetes.redaction", &globalConfig.Kubernetes.Redaction); err != nil {
+			GetLogger().Warn("Ignoring invalid kubernetes.redaction", zap.Error(err))
+		}
 	}
 	return globalConfig
 }
 
+// Reload re-reads the config file and replaces the global configuration, returning the previous and the new
+// configuration. On error the current configuration is kept
+func Reload() (*Config, *Config, error) {
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	previous := GetConfig()
+	globalConfig = nil
+	return previous, GetConfig(), nil
+}
+
+// RestartRequired lists the keys, such as server.port, whose values differ between two configurations
+// but only take effect after a restart
+func RestartRequired(previous, current *Config) []string {
+	var keys []string
+	before, after := reflect.ValueOf(*previous), reflect.ValueOf(*current)
+	for i := 0; i < before.NumField(); i++ {
+		section := before.Type().Field(i)
+		name := section.Tag.Get("mapstructure")
+		if liveKeys[name] {
+			continue
+		}
+		if section.Type.Kind() != reflect.Struct {
+			if !reflect.DeepEqual(before.Field(i).Interface(), after.Field(i).Interface()) {
+				keys = append(keys, name)
+			}
+			continue
+		}
+		for j := 0; j < section.Type.NumField(); j++ {
+			key := name + "." + section.Type.Field(j).Tag.Get("mapstructure")
+			if !liveKeys[key] && !reflect.DeepEqual(before.Field(i).Field(j).Interface(), after.Field(i).Field(j).Interface()) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// NewLogger builds the root logger: human-readable output at debug level when verbose, JSON at info level
+// otherwise, with log_level overriding the level. Its level can be changed later with SetLogLevel
+func NewLogger(verbose bool) (*zap.Logger, error) {
+	zapConfig := zap.NewProductionConfig()
+	if verbose {
+		zapConfig = zap.NewDevelopmentConfig()
+	}
+	defaultLogLevel = zapConfig.Level.Level()
+	if err := SetLogLevel(viper.GetString("log_level")); err != nil {
+		return nil, err
+	}
+	zapConfig.Level = logLevel
+	return zapConfig.Build()
+}
+
+// SetLogLevel changes the level of every logger built by NewLogger, including loggers derived from it.
+// An empty level restores the default for the verbose setting
+func SetLogLevel(level string) error {
+	if level == "" {
+		logLevel.SetLevel(defaultLogLevel)
+		return nil
+	}
+	parsed, err := zapcore.ParseLevel(strings.ToLower(level))
+	if err != nil {
+		return fmt.Errorf("invalid log level %q: use debug, info, warn or error", level)
+	}
+	logLevel.SetLevel(parsed)
+	return nil
+}
+
+// LogLevel returns the current level of the loggers built by NewLogger
+func LogLevel() zapcore.Level {
+	return logLevel.Level()
+}
+
 // SetLogger sets the global logger
 func SetLogger(logger *zap.Logger) {
 	globalLogger = logger
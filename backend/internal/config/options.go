@@ -0,0 +1,53 @@
+package config
+
+import (
+	"kube-sherlock/internal/ai"
+	"kube-sherlock/internal/kubernetes"
+	"kube-sherlock/internal/mcp"
+)
+
+// The option helpers below turn the configuration into service options. Every command and the server
+// build their services from them, so a new setting is wired in once and reaches all of them; callers
+// append options of their own, which override these
+
+// KubernetesOptions returns the options for kubernetes.NewService
+func (c *Config) KubernetesOptions() []kubernetes.Option {
+	return []kubernetes.Option{
+		kubernetes.WithNamespacePolicy(c.Kubernetes.AllowedNamespaces, c.Kubernetes.DeniedNamespaces),
+		kubernetes.WithImpersonation(c.Kubernetes.ImpersonateUser, c.Kubernetes.ImpersonateGroups),
+		kubernetes.WithKubeconfigContent([]byte(c.Kubernetes.ConfigContent)),
+		kubernetes.WithRedaction(c.Kubernetes.Redaction),
+		kubernetes.WithMaxLogReadBytes(c.Kubernetes.MaxLogReadBytes),
+	}
+}
+
+// AIOptions returns the options for ai.NewService
+func (c *Config) AIOptions() []ai.Option {
+	return []ai.Option{
+		ai.WithSystemPrompt(c.Gemini.SystemPrompt),
+		ai.WithFallbackModels(c.Gemini.FallbackModels...),
+		ai.WithSummarizeChunkSize(c.Gemini.SummarizeChunkBytes),
+		ai.WithAnalysisDataBudget(c.Gemini.AnalysisDataBytes),
+		ai.WithModelTokenLimits(c.Gemini.ModelTokenLimits),
+		ai.WithEndpoint(c.Gemini.Endpoint),
+		ai.WithCredentialsFile(c.Gemini.CredentialsFile),
+		ai.WithApplicationDefaultCredentials(c.Gemini.UseADC),
+		ai.WithProvider(c.Gemini.Provider, c.Gemini.MockFixturesFile),
+		ai.WithTroubleshootCache(c.Gemini.TroubleshootCacheSize, c.Gemini.TroubleshootCacheTTL),
+		ai.WithMaxConcurrentRequests(c.Gemini.MaxConcurrentRequests),
+		ai.WithMaxToolIterations(c.MCP.MaxIterations),
+		ai.WithQueryTimeout(c.MCP.QueryTimeout),
+	}
+}
+
+// MCPOptions returns the options for mcp.NewMCPService
+func (c *Config) MCPOptions() []mcp.Option {
+	return []mcp.Option{
+		mcp.WithLogLimits(c.MCP.MaxLogLines, c.MCP.MaxLogBytes),
+		mcp.WithExec(c.MCP.ExecEnabled, c.MCP.ExecAllowedCommands),
+		mcp.WithToolTimeouts(c.MCP.ToolTimeout, c.MCP.ToolTimeouts),
+		mcp.WithHealthScoreWeights(c.MCP.HealthScoreWeights),
+		mcp.WithMaxItems(c.MCP.MaxItems, c.MCP.ToolMaxItems),
+		mcp.WithCustomTools(c.MCP.CustomTools...),
+	}
+}
@@ -0,0 +1,75 @@
+package kubernetes
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ParseAgeFilter parses newerThan and olderThan durations such as 10m or 2h, where empty means no bound,
+// and validates them together
+func ParseAgeFilter(newerThan, olderThan string) (time.Duration, time.Duration, error) {
+	var durations [2]time.Duration
+	for i, param := range []struct{ name, value string }{{"newerThan", newerThan}, {"olderThan", olderThan}} {
+		if param.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(param.value)
+		if err != nil {
+			return 0, 0, fmt.Errorf("%w: %s must be a duration such as 10m or 2h", ErrInvalidAgeFilter, param.name)
+		}
+		durations[i] = d
+	}
+	if err := ValidateAgeFilter(durations[0], durations[1]); err != nil {
+		return 0, 0, err
+	}
+	return durations[0], durations[1], nil
+}
+
+// ValidateAgeFilter checks the NewerThan and OlderThan durations of a gather
+func ValidateAgeFilter(newerThan, olderThan time.Duration) error {
+	if newerThan < 0 || olderThan < 0 {
+		return fmt.Errorf("%w: newerThan and olderThan must not be negative", ErrInvalidAgeFilter)
+	}
+	if newerThan > 0 && olderThan > 0 && newerThan <= olderThan {
+		return fmt.Errorf("%w: newerThan (%s) must be longer than olderThan (%s) or nothing can match", ErrInvalidAgeFilter, newerThan, olderThan)
+	}
+	return nil
+}
+
+// filterByAge drops list items created more than newerThan ago or less than olderThan ago; a zero
+// duration doesn't filter. The API server can't select on creationTimestamp, so this runs after listing
+func filterByAge(list runtime.Object, newerThan, olderThan time.Duration, now time.Time) {
+	if newerThan == 0 && olderThan == 0 {
+		return
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return
+	}
+	kept := make([]runtime.Object, 0, len(items))
+	for _, item := range items {
+		accessor, err := meta.Accessor(item)
+		if err != nil {
+			continue
+		}
+		age := now.Sub(accessor.GetCreationTimestamp().Time)
+		if (newerThan == 0 || age <= newerThan) && (olderThan == 0 || age >= olderThan) {
+			kept = append(kept, item)
+		}
+	}
+	if len(kept) != len(items) {
+		meta.SetList(list, kept)
+	}
+}
+
+// durationString formats a filter duration for gather metadata, leaving zero empty
+func durationString(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
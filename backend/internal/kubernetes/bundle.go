@@ -0,0 +1,293 @@
+package kubernetes
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// Diagnostic bundle archive formats
+const (
+	BundleFormatTarGz = "tar.gz"
+	BundleFormatZip   = "zip"
+)
+
+// Limits on the logs a diagnostic bundle collects
+const (
+	DefaultBundleLogLines = 500
+	maxBundleLogLines     = 5000
+	maxBundleLogBytes     = 256 * 1024
+	maxBundleLogPods      = 50
+)
+
+// DefaultBundleResourceTypes are the resource types a bundle gathers when none are given
+var DefaultBundleResourceTypes = []string{
+	"pods", "deployments", "replicasets", "statefulsets", "daemonsets", "services", "endpoints",
+	"configmaps", "events", "poddisruptionbudgets", "resourcequotas", "limitranges",
+}
+
+// BundleOptions configures CollectBundle
+type BundleOptions struct {
+	// Gather selects the resources to include; pods are always gathered so unhealthy pods' logs can be found
+	Gather GatherOptions
+	// LogLines is how many of the most recent lines to keep from each container log; zero uses DefaultBundleLogLines
+	LogLines int64
+}
+
+// BundleManifest describes a bundle's contents; it is written to the bundle as metadata.json
+type BundleManifest struct {
+	Metadata      GatherMetadata `json:"metadata"`
+	ResourceTypes []string       `json:"resourceTypes"`
+	Files         []string       `json:"files"`
+	// LogPods counts the unhealthy pods whose logs were collected; SkippedLogPods those over the limit
+	LogPods        int `json:"logPods"`
+	SkippedLogPods int `json:"skippedLogPods,omitempty"`
+	// Errors lists resource types and logs that couldn't be collected
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Bundle is a collected diagnostic bundle, ready to be written as an archive
+type Bundle struct {
+	// Name is the bundle's top-level directory and the base of its file name
+	Name     string
+	Manifest BundleManifest
+	files    []bundleFile
+}
+
+// bundleFile is one file of a bundle, with its path below the bundle directory
+type bundleFile struct {
+	path string
+	data []byte
+}
+
+// ValidateBundleFormat returns ErrInvalidBundle for formats other than tar.gz and zip
+func ValidateBundleFormat(format string) error {
+	if format != BundleFormatTarGz && format != BundleFormatZip {
+		return fmt.Errorf("%w: unsupported format %q; use %s or %s", ErrInvalidBundle, format, BundleFormatTarGz, BundleFormatZip)
+	}
+	return nil
+}
+
+// CollectBundle gathers resources and the logs of unhealthy pods for a diagnostic bundle, as one YAML file per
+// resource type and namespace and one file per container log. Objects and logs are redacted as for any
+// gather. A gather failure fails the bundle; resource types and logs that can't be read are listed in the
+// manifest's errors instead
+func (s *Service) CollectBundle(ctx context.Context, opts BundleOptions) (*Bundle, error) {
+	if s == nil {
+		return nil, ErrClusterUnavailable
+	}
+	if err := ValidateResourceTypes(opts.Gather.ResourceTypes); err != nil {
+		return nil, err
+	}
+	if opts.LogLines <= 0 {
+		opts.LogLines = DefaultBundleLogLines
+	}
+	if opts.LogLines > maxBundleLogLines {
+		return nil, fmt.Errorf("%w: logLines must be at most %d", ErrInvalidBundle, maxBundleLogLines)
+	}
+
+	gather := opts.Gather
+	gather.Minimize = true
+	if len(gather.ResourceTypes) == 0 {
+		gather.ResourceTypes = DefaultBundleResourceTypes
+	}
+	if !slices.Contains(gather.ResourceTypes, "pods") {
+		gather.ResourceTypes = append([]string{"pods"}, gather.ResourceTypes...)
+	}
+
+	response, err := s.Gather(ctx, gather)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &Bundle{
+		Name: "kube-sherlock-bundle-" + time.Now().UTC().Format("20060102-150405"),
+		Manifest: BundleManifest{
+			Metadata:      response.Metadata,
+			ResourceTypes: gather.ResourceTypes,
+			Files:         []string{},
+		},
+	}
+
+	groups := response.ByNamespace()
+	namespaces := make([]string, 0, len(groups))
+	for namespace := range groups {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	var unhealthy []*v1.Pod
+	for _, namespace := range namespaces {
+		directory := namespace
+		if namespace == AllNamespaces {
+			directory = "all-namespaces"
+		}
+		resources := groups[namespace]
+		for _, resourceType := range gather.ResourceTypes {
+			if msg, ok := resources[resourceType+"_error"].(string); ok {
+				bundle.Manifest.Errors = append(bundle.Manifest.Errors, fmt.Sprintf("%s/%s: %s", directory, resourceType, msg))
+				continue
+			}
+			list, ok := resources[resourceType].(runtime.Object)
+			if !ok {
+				continue
+			}
+			data, err := listYAML(list)
+			if err != nil {
+				bundle.Manifest.Errors = append(bundle.Manifest.Errors, fmt.Sprintf("%s/%s: %v", directory, resourceType, err))
+				continue
+			}
+			// group/version/resource types would otherwise create nested directories
+			bundle.add(fmt.Sprintf("%s/%s.yaml", directory, strings.ReplaceAll(resourceType, "/", "_")), data)
+
+			if pods, ok := list.(*v1.PodList); ok {
+				for i := range pods.Items {
+					if !IsPodHealthy(&pods.Items[i]) {
+						unhealthy = append(unhealthy, &pods.Items[i])
+					}
+				}
+			}
+		}
+	}
+
+	if len(unhealthy) > maxBundleLogPods {
+		bundle.Manifest.SkippedLogPods = len(unhealthy) - maxBundleLogPods
+		unhealthy = unhealthy[:maxBundleLogPods]
+	}
+	for _, pod := range unhealthy {
+		if ctx.Err() != nil {
+			bundle.Manifest.Errors = append(bundle.Manifest.Errors, fmt.Sprintf("log collection stopped: %v", ctx.Err()))
+			break
+		}
+		s.collectPodLogs(ctx, bundle, pod, opts.LogLines)
+		bundle.Manifest.LogPods++
+	}
+
+	s.log(ctx).Info("Collected diagnostic bundle",
+		zap.Strings("namespaces", namespaces),
+		zap.Int("files", len(bundle.files)),
+		zap.Int("logPods", bundle.Manifest.LogPods),
+		zap.Int("errors", len(bundle.Manifest.Errors)))
+	return bundle, nil
+}
+
+// collectPodLogs adds the current logs of each of a pod's containers and, for containers that have
+// restarted, the logs of their previous run, which usually hold the crash
+func (s *Service) collectPodLogs(ctx context.Context, bundle *Bundle, pod *v1.Pod, lines int64) {
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		// Containers that never started have no logs
+		if status.State.Waiting != nil && status.RestartCount == 0 && status.LastTerminationState.Terminated == nil {
+			continue
+		}
+		runs := []bool{false}
+		if status.RestartCount > 0 {
+			runs = append(runs, true)
+		}
+		for _, previous := range runs {
+			path := fmt.Sprintf("%s/logs/%s/%s.log", pod.Namespace, pod.Name, status.Name)
+			if previous {
+				path = fmt.Sprintf("%s/logs/%s/%s.previous.log", pod.Namespace, pod.Name, status.Name)
+			}
+			tail := lines
+			logs, truncated, err := s.streamPodLogs(ctx, pod.Namespace, pod.Name, &v1.PodLogOptions{
+				Container: status.Name,
+				Previous:  previous,
+				TailLines: &tail,
+			}, maxBundleLogBytes)
+			if err != nil {
+				bundle.Manifest.Errors = append(bundle.Manifest.Errors, fmt.Sprintf("%s: %v", path, err))
+				continue
+			}
+			if truncated {
+				logs = fmt.Sprintf("[kube-sherlock: only the last %d bytes were kept]\n", maxBundleLogBytes) + logs
+			}
+			bundle.add(path, []byte(logs))
+		}
+	}
+}
+
+// listYAML renders a gathered list as a YAML List whose items carry their apiVersion and kind
+func listYAML(list runtime.Object) ([]byte, error) {
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		setKind(item)
+	}
+	return yaml.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      items,
+	})
+}
+
+// add appends a file to the bundle and lists it in the manifest
+func (b *Bundle) add(path string, data []byte) {
+	b.files = append(b.files, bundleFile{path: path, data: data})
+	b.Manifest.Files = append(b.Manifest.Files, path)
+}
+
+// FileName is the bundle's archive file name for format
+func (b *Bundle) FileName(format string) string {
+	return b.Name + "." + format
+}
+
+// WriteArchive writes the bundle, with its manifest as metadata.json, as a tar.gz or zip archive whose
+// files are under a single directory named after the bundle
+func (b *Bundle) WriteArchive(w io.Writer, format string) error {
+	if err := ValidateBundleFormat(format); err != nil {
+		return err
+	}
+	manifest, err := json.MarshalIndent(b.Manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to render bundle manifest: %w", err)
+	}
+	files := append([]bundleFile{{path: "metadata.json", data: manifest}}, b.files...)
+	modified := time.Now()
+
+	if format == BundleFormatZip {
+		archive := zip.NewWriter(w)
+		for _, file := range files {
+			entry, err := archive.CreateHeader(&zip.FileHeader{Name: b.Name + "/" + file.path, Method: zip.Deflate, Modified: modified})
+			if err != nil {
+				return fmt.Errorf("failed to write %s: %w", file.path, err)
+			}
+			if _, err := entry.Write(file.data); err != nil {
+				return fmt.Errorf("failed to write %s: %w", file.path, err)
+			}
+		}
+		return archive.Close()
+	}
+
+	compressed := gzip.NewWriter(w)
+	archive := tar.NewWriter(compressed)
+	for _, file := range files {
+		header := &tar.Header{Name: b.Name + "/" + file.path, Mode: 0o644, Size: int64(len(file.data)), ModTime: modified}
+		if err := archive.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.path, err)
+		}
+		if _, err := archive.Write(file.data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.path, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return compressed.Close()
+}
@@ -0,0 +1,244 @@
+package kubernetes
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Kinds of change reported by DiffSnapshots
+const (
+	ChangeAdded     = "added"
+	ChangeRemoved   = "removed"
+	ChangeModified  = "modified"
+	ChangeRecreated = "recreated"
+)
+
+// comparedMetadataFields are the metadata fields whose changes are reported; the rest is server bookkeeping
+var comparedMetadataFields = []string{"labels", "annotations", "ownerReferences", "finalizers", "deletionTimestamp", "generation"}
+
+// ObjectChange describes how a single object differs between two snapshots
+type ObjectChange struct {
+	ResourceType string `json:"resourceType"`
+	Namespace    string `json:"namespace,omitempty"`
+	Name         string `json:"name"`
+	Change       string `json:"change"`
+	// ChangedFields lists the top-level fields, one level deep, that differ on a modified object
+	ChangedFields []string `json:"changedFields,omitempty"`
+}
+
+// SnapshotDiff is the set of changes between two gathers of the same resources
+type SnapshotDiff struct {
+	From      string         `json:"from"`
+	To        string         `json:"to"`
+	Counts    map[string]int `json:"counts"`
+	Unchanged int            `json:"unchanged"`
+	Changes   []ObjectChange `json:"changes"`
+	// Errors holds <type>_error messages from either snapshot; those types are not compared
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// snapshotObject is an object from a snapshot with the fields used to detect changes
+type snapshotObject struct {
+	uid             types.UID
+	resourceVersion string
+	object          runtime.Object
+}
+
+// DiffSnapshots reports which objects were added, removed, modified or recreated between two gathers.
+// Objects are matched by resource type, namespace and name; a differing UID means the object was deleted
+// and recreated under the same name, and a differing resourceVersion means it was modified. Both
+// snapshots must be gathered without minimizing, which clears the UID and resourceVersion
+func DiffSnapshots(before, after *GatherResourcesResponse) *SnapshotDiff {
+	diff := &SnapshotDiff{
+		From:    before.Metadata.Timestamp,
+		To:      after.Metadata.Timestamp,
+		Counts:  map[string]int{},
+		Changes: []ObjectChange{},
+	}
+
+	resourceTypes := make(map[string]bool)
+	for _, snapshot := range []*GatherResourcesResponse{before, after} {
+		for key, value := range snapshot.Resources {
+			if resourceType, ok := strings.CutSuffix(key, "_error"); ok {
+				if msg, ok := value.(string); ok {
+					if diff.Errors == nil {
+						diff.Errors = map[string]string{}
+					}
+					diff.Errors[resourceType] = msg
+				}
+				continue
+			}
+			resourceTypes[key] = true
+		}
+	}
+
+	for resourceType := range resourceTypes {
+		if _, failed := diff.Errors[resourceType]; failed {
+			continue
+		}
+		old := snapshotObjects(before.Resources[resourceType])
+		current := snapshotObjects(after.Resources[resourceType])
+
+		for key, was := range old {
+			change := ObjectChange{ResourceType: resourceType, Namespace: key.Namespace, Name: key.Name}
+			now, ok := current[key]
+			switch {
+			case !ok:
+				change.Change = ChangeRemoved
+			case was.uid != now.uid:
+				change.Change = ChangeRecreated
+			case was.resourceVersion != now.resourceVersion:
+				change.Change = ChangeModified
+				change.ChangedFields = changedFields(was.object, now.object)
+			default:
+				diff.Unchanged++
+				continue
+			}
+			diff.Changes = append(diff.Changes, change)
+		}
+		for key := range current {
+			if _, ok := old[key]; !ok {
+				diff.Changes = append(diff.Changes, ObjectChange{
+					ResourceType: resourceType,
+					Namespace:    key.Namespace,
+					Name:         key.Name,
+					Change:       ChangeAdded,
+				})
+			}
+		}
+	}
+
+	for _, change := range diff.Changes {
+		diff.Counts[change.Change]++
+	}
+	sort.Slice(diff.Changes, func(i, j int) bool {
+		a, b := diff.Changes[i], diff.Changes[j]
+		if a.ResourceType != b.ResourceType {
+			return a.ResourceType < b.ResourceType
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Change < b.Change
+	})
+	return diff
+}
+
+// snapshotObjects indexes the items of a gathered list by namespace and name
+func snapshotObjects(list interface{}) map[types.NamespacedName]snapshotObject {
+	objects := make(map[types.NamespacedName]snapshotObject)
+	listObject, ok := list.(runtime.Object)
+	if !ok {
+		return objects
+	}
+	items, err := meta.ExtractList(listObject)
+	if err != nil {
+		return objects
+	}
+	for _, item := range items {
+		accessor, err := meta.Accessor(item)
+		if err != nil {
+			continue
+		}
+		objects[types.NamespacedName{Namespace: accessor.GetNamespace(), Name: accessor.GetName()}] = snapshotObject{
+			uid:             accessor.GetUID(),
+			resourceVersion: accessor.GetResourceVersion(),
+			object:          item,
+		}
+	}
+	return objects
+}
+
+// changedFields names the fields that differ between two versions of an object, such as spec.replicas or
+// status.containerStatuses. Metadata is limited to comparedMetadataFields
+func changedFields(before, after runtime.Object) []string {
+	old, err := runtime.DefaultUnstructuredConverter.ToUnstructured(before)
+	if err != nil {
+		return nil
+	}
+	current, err := runtime.DefaultUnstructuredConverter.ToUnstructured(after)
+	if err != nil {
+		return nil
+	}
+
+	var fields []string
+	oldMetadata, _ := old["metadata"].(map[string]interface{})
+	currentMetadata, _ := current["metadata"].(map[string]interface{})
+	for _, field := range comparedMetadataFields {
+		was, now := oldMetadata[field], currentMetadata[field]
+		if field == "annotations" {
+			was, now = withoutLastApplied(was), withoutLastApplied(now)
+		}
+		if !reflect.DeepEqual(was, now) {
+			fields = append(fields, "metadata."+field)
+		}
+	}
+
+	for _, key := range unionKeys(old, current) {
+		if key == "metadata" || key == "apiVersion" || key == "kind" {
+			continue
+		}
+		was, now := old[key], current[key]
+		if reflect.DeepEqual(was, now) {
+			continue
+		}
+		wasMap, wasOK := was.(map[string]interface{})
+		nowMap, nowOK := now.(map[string]interface{})
+		if !wasOK || !nowOK {
+			fields = append(fields, key)
+			continue
+		}
+		for _, child := range unionKeys(wasMap, nowMap) {
+			if !reflect.DeepEqual(wasMap[child], nowMap[child]) {
+				fields = append(fields, key+"."+child)
+			}
+		}
+	}
+	return fields
+}
+
+// withoutLastApplied drops kubectl's last-applied-configuration from an annotations map, since it only
+// repeats the changes reported elsewhere
+func withoutLastApplied(annotations interface{}) interface{} {
+	values, ok := annotations.(map[string]interface{})
+	if !ok {
+		return annotations
+	}
+	if _, ok := values[lastAppliedAnnotation]; !ok {
+		return annotations
+	}
+	trimmed := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		if key != lastAppliedAnnotation {
+			trimmed[key] = value
+		}
+	}
+	if len(trimmed) == 0 {
+		return nil
+	}
+	return trimmed
+}
+
+// unionKeys returns the keys present in either map, sorted
+func unionKeys(a, b map[string]interface{}) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var keys []string
+	for _, m := range []map[string]interface{}{a, b} {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
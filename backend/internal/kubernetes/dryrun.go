@@ -0,0 +1,261 @@
+package kubernetes
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+	"sigs.k8s.io/yaml"
+)
+
+// Limits on manifests accepted by ValidateManifest
+const (
+	MaxManifestBytes     = 256 * 1024
+	maxManifestDocuments = 20
+)
+
+// dryRunFieldManager is the field manager named in server-side dry-run applies
+const dryRunFieldManager = "kube-sherlock"
+
+// ManifestValidation is the outcome of validating one document of a manifest
+type ManifestValidation struct {
+	// Index is the document's position in the manifest, counting from 1
+	Index      int    `json:"index"`
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Valid      bool   `json:"valid"`
+	// Stage is the step that failed (parse, mapping, namespace or dry-run), or passed
+	Stage  string   `json:"stage"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ValidateManifest parses a multi-document YAML or JSON manifest and validates each document with a
+// server-side dry-run apply, so schema errors, unknown fields and admission webhook rejections show up
+// without changing anything. The dry-run needs the same create or patch permission a real apply would.
+// A document that fails doesn't stop the others being validated
+func (s *Service) ValidateManifest(ctx context.Context, manifest string) ([]ManifestValidation, error) {
+	if strings.TrimSpace(manifest) == "" {
+		return nil, fmt.Errorf("%w: manifest is empty", ErrInvalidManifest)
+	}
+	if len(manifest) > MaxManifestBytes {
+		return nil, fmt.Errorf("%w: manifest is larger than %d bytes", ErrInvalidManifest, MaxManifestBytes)
+	}
+
+	documents, err := splitManifest(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+	}
+	if len(documents) == 0 {
+		return nil, fmt.Errorf("%w: manifest has no documents", ErrInvalidManifest)
+	}
+	if len(documents) > maxManifestDocuments {
+		return nil, fmt.Errorf("%w: manifest has %d documents, at most %d can be validated at once", ErrInvalidManifest, len(documents), maxManifestDocuments)
+	}
+
+	results := make([]ManifestValidation, 0, len(documents))
+	for i, document := range documents {
+		results = append(results, s.validateDocument(ctx, i+1, document))
+	}
+	return results, nil
+}
+
+// splitManifest splits a manifest into its non-empty documents
+func splitManifest(manifest string) ([][]byte, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(strings.NewReader(manifest)))
+	var documents [][]byte
+	for {
+		document, err := reader.Read()
+		if err == io.EOF {
+			return documents, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(document)) > 0 {
+			documents = append(documents, document)
+		}
+	}
+}
+
+// validateDocument maps one document to its resource and dry-runs it
+func (s *Service) validateDocument(ctx context.Context, index int, document []byte) ManifestValidation {
+	result := ManifestValidation{Index: index, Stage: "parse"}
+
+	object := &unstructured.Unstructured{}
+	if err := utilyaml.Unmarshal(document, &object.Object); err != nil {
+		result.Errors = []string{fmt.Sprintf("not valid YAML: %v", err)}
+		return result
+	}
+	if object.Object == nil {
+		result.Errors = []string{"document is not an object"}
+		return result
+	}
+	result.APIVersion, result.Kind = object.GetAPIVersion(), object.GetKind()
+	result.Namespace, result.Name = object.GetNamespace(), object.GetName()
+	if result.APIVersion == "" || result.Kind == "" {
+		result.Errors = []string{"apiVersion and kind are required"}
+		return result
+	}
+	if result.Name == "" && object.GetGenerateName() == "" {
+		result.Errors = []string{"metadata.name is required"}
+		return result
+	}
+
+	result.Stage = "mapping"
+	gvk := object.GroupVersionKind()
+	mapping, err := s.restMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			result.Errors = []string{fmt.Sprintf("the cluster doesn't serve %s %s; check apiVersion and kind, or install its CRD", result.APIVersion, result.Kind)}
+		} else {
+			result.Errors = []string{fmt.Sprintf("failed to look up %s %s: %v", result.APIVersion, result.Kind, classifyAPIError(err))}
+		}
+		return result
+	}
+
+	result.Stage = "namespace"
+	var resource dynamic.ResourceInterface = s.dynamicFor(ctx).Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if result.Namespace == "" {
+			result.Namespace = s.DefaultNamespace()
+			object.SetNamespace(result.Namespace)
+		}
+		if err := s.checkNamespace(result.Namespace); err != nil {
+			result.Errors = []string{err.Error()}
+			return result
+		}
+		resource = s.dynamicFor(ctx).Resource(mapping.Resource).Namespace(result.Namespace)
+	} else if result.Namespace != "" {
+		result.Errors = []string{fmt.Sprintf("%s is cluster-scoped and can't have a namespace", result.Kind)}
+		return result
+	}
+
+	result.Stage = "dry-run"
+	if err := dryRun(ctx, resource, object); err != nil {
+		s.log(ctx).Info("Manifest document failed dry-run",
+			zap.Int("document", index), zap.String("kind", result.Kind), zap.String("name", result.Name), zap.Error(err))
+		// Webhook messages can echo field values back
+		for _, message := range dryRunErrors(err) {
+			result.Errors = append(result.Errors, s.redactor.text(message))
+		}
+		return result
+	}
+	result.Stage = "passed"
+	result.Valid = true
+	return result
+}
+
+// dryRun applies object server-side without persisting it, rejecting unknown and duplicate fields.
+// Objects with only generateName can't be applied, so they are dry-run created instead
+func dryRun(ctx context.Context, resource dynamic.ResourceInterface, object *unstructured.Unstructured) error {
+	if object.GetName() == "" {
+		_, err := resource.Create(ctx, object, metav1.CreateOptions{
+			DryRun:          []string{metav1.DryRunAll},
+			FieldManager:    dryRunFieldManager,
+			FieldValidation: metav1.FieldValidationStrict,
+		})
+		return err
+	}
+
+	data, err := json.Marshal(object.Object)
+	if err != nil {
+		return err
+	}
+	force := true
+	_, err = resource.Patch(ctx, object.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+		DryRun:          []string{metav1.DryRunAll},
+		Force:           &force,
+		FieldManager:    dryRunFieldManager,
+		FieldValidation: metav1.FieldValidationStrict,
+	})
+	return err
+}
+
+// dryRunErrors lists the causes of a dry-run failure, one per invalid field where the API server names them
+func dryRunErrors(err error) []string {
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) {
+		return []string{err.Error()}
+	}
+
+	details := status.Status().Details
+	var messages []string
+	if details != nil {
+		for _, cause := range details.Causes {
+			switch {
+			case cause.Field != "" && !strings.Contains(cause.Message, cause.Field):
+				messages = append(messages, fmt.Sprintf("%s: %s", cause.Field, cause.Message))
+			case cause.Message != "":
+				messages = append(messages, cause.Message)
+			}
+		}
+	}
+	if len(messages) == 0 {
+		messages = []string{status.Status().Message}
+	}
+	if apierrors.IsForbidden(err) {
+		messages = append(messages, "the dry-run needs the same create or patch permission as a real apply")
+	}
+	return messages
+}
+
+// restMapper returns the service's discovery-backed REST mapper, creating it on first use. Discovery
+// results are cached; a kind the cache doesn't know triggers one refresh, so new CRDs are found
+func (s *Service) restMapper() meta.RESTMapper {
+	s.mapperOnce.Do(func() {
+		s.mapper = restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(s.clientset.Discovery()))
+	})
+	return s.mapper
+}
+
+// RedactManifest returns the manifest with Secret values removed and the redaction policy applied to
+// every document, for showing it to the model. Documents that don't parse are redacted as text
+func (s *Service) RedactManifest(manifest string) string {
+	documents, err := splitManifest(manifest)
+	if err != nil {
+		return s.redactor.text(manifest)
+	}
+
+	redacted := make([]string, 0, len(documents))
+	for _, document := range documents {
+		var object map[string]interface{}
+		if err := utilyaml.Unmarshal(document, &object); err != nil || object == nil {
+			redacted = append(redacted, s.redactor.text(string(document)))
+			continue
+		}
+		if object["kind"] == "Secret" {
+			for _, field := range []string{"data", "stringData"} {
+				if values, ok := object[field].(map[string]interface{}); ok {
+					for key := range values {
+						values[key] = redactedValue
+					}
+				}
+			}
+		}
+		s.redactor.value(object)
+		data, err := yaml.Marshal(object)
+		if err != nil {
+			redacted = append(redacted, s.redactor.text(string(document)))
+			continue
+		}
+		redacted = append(redacted, string(data))
+	}
+	return strings.Join(redacted, "---\n")
+}
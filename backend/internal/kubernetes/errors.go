@@ -0,0 +1,54 @@
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Sentinel errors returned (wrapped) by the Kubernetes service so callers can use errors.Is
+var (
+	// ErrClusterUnavailable means the API server could not be reached or is not serving requests
+	ErrClusterUnavailable = errors.New("kubernetes cluster unavailable")
+	// ErrNotFound means the requested namespace or resource does not exist
+	ErrNotFound = errors.New("kubernetes resource not found")
+	// ErrForbidden means the configured credentials are not allowed to perform the request
+	ErrForbidden = errors.New("kubernetes access forbidden")
+	// ErrNamespaceNotPermitted means the namespace is excluded by the configured allow/deny lists
+	ErrNamespaceNotPermitted = errors.New("namespace not permitted")
+	// ErrInvalidKubeconfig means kubeconfig content supplied directly could not be decoded or parsed
+	ErrInvalidKubeconfig = errors.New("invalid kubeconfig content")
+	// ErrMetricsUnavailable means the metrics.k8s.io API is not served, usually because metrics-server is not installed
+	ErrMetricsUnavailable = errors.New("resource metrics unavailable")
+	// ErrUnsupportedResourceType means a resource type is neither a built-in type nor group/version/resource
+	ErrUnsupportedResourceType = errors.New("unsupported resource type")
+	// ErrInvalidNamespaces means a namespace list for a multi-namespace gather is empty or includes AllNamespaces
+	ErrInvalidNamespaces = errors.New("invalid namespace list")
+	// ErrContextNotFound means the configured kubeconfig context is not defined in the kubeconfig
+	ErrContextNotFound = errors.New("kubeconfig context not found")
+	// ErrInvalidAgeFilter means a gather's newerThan/olderThan durations are negative or can't both match
+	ErrInvalidAgeFilter = errors.New("invalid age filter")
+	// ErrInvalidManifest means a manifest to validate is empty, too large, has too many documents or isn't YAML
+	ErrInvalidManifest = errors.New("invalid manifest")
+	// ErrInvalidBundle means a diagnostic bundle was requested in an unsupported format or with too many log lines
+	ErrInvalidBundle = errors.New("invalid bundle request")
+)
+
+// classifyAPIError wraps err with the sentinel matching its API status, or returns it unchanged
+func classifyAPIError(err error) error {
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return nil
+	case apierrors.IsNotFound(err):
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err):
+		return fmt.Errorf("%w: %w", ErrForbidden, err)
+	case apierrors.IsServiceUnavailable(err), apierrors.IsTimeout(err), apierrors.IsServerTimeout(err),
+		errors.As(err, &netErr):
+		return fmt.Errorf("%w: %w", ErrClusterUnavailable, err)
+	}
+	return err
+}
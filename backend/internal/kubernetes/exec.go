@@ -0,0 +1,90 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/util/exec"
+)
+
+// ExecResult is the captured output of a command run in a container
+type ExecResult struct {
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	ExitCode int    `json:"exitCode"`
+	// Truncated means stdout or stderr exceeded the byte limit and was cut off
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// cappedBuffer keeps the first limit bytes written to it and discards the rest
+type cappedBuffer struct {
+	data      []byte
+	limit     int64
+	truncated bool
+}
+
+// Write stores what fits under the limit but always reports the full length so the stream keeps flowing
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	remaining := b.limit - int64(len(b.data))
+	if b.limit > 0 && int64(len(p)) > remaining {
+		if remaining > 0 {
+			b.data = append(b.data, p[:remaining]...)
+		}
+		b.truncated = true
+		return len(p), nil
+	}
+	b.data = append(b.data, p...)
+	return len(p), nil
+}
+
+// ExecInPod runs command in a container without a TTY or stdin and captures its output, keeping at most
+// maxBytes of stdout and of stderr. A command that exits non-zero is not an error; its code is in the
+// result. An empty container name uses the pod's only container
+func (s *Service) ExecInPod(ctx context.Context, namespace, podName, containerName string, command []string, maxBytes int64) (*ExecResult, error) {
+	if err := s.checkNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	request := s.clientsetFor(ctx).CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{
+			Container: containerName,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(s.configFor(ctx), "POST", request.URL())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exec session: %w", err)
+	}
+
+	stdout := &cappedBuffer{limit: maxBytes}
+	stderr := &cappedBuffer{limit: maxBytes}
+	err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+
+	result := &ExecResult{
+		Stdout:    s.redactor.text(string(stdout.data)),
+		Stderr:    s.redactor.text(string(stderr.data)),
+		Truncated: stdout.truncated || stderr.truncated,
+	}
+	var exitErr exec.CodeExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.Code
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to exec in pod: %w", classifyAPIError(err))
+	}
+	return result, nil
+}
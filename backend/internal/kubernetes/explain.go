@@ -0,0 +1,91 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// maxResourceEvents caps the events ResourceDetails carries, newest first
+const maxResourceEvents = 20
+
+// ResourceDetails is a single object and the events about it, for explaining what the object is and
+// whether it is healthy
+type ResourceDetails struct {
+	ResourceRef
+	// Object is minimized and redacted; Secret values are replaced by a placeholder
+	Object map[string]interface{} `json:"object"`
+	// Events are the most recent events about the object, newest first
+	Events []v1.Event `json:"events"`
+	// EventsError is set when the object's events couldn't be listed
+	EventsError string `json:"eventsError,omitempty"`
+}
+
+// GetResourceDetails fetches one object of any kind with the events about it. kind may be a kind, resource
+// or short name, such as Deployment, deployments or deploy; namespace is ignored for cluster-scoped kinds.
+// Failing to list events doesn't fail the call
+func (s *Service) GetResourceDetails(ctx context.Context, kind, namespace, name string) (*ResourceDetails, error) {
+	if s == nil {
+		return nil, ErrClusterUnavailable
+	}
+	gvr, gvk, namespaced, err := s.resolveKind(kind)
+	if err != nil {
+		return nil, err
+	}
+	if !namespaced {
+		namespace = ""
+	}
+
+	object, err := s.GetResource(ctx, gvr, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	MinimizeObject(object)
+
+	details := &ResourceDetails{
+		ResourceRef: ResourceRef{Kind: gvk.Kind, Namespace: namespace, Name: name},
+		Object:      object.Object,
+		Events:      []v1.Event{},
+	}
+	events, err := s.objectEvents(ctx, gvk.Kind, namespace, name)
+	if err != nil {
+		s.log(ctx).Warn("Failed to list events for resource", zap.Error(err), zap.String("resource", details.String()))
+		details.EventsError = err.Error()
+	} else {
+		details.Events = events
+	}
+	return details, nil
+}
+
+// objectEvents lists the most recent events about an object. Events about cluster-scoped objects are
+// searched in every namespace the policy permits
+func (s *Service) objectEvents(ctx context.Context, kind, namespace, name string) ([]v1.Event, error) {
+	selector := fields.Set{
+		"involvedObject.kind": kind,
+		"involvedObject.name": name,
+	}.AsSelector().String()
+
+	list, err := s.clientsetFor(ctx).CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", classifyAPIError(err))
+	}
+	if namespace == "" {
+		s.filterNamespaced(list)
+	}
+	s.redactor.list(list)
+	MinimizeList(list)
+
+	events := list.Items
+	sort.SliceStable(events, func(i, j int) bool {
+		return EventTime(&events[i]).After(EventTime(&events[j]).Time)
+	})
+	if len(events) > maxResourceEvents {
+		events = events[:maxResourceEvents]
+	}
+	return events, nil
+}
@@ -0,0 +1,83 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// ErrInvalidImpersonation means groups were requested without a user to impersonate
+var ErrInvalidImpersonation = errors.New("impersonating groups requires a user")
+
+// WithImpersonation makes every request act as user and groups instead of the configured credentials
+func WithImpersonation(user string, groups []string) Option {
+	return func(s *Service) {
+		s.impersonate = rest.ImpersonationConfig{UserName: user, Groups: groups}
+	}
+}
+
+// impersonatedClients are clients that act as an identity chosen for a single request
+type impersonatedClients struct {
+	clientset kubernetes.Interface
+	dynamic   dynamic.Interface
+	config    *rest.Config
+}
+
+type impersonatedClientsKey struct{}
+
+// ContextWithImpersonation returns a context whose requests act as user and groups, overriding the
+// configured impersonation. An empty user and no groups returns ctx unchanged
+func (s *Service) ContextWithImpersonation(ctx context.Context, user string, groups []string) (context.Context, error) {
+	if user == "" && len(groups) == 0 {
+		return ctx, nil
+	}
+	if user == "" {
+		return nil, ErrInvalidImpersonation
+	}
+
+	config := rest.CopyConfig(s.config)
+	config.Impersonate = rest.ImpersonationConfig{UserName: user, Groups: groups}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create impersonating clientset: %w", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create impersonating dynamic client: %w", err)
+	}
+
+	return context.WithValue(ctx, impersonatedClientsKey{}, &impersonatedClients{
+		clientset: clientset,
+		dynamic:   dynamicClient,
+		config:    config,
+	}), nil
+}
+
+// clientsetFor returns the typed client for ctx, honoring per-request impersonation
+func (s *Service) clientsetFor(ctx context.Context) kubernetes.Interface {
+	if clients, ok := ctx.Value(impersonatedClientsKey{}).(*impersonatedClients); ok {
+		return clients.clientset
+	}
+	return s.clientset
+}
+
+// dynamicFor returns the dynamic client for ctx, honoring per-request impersonation
+func (s *Service) dynamicFor(ctx context.Context) dynamic.Interface {
+	if clients, ok := ctx.Value(impersonatedClientsKey{}).(*impersonatedClients); ok {
+		return clients.dynamic
+	}
+	return s.dynamicClient
+}
+
+// configFor returns the REST config for ctx, honoring per-request impersonation
+func (s *Service) configFor(ctx context.Context) *rest.Config {
+	if clients, ok := ctx.Value(impersonatedClientsKey{}).(*impersonatedClients); ok {
+		return clients.config
+	}
+	return s.config
+}
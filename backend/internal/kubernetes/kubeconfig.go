@@ -0,0 +1,54 @@
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// WithKubeconfigContent loads the kubeconfig from content, as YAML or base64-encoded YAML, instead of
+// from a file or the in-cluster config. Empty content is ignored
+func WithKubeconfigContent(content []byte) Option {
+	return func(s *Service) {
+		s.kubeconfigContent = content
+	}
+}
+
+// decodeKubeconfigContent returns content as kubeconfig YAML, decoding it first when it is base64
+func decodeKubeconfigContent(content []byte) []byte {
+	trimmed := bytes.TrimSpace(content)
+	decoded := make([]byte, base64.StdEncoding.DecodedLen(len(trimmed)))
+	if n, err := base64.StdEncoding.Decode(decoded, trimmed); err == nil {
+		return decoded[:n]
+	}
+	return trimmed
+}
+
+// configFromContent builds a REST config from kubeconfig content for contextName, or its current
+// context when empty, and returns the namespace set on that context
+func configFromContent(content []byte, contextName string) (*rest.Config, string, error) {
+	rawConfig, err := clientcmd.Load(decodeKubeconfigContent(content))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrInvalidKubeconfig, err)
+	}
+
+	if err := checkContext(contextNames(rawConfig), contextName); err != nil {
+		return nil, "", err
+	}
+
+	clientConfig := clientcmd.NewNonInteractiveClientConfig(*rawConfig, contextName, &clientcmd.ConfigOverrides{}, nil)
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrInvalidKubeconfig, err)
+	}
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil || namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	return config, namespace, nil
+}
@@ -0,0 +1,77 @@
+package kubernetes
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxLogReadBytes is the most log output a single read takes from the API server when not configured.
+// It bounds memory however many lines are asked for, independent of the output limits callers apply
+const DefaultMaxLogReadBytes = 16 * 1024 * 1024
+
+// DefaultLogTailLines is read by GetPodLogs when no line count is given, so a log is never read whole by accident
+const DefaultLogTailLines = 1000
+
+// WithMaxLogReadBytes caps the log output a single read takes from the API server; reading stops at the
+// cap and the output ends with a notice. A non-positive n keeps DefaultMaxLogReadBytes
+func WithMaxLogReadBytes(n int64) Option {
+	return func(s *Service) {
+		if n > 0 {
+			s.maxLogReadBytes = n
+		}
+	}
+}
+
+// logReadLimit returns the most bytes a single log read may take
+func (s *Service) logReadLimit() int64 {
+	if s.maxLogReadBytes > 0 {
+		return s.maxLogReadBytes
+	}
+	return DefaultMaxLogReadBytes
+}
+
+// readLogStream reads logs until they end or readLimit bytes were read, keeping at most keepBytes of the
+// most recent output (0 keeps everything read). trimmed reports that older output was dropped to fit
+// keepBytes, and stopped that reading ended at readLimit
+func readLogStream(logs io.Reader, keepBytes, readLimit int64) (result []byte, trimmed, stopped bool) {
+	buf := make([]byte, 32*1024)
+	var read int64
+	for {
+		n, err := logs.Read(buf)
+		if n > 0 {
+			if read+int64(n) > readLimit {
+				n = int(readLimit - read)
+				stopped = true
+			}
+			read += int64(n)
+			result = append(result, buf[:n]...)
+			if keepBytes > 0 && int64(len(result)) > keepBytes {
+				result = result[int64(len(result))-keepBytes:]
+				trimmed = true
+			}
+		}
+		if stopped || err != nil {
+			break
+		}
+	}
+
+	// Drop the partial first line left behind by trimming
+	if trimmed {
+		if idx := bytes.IndexByte(result, '\n'); idx >= 0 {
+			result = result[idx+1:]
+		}
+	}
+	// Drop the partial last line left behind by stopping
+	if stopped {
+		if idx := bytes.LastIndexByte(result, '\n'); idx >= 0 {
+			result = result[:idx+1]
+		}
+	}
+	return result, trimmed, stopped
+}
+
+// logReadLimitNotice ends log output that was cut off at the read limit
+func logReadLimitNotice(readLimit int64) string {
+	return fmt.Sprintf("\n[kube-sherlock: stopped reading after %d bytes; ask for fewer lines to see the most recent output]\n", readLimit)
+}
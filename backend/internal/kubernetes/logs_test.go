@@ -0,0 +1,132 @@
+package kubernetes
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// lineReader produces numbered 13-byte log lines, "line 0000001\n" onwards, up to total bytes or
+// forever when total is negative, counting the bytes read from it
+type lineReader struct {
+	total int64
+	read  int64
+	next  []byte
+	line  int
+}
+
+func (r *lineReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if r.total >= 0 && r.read >= r.total {
+			break
+		}
+		if len(r.next) == 0 {
+			r.line++
+			r.next = []byte(fmt.Sprintf("line %07d\n", r.line))
+		}
+		count := copy(p[n:], r.next)
+		if r.total >= 0 && r.read+int64(count) > r.total {
+			count = int(r.total - r.read)
+		}
+		r.next = r.next[count:]
+		r.read += int64(count)
+		n += count
+	}
+	if n == 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// checkLines fails unless data holds only whole, consecutive lines from a lineReader, returning the
+// first and last line numbers
+func checkLines(t *testing.T, data []byte) (first, last int) {
+	t.Helper()
+	if len(data) == 0 {
+		t.Fatal("no log lines returned")
+	}
+	if data[len(data)-1] != '\n' {
+		t.Fatalf("output ends with a partial line: %q", data[bytes.LastIndexByte(data, '\n')+1:])
+	}
+	for i, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		number, err := strconv.Atoi(strings.TrimPrefix(line, "line "))
+		if len(line) != 12 || err != nil {
+			t.Fatalf("line %d is partial or malformed: %q", i, line)
+		}
+		if i == 0 {
+			first = number
+		} else if number != last+1 {
+			t.Fatalf("line %d is %d, want %d", i, number, last+1)
+		}
+		last = number
+	}
+	return first, last
+}
+
+func TestReadLogStream(t *testing.T) {
+	const chunk = 32 * 1024
+	tests := []struct {
+		name         string
+		total        int64
+		keepBytes    int64
+		readLimit    int64
+		wantTrimmed  bool
+		wantStopped  bool
+		wantComplete bool
+	}{
+		{name: "under both limits", total: 13 * 1000, keepBytes: 64 * 1024, readLimit: 1 << 20, wantComplete: true},
+		{name: "keeping everything", total: 13 * 100000, readLimit: 4 << 20, wantComplete: true},
+		{name: "over keepBytes", total: 13 * 100000, keepBytes: 64 * 1024, readLimit: 4 << 20, wantTrimmed: true},
+		{name: "endless stream keeping everything", total: -1, readLimit: 1 << 20, wantStopped: true},
+		{name: "endless stream over keepBytes", total: -1, keepBytes: 64 * 1024, readLimit: 1 << 20, wantTrimmed: true, wantStopped: true},
+		{name: "read limit inside a chunk", total: -1, readLimit: chunk + 1000, wantStopped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &lineReader{total: tt.total}
+			result, trimmed, stopped := readLogStream(reader, tt.keepBytes, tt.readLimit)
+
+			if trimmed != tt.wantTrimmed || stopped != tt.wantStopped {
+				t.Errorf("trimmed, stopped = %v, %v, want %v, %v", trimmed, stopped, tt.wantTrimmed, tt.wantStopped)
+			}
+			first, last := checkLines(t, result)
+
+			// Reading stops at the limit, and only the most recent keepBytes are held
+			if reader.read > tt.readLimit+chunk {
+				t.Errorf("read %d bytes from the stream, want at most the %d byte limit plus one chunk", reader.read, tt.readLimit)
+			}
+			if int64(len(result)) > tt.readLimit {
+				t.Errorf("returned %d bytes, over the %d byte read limit", len(result), tt.readLimit)
+			}
+			if tt.keepBytes > 0 {
+				if int64(len(result)) > tt.keepBytes {
+					t.Errorf("returned %d bytes, want at most keepBytes (%d)", len(result), tt.keepBytes)
+				}
+				if int64(cap(result)) > 2*(tt.keepBytes+chunk) {
+					t.Errorf("result holds %d bytes of memory, want it bounded by keepBytes (%d)", cap(result), tt.keepBytes)
+				}
+			}
+
+			if tt.wantComplete {
+				if first != 1 || int64(last) != tt.total/13 {
+					t.Errorf("returned lines %d to %d, want all %d", first, last, tt.total/13)
+				}
+				return
+			}
+			if trimmed && first == 1 {
+				t.Error("trimmed output still starts at the first line")
+			}
+			if !stopped && int64(last) != tt.total/13 {
+				t.Errorf("last line is %d, want the stream's last line %d", last, tt.total/13)
+			}
+			if stopped && int64(last) != tt.readLimit/13 {
+				t.Errorf("last line is %d, want the last whole line within the read limit (%d)", last, tt.readLimit/13)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// ManifestYAML renders gathered resources as a YAML List whose items carry their apiVersion and kind,
+// so the output can be edited and applied. Gather metadata and per-type errors become leading comments.
+// Pass a minimized response to drop resourceVersion and uid, which would make the manifests conflict
+func ManifestYAML(response *GatherResourcesResponse) ([]byte, error) {
+	var out strings.Builder
+	if len(response.Metadata.Namespaces) > 0 {
+		fmt.Fprintf(&out, "# Gathered from namespaces %s", strings.Join(response.Metadata.Namespaces, ", "))
+	} else {
+		fmt.Fprintf(&out, "# Gathered from namespace %q", response.Metadata.Namespace)
+	}
+	if response.Metadata.ClusterContext != "" {
+		fmt.Fprintf(&out, " in context %q", response.Metadata.ClusterContext)
+	}
+	fmt.Fprintf(&out, " at %s\n", response.Metadata.Timestamp)
+	if len(response.Metadata.Truncated) > 0 {
+		fmt.Fprintf(&out, "# Truncated: %s\n", strings.Join(response.Metadata.Truncated, ", "))
+	}
+
+	groups := response.ByNamespace()
+	namespaces := make([]string, 0, len(groups))
+	for namespace := range groups {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	items := []runtime.Object{}
+	for _, namespace := range namespaces {
+		resources := groups[namespace]
+		// Errors are labeled with their namespace only when several were gathered
+		prefix := ""
+		if len(response.Metadata.Namespaces) > 0 {
+			prefix = namespace + "/"
+		}
+
+		keys := make([]string, 0, len(resources))
+		for key := range resources {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			switch value := resources[key].(type) {
+			case string:
+				fmt.Fprintf(&out, "# Error gathering %s%s: %s\n", prefix, strings.TrimSuffix(key, "_error"), value)
+			case runtime.Object:
+				if key == "secrets" {
+					out.WriteString("# Secret data is redacted; applying these secrets would clear their data\n")
+				}
+				listItems, err := meta.ExtractList(value)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read %s%s: %w", prefix, key, err)
+				}
+				for _, item := range listItems {
+					setKind(item)
+					items = append(items, item)
+				}
+			}
+		}
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      items,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render YAML: %w", err)
+	}
+	out.Write(data)
+	return []byte(out.String()), nil
+}
+
+// setKind fills in apiVersion and kind, which typed clients leave empty on list items
+func setKind(object runtime.Object) {
+	if !object.GetObjectKind().GroupVersionKind().Empty() {
+		return
+	}
+	if kinds, _, err := scheme.Scheme.ObjectKinds(object); err == nil && len(kinds) > 0 {
+		object.GetObjectKind().SetGroupVersionKind(kinds[0])
+	}
+}
@@ -0,0 +1,65 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// podMetricsGVR is the metrics-server resource reporting per-pod usage
+var podMetricsGVR = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+
+// PodUsage is the current CPU and memory usage of a pod, summed over its containers
+type PodUsage struct {
+	Namespace string
+	Name      string
+	CPU       resource.Quantity
+	Memory    resource.Quantity
+}
+
+// ListPodUsage returns pod usage from metrics-server. An empty namespace lists across all namespaces.
+// It returns ErrMetricsUnavailable when the metrics.k8s.io API is not served
+func (s *Service) ListPodUsage(ctx context.Context, namespace string) ([]PodUsage, error) {
+	list, err := s.ListCustomResources(ctx, podMetricsGVR, namespace, "")
+	if errors.Is(err, ErrNotFound) || apierrors.IsServiceUnavailable(err) {
+		return nil, fmt.Errorf("%w: %w", ErrMetricsUnavailable, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make([]PodUsage, 0, len(list.Items))
+	for _, item := range list.Items {
+		usages = append(usages, podUsage(item))
+	}
+	return usages, nil
+}
+
+// podUsage sums container usage from a PodMetrics object
+func podUsage(item unstructured.Unstructured) PodUsage {
+	usage := PodUsage{Namespace: item.GetNamespace(), Name: item.GetName()}
+
+	containers, _, _ := unstructured.NestedSlice(item.Object, "containers")
+	for _, c := range containers {
+		container, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if value, ok, _ := unstructured.NestedString(container, "usage", "cpu"); ok {
+			if quantity, err := resource.ParseQuantity(value); err == nil {
+				usage.CPU.Add(quantity)
+			}
+		}
+		if value, ok, _ := unstructured.NestedString(container, "usage", "memory"); ok {
+			if quantity, err := resource.ParseQuantity(value); err == nil {
+				usage.Memory.Add(quantity)
+			}
+		}
+	}
+	return usage
+}
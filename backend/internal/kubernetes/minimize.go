@@ -0,0 +1,65 @@
+package kubernetes
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// lastAppliedAnnotation holds kubectl's copy of the applied manifest, often as large as the object itself
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// MinimizeList strips bookkeeping metadata from every item in a list object; see MinimizeObject
+func MinimizeList(list runtime.Object) {
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return
+	}
+	for _, item := range items {
+		if object, ok := item.(*unstructured.Unstructured); ok {
+			minimizeUnstructured(object)
+			continue
+		}
+		if accessor, err := meta.Accessor(item); err == nil {
+			MinimizeObject(accessor)
+		}
+	}
+}
+
+// MinimizeObject removes managedFields, resourceVersion, uid, generation and the last-applied-configuration
+// annotation, which are large or meaningless for diagnosis
+func MinimizeObject(object metav1.Object) {
+	if object, ok := object.(*unstructured.Unstructured); ok {
+		minimizeUnstructured(object)
+		return
+	}
+
+	object.SetManagedFields(nil)
+	object.SetResourceVersion("")
+	object.SetUID("")
+	object.SetGeneration(0)
+	removeLastApplied(object)
+}
+
+// minimizeUnstructured removes the same fields as MinimizeObject; the unstructured setters would
+// leave empty values behind instead
+func minimizeUnstructured(object *unstructured.Unstructured) {
+	for _, field := range []string{"managedFields", "resourceVersion", "uid", "generation"} {
+		unstructured.RemoveNestedField(object.Object, "metadata", field)
+	}
+	removeLastApplied(object)
+}
+
+// removeLastApplied drops the last-applied-configuration annotation, leaving other annotations intact
+func removeLastApplied(object metav1.Object) {
+	annotations := object.GetAnnotations()
+	if _, ok := annotations[lastAppliedAnnotation]; !ok {
+		return
+	}
+	delete(annotations, lastAppliedAnnotation)
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	object.SetAnnotations(annotations)
+}
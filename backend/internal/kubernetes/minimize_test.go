@@ -0,0 +1,157 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// appliedDeployment returns a Deployment as the API server returns it after kubectl apply and a rollout,
+// with managedFields from both and kubectl's last-applied annotation
+func appliedDeployment() *appsv1.Deployment {
+	replicas := int32(3)
+	labels := map[string]string{"app": "api", "tier": "backend"}
+	deployment := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "api",
+			Namespace:       "shop",
+			UID:             "4f1c8a2e-7d3b-4e59-9a61-0c2f5b8d7e13",
+			ResourceVersion: "184467",
+			Generation:      7,
+			Labels:          labels,
+			Annotations:     map[string]string{"deployment.kubernetes.io/revision": "7"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name:  "api",
+						Image: "registry.example.com/shop/api:1.14.2",
+						Ports: []v1.ContainerPort{{Name: "http", ContainerPort: 8080, Protocol: v1.ProtocolTCP}},
+						Env:   []v1.EnvVar{{Name: "LOG_LEVEL", Value: "info"}, {Name: "DB_HOST", Value: "postgres.shop.svc"}},
+						ReadinessProbe: &v1.Probe{
+							ProbeHandler: v1.ProbeHandler{HTTPGet: &v1.HTTPGetAction{Path: "/healthz", Port: intstr.FromString("http")}},
+						},
+					}},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 7,
+			Replicas:           3,
+			ReadyReplicas:      3,
+			AvailableReplicas:  3,
+			UpdatedReplicas:    3,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: v1.ConditionTrue, Reason: "MinimumReplicasAvailable", Message: "Deployment has minimum availability."},
+				{Type: appsv1.DeploymentProgressing, Status: v1.ConditionTrue, Reason: "NewReplicaSetAvailable", Message: `ReplicaSet "api-7c9d5b6f8" has successfully progressed.`},
+			},
+		},
+	}
+
+	lastApplied, _ := json.Marshal(struct {
+		metav1.TypeMeta
+		Metadata metav1.ObjectMeta     `json:"metadata"`
+		Spec     appsv1.DeploymentSpec `json:"spec"`
+	}{deployment.TypeMeta, metav1.ObjectMeta{Name: "api", Namespace: "shop", Labels: labels}, deployment.Spec})
+	deployment.Annotations[lastAppliedAnnotation] = string(lastApplied)
+
+	deployment.ManagedFields = []metav1.ManagedFieldsEntry{
+		{
+			Manager:    "kubectl-client-side-apply",
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			APIVersion: "apps/v1",
+			FieldsType: "FieldsV1",
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{".":{},"f:kubectl.kubernetes.io/last-applied-configuration":{}},` +
+				`"f:labels":{".":{},"f:app":{},"f:tier":{}}},"f:spec":{"f:progressDeadlineSeconds":{},"f:replicas":{},"f:revisionHistoryLimit":{},` +
+				`"f:selector":{},"f:strategy":{"f:rollingUpdate":{".":{},"f:maxSurge":{},"f:maxUnavailable":{}},"f:type":{}},` +
+				`"f:template":{"f:metadata":{"f:labels":{".":{},"f:app":{},"f:tier":{}}},"f:spec":{"f:containers":{"k:{\"name\":\"api\"}":{".":{},` +
+				`"f:env":{".":{},"k:{\"name\":\"DB_HOST\"}":{".":{},"f:name":{},"f:value":{}},"k:{\"name\":\"LOG_LEVEL\"}":{".":{},"f:name":{},"f:value":{}}},` +
+				`"f:image":{},"f:imagePullPolicy":{},"f:name":{},"f:ports":{".":{},"k:{\"containerPort\":8080,\"protocol\":\"TCP\"}":{".":{},` +
+				`"f:containerPort":{},"f:name":{},"f:protocol":{}}},"f:readinessProbe":{".":{},"f:failureThreshold":{},"f:httpGet":{".":{},` +
+				`"f:path":{},"f:port":{},"f:scheme":{}},"f:periodSeconds":{},"f:successThreshold":{},"f:timeoutSeconds":{}},"f:resources":{},` +
+				`"f:terminationMessagePath":{},"f:terminationMessagePolicy":{}}},"f:dnsPolicy":{},"f:restartPolicy":{},"f:schedulerName":{},` +
+				`"f:securityContext":{},"f:terminationGracePeriodSeconds":{}}}}}`)},
+		},
+		{
+			Manager:     "kube-controller-manager",
+			Operation:   metav1.ManagedFieldsOperationUpdate,
+			APIVersion:  "apps/v1",
+			FieldsType:  "FieldsV1",
+			Subresource: "status",
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{"f:deployment.kubernetes.io/revision":{}}},` +
+				`"f:status":{"f:availableReplicas":{},"f:conditions":{".":{},"k:{\"type\":\"Available\"}":{".":{},"f:lastTransitionTime":{},` +
+				`"f:lastUpdateTime":{},"f:message":{},"f:reason":{},"f:status":{},"f:type":{}},"k:{\"type\":\"Progressing\"}":{".":{},` +
+				`"f:lastTransitionTime":{},"f:lastUpdateTime":{},"f:message":{},"f:reason":{},"f:status":{},"f:type":{}}},` +
+				`"f:observedGeneration":{},"f:readyReplicas":{},"f:replicas":{},"f:updatedReplicas":{}}}`)},
+		},
+	}
+	return deployment
+}
+
+func TestMinimizeObjectTokenSavings(t *testing.T) {
+	deployment := appliedDeployment()
+	full, err := json.Marshal(deployment)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	MinimizeObject(deployment)
+	minimized, err := json.Marshal(deployment)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Tokens are estimated at 4 bytes each, as the AI service budgets prompts
+	fullTokens, minimizedTokens := (len(full)+3)/4, (len(minimized)+3)/4
+	savings := 100 * (fullTokens - minimizedTokens) / fullTokens
+	t.Logf("Deployment JSON: %d bytes (~%d tokens) full, %d bytes (~%d tokens) minimized, %d%% fewer tokens",
+		len(full), fullTokens, len(minimized), minimizedTokens, savings)
+	if savings < 60 {
+		t.Errorf("minimizing saved %d%% of tokens, want at least 60%%", savings)
+	}
+
+	if len(deployment.ManagedFields) != 0 || deployment.ResourceVersion != "" || deployment.UID != "" || deployment.Generation != 0 {
+		t.Errorf("bookkeeping metadata left after minimizing: %+v", deployment.ObjectMeta)
+	}
+	if _, ok := deployment.Annotations[lastAppliedAnnotation]; ok {
+		t.Error("last-applied-configuration annotation left after minimizing")
+	}
+	if deployment.Annotations["deployment.kubernetes.io/revision"] != "7" {
+		t.Error("minimizing removed an unrelated annotation")
+	}
+	if deployment.Status.ReadyReplicas != 3 || deployment.Spec.Template.Spec.Containers[0].Image == "" {
+		t.Error("minimizing removed spec or status")
+	}
+}
+
+func TestGatherMinimize(t *testing.T) {
+	service, _, ctx := newFakeService(appliedDeployment())
+
+	full, err := service.Gather(ctx, GatherOptions{ResourceTypes: []string{"deployments"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	minimized, err := service.Gather(ctx, GatherOptions{ResourceTypes: []string{"deployments"}, Minimize: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fullData, _ := json.Marshal(full.Resources)
+	minimizedData, _ := json.Marshal(minimized.Resources)
+	t.Logf("gathered deployments: %d bytes full, %d bytes minimized", len(fullData), len(minimizedData))
+	if len(minimizedData)*2 > len(fullData) {
+		t.Errorf("minimized gather is %d bytes, want at most half of the full %d bytes", len(minimizedData), len(fullData))
+	}
+	if items := minimized.Resources["deployments"].(*appsv1.DeploymentList).Items; len(items) != 1 || items[0].ManagedFields != nil {
+		t.Errorf("minimized deployments = %+v, want one without managedFields", items)
+	}
+}
@@ -0,0 +1,87 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// gatherNamespaces gathers the requested types from each of opts.Namespaces in turn and keys the results
+// by namespace. Every namespace is checked against the namespace policy before anything is listed, so a
+// refused namespace fails the whole gather as it would for a single namespace
+func (s *Service) gatherNamespaces(ctx context.Context, opts GatherOptions) (*GatherResourcesResponse, error) {
+	namespaces, err := NormalizeNamespaces(opts.Namespaces)
+	if err != nil {
+		return nil, err
+	}
+	for _, namespace := range namespaces {
+		if err := s.checkNamespace(namespace); err != nil {
+			s.log(ctx).Warn("Refusing to gather from namespace", zap.String("namespace", namespace))
+			return nil, err
+		}
+	}
+
+	resources := make(map[string]interface{}, len(namespaces))
+	for _, namespace := range namespaces {
+		single := opts
+		single.Namespace = namespace
+		single.Namespaces = nil
+		response, err := s.Gather(ctx, single)
+		if err != nil {
+			return nil, fmt.Errorf("failed to gather from namespace %s: %w", namespace, err)
+		}
+		resources[namespace] = response.Resources
+	}
+
+	return &GatherResourcesResponse{
+		Resources: resources,
+		Metadata: GatherMetadata{
+			Timestamp:      time.Now().UTC().Format(time.RFC3339),
+			ClusterContext: s.contextName,
+			Namespace:      strings.Join(namespaces, ","),
+			Namespaces:     namespaces,
+			NewerThan:      durationString(opts.NewerThan),
+			OlderThan:      durationString(opts.OlderThan),
+		},
+	}, nil
+}
+
+// NormalizeNamespaces trims and de-duplicates a namespace list, keeping its order. It returns
+// ErrInvalidNamespaces when no namespace is left or the list includes AllNamespaces
+func NormalizeNamespaces(namespaces []string) ([]string, error) {
+	seen := make(map[string]bool, len(namespaces))
+	var normalized []string
+	for _, namespace := range namespaces {
+		namespace = strings.TrimSpace(namespace)
+		if namespace == "" || seen[namespace] {
+			continue
+		}
+		if namespace == AllNamespaces {
+			return nil, fmt.Errorf("%w: %q can't be combined with a namespace list; gather cluster-wide instead", ErrInvalidNamespaces, AllNamespaces)
+		}
+		seen[namespace] = true
+		normalized = append(normalized, namespace)
+	}
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("%w: no namespaces given", ErrInvalidNamespaces)
+	}
+	return normalized, nil
+}
+
+// ByNamespace returns the gathered resources grouped by namespace. A multi-namespace gather is returned as
+// is; any other gather is a single group under its metadata namespace
+func (r *GatherResourcesResponse) ByNamespace() map[string]map[string]interface{} {
+	if len(r.Metadata.Namespaces) == 0 {
+		return map[string]map[string]interface{}{r.Metadata.Namespace: r.Resources}
+	}
+	groups := make(map[string]map[string]interface{}, len(r.Metadata.Namespaces))
+	for _, namespace := range r.Metadata.Namespaces {
+		if resources, ok := r.Resources[namespace].(map[string]interface{}); ok {
+			groups[namespace] = resources
+		}
+	}
+	return groups
+}
@@ -0,0 +1,79 @@
+package kubernetes
+
+import (
+	"fmt"
+	"path"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// NamespacePolicy restricts which namespaces the service may read. Entries may be glob patterns
+// such as "team-*". A namespace matching Denied is always refused; when Allowed is non-empty,
+// only namespaces matching it are permitted.
+type NamespacePolicy struct {
+	Allowed []string
+	Denied  []string
+}
+
+// Option configures optional Kubernetes service behavior
+type Option func(*Service)
+
+// WithNamespacePolicy restricts the service to the namespaces permitted by the allow and deny lists
+func WithNamespacePolicy(allowed, denied []string) Option {
+	return func(s *Service) {
+		s.namespacePolicy = NamespacePolicy{Allowed: allowed, Denied: denied}
+	}
+}
+
+// Permits reports whether namespace may be read under the policy
+func (p NamespacePolicy) Permits(namespace string) bool {
+	if matchesAny(p.Denied, namespace) {
+		return false
+	}
+	return len(p.Allowed) == 0 || matchesAny(p.Allowed, namespace)
+}
+
+// matchesAny reports whether namespace matches any of the patterns
+func matchesAny(patterns []string, namespace string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, namespace); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNamespace returns ErrNamespaceNotPermitted when a specific namespace is excluded by the policy.
+// The empty namespace (all namespaces or cluster-scoped) is permitted; its results are filtered instead
+func (s *Service) checkNamespace(namespace string) error {
+	if namespace == "" || s.namespacePolicy.Permits(namespace) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrNamespaceNotPermitted, namespace)
+}
+
+// filterNamespaced removes items in namespaces excluded by the policy from any list object
+func (s *Service) filterNamespaced(list runtime.Object) {
+	if len(s.namespacePolicy.Allowed) == 0 && len(s.namespacePolicy.Denied) == 0 {
+		return
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return
+	}
+	kept := make([]runtime.Object, 0, len(items))
+	for _, item := range items {
+		accessor, err := meta.Accessor(item)
+		if err != nil {
+			continue
+		}
+		if ns := accessor.GetNamespace(); ns == "" || s.namespacePolicy.Permits(ns) {
+			kept = append(kept, item)
+		}
+	}
+	if len(kept) != len(items) {
+		meta.SetList(list, kept)
+	}
+}
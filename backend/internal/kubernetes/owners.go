@@ -0,0 +1,124 @@
+package kubernetes
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxOwnerDepth bounds how many ownerReference hops OwnerResolver follows
+const maxOwnerDepth = 3
+
+// ControllerRef names the top-level controller that owns an object
+type ControllerRef struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// String renders the controller as Kind/Name, or an empty string for objects without a controller
+func (c ControllerRef) String() string {
+	if c.Kind == "" {
+		return ""
+	}
+	return c.Kind + "/" + c.Name
+}
+
+// OwnerResolver maps objects to their top-level controller by following controller ownerReferences
+// through ReplicaSets to Deployments and through Jobs to CronJobs. ReplicaSets and Jobs are listed at
+// most once per namespace and cached, so resolving many pods takes at most two list calls per namespace.
+// Create one per gather or tool call; it is not safe for concurrent use
+type OwnerResolver struct {
+	service *Service
+	// owners maps kind and namespace, then name, to the controller of that ReplicaSet or Job
+	owners map[string]map[string]*metav1.OwnerReference
+}
+
+// NewOwnerResolver returns an OwnerResolver with an empty cache. On a nil service, objects resolve to
+// their direct controller
+func (s *Service) NewOwnerResolver() *OwnerResolver {
+	return &OwnerResolver{
+		service: s,
+		owners:  make(map[string]map[string]*metav1.OwnerReference),
+	}
+}
+
+// Controller returns the top-level controller of an object in namespace with the given ownerReferences,
+// such as Deployment/api for a pod owned by one of its ReplicaSets. When an intermediate owner can't be
+// read, the last resolved owner is returned
+func (r *OwnerResolver) Controller(ctx context.Context, namespace string, ownerRefs []metav1.OwnerReference) ControllerRef {
+	ref := controllerOf(ownerRefs)
+	if ref == nil {
+		return ControllerRef{}
+	}
+	for depth := 0; depth < maxOwnerDepth && (ref.Kind == "ReplicaSet" || ref.Kind == "Job"); depth++ {
+		parent := r.ownerOf(ctx, ref.Kind, namespace, ref.Name)
+		if parent == nil {
+			break
+		}
+		ref = parent
+	}
+	return ControllerRef{Kind: ref.Kind, Name: ref.Name}
+}
+
+// ownerOf returns the controller of a ReplicaSet or Job, listing that kind in the namespace on first use
+func (r *OwnerResolver) ownerOf(ctx context.Context, kind, namespace, name string) *metav1.OwnerReference {
+	if r.service == nil {
+		return nil
+	}
+	key := kind + "/" + namespace
+	owners, ok := r.owners[key]
+	if !ok {
+		owners = r.listOwners(ctx, kind, namespace)
+		r.owners[key] = owners
+	}
+	return owners[name]
+}
+
+// listOwners lists the ReplicaSets or Jobs in a namespace and indexes their controllers by name. Failures
+// are logged and leave the kind unresolved in that namespace
+func (r *OwnerResolver) listOwners(ctx context.Context, kind, namespace string) map[string]*metav1.OwnerReference {
+	owners := make(map[string]*metav1.OwnerReference)
+	if err := r.service.checkNamespace(namespace); err != nil {
+		return owners
+	}
+
+	var items []metav1.Object
+	switch kind {
+	case "ReplicaSet":
+		list, err := r.service.clientsetFor(ctx).AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			r.service.log(ctx).Debug("Failed to list replicasets for owner resolution", zap.String("namespace", namespace), zap.Error(err))
+			return owners
+		}
+		for i := range list.Items {
+			items = append(items, &list.Items[i])
+		}
+	case "Job":
+		list, err := r.service.clientsetFor(ctx).BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			r.service.log(ctx).Debug("Failed to list jobs for owner resolution", zap.String("namespace", namespace), zap.Error(err))
+			return owners
+		}
+		for i := range list.Items {
+			items = append(items, &list.Items[i])
+		}
+	}
+
+	for _, item := range items {
+		if ref := controllerOf(item.GetOwnerReferences()); ref != nil {
+			owners[item.GetName()] = ref
+		}
+	}
+	return owners
+}
+
+// controllerOf returns the ownerReference marked as the controller, or nil
+func controllerOf(ownerRefs []metav1.OwnerReference) *metav1.OwnerReference {
+	for i := range ownerRefs {
+		if ref := &ownerRefs[i]; ref.Controller != nil && *ref.Controller {
+			return ref
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AccessCheck describes a single permission to verify with an access review
+type AccessCheck struct {
+	Namespace string
+	// ServiceAccount is checked when set; otherwise the service's own credentials are checked
+	ServiceAccount string
+	Verb           string
+	Group          string
+	Resource       string
+	Subresource    string
+	Name           string
+}
+
+// ListRoles lists the Roles in a namespace
+func (s *Service) ListRoles(ctx context.Context, namespace string) (*rbacv1.RoleList, error) {
+	if err := s.checkNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	roles, err := s.clientsetFor(ctx).RbacV1().Roles(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		s.log(ctx).Error("Failed to list roles", zap.Error(err), zap.String("namespace", namespace))
+		return nil, fmt.Errorf("failed to list roles in %s: %w", namespace, classifyAPIError(err))
+	}
+	return roles, nil
+}
+
+// ListRoleBindings lists the RoleBindings in a namespace
+func (s *Service) ListRoleBindings(ctx context.Context, namespace string) (*rbacv1.RoleBindingList, error) {
+	if err := s.checkNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	bindings, err := s.clientsetFor(ctx).RbacV1().RoleBindings(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		s.log(ctx).Error("Failed to list role bindings", zap.Error(err), zap.String("namespace", namespace))
+		return nil, fmt.Errorf("failed to list role bindings in %s: %w", namespace, classifyAPIError(err))
+	}
+	return bindings, nil
+}
+
+// ListClusterRoleBindings lists all ClusterRoleBindings
+func (s *Service) ListClusterRoleBindings(ctx context.Context) (*rbacv1.ClusterRoleBindingList, error) {
+	bindings, err := s.clientsetFor(ctx).RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		s.log(ctx).Error("Failed to list cluster role bindings", zap.Error(err))
+		return nil, fmt.Errorf("failed to list cluster role bindings: %w", classifyAPIError(err))
+	}
+	return bindings, nil
+}
+
+// GetClusterRole retrieves a single ClusterRole by name
+func (s *Service) GetClusterRole(ctx context.Context, name string) (*rbacv1.ClusterRole, error) {
+	role, err := s.clientsetFor(ctx).RbacV1().ClusterRoles().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		s.log(ctx).Error("Failed to get cluster role", zap.Error(err), zap.String("clusterRole", name))
+		return nil, fmt.Errorf("failed to get cluster role %s: %w", name, classifyAPIError(err))
+	}
+	return role, nil
+}
+
+// CheckAccess runs a SubjectAccessReview for a service account, or a SelfSubjectAccessReview when none is given
+func (s *Service) CheckAccess(ctx context.Context, check AccessCheck) (*authorizationv1.SubjectAccessReviewStatus, error) {
+	if err := s.checkNamespace(check.Namespace); err != nil {
+		return nil, err
+	}
+
+	attributes := &authorizationv1.ResourceAttributes{
+		Namespace:   check.Namespace,
+		Verb:        check.Verb,
+		Group:       check.Group,
+		Resource:    check.Resource,
+		Subresource: check.Subresource,
+		Name:        check.Name,
+	}
+
+	if check.ServiceAccount == "" {
+		review, err := s.clientsetFor(ctx).AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: attributes},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			s.log(ctx).Error("Failed to run self subject access review", zap.Error(err))
+			return nil, fmt.Errorf("failed to check access: %w", classifyAPIError(err))
+		}
+		return &review.Status, nil
+	}
+
+	review, err := s.clientsetFor(ctx).AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: attributes,
+			User:               ServiceAccountUsername(check.Namespace, check.ServiceAccount),
+			Groups:             ServiceAccountGroups(check.Namespace),
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		s.log(ctx).Error("Failed to run subject access review", zap.Error(err))
+		return nil, fmt.Errorf("failed to check access for service account %s/%s: %w",
+			check.Namespace, check.ServiceAccount, classifyAPIError(err))
+	}
+	return &review.Status, nil
+}
+
+// ServiceAccountUsername returns the username the API server authenticates a service account as
+func ServiceAccountUsername(namespace, name string) string {
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name)
+}
+
+// ServiceAccountGroups returns the groups every service account in namespace belongs to
+func ServiceAccountGroups(namespace string) []string {
+	return []string{"system:serviceaccounts", "system:serviceaccounts:" + namespace, "system:authenticated"}
+}
@@ -0,0 +1,438 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/restmapper"
+)
+
+// Limits on the graph RelatedResources walks
+const (
+	maxRelatedResources = 40
+	maxOwnerHops        = 5
+	maxRelatedChildren  = 10
+)
+
+// Relations of a resource to the one RelatedResources started from
+const (
+	RelationSelf       = "self"
+	RelationOwner      = "owner"
+	RelationOwned      = "owned"
+	RelationReferenced = "referenced"
+)
+
+// ResourceRef identifies an object; Namespace is empty for cluster-scoped objects
+type ResourceRef struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// String renders the reference as Kind/namespace/name, or Kind/name for cluster-scoped objects
+func (r ResourceRef) String() string {
+	if r.Namespace == "" {
+		return r.Kind + "/" + r.Name
+	}
+	return r.Kind + "/" + r.Namespace + "/" + r.Name
+}
+
+// RelatedResource is one object in the graph of related resources
+type RelatedResource struct {
+	ResourceRef
+	Relation string `json:"relation"`
+	// Missing means the object is referenced but doesn't exist, often the cause of the failure itself
+	Missing bool   `json:"missing,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// Object is the minimized and redacted object; Secrets are only described by their type and key names
+	Object interface{} `json:"object,omitempty"`
+}
+
+// ResourceEdge connects two related resources, named as by ResourceRef.String, e.g. a ReplicaSet that
+// owns a pod or a pod that mounts a ConfigMap
+type ResourceEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type"`
+}
+
+// RelatedResources is the graph of resources connected to a starting resource
+type RelatedResources struct {
+	Root      ResourceRef       `json:"root"`
+	Resources []RelatedResource `json:"resources"`
+	Edges     []ResourceEdge    `json:"edges"`
+	// Truncated is set when the graph was cut at its size limits
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// relatedWalker builds a RelatedResources graph, fetching each object once
+type relatedWalker struct {
+	service        *Service
+	includeObjects bool
+	result         *RelatedResources
+	seen           map[string]bool
+}
+
+// RelatedResources discovers the resources connected to one object: its owners, followed up the
+// ownerReferences chain, the objects it owns, such as a Deployment's ReplicaSets and their pods, and for
+// pods and workloads the ConfigMaps, Secrets, PersistentVolumeClaims (with their volumes and storage
+// classes) and service account their pod spec references. kind may be a kind, resource or short name,
+// such as Deployment, deployments or deploy. Referenced objects that don't exist are included as missing.
+// With includeObjects, each object is returned minimized and redacted
+func (s *Service) RelatedResources(ctx context.Context, kind, namespace, name string, includeObjects bool) (*RelatedResources, error) {
+	if s == nil {
+		return nil, ErrClusterUnavailable
+	}
+	gvr, gvk, namespaced, err := s.resolveKind(kind)
+	if err != nil {
+		return nil, err
+	}
+	if !namespaced {
+		namespace = ""
+	}
+
+	root, err := s.GetResource(ctx, gvr, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	walker := &relatedWalker{
+		service:        s,
+		includeObjects: includeObjects,
+		result: &RelatedResources{
+			Root:      ResourceRef{Kind: gvk.Kind, Namespace: namespace, Name: name},
+			Resources: []RelatedResource{},
+			Edges:     []ResourceEdge{},
+		},
+		seen: make(map[string]bool),
+	}
+	walker.add(walker.result.Root, RelationSelf, root, nil)
+	walker.owners(ctx, root, 0)
+	walker.references(ctx, root)
+	walker.children(ctx, root, gvk.Kind, 0)
+
+	s.log(ctx).Info("Discovered related resources",
+		zap.String("root", walker.result.Root.String()),
+		zap.Int("resources", len(walker.result.Resources)),
+		zap.Bool("truncated", walker.result.Truncated))
+	return walker.result, nil
+}
+
+// resolveKind maps a kind, resource or short name to its resource, kind and scope
+func (s *Service) resolveKind(kind string) (schema.GroupVersionResource, schema.GroupVersionKind, bool, error) {
+	mapper := restmapper.NewShortcutExpander(s.restMapper(), s.clientset.Discovery(), func(string) {})
+	gvr, err := mapper.ResourceFor(schema.GroupVersionResource{Resource: strings.ToLower(kind)})
+	if err != nil {
+		return schema.GroupVersionResource{}, schema.GroupVersionKind{}, false, fmt.Errorf("%w: unknown kind %q", ErrUnsupportedResourceType, kind)
+	}
+	gvk, err := mapper.KindFor(gvr)
+	if err != nil {
+		return schema.GroupVersionResource{}, schema.GroupVersionKind{}, false, fmt.Errorf("%w: unknown kind %q", ErrUnsupportedResourceType, kind)
+	}
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return schema.GroupVersionResource{}, schema.GroupVersionKind{}, false, fmt.Errorf("%w: unknown kind %q", ErrUnsupportedResourceType, kind)
+	}
+	return gvr, gvk, mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+}
+
+// add records an object, or a failure to get it when object is nil, unless it was already seen or the graph
+// is full. It reports whether the object is new, so callers only walk from it once
+func (w *relatedWalker) add(ref ResourceRef, relation string, object interface{}, err error) bool {
+	key := ref.String()
+	if w.seen[key] {
+		return false
+	}
+	if len(w.result.Resources) >= maxRelatedResources {
+		w.result.Truncated = true
+		return false
+	}
+	w.seen[key] = true
+
+	resource := RelatedResource{ResourceRef: ref, Relation: relation}
+	switch {
+	case errors.Is(err, ErrNotFound):
+		resource.Missing = true
+	case err != nil:
+		resource.Error = err.Error()
+	case w.includeObjects:
+		if accessor, err := meta.Accessor(object); err == nil {
+			MinimizeObject(accessor)
+		}
+		resource.Object = object
+	}
+	w.result.Resources = append(w.result.Resources, resource)
+	return err == nil
+}
+
+// edge records that from relates to to, when both are in the graph
+func (w *relatedWalker) edge(from, to ResourceRef, edgeType string) {
+	if !w.seen[from.String()] || !w.seen[to.String()] {
+		return
+	}
+	w.result.Edges = append(w.result.Edges, ResourceEdge{From: from.String(), To: to.String(), Type: edgeType})
+}
+
+// owners follows an object's ownerReferences upward
+func (w *relatedWalker) owners(ctx context.Context, object *unstructured.Unstructured, depth int) {
+	if depth >= maxOwnerHops {
+		return
+	}
+	child := refOf(object)
+	for _, ownerRef := range object.GetOwnerReferences() {
+		gv, err := schema.ParseGroupVersion(ownerRef.APIVersion)
+		if err != nil {
+			continue
+		}
+		ref := ResourceRef{Kind: ownerRef.Kind, Namespace: object.GetNamespace(), Name: ownerRef.Name}
+		mapping, err := w.service.restMapper().RESTMapping(gv.WithKind(ownerRef.Kind).GroupKind(), gv.Version)
+		if err != nil {
+			w.add(ref, RelationOwner, nil, fmt.Errorf("unknown kind %s: %w", ownerRef.Kind, err))
+			w.edge(ref, child, "owns")
+			continue
+		}
+		if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+			ref.Namespace = ""
+		}
+		owner, err := w.service.GetResource(ctx, mapping.Resource, ref.Namespace, ref.Name)
+		isNew := w.add(ref, RelationOwner, owner, err)
+		w.edge(ref, child, "owns")
+		if isNew {
+			w.owners(ctx, owner, depth+1)
+		}
+	}
+}
+
+// children lists the objects a workload owns: a Deployment's ReplicaSets, a CronJob's Jobs and the pods of
+// ReplicaSets, StatefulSets, DaemonSets and Jobs, newest first and at most maxRelatedChildren of each kind
+func (w *relatedWalker) children(ctx context.Context, object *unstructured.Unstructured, kind string, depth int) {
+	var childGVR schema.GroupVersionResource
+	var childKind string
+	switch kind {
+	case "Deployment":
+		childGVR, childKind = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "replicasets"}, "ReplicaSet"
+	case "CronJob":
+		childGVR, childKind = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}, "Job"
+	case "ReplicaSet", "StatefulSet", "DaemonSet", "Job":
+		childGVR, childKind = schema.GroupVersionResource{Version: "v1", Resource: "pods"}, "Pod"
+	default:
+		return
+	}
+
+	// The workload's selector narrows the list; CronJobs have none, and Jobs are matched by owner below
+	selector := ""
+	if labels, ok, _ := unstructured.NestedStringMap(object.Object, "spec", "selector", "matchLabels"); ok && kind != "CronJob" {
+		selector = metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: labels})
+	}
+	list, err := w.service.ListCustomResources(ctx, childGVR, object.GetNamespace(), selector)
+	if err != nil {
+		w.service.log(ctx).Debug("Failed to list owned resources", zap.String("kind", childKind), zap.Error(err))
+		return
+	}
+
+	parent := refOf(object)
+	var owned []*unstructured.Unstructured
+	for i := range list.Items {
+		if ownedBy(&list.Items[i], object.GetUID()) {
+			owned = append(owned, &list.Items[i])
+		}
+	}
+	// Newest first, so a Deployment's current ReplicaSet comes before old ones
+	sort.SliceStable(owned, func(i, j int) bool {
+		return owned[i].GetCreationTimestamp().After(owned[j].GetCreationTimestamp().Time)
+	})
+	if len(owned) > maxRelatedChildren {
+		owned = owned[:maxRelatedChildren]
+		w.result.Truncated = true
+	}
+	for _, child := range owned {
+		ref := ResourceRef{Kind: childKind, Namespace: child.GetNamespace(), Name: child.GetName()}
+		if w.add(ref, RelationOwned, child, nil) {
+			w.edge(parent, ref, "owns")
+			if depth == 0 {
+				w.children(ctx, child, childKind, depth+1)
+			}
+		}
+	}
+}
+
+// ownedBy reports whether any of an object's ownerReferences points at uid
+func ownedBy(object *unstructured.Unstructured, uid types.UID) bool {
+	for _, ref := range object.GetOwnerReferences() {
+		if ref.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// references adds the ConfigMaps, Secrets, PersistentVolumeClaims and service account that a pod's or
+// workload's pod spec references
+func (w *relatedWalker) references(ctx context.Context, object *unstructured.Unstructured) {
+	spec, ok := podSpecOf(object)
+	if !ok {
+		return
+	}
+	from := refOf(object)
+	namespace := object.GetNamespace()
+
+	for _, ref := range podSpecReferences(spec) {
+		to := ResourceRef{Kind: ref.kind, Namespace: namespace, Name: ref.name}
+		if w.seen[to.String()] {
+			w.edge(from, to, ref.edge)
+			continue
+		}
+		var related interface{}
+		var err error
+		switch ref.kind {
+		case "ConfigMap":
+			related, err = w.service.GetConfigMap(ctx, namespace, ref.name)
+		case "Secret":
+			related, err = w.service.GetSecretMetadata(ctx, namespace, ref.name)
+		case "ServiceAccount":
+			related, err = w.service.GetServiceAccount(ctx, namespace, ref.name)
+		case "PersistentVolumeClaim":
+			related, err = w.service.GetResource(ctx, schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}, namespace, ref.name)
+		}
+		// Optional references to missing objects are expected, so they aren't reported
+		if errors.Is(err, ErrNotFound) && ref.optional {
+			continue
+		}
+		isNew := w.add(to, RelationReferenced, related, err)
+		w.edge(from, to, ref.edge)
+		if claim, ok := related.(*unstructured.Unstructured); ok && isNew {
+			w.claimStorage(ctx, claim)
+		}
+	}
+}
+
+// claimStorage adds the PersistentVolume a claim is bound to and its StorageClass
+func (w *relatedWalker) claimStorage(ctx context.Context, claim *unstructured.Unstructured) {
+	from := refOf(claim)
+	if volume, _, _ := unstructured.NestedString(claim.Object, "spec", "volumeName"); volume != "" {
+		to := ResourceRef{Kind: "PersistentVolume", Name: volume}
+		object, err := w.service.GetResource(ctx, schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumes"}, "", volume)
+		w.add(to, RelationReferenced, object, err)
+		w.edge(from, to, "bound to")
+	}
+	if class, _, _ := unstructured.NestedString(claim.Object, "spec", "storageClassName"); class != "" {
+		to := ResourceRef{Kind: "StorageClass", Name: class}
+		object, err := w.service.GetResource(ctx, schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1", Resource: "storageclasses"}, "", class)
+		w.add(to, RelationReferenced, object, err)
+		w.edge(from, to, "storage class")
+	}
+}
+
+// refOf returns the reference to an unstructured object
+func refOf(object *unstructured.Unstructured) ResourceRef {
+	return ResourceRef{Kind: object.GetKind(), Namespace: object.GetNamespace(), Name: object.GetName()}
+}
+
+// podSpecOf extracts the pod spec of a pod, a workload's pod template or a CronJob's job template
+func podSpecOf(object *unstructured.Unstructured) (*v1.PodSpec, bool) {
+	var path []string
+	switch object.GetKind() {
+	case "Pod":
+		path = []string{"spec"}
+	case "Deployment", "ReplicaSet", "StatefulSet", "DaemonSet", "Job", "ReplicationController":
+		path = []string{"spec", "template", "spec"}
+	case "CronJob":
+		path = []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	default:
+		return nil, false
+	}
+	raw, ok, err := unstructured.NestedMap(object.Object, path...)
+	if err != nil || !ok {
+		return nil, false
+	}
+	var spec v1.PodSpec
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &spec); err != nil {
+		return nil, false
+	}
+	return &spec, true
+}
+
+// podSpecReference is an object a pod spec refers to, and how
+type podSpecReference struct {
+	kind     string
+	name     string
+	edge     string
+	optional bool
+}
+
+// podSpecReferences lists the ConfigMaps, Secrets, claims and service account a pod spec refers to, once
+// per object and way of use
+func podSpecReferences(spec *v1.PodSpec) []podSpecReference {
+	var refs []podSpecReference
+	seen := make(map[podSpecReference]bool)
+	add := func(kind, name, edge string, optional *bool) {
+		ref := podSpecReference{kind: kind, name: name, edge: edge, optional: optional != nil && *optional}
+		if name != "" && !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	serviceAccount := spec.ServiceAccountName
+	if serviceAccount == "" {
+		serviceAccount = "default"
+	}
+	add("ServiceAccount", serviceAccount, "runs as", nil)
+	for _, secret := range spec.ImagePullSecrets {
+		add("Secret", secret.Name, "pulls images with", nil)
+	}
+	for _, volume := range spec.Volumes {
+		switch {
+		case volume.ConfigMap != nil:
+			add("ConfigMap", volume.ConfigMap.Name, "mounts", volume.ConfigMap.Optional)
+		case volume.Secret != nil:
+			add("Secret", volume.Secret.SecretName, "mounts", volume.Secret.Optional)
+		case volume.PersistentVolumeClaim != nil:
+			add("PersistentVolumeClaim", volume.PersistentVolumeClaim.ClaimName, "mounts", nil)
+		case volume.Projected != nil:
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					add("ConfigMap", source.ConfigMap.Name, "mounts", source.ConfigMap.Optional)
+				}
+				if source.Secret != nil {
+					add("Secret", source.Secret.Name, "mounts", source.Secret.Optional)
+				}
+			}
+		}
+	}
+	for _, containers := range [][]v1.Container{spec.InitContainers, spec.Containers} {
+		for _, container := range containers {
+			for _, envFrom := range container.EnvFrom {
+				if envFrom.ConfigMapRef != nil {
+					add("ConfigMap", envFrom.ConfigMapRef.Name, "env from", envFrom.ConfigMapRef.Optional)
+				}
+				if envFrom.SecretRef != nil {
+					add("Secret", envFrom.SecretRef.Name, "env from", envFrom.SecretRef.Optional)
+				}
+			}
+			for _, env := range container.Env {
+				if env.ValueFrom == nil {
+					continue
+				}
+				if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+					add("ConfigMap", ref.Name, "env from", ref.Optional)
+				}
+				if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+					add("Secret", ref.Name, "env from", ref.Optional)
+				}
+			}
+		}
+	}
+	return refs
+}
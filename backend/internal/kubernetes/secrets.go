@@ -0,0 +1,67 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// SecretMetadata describes a Secret without its values
+type SecretMetadata struct {
+	Name      string        `json:"name"`
+	Namespace string        `json:"namespace"`
+	Type      v1.SecretType `json:"type"`
+	Keys      []string      `json:"keys"`
+	Immutable bool          `json:"immutable,omitempty"`
+}
+
+// GetSecretMetadata returns a Secret's type and key names. Values never leave this method
+func (s *Service) GetSecretMetadata(ctx context.Context, namespace, name string) (*SecretMetadata, error) {
+	if err := s.checkNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	secret, err := s.clientsetFor(ctx).CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		s.log(ctx).Error("Failed to get secret", zap.Error(err), zap.String("secret", name))
+		return nil, fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, classifyAPIError(err))
+	}
+
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return &SecretMetadata{
+		Name:      secret.Name,
+		Namespace: secret.Namespace,
+		Type:      secret.Type,
+		Keys:      keys,
+		Immutable: secret.Immutable != nil && *secret.Immutable,
+	}, nil
+}
+
+// stripSecretValues replaces the values of a Secret read through the dynamic client with a placeholder,
+// keeping its key names, so Secret values never leave the service whatever the redaction policy
+func stripSecretValues(gvr schema.GroupVersionResource, object *unstructured.Unstructured) {
+	if gvr.Group != "" || gvr.Resource != "secrets" {
+		return
+	}
+	for _, field := range []string{"data", "stringData"} {
+		values, ok := object.Object[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key := range values {
+			values[key] = redactedValue
+		}
+	}
+	removeLastApplied(object)
+}
@@ -1,26 +1,63 @@
 package kubernetes
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/client-go/util/homedir"
+
+	"kube-sherlock/internal/logging"
 )
 
 // Service handles Kubernetes cluster interactions
 type Service struct {
-	clientset   *kubernetes.Clientset
-	config      *rest.Config
-	contextName string
-	logger      *zap.Logger
+	clientset     *kubernetes.Clientset
+	dynamicClient dynamic.Interface
+	config        *rest.Config
+	contextName   string
+	logger        *zap.Logger
+	// namespacePolicy limits which namespaces can be read
+	namespacePolicy NamespacePolicy
+	// impersonate is the identity all requests act as, when configured
+	impersonate rest.ImpersonationConfig
+	// defaultNamespace is used when a request names no namespace
+	defaultNamespace string
+	// kubeconfigContent, when set, is used instead of the kubeconfig file
+	kubeconfigContent []byte
+	// redaction is the configured policy; redactor is its compiled form, nil when disabled
+	redaction RedactionPolicy
+	redactor  *redactor
+	// gathers lets concurrent identical gathers share one set of list calls, and flights tracks the callers
+	// waiting on each so the last to leave cancels it; flightsMu guards flights. See sharedGather
+	gathers   singleflight.Group
+	flightsMu sync.Mutex
+	flights   map[string]*gatherFlight
+	// maxLogReadBytes caps a single log read; see logReadLimit
+	maxLogReadBytes int64
+	// mapper maps kinds to resources for manifest validation; see restMapper
+	mapperOnce sync.Once
+	mapper     meta.RESTMapper
 }
 
 // GatherResourcesResponse represents the response with gathered resource data
@@ -34,14 +71,55 @@ type GatherMetadata struct {
 	Timestamp      string `json:"timestamp"`
 	ClusterContext string `json:"clusterContext"`
 	Namespace      string `json:"namespace"`
+	AllNamespaces  bool   `json:"allNamespaces,omitempty"`
+	// Namespaces lists the namespaces of a multi-namespace gather, whose resources are keyed by namespace
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Truncated lists resource types whose results were cut off at the list limit
+	Truncated []string `json:"truncated,omitempty"`
+	// NewerThan and OlderThan echo the age filter applied to the results
+	NewerThan string `json:"newerThan,omitempty"`
+	OlderThan string `json:"olderThan,omitempty"`
 }
 
+// AllNamespaces is the namespace value that gathers resources cluster-wide
+const AllNamespaces = "*"
+
+// allNamespacesListLimit caps items per resource type for cluster-wide gathers to bound payload size
+const allNamespacesListLimit = 500
+
+// maxConcurrentGathers bounds how many resource types Gather lists in parallel
+const maxConcurrentGathers = 4
+
 // NewService creates a new Kubernetes service
-func NewService(configPath, contextName string, logger *zap.Logger) (*Service, error) {
+func NewService(configPath, contextName string, logger *zap.Logger, opts ...Option) (*Service, error) {
+	service := &Service{
+		contextName: contextName,
+		logger:      logger,
+	}
+	for _, opt := range opts {
+		opt(service)
+	}
+
 	var config *rest.Config
 	var err error
 
-	if configPath == "" {
+	if service.redactor, err = newRedactor(service.redaction); err != nil {
+		return nil, err
+	}
+
+	if len(service.kubeconfigContent) > 0 {
+		config, service.defaultNamespace, err = configFromContent(service.kubeconfigContent, contextName)
+		if err != nil {
+			logger.Error("Failed to load kubeconfig content", zap.Error(err))
+			return nil, err
+		}
+		logger.Info("Using kubeconfig from provided content instead of a file")
+	} else {
+		service.defaultNamespace = KubeconfigNamespace(configPath, contextName)
+	}
+
+	// A named context always refers to a kubeconfig, so in-cluster config is only tried without one
+	if config == nil && configPath == "" && contextName == "" {
 		// Try in-cluster config first
 		config, err = rest.InClusterConfig()
 		if err != nil {
@@ -53,12 +131,22 @@ func NewService(configPath, contextName string, logger *zap.Logger) (*Service, e
 	}
 
 	if config == nil {
-		// Load from kubeconfig
-		config, err = clientcmd.BuildConfigFromFlags("", configPath)
+		// Load from kubeconfig, honoring the configured context
+		config, err = configFromFile(configPath, contextName)
 		if err != nil {
-			logger.Error("Failed to load kubeconfig", zap.Error(err), zap.String("path", configPath))
-			return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+			logger.Error("Failed to load kubeconfig", zap.Error(err), zap.String("path", configPath), zap.String("context", contextName))
+			return nil, err
+		}
+	}
+
+	if service.impersonate.UserName != "" || len(service.impersonate.Groups) > 0 {
+		if service.impersonate.UserName == "" {
+			return nil, ErrInvalidImpersonation
 		}
+		config.Impersonate = service.impersonate
+		logger.Info("Impersonating for all Kubernetes requests",
+			zap.String("user", service.impersonate.UserName),
+			zap.Strings("groups", service.impersonate.Groups))
 	}
 
 	// Create clientset
@@ -68,161 +156,780 @@ func NewService(configPath, contextName string, logger *zap.Logger) (*Service, e
 		return nil, fmt.Errorf("failed to create clientset: %w", err)
 	}
 
+	// Create dynamic client for custom resources
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		logger.Error("Failed to create Kubernetes dynamic client", zap.Error(err))
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	service.clientset = clientset
+	service.dynamicClient = dynamicClient
+	service.config = config
+
 	// Test connection
 	testCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	_, err = clientset.CoreV1().Namespaces().List(testCtx, metav1.ListOptions{Limit: 1})
-	if err != nil {
+	if err := service.Ping(testCtx); err != nil {
 		logger.Error("Failed to connect to Kubernetes cluster", zap.Error(err))
 		return nil, fmt.Errorf("failed to connect to cluster: %w", err)
 	}
 
-	logger.Info("Successfully connected to Kubernetes cluster")
+	logger.Info("Successfully connected to Kubernetes cluster", zap.String("defaultNamespace", service.defaultNamespace))
+	return service, nil
+}
 
-	return &Service{
-		clientset:   clientset,
-		config:      config,
-		contextName: contextName,
-		logger:      logger,
-	}, nil
+// log returns the request-scoped logger from ctx, falling back to the service logger
+func (s *Service) log(ctx context.Context) *zap.Logger {
+	return logging.FromContext(ctx, s.logger)
+}
+
+// ListContexts returns the context names defined in the kubeconfig and the current context.
+// An empty configPath uses the default loading rules (KUBECONFIG or ~/.kube/config).
+func ListContexts(configPath string) ([]string, string, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if configPath != "" {
+		loadingRules.ExplicitPath = configPath
+	}
+
+	rawConfig, err := loadingRules.Load()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	return contextNames(rawConfig), rawConfig.CurrentContext, nil
+}
+
+// contextNames returns the names of the contexts a kubeconfig defines, sorted
+func contextNames(rawConfig *clientcmdapi.Config) []string {
+	contexts := make([]string, 0, len(rawConfig.Contexts))
+	for name := range rawConfig.Contexts {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+	return contexts
+}
+
+// ValidateContext checks that the kubeconfig loads and, when contextName is set, that it defines that
+// context. The error for a missing context lists the contexts that exist
+func ValidateContext(configPath, contextName string) error {
+	contexts, _, err := ListContexts(configPath)
+	if err != nil {
+		return err
+	}
+	return checkContext(contexts, contextName)
+}
+
+// checkContext returns ErrContextNotFound, listing the available contexts, when contextName is set and
+// not one of contexts
+func checkContext(contexts []string, contextName string) error {
+	if contextName == "" || slices.Contains(contexts, contextName) {
+		return nil
+	}
+	if len(contexts) == 0 {
+		return fmt.Errorf("%w: %q: the kubeconfig defines no contexts", ErrContextNotFound, contextName)
+	}
+	return fmt.Errorf("%w: %q; available contexts: %s", ErrContextNotFound, contextName, strings.Join(contexts, ", "))
+}
+
+// configFromFile builds a client config from the kubeconfig at configPath, or the default kubeconfig
+// locations when it is empty, using contextName in place of the current context when it is set
+func configFromFile(configPath, contextName string) (*rest.Config, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if configPath != "" {
+		loadingRules.ExplicitPath = configPath
+	}
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules,
+		&clientcmd.ConfigOverrides{CurrentContext: contextName})
+
+	if contextName != "" {
+		rawConfig, err := clientConfig.RawConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+		}
+		if err := checkContext(contextNames(&rawConfig), contextName); err != nil {
+			return nil, err
+		}
+	}
+
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	return config, nil
+}
+
+// KubeconfigNamespace returns the namespace set on the kubeconfig context (the current context when
+// contextName is empty), or the pod's namespace in-cluster, falling back to "default"
+func KubeconfigNamespace(configPath, contextName string) string {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if configPath != "" {
+		loadingRules.ExplicitPath = configPath
+	}
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules,
+		&clientcmd.ConfigOverrides{CurrentContext: contextName})
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil || namespace == "" {
+		return metav1.NamespaceDefault
+	}
+	return namespace
+}
+
+// DefaultNamespace returns the namespace used when a request names none. It is safe to call on a nil service
+func (s *Service) DefaultNamespace() string {
+	if s == nil || s.defaultNamespace == "" {
+		return metav1.NamespaceDefault
+	}
+	return s.defaultNamespace
+}
+
+// SupportedResourceTypes are the resource types Gather lists by name. Any other resource can be
+// requested as group/version/resource
+var SupportedResourceTypes = []string{
+	"pods", "deployments", "replicasets", "statefulsets", "daemonsets", "services",
+	"endpoints", "endpointslices", "configmaps", "secrets", "events", "networkpolicies",
+	"resourcequotas", "limitranges", "poddisruptionbudgets",
+}
+
+// ValidateResourceTypes returns an ErrUnsupportedResourceType error naming every type that Gather would
+// reject, along with the supported types
+func ValidateResourceTypes(resourceTypes []string) error {
+	var unsupported []string
+	for _, resourceType := range resourceTypes {
+		if !slices.Contains(SupportedResourceTypes, resourceType) {
+			if _, ok := ParseGroupVersionResource(resourceType); !ok {
+				unsupported = append(unsupported, fmt.Sprintf("%q", resourceType))
+			}
+		}
+	}
+	if len(unsupported) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w %s; supported types: %s, or group/version/resource (e.g. apps/v1/controllerrevisions)",
+		ErrUnsupportedResourceType, strings.Join(unsupported, ", "), strings.Join(SupportedResourceTypes, ", "))
 }
 
-// GatherResources gathers specified Kubernetes resources
+// GatherOptions configures a gather operation
+type GatherOptions struct {
+	ResourceTypes []string
+	// Namespace to gather from; empty means the default namespace and AllNamespaces gathers cluster-wide
+	Namespace string
+	// Namespaces gathers from each listed namespace instead of Namespace. Resources are then keyed by
+	// namespace, each holding that namespace's resources as a single-namespace gather would
+	Namespaces []string
+	// LabelSelector applies to every resource type without an entry in LabelSelectors
+	LabelSelector string
+	// LabelSelectors overrides LabelSelector per resource type
+	LabelSelectors map[string]string
+	// FieldSelectors sets a field selector per resource type, such as spec.nodeName=node-1 for pods.
+	// Supported fields depend on the type and are checked by the API server. group/version/resource types
+	// ignore them
+	FieldSelectors map[string]string
+	// Minimize strips managedFields and other bookkeeping metadata from returned objects
+	Minimize bool
+	// NewerThan and OlderThan keep only objects whose creationTimestamp is at most or at least that long
+	// ago; zero doesn't filter. The API server can't select on age, so they apply after listing and
+	// don't reduce what is fetched
+	NewerThan time.Duration
+	OlderThan time.Duration
+}
+
+// selectorFor returns the label selector to use for a resource type
+func (o GatherOptions) selectorFor(resourceType string) string {
+	if selector, ok := o.LabelSelectors[resourceType]; ok {
+		return selector
+	}
+	return o.LabelSelector
+}
+
+// fieldSelectorFor returns the field selector to use for a resource type
+func (o GatherOptions) fieldSelectorFor(resourceType string) string {
+	return o.FieldSelectors[resourceType]
+}
+
+// GatherResources gathers specified Kubernetes resources. A namespace of AllNamespaces gathers
+// cluster-wide, capped at allNamespacesListLimit items per resource type.
 func (s *Service) GatherResources(ctx context.Context, resourceTypes []string, namespace, labelSelector string) (*GatherResourcesResponse, error) {
+	return s.Gather(ctx, GatherOptions{
+		ResourceTypes: resourceTypes,
+		Namespace:     namespace,
+		LabelSelector: labelSelector,
+	})
+}
+
+// Gather gathers Kubernetes resources as described by opts. It returns ErrClusterUnavailable on a nil
+// service so callers holding an optional *Service behind an interface don't need their own nil check
+func (s *Service) Gather(ctx context.Context, opts GatherOptions) (*GatherResourcesResponse, error) {
+	if s == nil {
+		return nil, ErrClusterUnavailable
+	}
+	if err := ValidateAgeFilter(opts.NewerThan, opts.OlderThan); err != nil {
+		return nil, err
+	}
+	return s.sharedGather(ctx, opts)
+}
+
+// gather does the work of Gather; concurrent identical calls share one through sharedGather
+func (s *Service) gather(ctx context.Context, opts GatherOptions) (*GatherResourcesResponse, error) {
+	if len(opts.Namespaces) > 0 {
+		return s.gatherNamespaces(ctx, opts)
+	}
+
 	resources := make(map[string]interface{})
+	resourceTypes := opts.ResourceTypes
+	namespace := opts.Namespace
 
-	// If no namespace specified, use "default"
+	// If no namespace specified, use the kubeconfig context's namespace
 	if namespace == "" {
-		namespace = "default"
+		namespace = s.DefaultNamespace()
 	}
 
-	s.logger.Info("Gathering resources",
+	// client-go lists across all namespaces when given the empty namespace
+	allNamespaces := namespace == AllNamespaces
+	listNamespace := namespace
+	if allNamespaces {
+		listNamespace = metav1.NamespaceAll
+	}
+
+	if !allNamespaces {
+		if err := s.checkNamespace(namespace); err != nil {
+			s.log(ctx).Warn("Refusing to gather from namespace", zap.String("namespace", namespace))
+			return nil, err
+		}
+	}
+
+	s.log(ctx).Info("Gathering resources",
 		zap.Strings("types", resourceTypes),
 		zap.String("namespace", namespace),
-		zap.String("labelSelector", labelSelector))
+		zap.String("labelSelector", opts.LabelSelector),
+		zap.Any("labelSelectors", opts.LabelSelectors),
+		zap.Any("fieldSelectors", opts.FieldSelectors))
+
+	// Types are listed concurrently; mu guards resources and truncated
+	var mu sync.Mutex
+	var truncated []string
+	store := func(key string, value interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		resources[key] = value
+	}
 
-	listOptions := metav1.ListOptions{}
-	if labelSelector != "" {
-		listOptions.LabelSelector = labelSelector
+	// recordList notes lists cut off at the limit, drops items in namespaces the policy excludes or
+	// outside the age filter and minimizes items when requested
+	now := time.Now()
+	recordList := func(resourceType string, list metav1.ListInterface) {
+		if object, ok := list.(runtime.Object); ok {
+			if allNamespaces {
+				s.filterNamespaced(object)
+			}
+			filterByAge(object, opts.NewerThan, opts.OlderThan, now)
+			s.redactor.list(object)
+			if opts.Minimize {
+				MinimizeList(object)
+			}
+		}
+		if list.GetContinue() != "" {
+			mu.Lock()
+			truncated = append(truncated, resourceType)
+			mu.Unlock()
+		}
 	}
 
-	for _, resourceType := range resourceTypes {
+	// gatherType lists a single resource type; failures are recorded under <type>_error so one
+	// type failing doesn't affect the others
+	gatherType := func(resourceType string) {
+		labelSelector := opts.selectorFor(resourceType)
+		listOptions := metav1.ListOptions{LabelSelector: labelSelector, FieldSelector: opts.fieldSelectorFor(resourceType)}
+		if allNamespaces {
+			listOptions.Limit = allNamespacesListLimit
+		}
+
 		switch resourceType {
 		case "pods":
-			pods, err := s.clientset.CoreV1().Pods(namespace).List(ctx, listOptions)
+			pods, err := s.clientsetFor(ctx).CoreV1().Pods(listNamespace).List(ctx, listOptions)
 			if err != nil {
-				s.logger.Error("Failed to list pods", zap.Error(err))
-				resources["pods_error"] = err.Error()
+				s.log(ctx).Error("Failed to list pods", zap.Error(err))
+				store("pods_error", err.Error())
 			} else {
-				resources["pods"] = pods
+				recordList("pods", pods)
+				store("pods", pods)
 			}
 
 		case "deployments":
-			deployments, err := s.clientset.AppsV1().Deployments(namespace).List(ctx, listOptions)
+			deployments, err := s.clientsetFor(ctx).AppsV1().Deployments(listNamespace).List(ctx, listOptions)
 			if err != nil {
-				s.logger.Error("Failed to list deployments", zap.Error(err))
-				resources["deployments_error"] = err.Error()
+				s.log(ctx).Error("Failed to list deployments", zap.Error(err))
+				store("deployments_error", err.Error())
 			} else {
-				resources["deployments"] = deployments
+				recordList("deployments", deployments)
+				store("deployments", deployments)
 			}
 
 		case "services":
-			services, err := s.clientset.CoreV1().Services(namespace).List(ctx, listOptions)
+			services, err := s.clientsetFor(ctx).CoreV1().Services(listNamespace).List(ctx, listOptions)
 			if err != nil {
-				s.logger.Error("Failed to list services", zap.Error(err))
-				resources["services_error"] = err.Error()
+				s.log(ctx).Error("Failed to list services", zap.Error(err))
+				store("services_error", err.Error())
 			} else {
-				resources["services"] = services
+				recordList("services", services)
+				store("services", services)
 			}
 
 		case "configmaps":
-			configMaps, err := s.clientset.CoreV1().ConfigMaps(namespace).List(ctx, listOptions)
+			configMaps, err := s.clientsetFor(ctx).CoreV1().ConfigMaps(listNamespace).List(ctx, listOptions)
 			if err != nil {
-				s.logger.Error("Failed to list configmaps", zap.Error(err))
-				resources["configmaps_error"] = err.Error()
+				s.log(ctx).Error("Failed to list configmaps", zap.Error(err))
+				store("configmaps_error", err.Error())
 			} else {
-				resources["configmaps"] = configMaps
+				recordList("configmaps", configMaps)
+				store("configmaps", configMaps)
 			}
 
 		case "secrets":
-			secrets, err := s.clientset.CoreV1().Secrets(namespace).List(ctx, listOptions)
+			secrets, err := s.clientsetFor(ctx).CoreV1().Secrets(listNamespace).List(ctx, listOptions)
 			if err != nil {
-				s.logger.Error("Failed to list secrets", zap.Error(err))
-				resources["secrets_error"] = err.Error()
+				s.log(ctx).Error("Failed to list secrets", zap.Error(err))
+				store("secrets_error", err.Error())
 			} else {
-				// Redact secret data for security
+				// Redact secret data for security; kubectl's last-applied annotation holds a copy of it
 				for i := range secrets.Items {
 					secrets.Items[i].Data = map[string][]byte{}
 					secrets.Items[i].StringData = map[string]string{}
+					removeLastApplied(&secrets.Items[i])
 				}
-				resources["secrets"] = secrets
+				recordList("secrets", secrets)
+				store("secrets", secrets)
 			}
 
 		case "events":
-			events, err := s.clientset.CoreV1().Events(namespace).List(ctx, listOptions)
+			events, err := s.clientsetFor(ctx).CoreV1().Events(listNamespace).List(ctx, listOptions)
 			if err != nil {
-				s.logger.Error("Failed to list events", zap.Error(err))
-				resources["events_error"] = err.Error()
+				s.log(ctx).Error("Failed to list events", zap.Error(err))
+				store("events_error", err.Error())
 			} else {
-				resources["events"] = events
+				recordList("events", events)
+				store("events", events)
 			}
 
 		case "replicasets":
-			replicaSets, err := s.clientset.AppsV1().ReplicaSets(namespace).List(ctx, listOptions)
+			replicaSets, err := s.clientsetFor(ctx).AppsV1().ReplicaSets(listNamespace).List(ctx, listOptions)
+			if err != nil {
+				s.log(ctx).Error("Failed to list replicasets", zap.Error(err))
+				store("replicasets_error", err.Error())
+			} else {
+				recordList("replicasets", replicaSets)
+				store("replicasets", replicaSets)
+			}
+
+		case "endpoints":
+			endpoints, err := s.clientsetFor(ctx).CoreV1().Endpoints(listNamespace).List(ctx, listOptions)
+			if err != nil {
+				s.log(ctx).Error("Failed to list endpoints", zap.Error(err))
+				store("endpoints_error", err.Error())
+			} else {
+				recordList("endpoints", endpoints)
+				store("endpoints", endpoints)
+			}
+
+		case "endpointslices":
+			endpointSlices, err := s.clientsetFor(ctx).DiscoveryV1().EndpointSlices(listNamespace).List(ctx, listOptions)
+			if err != nil {
+				s.log(ctx).Error("Failed to list endpointslices", zap.Error(err))
+				store("endpointslices_error", err.Error())
+			} else {
+				recordList("endpointslices", endpointSlices)
+				store("endpointslices", endpointSlices)
+			}
+
+		case "statefulsets":
+			statefulSets, err := s.clientsetFor(ctx).AppsV1().StatefulSets(listNamespace).List(ctx, listOptions)
+			if err != nil {
+				s.log(ctx).Error("Failed to list statefulsets", zap.Error(err))
+				store("statefulsets_error", err.Error())
+			} else {
+				recordList("statefulsets", statefulSets)
+				store("statefulsets", statefulSets)
+			}
+
+		case "daemonsets":
+			daemonSets, err := s.clientsetFor(ctx).AppsV1().DaemonSets(listNamespace).List(ctx, listOptions)
 			if err != nil {
-				s.logger.Error("Failed to list replicasets", zap.Error(err))
-				resources["replicasets_error"] = err.Error()
+				s.log(ctx).Error("Failed to list daemonsets", zap.Error(err))
+				store("daemonsets_error", err.Error())
 			} else {
-				resources["replicasets"] = replicaSets
+				recordList("daemonsets", daemonSets)
+				store("daemonsets", daemonSets)
+			}
+
+		case "networkpolicies":
+			networkPolicies, err := s.clientsetFor(ctx).NetworkingV1().NetworkPolicies(listNamespace).List(ctx, listOptions)
+			if err != nil {
+				s.log(ctx).Error("Failed to list networkpolicies", zap.Error(err))
+				store("networkpolicies_error", err.Error())
+			} else {
+				recordList("networkpolicies", networkPolicies)
+				store("networkpolicies", networkPolicies)
+			}
+
+		case "resourcequotas":
+			resourceQuotas, err := s.clientsetFor(ctx).CoreV1().ResourceQuotas(listNamespace).List(ctx, listOptions)
+			if err != nil {
+				s.log(ctx).Error("Failed to list resourcequotas", zap.Error(err))
+				store("resourcequotas_error", err.Error())
+			} else {
+				recordList("resourcequotas", resourceQuotas)
+				store("resourcequotas", resourceQuotas)
+			}
+
+		case "limitranges":
+			limitRanges, err := s.clientsetFor(ctx).CoreV1().LimitRanges(listNamespace).List(ctx, listOptions)
+			if err != nil {
+				s.log(ctx).Error("Failed to list limitranges", zap.Error(err))
+				store("limitranges_error", err.Error())
+			} else {
+				recordList("limitranges", limitRanges)
+				store("limitranges", limitRanges)
+			}
+
+		case "poddisruptionbudgets":
+			budgets, err := s.clientsetFor(ctx).PolicyV1().PodDisruptionBudgets(listNamespace).List(ctx, listOptions)
+			if err != nil {
+				s.log(ctx).Error("Failed to list poddisruptionbudgets", zap.Error(err))
+				store("poddisruptionbudgets_error", err.Error())
+			} else {
+				recordList("poddisruptionbudgets", budgets)
+				store("poddisruptionbudgets", budgets)
 			}
 
 		default:
-			s.logger.Warn("Unsupported resource type", zap.String("type", resourceType))
-			resources[resourceType+"_error"] = fmt.Sprintf("unsupported resource type: %s", resourceType)
+			// Arbitrary resources can be requested as group/version/resource
+			if gvr, ok := ParseGroupVersionResource(resourceType); ok {
+				list, err := s.ListCustomResources(ctx, gvr, listNamespace, labelSelector)
+				if err != nil {
+					store(resourceType+"_error", err.Error())
+				} else {
+					recordList(resourceType, list)
+					store(resourceType, list)
+				}
+				return
+			}
+
+			s.log(ctx).Warn("Unsupported resource type", zap.String("type", resourceType))
+			store(resourceType+"_error", fmt.Sprintf("unsupported resource type: %s", resourceType))
 		}
 	}
 
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentGathers)
+	for _, resourceType := range resourceTypes {
+		wg.Add(1)
+		go func(resourceType string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			gatherType(resourceType)
+		}(resourceType)
+	}
+	wg.Wait()
+	sort.Strings(truncated)
+
 	response := &GatherResourcesResponse{
 		Resources: resources,
 		Metadata: GatherMetadata{
 			Timestamp:      time.Now().UTC().Format(time.RFC3339),
 			ClusterContext: s.contextName,
 			Namespace:      namespace,
+			AllNamespaces:  allNamespaces,
+			Truncated:      truncated,
+			NewerThan:      durationString(opts.NewerThan),
+			OlderThan:      durationString(opts.OlderThan),
 		},
 	}
 
 	return response, nil
 }
 
-// GetPodLogs retrieves logs from a specific pod
-func (s *Service) GetPodLogs(ctx context.Context, namespace, podName, containerName string, lines int64) (string, error) {
+// Ping checks that the API server answers with a minimal namespaces list. A Forbidden response still
+// proves the cluster is reachable, e.g. for a namespace-scoped identity
+func (s *Service) Ping(ctx context.Context) error {
+	if s == nil {
+		return ErrClusterUnavailable
+	}
+	_, err := s.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{Limit: 1})
+	if err != nil && !apierrors.IsForbidden(err) {
+		return fmt.Errorf("%w: %w", ErrClusterUnavailable, err)
+	}
+	return nil
+}
+
+// ServerVersion returns the Kubernetes version reported by the API server
+func (s *Service) ServerVersion() (string, error) {
+	info, err := s.clientset.Discovery().ServerVersion()
+	if err != nil {
+		s.logger.Error("Failed to get server version", zap.Error(err))
+		return "", fmt.Errorf("failed to get server version: %w", classifyAPIError(err))
+	}
+	return info.GitVersion, nil
+}
+
+// IsPodHealthy reports whether a pod is Succeeded, or Running with all containers ready
+func IsPodHealthy(pod *v1.Pod) bool {
+	switch pod.Status.Phase {
+	case v1.PodSucceeded:
+		return true
+	case v1.PodRunning:
+		for _, status := range pod.Status.ContainerStatuses {
+			if !status.Ready {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// UnhealthyPods returns the names of pods in the list that are not healthy
+func UnhealthyPods(pods *v1.PodList) []string {
+	var unhealthy []string
+	for i := range pods.Items {
+		if !IsPodHealthy(&pods.Items[i]) {
+			unhealthy = append(unhealthy, pods.Items[i].Name)
+		}
+	}
+	return unhealthy
+}
+
+// EventTime returns when an event was last observed, falling back to its creation time
+func EventTime(event *v1.Event) metav1.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp
+	case !event.EventTime.IsZero():
+		return metav1.Time{Time: event.EventTime.Time}
+	}
+	return event.CreationTimestamp
+}
+
+// ListNamespaces lists all namespaces in the cluster
+func (s *Service) ListNamespaces(ctx context.Context) (*v1.NamespaceList, error) {
+	namespaces, err := s.clientsetFor(ctx).CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		s.log(ctx).Error("Failed to list namespaces", zap.Error(err))
+		return nil, fmt.Errorf("failed to list namespaces: %w", classifyAPIError(err))
+	}
+
+	permitted := namespaces.Items[:0]
+	for _, namespace := range namespaces.Items {
+		if s.namespacePolicy.Permits(namespace.Name) {
+			permitted = append(permitted, namespace)
+		}
+	}
+	namespaces.Items = permitted
+	return namespaces, nil
+}
+
+// ParseGroupVersionResource parses a "group/version/resource" string. The core group is written
+// with an empty group, e.g. "/v1/pods".
+func ParseGroupVersionResource(value string) (schema.GroupVersionResource, bool) {
+	parts := strings.Split(value, "/")
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return schema.GroupVersionResource{}, false
+	}
+	return schema.GroupVersionResource{Group: parts[0], Version: parts[1], Resource: parts[2]}, true
+}
+
+// ListCustomResources lists arbitrary resources, including custom resources, through the dynamic client.
+// An empty namespace lists across all namespaces or lists cluster-scoped resources.
+func (s *Service) ListCustomResources(ctx context.Context, gvr schema.GroupVersionResource, namespace, labelSelector string) (*unstructured.UnstructuredList, error) {
+	if err := s.checkNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	listOptions := metav1.ListOptions{LabelSelector: labelSelector}
+	if namespace == "" {
+		listOptions.Limit = allNamespacesListLimit
+	}
+
+	list, err := s.dynamicFor(ctx).Resource(gvr).Namespace(namespace).List(ctx, listOptions)
+	if err != nil {
+		s.log(ctx).Error("Failed to list custom resources", zap.Error(err), zap.String("gvr", gvr.String()))
+		return nil, fmt.Errorf("failed to list %s: %w", gvr.String(), classifyAPIError(err))
+	}
+	for i := range list.Items {
+		stripSecretValues(gvr, &list.Items[i])
+	}
+	s.redactor.list(list)
+	s.filterNamespaced(list)
+	return list, nil
+}
+
+// GetResource retrieves a single object of any resource type through the dynamic client
+func (s *Service) GetResource(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	if err := s.checkNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	object, err := s.dynamicFor(ctx).Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		s.log(ctx).Error("Failed to get resource", zap.Error(err), zap.String("gvr", gvr.String()), zap.String("name", name))
+		return nil, fmt.Errorf("failed to get %s %s/%s: %w", gvr.Resource, namespace, name, classifyAPIError(err))
+	}
+	stripSecretValues(gvr, object)
+	s.redactor.object(object)
+	return object, nil
+}
+
+// GetPod retrieves a single pod by name
+func (s *Service) GetPod(ctx context.Context, namespace, podName string) (*v1.Pod, error) {
+	if err := s.checkNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	pod, err := s.clientsetFor(ctx).CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		s.log(ctx).Error("Failed to get pod", zap.Error(err), zap.String("pod", podName))
+		return nil, fmt.Errorf("failed to get pod %s/%s: %w", namespace, podName, classifyAPIError(err))
+	}
+	s.redactor.object(pod)
+	return pod, nil
+}
+
+// GetNode retrieves a single node by name
+func (s *Service) GetNode(ctx context.Context, nodeName string) (*v1.Node, error) {
+	node, err := s.clientsetFor(ctx).CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		s.log(ctx).Error("Failed to get node", zap.Error(err), zap.String("node", nodeName))
+		return nil, fmt.Errorf("failed to get node %s: %w", nodeName, classifyAPIError(err))
+	}
+	return node, nil
+}
+
+// GetService retrieves a single service by name
+func (s *Service) GetService(ctx context.Context, namespace, serviceName string) (*v1.Service, error) {
+	if err := s.checkNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	service, err := s.clientsetFor(ctx).CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
+	if err != nil {
+		s.log(ctx).Error("Failed to get service", zap.Error(err), zap.String("service", serviceName))
+		return nil, fmt.Errorf("failed to get service %s/%s: %w", namespace, serviceName, classifyAPIError(err))
+	}
+	return service, nil
+}
+
+// GetServiceAccount retrieves a single service account by name
+func (s *Service) GetServiceAccount(ctx context.Context, namespace, name string) (*v1.ServiceAccount, error) {
+	if err := s.checkNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	serviceAccount, err := s.clientsetFor(ctx).CoreV1().ServiceAccounts(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		s.log(ctx).Error("Failed to get service account", zap.Error(err), zap.String("serviceAccount", name))
+		return nil, fmt.Errorf("failed to get service account %s/%s: %w", namespace, name, classifyAPIError(err))
+	}
+	return serviceAccount, nil
+}
+
+// GetConfigMap retrieves a single ConfigMap by name
+func (s *Service) GetConfigMap(ctx context.Context, namespace, name string) (*v1.ConfigMap, error) {
+	if err := s.checkNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	configMap, err := s.clientsetFor(ctx).CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		s.log(ctx).Error("Failed to get configmap", zap.Error(err), zap.String("configmap", name))
+		return nil, fmt.Errorf("failed to get configmap %s/%s: %w", namespace, name, classifyAPIError(err))
+	}
+	s.redactor.object(configMap)
+	return configMap, nil
+}
+
+// GetPodLogs retrieves the last lines of a pod's logs (DefaultLogTailLines when lines isn't positive),
+// keeping at most maxBytes of the most recent output (0 for no limit). The returned bool reports whether
+// the output was truncated to fit maxBytes or the read limit
+func (s *Service) GetPodLogs(ctx context.Context, namespace, podName, containerName string, lines, maxBytes int64) (string, bool, error) {
+	if err := s.checkNamespace(namespace); err != nil {
+		return "", false, err
+	}
+
+	if lines <= 0 {
+		lines = DefaultLogTailLines
+	}
 	options := &v1.PodLogOptions{
 		Container: containerName,
+		TailLines: &lines,
 	}
 
-	if lines > 0 {
-		options.TailLines = &lines
+	return s.streamPodLogs(ctx, namespace, podName, options, maxBytes)
+}
+
+// maxFollowedLogLine is the longest log line FollowPodLogs accepts
+const maxFollowedLogLine = 1024 * 1024
+
+// FollowPodLogs streams a container's logs as they are written, starting with its last tailLines lines
+// (0 for none), and calls onLine with each redacted line. It returns nil when ctx ends or the container
+// stops writing, e.g. because it exited
+func (s *Service) FollowPodLogs(ctx context.Context, namespace, podName, containerName string, tailLines int64, onLine func(line string)) error {
+	if err := s.checkNamespace(namespace); err != nil {
+		return err
 	}
 
-	request := s.clientset.CoreV1().Pods(namespace).GetLogs(podName, options)
+	options := &v1.PodLogOptions{
+		Container: containerName,
+		Follow:    true,
+		TailLines: &tailLines,
+	}
+	logs, err := s.clientsetFor(ctx).CoreV1().Pods(namespace).GetLogs(podName, options).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to follow pod logs: %w", classifyAPIError(err))
+	}
+	defer logs.Close()
+
+	scanner := bufio.NewScanner(logs)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxFollowedLogLine)
+	for scanner.Scan() {
+		onLine(s.redactor.text(scanner.Text()))
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to read pod logs: %w", err)
+	}
+	return nil
+}
+
+// streamPodLogs reads the logs selected by options, keeping at most maxBytes of the most recent output
+// (0 for no limit), and redacts them. Reading stops at the service's read limit whatever maxBytes is, and
+// the output then ends with a notice. The returned bool reports whether the output was truncated
+func (s *Service) streamPodLogs(ctx context.Context, namespace, podName string, options *v1.PodLogOptions, maxBytes int64) (string, bool, error) {
+	// The API server stops sending past the limit too; one byte more tells a log of exactly the limit apart
+	readLimit := s.logReadLimit()
+	limitBytes := readLimit + 1
+	limited := *options
+	limited.LimitBytes = &limitBytes
+
+	request := s.clientsetFor(ctx).CoreV1().Pods(namespace).GetLogs(podName, &limited)
 	logs, err := request.Stream(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to get pod logs: %w", err)
+		return "", false, fmt.Errorf("failed to get pod logs: %w", classifyAPIError(err))
 	}
 	defer logs.Close()
 
-	buf := make([]byte, 2048)
-	var result []byte
-	for {
-		n, err := logs.Read(buf)
-		if n > 0 {
-			result = append(result, buf[:n]...)
-		}
-		if err != nil {
-			break
-		}
+	result, trimmed, stopped := readLogStream(logs, maxBytes, readLimit)
+	if stopped {
+		s.log(ctx).Warn("Stopped reading pod logs at the read limit",
+			zap.String("pod", podName),
+			zap.String("container", options.Container),
+			zap.Int64("limit", readLimit))
 	}
 
-	return string(result), nil
+	output := s.redactor.text(string(result))
+	if stopped {
+		output += logReadLimitNotice(readLimit)
+	}
+	return output, trimmed || stopped, nil
 }
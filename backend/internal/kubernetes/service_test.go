@@ -0,0 +1,204 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newFakeService returns a service and a context whose requests go to a fake clientset holding objects
+func newFakeService(objects ...runtime.Object) (*Service, *fake.Clientset, context.Context) {
+	clientset := fake.NewSimpleClientset(objects...)
+	ctx := context.WithValue(context.Background(), impersonatedClientsKey{}, &impersonatedClients{
+		clientset: clientset,
+		config:    &rest.Config{},
+	})
+	return &Service{logger: zap.NewNop(), defaultNamespace: "shop"}, clientset, ctx
+}
+
+func TestGatherConcurrentMatchesSequential(t *testing.T) {
+	service, clientset, ctx := newFakeService(
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "shop"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-2", Namespace: "shop"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "elsewhere"}},
+		&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "shop"}},
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "api-config", Namespace: "shop"}},
+		&v1.Event{ObjectMeta: metav1.ObjectMeta{Name: "api-1.1", Namespace: "shop"}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "api-5d8f", Namespace: "shop"}},
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "shop"}},
+		&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "shop"}},
+	)
+	// One failing type must not affect the others
+	clientset.PrependReactor("list", "deployments", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("deployments unavailable")
+	})
+
+	types := []string{
+		"pods", "deployments", "services", "configmaps", "events", "replicasets",
+		"statefulsets", "daemonsets", "endpoints", "widgets",
+	}
+
+	concurrent, err := service.Gather(ctx, GatherOptions{ResourceTypes: types})
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+
+	// Gathering one type at a time is the sequential baseline
+	sequential := make(map[string]interface{})
+	for _, resourceType := range types {
+		response, err := service.Gather(ctx, GatherOptions{ResourceTypes: []string{resourceType}})
+		if err != nil {
+			t.Fatalf("Gather %s: %v", resourceType, err)
+		}
+		for key, value := range response.Resources {
+			sequential[key] = value
+		}
+	}
+
+	if !reflect.DeepEqual(concurrent.Resources, sequential) {
+		t.Errorf("concurrent gather differs from sequential gather:\nconcurrent: %v\nsequential: %v", concurrent.Resources, sequential)
+	}
+
+	if pods, ok := concurrent.Resources["pods"].(*v1.PodList); !ok || len(pods.Items) != 2 {
+		t.Errorf("pods = %v, want the 2 pods in namespace shop", concurrent.Resources["pods"])
+	}
+	if _, ok := concurrent.Resources["deployments_error"]; !ok {
+		t.Error("deployments_error missing for the failing type")
+	}
+	if _, ok := concurrent.Resources["widgets_error"]; !ok {
+		t.Error("widgets_error missing for the unsupported type")
+	}
+	if got := len(concurrent.Resources); got != len(types) {
+		t.Errorf("got %d resource keys, want one per type (%d): %v", got, len(types), concurrent.Resources)
+	}
+}
+
+// newTestAPIServer returns a service whose requests go to an httptest API server. The server answers the
+// namespace list NewService pings with and hands every other request to handler
+func newTestAPIServer(t *testing.T, handler http.HandlerFunc) *Service {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/namespaces" {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(&v1.NamespaceList{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "NamespaceList"}})
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+    namespace: shop
+users:
+- name: test
+  user:
+    token: test
+current-context: test
+`, server.URL)
+	service, err := NewService("", "test", zap.NewNop(), WithKubeconfigContent([]byte(kubeconfig)))
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	return service
+}
+
+func TestGatherResourcesStopsWhenContextEnds(t *testing.T) {
+	tests := []struct {
+		name    string
+		context func(context.Context) (context.Context, context.CancelFunc)
+		want    error
+	}{
+		{
+			name: "deadline",
+			context: func(ctx context.Context) (context.Context, context.CancelFunc) {
+				return context.WithTimeout(ctx, 100*time.Millisecond)
+			},
+			want: context.DeadlineExceeded,
+		},
+		{
+			name: "cancel",
+			context: func(ctx context.Context) (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(ctx)
+				time.AfterFunc(100*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			want: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The pod list hangs like an unresponsive API server until the request itself goes away, which
+			// only happens when the caller's context reaches the HTTP request
+			listed := make(chan struct{})
+			abandoned := make(chan struct{})
+			service := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/namespaces/shop/pods" {
+					http.NotFound(w, r)
+					return
+				}
+				close(listed)
+				select {
+				case <-r.Context().Done():
+					close(abandoned)
+				case <-time.After(10 * time.Second):
+				}
+			})
+			ctx, cancel := tt.context(context.Background())
+			defer cancel()
+
+			start := time.Now()
+			response, err := service.GatherResources(ctx, []string{"pods"}, "shop", "")
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Fatalf("GatherResources returned after %v, want it to stop when the context ends", elapsed)
+			}
+			select {
+			case <-listed:
+			case <-time.After(time.Second):
+				t.Fatal("pods were never listed")
+			}
+			select {
+			case <-abandoned:
+			case <-time.After(5 * time.Second):
+				t.Fatal("the API server never saw the list request canceled")
+			}
+
+			// Depending on which notices first, the gather fails or records the list failure
+			if err != nil {
+				if !errors.Is(err, tt.want) {
+					t.Errorf("err = %v, want %v", err, tt.want)
+				}
+				return
+			}
+			if got, _ := response.Resources["pods_error"].(string); !strings.Contains(got, tt.want.Error()) {
+				t.Errorf("pods_error = %q, want it to report %q", got, tt.want.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// gatherKey identifies gathers that return the same result, so they can share one set of list calls
+type gatherKey struct {
+	ResourceTypes  []string          `json:"resourceTypes"`
+	Namespace      string            `json:"namespace"`
+	Namespaces     []string          `json:"namespaces,omitempty"`
+	LabelSelector  string            `json:"labelSelector"`
+	LabelSelectors map[string]string `json:"labelSelectors,omitempty"`
+	FieldSelectors map[string]string `json:"fieldSelectors,omitempty"`
+	Minimize       bool              `json:"minimize"`
+	NewerThan      int64             `json:"newerThan"`
+	OlderThan      int64             `json:"olderThan"`
+	// User and Groups are the identity chosen for this request, so one caller never sees results read
+	// with another's permissions
+	User   string   `json:"user,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+// sharedGatherKey normalizes opts and the request's identity into a singleflight key
+func (s *Service) sharedGatherKey(ctx context.Context, opts GatherOptions) string {
+	key := gatherKey{
+		ResourceTypes:  append([]string(nil), opts.ResourceTypes...),
+		Namespace:      opts.Namespace,
+		Namespaces:     opts.Namespaces,
+		LabelSelector:  opts.LabelSelector,
+		LabelSelectors: opts.LabelSelectors,
+		FieldSelectors: opts.FieldSelectors,
+		Minimize:       opts.Minimize,
+		NewerThan:      int64(opts.NewerThan),
+		OlderThan:      int64(opts.OlderThan),
+	}
+	// Types are gathered into a map, so their order doesn't change the result
+	sort.Strings(key.ResourceTypes)
+	if key.Namespace == "" && len(key.Namespaces) == 0 {
+		key.Namespace = s.DefaultNamespace()
+	}
+	if clients, ok := ctx.Value(impersonatedClientsKey{}).(*impersonatedClients); ok {
+		key.User = clients.config.Impersonate.UserName
+		key.Groups = clients.config.Impersonate.Groups
+	}
+
+	// Maps marshal with sorted keys, so equal options always produce equal keys
+	data, _ := json.Marshal(key)
+	return string(data)
+}
+
+// gatherFlight is a shared gather in flight and the number of callers waiting on it
+type gatherFlight struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waiters int
+	// expired records that ctx ended before the gather returned, so its errors may only reflect that
+	expired bool
+}
+
+// sharedGather runs gather for opts, or waits for an identical gather already in flight and shares its
+// result. The shared gather keeps the values and deadline of the caller that started it but not its
+// cancellation, so one caller going away doesn't fail the others; each caller still stops waiting when its
+// own context ends, and the last caller to stop waiting cancels the gather
+func (s *Service) sharedGather(ctx context.Context, opts GatherOptions) (*GatherResourcesResponse, error) {
+	key := s.sharedGatherKey(ctx, opts)
+
+	// Joining happens under flightsMu, and a finished or abandoned flight is forgotten under it, so every
+	// caller of a singleflight call is counted on that call's gatherFlight
+	s.flightsMu.Lock()
+	current, ok := s.flights[key]
+	if !ok {
+		current = &gatherFlight{}
+		if deadline, hasDeadline := ctx.Deadline(); hasDeadline {
+			current.ctx, current.cancel = context.WithDeadline(context.WithoutCancel(ctx), deadline)
+		} else {
+			current.ctx, current.cancel = context.WithCancel(context.WithoutCancel(ctx))
+		}
+		if s.flights == nil {
+			s.flights = make(map[string]*gatherFlight)
+		}
+		s.flights[key] = current
+	}
+	current.waiters++
+	flight := s.gathers.DoChan(key, func() (interface{}, error) {
+		defer s.endFlight(key, current)
+		response, err := s.gather(current.ctx, opts)
+		current.expired = current.ctx.Err() != nil
+		return response, err
+	})
+	s.flightsMu.Unlock()
+
+	var result singleflight.Result
+	select {
+	case <-ctx.Done():
+		s.leaveFlight(key, current)
+		return nil, ctx.Err()
+	case result = <-flight:
+	}
+	response, _ := result.Val.(*GatherResourcesResponse)
+	if !result.Shared {
+		return response, result.Err
+	}
+
+	// The shared gather ran out of its starter's time, but this caller still has some of its own. Running out
+	// fails the gather or, more often, only the types still being listed, which record it as their error
+	if ctx.Err() == nil && (isContextError(result.Err) || (result.Err == nil && current.expired && hasGatherErrors(response.Resources))) {
+		s.log(ctx).Debug("Shared gather hit its starter's deadline, gathering again", zap.Error(result.Err))
+		return s.gather(ctx, opts)
+	}
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	s.log(ctx).Debug("Shared an in-flight gather",
+		zap.Strings("types", opts.ResourceTypes),
+		zap.String("namespace", opts.Namespace))
+
+	// Every caller sharing the gather, including the one that started it, gets its own deep copy: callers
+	// such as the manifest export fill in list items' kinds, which would race on shared objects
+	shared := *response
+	shared.Resources = copyResources(response.Resources)
+	shared.Metadata.Namespaces = append([]string(nil), response.Metadata.Namespaces...)
+	shared.Metadata.Truncated = append([]string(nil), response.Metadata.Truncated...)
+	return &shared, nil
+}
+
+// leaveFlight records that a caller stopped waiting on flight, canceling and forgetting it when no caller is left
+func (s *Service) leaveFlight(key string, flight *gatherFlight) {
+	s.flightsMu.Lock()
+	defer s.flightsMu.Unlock()
+	flight.waiters--
+	if flight.waiters == 0 {
+		s.forgetFlight(key, flight)
+	}
+}
+
+// endFlight forgets flight once its gather returned
+func (s *Service) endFlight(key string, flight *gatherFlight) {
+	s.flightsMu.Lock()
+	defer s.flightsMu.Unlock()
+	s.forgetFlight(key, flight)
+}
+
+// forgetFlight cancels flight and, if it is still the flight for key, removes it so the next caller starts a
+// new gather. The caller holds flightsMu
+func (s *Service) forgetFlight(key string, flight *gatherFlight) {
+	flight.cancel()
+	if s.flights[key] == flight {
+		delete(s.flights, key)
+		s.gathers.Forget(key)
+	}
+}
+
+// copyResources deep-copies gathered resources: lists, error strings and the per-namespace maps of a
+// multi-namespace gather
+func copyResources(resources map[string]interface{}) map[string]interface{} {
+	if resources == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(resources))
+	for key, value := range resources {
+		switch v := value.(type) {
+		case runtime.Object:
+			copied[key] = v.DeepCopyObject()
+		case map[string]interface{}:
+			copied[key] = copyResources(v)
+		default:
+			copied[key] = v
+		}
+	}
+	return copied
+}
+
+// hasGatherErrors reports whether any resource type, in any namespace, failed to gather
+func hasGatherErrors(resources map[string]interface{}) bool {
+	for key, value := range resources {
+		if strings.HasSuffix(key, "_error") {
+			return true
+		}
+		if namespaced, ok := value.(map[string]interface{}); ok && hasGatherErrors(namespaced) {
+			return true
+		}
+	}
+	return false
+}
+
+// isContextError reports whether err comes from a canceled or expired context
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,204 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCopyResources(t *testing.T) {
+	pods := &v1.PodList{Items: []v1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "shop"}}}}
+	original := map[string]interface{}{
+		"shop":    map[string]interface{}{"pods": pods, "events_error": "events are forbidden"},
+		"billing": map[string]interface{}{},
+	}
+
+	copied := copyResources(original)
+	shop := copied["shop"].(map[string]interface{})
+	copiedPods := shop["pods"].(*v1.PodList)
+	if copiedPods == pods {
+		t.Fatal("the pod list is shared with the original")
+	}
+	if shop["events_error"] != "events are forbidden" {
+		t.Errorf("events_error = %v, want the original error", shop["events_error"])
+	}
+
+	// Adjusting the copy leaves the original untouched
+	setKind(&copiedPods.Items[0])
+	shop["services"] = &v1.ServiceList{}
+	if kind := pods.Items[0].Kind; kind != "" {
+		t.Errorf("original pod kind = %q after setting the copy's kind", kind)
+	}
+	if _, ok := original["shop"].(map[string]interface{})["services"]; ok {
+		t.Error("adding to the copy's namespace map changed the original")
+	}
+}
+
+func TestSharedGatherCopiesPerCaller(t *testing.T) {
+	service, clientset, ctx := newFakeService(
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "shop"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "billing"}},
+	)
+	// The first list waits until the second caller has had time to join the gather in flight
+	listed := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	clientset.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		once.Do(func() {
+			close(listed)
+			<-release
+		})
+		return false, nil, nil
+	})
+
+	opts := GatherOptions{ResourceTypes: []string{"pods"}, Namespaces: []string{"shop", "billing"}}
+	responses := make([]*GatherResourcesResponse, 2)
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	gather := func(i int) {
+		defer wg.Done()
+		responses[i], errs[i] = service.Gather(ctx, opts)
+	}
+	wg.Add(2)
+	go gather(0)
+	<-listed
+	go gather(1)
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Gather %d: %v", i, err)
+		}
+	}
+	for _, namespace := range opts.Namespaces {
+		first := responses[0].Resources[namespace].(map[string]interface{})["pods"].(*v1.PodList)
+		second := responses[1].Resources[namespace].(map[string]interface{})["pods"].(*v1.PodList)
+		if first == second {
+			t.Errorf("callers share the %s pod list", namespace)
+		}
+	}
+
+	// Both callers fill in kinds at once, as concurrent manifest exports do; run with -race to catch sharing
+	wg.Add(2)
+	for _, response := range responses {
+		go func(response *GatherResourcesResponse) {
+			defer wg.Done()
+			for _, namespace := range opts.Namespaces {
+				pods := response.Resources[namespace].(map[string]interface{})["pods"].(*v1.PodList)
+				for i := range pods.Items {
+					setKind(&pods.Items[i])
+				}
+			}
+		}(response)
+	}
+	wg.Wait()
+}
+
+func TestSharedGatherCanceledWhenEveryCallerLeaves(t *testing.T) {
+	listed := make(chan struct{}, 2)
+	abandoned := make(chan struct{}, 2)
+	service := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		listed <- struct{}{}
+		select {
+		case <-r.Context().Done():
+			abandoned <- struct{}{}
+		case <-time.After(10 * time.Second):
+		}
+	})
+
+	gather := func(ctx context.Context, errs chan<- error) {
+		_, err := service.GatherResources(ctx, []string{"pods"}, "shop", "")
+		errs <- err
+	}
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	defer cancelFirst()
+	secondCtx, cancelSecond := context.WithCancel(context.Background())
+	defer cancelSecond()
+	firstErr, secondErr := make(chan error, 1), make(chan error, 1)
+
+	go gather(firstCtx, firstErr)
+	<-listed
+	go gather(secondCtx, secondErr)
+	time.Sleep(50 * time.Millisecond)
+
+	// The starter leaving doesn't cancel the list the other caller still waits on
+	cancelFirst()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("first caller err = %v, want context.Canceled", err)
+	}
+	select {
+	case <-abandoned:
+		t.Fatal("the list was canceled while a caller still waited on it")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// The last caller leaving does
+	cancelSecond()
+	if err := <-secondErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("second caller err = %v, want context.Canceled", err)
+	}
+	select {
+	case <-abandoned:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the list kept running after every caller left")
+	}
+	if len(listed) != 0 {
+		t.Errorf("pods listed %d more times, want the callers to share one list", len(listed))
+	}
+}
+
+func TestSharedGatherRetriesStartersTimeouts(t *testing.T) {
+	listed := make(chan struct{}, 1)
+	var mu sync.Mutex
+	requests := 0
+	service := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		first := requests == 1
+		mu.Unlock()
+		if first {
+			// The first list outlasts the starter's deadline
+			listed <- struct{}{}
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&v1.PodList{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "PodList"},
+			Items:    []v1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "shop"}}},
+		})
+	})
+
+	starterCtx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	starterDone := make(chan struct{})
+	go func() {
+		defer close(starterDone)
+		service.GatherResources(starterCtx, []string{"pods"}, "shop", "")
+	}()
+	<-listed
+
+	// The joiner has no deadline of its own, so the starter's timeout must not become its result
+	response, err := service.GatherResources(context.Background(), []string{"pods"}, "shop", "")
+	<-starterDone
+	if err != nil {
+		t.Fatalf("joiner err = %v, want its own gather to succeed", err)
+	}
+	if got, ok := response.Resources["pods_error"]; ok {
+		t.Errorf("joiner got the starter's pods_error %q", got)
+	}
+	if pods, ok := response.Resources["pods"].(*v1.PodList); !ok || len(pods.Items) != 1 {
+		t.Errorf("pods = %v, want the pod listed again", response.Resources["pods"])
+	}
+}
@@ -0,0 +1,42 @@
+package mcp
+
+import "context"
+
+// adminOnlyTools are the tools WithAdminOnlyTools reserves for admin requests. exec_in_pod can read any
+// file its allowed programs can, such as mounted secrets and service account tokens
+var adminOnlyTools = map[string]bool{
+	"exec_in_pod": true,
+}
+
+// IsAdminOnly reports whether name is a tool WithAdminOnlyTools reserves for admin requests
+func IsAdminOnly(name string) bool {
+	return adminOnlyTools[name]
+}
+
+// WithAdminOnlyTools reserves the admin-only tools for requests whose context carries WithAdmin: other
+// requests don't see them in ToolsJSON, and ExecuteTool refuses them. The API server enables it, as anyone
+// who can reach it can ask a query; the CLI runs with its user's own credentials and doesn't
+func WithAdminOnlyTools(enabled bool) Option {
+	return func(m *MCPService) {
+		m.restrictAdminTools = enabled
+	}
+}
+
+// adminKey marks a request context as coming from an admin
+type adminKey struct{}
+
+// WithAdmin returns a context whose requests may see and run the admin-only tools
+func WithAdmin(ctx context.Context) context.Context {
+	return context.WithValue(ctx, adminKey{}, true)
+}
+
+// isAdmin reports whether ctx was marked with WithAdmin
+func isAdmin(ctx context.Context) bool {
+	admin, _ := ctx.Value(adminKey{}).(bool)
+	return admin
+}
+
+// toolAllowed reports whether the request with ctx may see and run the tool name
+func (m *MCPService) toolAllowed(ctx context.Context, name string) bool {
+	return !m.restrictAdminTools || !adminOnlyTools[name] || isAdmin(ctx)
+}
@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"kube-sherlock/internal/kubernetes"
+)
+
+func TestAdminOnlyTools(t *testing.T) {
+	exec := ToolRequest{Name: "exec_in_pod", Arguments: map[string]interface{}{"podName": "api-1", "command": "cat /etc/hostname"}}
+	tests := []struct {
+		name     string
+		restrict bool
+		admin    bool
+		wantTool bool
+	}{
+		{name: "restricted, not admin", restrict: true},
+		{name: "restricted, admin", restrict: true, admin: true, wantTool: true},
+		{name: "unrestricted", wantTool: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMCPService(nil, zap.NewNop(), WithExec(true, nil), WithAdminOnlyTools(tt.restrict))
+			ctx := context.Background()
+			if tt.admin {
+				ctx = WithAdmin(ctx)
+			}
+
+			if listed := strings.Contains(m.ToolsJSON(ctx), `"exec_in_pod"`); listed != tt.wantTool {
+				t.Errorf("exec_in_pod in the prompt's tools = %v, want %v", listed, tt.wantTool)
+			}
+			if !strings.Contains(m.ToolsJSON(ctx), `"get_pod_health"`) {
+				t.Error("other tools missing from the prompt's tools")
+			}
+
+			// Only the refusal differs: allowed, the tool gets as far as the missing cluster
+			_, err := m.ExecuteTool(ctx, exec)
+			if refused := errors.Is(err, ErrAdminRequired); refused == tt.wantTool {
+				t.Errorf("ExecuteTool err = %v, refused = %v, want refused = %v", err, refused, !tt.wantTool)
+			}
+			if tt.wantTool && !errors.Is(err, kubernetes.ErrClusterUnavailable) {
+				t.Errorf("ExecuteTool err = %v, want %v", err, kubernetes.ErrClusterUnavailable)
+			}
+
+			results := m.ExecuteTools(ctx, []ToolRequest{exec, {Name: "get_namespaces"}})
+			if refused := errors.Is(results[0].Err, ErrAdminRequired); refused == tt.wantTool {
+				t.Errorf("batched exec_in_pod err = %v, want refused = %v", results[0].Err, !tt.wantTool)
+			}
+			if errors.Is(results[1].Err, ErrAdminRequired) {
+				t.Errorf("get_namespaces refused as admin-only: %v", results[1].Err)
+			}
+		})
+	}
+}
+
+func TestDefaultExecCommandsAreReadOnly(t *testing.T) {
+	// These can change state or start other programs, so only an explicit allow-list may include them
+	for _, program := range []string{"date", "hostname", "env", "sh", "find", "xargs"} {
+		for _, allowed := range DefaultExecCommands {
+			if allowed == program {
+				t.Errorf("DefaultExecCommands includes %s", program)
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package mcp
+
+import (
+	"fmt"
+	"time"
+
+	"kube-sherlock/internal/kubernetes"
+)
+
+// getAgeFilter reads the newerThan and olderThan duration arguments of the pod and event tools. They
+// filter on creationTimestamp after listing, since the API server can't select on age
+func getAgeFilter(args map[string]interface{}) (time.Duration, time.Duration, error) {
+	newerThan, olderThan, err := kubernetes.ParseAgeFilter(getStringParam(args, "newerThan", ""), getStringParam(args, "olderThan", ""))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %w", ErrInvalidArguments, err)
+	}
+	return newerThan, olderThan, nil
+}
+
+// ageFilterText describes an age filter for a tool's output header, or returns an empty string without one
+func ageFilterText(newerThan, olderThan time.Duration) string {
+	switch {
+	case newerThan > 0 && olderThan > 0:
+		return fmt.Sprintf(" created between %s and %s ago", olderThan, newerThan)
+	case newerThan > 0:
+		return fmt.Sprintf(" created in the last %s", newerThan)
+	case olderThan > 0:
+		return fmt.Sprintf(" created more than %s ago", olderThan)
+	}
+	return ""
+}
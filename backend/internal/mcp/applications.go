@@ -0,0 +1,332 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"kube-sherlock/internal/kubernetes"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// maxEventsPerObject caps the events attached to each object in an application overview
+const maxEventsPerObject = 5
+
+// eventSummary is a compact view of an event attached to the object it concerns
+type eventSummary struct {
+	Type     string `json:"type"`
+	Reason   string `json:"reason"`
+	Message  string `json:"message"`
+	Count    int32  `json:"count,omitempty"`
+	LastSeen string `json:"lastSeen,omitempty"`
+}
+
+// applicationPod summarizes a pod in an application overview
+type applicationPod struct {
+	Name     string         `json:"name"`
+	Phase    string         `json:"phase"`
+	Ready    string         `json:"ready"`
+	Restarts int32          `json:"restarts"`
+	Node     string         `json:"node,omitempty"`
+	Reason   string         `json:"reason,omitempty"`
+	Owner    string         `json:"owner,omitempty"`
+	Events   []eventSummary `json:"events,omitempty"`
+}
+
+// applicationReplicaSet summarizes a ReplicaSet and the pods it owns
+type applicationReplicaSet struct {
+	Name     string           `json:"name"`
+	Revision string           `json:"revision,omitempty"`
+	Desired  int32            `json:"desired"`
+	Ready    int32            `json:"ready"`
+	Events   []eventSummary   `json:"events,omitempty"`
+	Pods     []applicationPod `json:"pods"`
+}
+
+// applicationDeployment summarizes a Deployment and the ReplicaSets it owns
+type applicationDeployment struct {
+	Name           string                  `json:"name"`
+	RolloutStatus  string                  `json:"rolloutStatus,omitempty"`
+	RolloutMessage string                  `json:"rolloutMessage,omitempty"`
+	Desired        int32                   `json:"desired"`
+	Ready          int32                   `json:"ready"`
+	Available      int32                   `json:"available"`
+	Note           string                  `json:"note,omitempty"`
+	Events         []eventSummary          `json:"events,omitempty"`
+	ReplicaSets    []applicationReplicaSet `json:"replicaSets"`
+	// InactiveReplicaSets counts old revisions scaled to zero with no pods
+	InactiveReplicaSets int `json:"inactiveReplicaSets,omitempty"`
+}
+
+// applicationOverview correlates the workloads, pods and events selected by a label selector
+type applicationOverview struct {
+	Namespace          string                  `json:"namespace"`
+	LabelSelector      string                  `json:"labelSelector"`
+	Deployments        []applicationDeployment `json:"deployments"`
+	UnownedReplicaSets []applicationReplicaSet `json:"unownedReplicaSets,omitempty"`
+	OtherPods          []applicationPod        `json:"otherPods,omitempty"`
+}
+
+// getApplicationOverview links the Deployments, ReplicaSets, pods and events matching a label selector
+// through their ownerReferences
+func (m *MCPService) getApplicationOverview(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+	labelSelector := getStringParam(args, "labelSelector", "")
+
+	if labelSelector == "" {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Label selector is required for an application overview (for example app=my-app)",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: label selector is required", ErrInvalidArguments)
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	// Not minimized: objects are correlated by UID, which minimizing strips, and only summaries are returned
+	resourceTypes := []string{"deployments", "replicasets", "pods"}
+	resources, err := m.k8sService.Gather(ctx, kubernetes.GatherOptions{
+		ResourceTypes: resourceTypes,
+		Namespace:     namespace,
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error gathering application resources: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+
+	// Events carry no application labels, so they are matched to the objects by UID
+	events, err := m.k8sService.Gather(ctx, kubernetes.GatherOptions{
+		ResourceTypes: []string{"events"},
+		Namespace:     namespace,
+	})
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error gathering events: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+
+	deployments, _ := resources.Resources["deployments"].(*appsv1.DeploymentList)
+	replicaSets, _ := resources.Resources["replicasets"].(*appsv1.ReplicaSetList)
+	pods, _ := resources.Resources["pods"].(*v1.PodList)
+	eventList, _ := events.Resources["events"].(*v1.EventList)
+
+	overview := buildApplicationOverview(deployments, replicaSets, pods, eventList)
+	overview.Namespace = namespace
+	overview.LabelSelector = labelSelector
+
+	var gatherErrors []string
+	for _, resourceType := range resourceTypes {
+		if msg, ok := resources.Resources[resourceType+"_error"].(string); ok {
+			gatherErrors = append(gatherErrors, fmt.Sprintf("%s: %s", resourceType, msg))
+		}
+	}
+	if msg, ok := events.Resources["events_error"].(string); ok {
+		gatherErrors = append(gatherErrors, fmt.Sprintf("events: %s", msg))
+	}
+
+	overviewData, _ := json.MarshalIndent(overview, "", "  ")
+	text := fmt.Sprintf("Application overview for '%s' in namespace '%s':\n\n%s", labelSelector, namespace, string(overviewData))
+	if len(overview.Deployments) == 0 && len(overview.UnownedReplicaSets) == 0 && len(overview.OtherPods) == 0 {
+		text = fmt.Sprintf("No deployments, replicasets or pods match '%s' in namespace '%s'", labelSelector, namespace)
+	}
+	if len(gatherErrors) > 0 {
+		text += fmt.Sprintf("\n\nErrors:\n%s", strings.Join(gatherErrors, "\n"))
+	}
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: text,
+		}},
+	}, nil
+}
+
+// buildApplicationOverview nests pods under their ReplicaSets and ReplicaSets under their Deployments.
+// Deployments that own a matching ReplicaSet but don't match the selector themselves are still listed
+func buildApplicationOverview(deployments *appsv1.DeploymentList, replicaSets *appsv1.ReplicaSetList, pods *v1.PodList, events *v1.EventList) applicationOverview {
+	eventsByUID := indexEvents(events)
+	overview := applicationOverview{Deployments: []applicationDeployment{}}
+
+	podsByOwner := make(map[types.UID][]applicationPod)
+	if pods != nil {
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			summary := summarizeApplicationPod(pod, eventsByUID[pod.UID])
+			owner := metav1.GetControllerOf(pod)
+			if owner != nil && owner.Kind == "ReplicaSet" {
+				podsByOwner[owner.UID] = append(podsByOwner[owner.UID], summary)
+				continue
+			}
+			if owner != nil {
+				summary.Owner = owner.Kind + "/" + owner.Name
+			}
+			overview.OtherPods = append(overview.OtherPods, summary)
+		}
+	}
+
+	deploymentIndex := make(map[types.UID]int)
+	if deployments != nil {
+		for i := range deployments.Items {
+			deployment := &deployments.Items[i]
+			status := deploymentStatus(deployment)
+			deploymentIndex[deployment.UID] = len(overview.Deployments)
+			overview.Deployments = append(overview.Deployments, applicationDeployment{
+				Name:           deployment.Name,
+				RolloutStatus:  status.RolloutStatus,
+				RolloutMessage: status.RolloutMessage,
+				Desired:        status.Desired,
+				Ready:          status.Ready,
+				Available:      status.Available,
+				Events:         eventsByUID[deployment.UID],
+				ReplicaSets:    []applicationReplicaSet{},
+			})
+		}
+	}
+
+	if replicaSets != nil {
+		for i := range replicaSets.Items {
+			rs := &replicaSets.Items[i]
+			desired := int32(1)
+			if rs.Spec.Replicas != nil {
+				desired = *rs.Spec.Replicas
+			}
+			rsPods := podsByOwner[rs.UID]
+			delete(podsByOwner, rs.UID)
+			summary := applicationReplicaSet{
+				Name:     rs.Name,
+				Revision: rs.Annotations["deployment.kubernetes.io/revision"],
+				Desired:  desired,
+				Ready:    rs.Status.ReadyReplicas,
+				Events:   eventsByUID[rs.UID],
+				Pods:     rsPods,
+			}
+			if summary.Pods == nil {
+				summary.Pods = []applicationPod{}
+			}
+
+			owner := metav1.GetControllerOf(rs)
+			if owner == nil || owner.Kind != "Deployment" {
+				overview.UnownedReplicaSets = append(overview.UnownedReplicaSets, summary)
+				continue
+			}
+			idx, ok := deploymentIndex[owner.UID]
+			if !ok {
+				idx = len(overview.Deployments)
+				deploymentIndex[owner.UID] = idx
+				overview.Deployments = append(overview.Deployments, applicationDeployment{
+					Name:        owner.Name,
+					Note:        "deployment labels don't match the selector; listed because it owns a matching replicaset",
+					ReplicaSets: []applicationReplicaSet{},
+				})
+			}
+			if desired == 0 && len(rsPods) == 0 && len(summary.Events) == 0 {
+				overview.Deployments[idx].InactiveReplicaSets++
+				continue
+			}
+			overview.Deployments[idx].ReplicaSets = append(overview.Deployments[idx].ReplicaSets, summary)
+		}
+	}
+
+	// Pods whose ReplicaSet didn't match the selector
+	for _, orphaned := range podsByOwner {
+		for _, pod := range orphaned {
+			pod.Owner = "ReplicaSet (not matched by the selector)"
+			overview.OtherPods = append(overview.OtherPods, pod)
+		}
+	}
+	sort.Slice(overview.OtherPods, func(i, j int) bool {
+		return overview.OtherPods[i].Name < overview.OtherPods[j].Name
+	})
+
+	return overview
+}
+
+// summarizeApplicationPod reports a pod's readiness, restarts and the most relevant container problem
+func summarizeApplicationPod(pod *v1.Pod, events []eventSummary) applicationPod {
+	summary := applicationPod{
+		Name:   pod.Name,
+		Phase:  string(pod.Status.Phase),
+		Node:   pod.Spec.NodeName,
+		Reason: pod.Status.Reason,
+		Events: events,
+	}
+
+	ready := 0
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Ready {
+			ready++
+		}
+		summary.Restarts += status.RestartCount
+		if summary.Reason != "" {
+			continue
+		}
+		if status.State.Waiting != nil {
+			summary.Reason = status.State.Waiting.Reason
+		} else if status.State.Terminated != nil {
+			summary.Reason = status.State.Terminated.Reason
+		}
+	}
+	summary.Ready = fmt.Sprintf("%d/%d", ready, len(pod.Spec.Containers))
+
+	return summary
+}
+
+// indexEvents groups events by the UID of the object they concern, keeping the most recent per object
+func indexEvents(events *v1.EventList) map[types.UID][]eventSummary {
+	byUID := make(map[types.UID][]eventSummary)
+	if events == nil {
+		return byUID
+	}
+
+	items := make([]v1.Event, len(events.Items))
+	copy(items, events.Items)
+	sort.Slice(items, func(i, j int) bool {
+		return kubernetes.EventTime(&items[i]).After(kubernetes.EventTime(&items[j]).Time)
+	})
+
+	for i := range items {
+		event := &items[i]
+		uid := event.InvolvedObject.UID
+		if uid == "" || len(byUID[uid]) >= maxEventsPerObject {
+			continue
+		}
+		summary := eventSummary{
+			Type:    event.Type,
+			Reason:  event.Reason,
+			Message: event.Message,
+			Count:   event.Count,
+		}
+		if t := kubernetes.EventTime(event); !t.IsZero() {
+			summary.LastSeen = t.UTC().Format(time.RFC3339)
+		}
+		byUID[uid] = append(byUID[uid], summary)
+	}
+	return byUID
+}
@@ -0,0 +1,69 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// maxToolWorkers bounds how many tools ExecuteTools runs at once
+const maxToolWorkers = 4
+
+// BatchToolResult is the outcome of one request of an ExecuteTools batch
+type BatchToolResult struct {
+	// Index is the request's position in the batch
+	Index  int         `json:"index"`
+	Name   string      `json:"name"`
+	Result *ToolResult `json:"result,omitempty"`
+	// Err is the error ExecuteTool returned for this request; Result usually describes it too
+	Err error `json:"-"`
+}
+
+// ExecuteTools runs several tool requests concurrently with a bounded worker pool and returns one result
+// per request, in request order. A failing or panicking tool only affects its own result. Requests not
+// started before ctx ends get ctx's error
+func (m *MCPService) ExecuteTools(ctx context.Context, requests []ToolRequest) []BatchToolResult {
+	results := make([]BatchToolResult, len(requests))
+	for i, request := range requests {
+		results[i].Index = i
+		results[i].Name = request.Name
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	workers := min(maxToolWorkers, len(requests))
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					results[i].Err = err
+					continue
+				}
+				results[i].Result, results[i].Err = m.executeIsolated(ctx, requests[i])
+			}
+		}()
+	}
+	for i := range requests {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// executeIsolated runs ExecuteTool, turning a panic in the tool into an error so it can't take down
+// the rest of the batch
+func (m *MCPService) executeIsolated(ctx context.Context, request ToolRequest) (result *ToolResult, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			m.log(ctx).Error("MCP tool panicked", zap.String("tool", request.Name), zap.Any("panic", recovered))
+			result, err = nil, fmt.Errorf("tool %s failed unexpectedly: %v", request.Name, recovered)
+		}
+	}()
+	return m.ExecuteTool(ctx, request)
+}
@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"kube-sherlock/internal/kubernetes"
+)
+
+// newTestCluster returns a Kubernetes service backed by an API server that only serves namespaces
+func newTestCluster(t *testing.T) *kubernetes.Service {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&v1.NamespaceList{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "NamespaceList"},
+			Items: []v1.Namespace{
+				{ObjectMeta: metav1.ObjectMeta{Name: "shop"}, Status: v1.NamespaceStatus{Phase: v1.NamespaceActive}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "billing"}, Status: v1.NamespaceStatus{Phase: v1.NamespaceActive}},
+			},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+    namespace: shop
+users:
+- name: test
+  user:
+    token: test
+current-context: test
+`, server.URL)
+	service, err := kubernetes.NewService("", "test", zap.NewNop(), kubernetes.WithKubeconfigContent([]byte(kubeconfig)))
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	return service
+}
+
+func TestExecuteToolsOrderAndIsolation(t *testing.T) {
+	m := NewMCPService(newTestCluster(t), zap.NewNop())
+	// A custom tool without its templates panics when run
+	m.tools["broken_tool"] = Tool{Name: "broken_tool"}
+	m.customTools["broken_tool"] = &customTool{}
+
+	requests := []ToolRequest{
+		{Name: "get_namespaces"},
+		{Name: "get_pod_logs", Arguments: map[string]interface{}{}},
+		{Name: "no_such_tool"},
+		{Name: "broken_tool"},
+		{Name: "get_namespaces"},
+	}
+	results := m.ExecuteTools(context.Background(), requests)
+
+	if len(results) != len(requests) {
+		t.Fatalf("got %d results for %d requests", len(results), len(requests))
+	}
+	for i, result := range results {
+		if result.Index != i || result.Name != requests[i].Name {
+			t.Errorf("result %d is #%d %s, want #%d %s", i, result.Index, result.Name, i, requests[i].Name)
+		}
+	}
+
+	for _, i := range []int{0, 4} {
+		if results[i].Err != nil || results[i].Result == nil || results[i].Result.IsError {
+			t.Errorf("result %d: err = %v, result = %+v, want success", i, results[i].Err, results[i].Result)
+			continue
+		}
+		if text := results[i].Result.Content[0].Text; !strings.Contains(text, "shop") || !strings.Contains(text, "billing") {
+			t.Errorf("result %d doesn't list the namespaces:\n%s", i, text)
+		}
+	}
+	if !errors.Is(results[1].Err, ErrInvalidArguments) || results[1].Result == nil || !results[1].Result.IsError {
+		t.Errorf("result 1: err = %v, result = %+v, want an invalid arguments error result", results[1].Err, results[1].Result)
+	}
+	if !errors.Is(results[2].Err, ErrToolNotFound) {
+		t.Errorf("result 2: err = %v, want ErrToolNotFound", results[2].Err)
+	}
+	if results[3].Err == nil || !strings.Contains(results[3].Err.Error(), "failed unexpectedly") || results[3].Result != nil {
+		t.Errorf("result 3: err = %v, result = %+v, want the recovered panic", results[3].Err, results[3].Result)
+	}
+}
+
+func TestExecuteToolsCanceled(t *testing.T) {
+	m := NewMCPService(nil, zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := m.ExecuteTools(ctx, []ToolRequest{{Name: "get_namespaces"}, {Name: "get_pod_health"}})
+	for i, result := range results {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("result %d: err = %v, want context.Canceled", i, result.Err)
+		}
+	}
+}
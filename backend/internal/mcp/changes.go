@@ -0,0 +1,150 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"kube-sherlock/internal/kubernetes"
+)
+
+// defaultChangeResourceTypes are the resource types watched by detect_changes when none are given
+const defaultChangeResourceTypes = "pods,deployments,replicasets,configmaps"
+
+// maxChangeInterval bounds how long detect_changes waits between snapshots. It stays below the default
+// server.request_timeout (60s) with time left for both snapshots; tighter deadlines shorten the wait further
+const maxChangeInterval = 45
+
+// changeSnapshotAllowance is the time detect_changes keeps free before its deadline for the second snapshot
+const changeSnapshotAllowance = 10 * time.Second
+
+// maxReportedChanges caps the changes listed in detect_changes output; the counts still cover every change
+const maxReportedChanges = 100
+
+// detectChanges gathers the same resources twice, intervalSeconds apart, and reports the objects that were
+// added, removed, modified or recreated in between
+func (m *MCPService) detectChanges(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+	labelSelector := getStringParam(args, "labelSelector", "")
+	interval := getIntParam(args, "intervalSeconds", 15)
+
+	var resourceTypes []string
+	for _, resourceType := range strings.Split(getStringParam(args, "resourceTypes", defaultChangeResourceTypes), ",") {
+		if resourceType = strings.TrimSpace(resourceType); resourceType != "" {
+			resourceTypes = append(resourceTypes, resourceType)
+		}
+	}
+
+	if len(resourceTypes) == 0 {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "resourceTypes must name at least one resource type",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: resourceTypes must name at least one resource type", ErrInvalidArguments)
+	}
+	if interval <= 0 || interval > maxChangeInterval {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("intervalSeconds must be between 1 and %d", maxChangeInterval),
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: intervalSeconds must be between 1 and %d", ErrInvalidArguments, maxChangeInterval)
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	// Snapshots aren't minimized: the diff relies on UIDs and resourceVersions
+	opts := kubernetes.GatherOptions{
+		ResourceTypes: resourceTypes,
+		Namespace:     namespace,
+		LabelSelector: labelSelector,
+	}
+	before, err := m.k8sService.Gather(ctx, opts)
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error gathering resources: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+
+	// Wait no longer than the caller's deadline allows, so the second snapshot can still be taken
+	wait := time.Duration(interval) * time.Second
+	shortened := false
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline) - changeSnapshotAllowance; remaining < wait {
+			wait = max(remaining, 0).Truncate(time.Second)
+			shortened = true
+		}
+	}
+	if wait <= 0 {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Not enough time left before the request deadline to wait between snapshots",
+			}},
+			IsError: true,
+		}, context.DeadlineExceeded
+	}
+
+	select {
+	case <-time.After(wait):
+	case <-ctx.Done():
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Cancelled while waiting between snapshots: %v", ctx.Err()),
+			}},
+			IsError: true,
+		}, ctx.Err()
+	}
+
+	after, err := m.k8sService.Gather(ctx, opts)
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error gathering resources: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+
+	diff := kubernetes.DiffSnapshots(before, after)
+	total := len(diff.Changes)
+	if total > maxReportedChanges {
+		diff.Changes = diff.Changes[:maxReportedChanges]
+	}
+
+	diffData, _ := json.MarshalIndent(diff, "", "  ")
+	text := fmt.Sprintf("Changes in namespace '%s' over %s (%d change(s), %d unchanged object(s)):\n\n%s",
+		namespace, wait, total, diff.Unchanged, string(diffData))
+	if shortened {
+		text += fmt.Sprintf("\n\nThe wait was shortened from %ds to %s to finish before the request deadline", interval, wait)
+	}
+	if total > maxReportedChanges {
+		text += fmt.Sprintf("\n\nOnly the first %d of %d changes are listed", maxReportedChanges, total)
+	}
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: text,
+		}},
+	}, nil
+}
@@ -0,0 +1,235 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"kube-sherlock/internal/kubernetes"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// comparableKinds maps the kind argument of compare_resources to its resource
+var comparableKinds = map[string]schema.GroupVersionResource{
+	"deployment":  {Group: "apps", Version: "v1", Resource: "deployments"},
+	"statefulset": {Group: "apps", Version: "v1", Resource: "statefulsets"},
+	"daemonset":   {Group: "apps", Version: "v1", Resource: "daemonsets"},
+	"service":     {Version: "v1", Resource: "services"},
+	"configmap":   {Version: "v1", Resource: "configmaps"},
+	"ingress":     {Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+}
+
+// noisyMetadataFields are server-populated fields that always differ between objects
+var noisyMetadataFields = []string{"managedFields", "resourceVersion", "uid", "creationTimestamp", "generation", "selfLink", "namespace", "ownerReferences"}
+
+// noisyAnnotations are controller and tooling annotations that don't reflect intended configuration
+var noisyAnnotations = []string{"deployment.kubernetes.io/revision", "kubectl.kubernetes.io/last-applied-configuration"}
+
+// maxDiffEntries caps the diff size returned to the model
+const maxDiffEntries = 200
+
+// compareResources diffs the spec of the same kind of resource across two namespaces
+func (m *MCPService) compareResources(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	kind := strings.ToLower(getStringParam(args, "kind", ""))
+	name := getStringParam(args, "name", "")
+	leftNamespace := getStringParam(args, "namespace", "")
+	rightNamespace := getStringParam(args, "otherNamespace", "")
+	rightName := getStringParam(args, "otherName", name)
+
+	gvr, ok := comparableKinds[kind]
+	if !ok {
+		gvr, ok = kubernetes.ParseGroupVersionResource(kind)
+	}
+	if !ok || name == "" || leftNamespace == "" || rightNamespace == "" {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "kind, name, namespace and otherNamespace are required; kind is deployment, statefulset, daemonset, service, configmap, ingress or group/version/resource",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: kind, name, namespace and otherNamespace are required", ErrInvalidArguments)
+	}
+	if gvr.Resource == "secrets" {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Secrets cannot be compared because their data would be exposed",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: secrets cannot be compared", ErrInvalidArguments)
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	left, err := m.k8sService.GetResource(ctx, gvr, leftNamespace, name)
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error getting resource to compare: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+
+	right, err := m.k8sService.GetResource(ctx, gvr, rightNamespace, rightName)
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error getting resource to compare: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+
+	entries := diffObjects(normalizeForDiff(left), normalizeForDiff(right))
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: formatResourceDiff(gvr.Resource, leftNamespace+"/"+name, rightNamespace+"/"+rightName, entries),
+		}},
+	}, nil
+}
+
+// normalizeForDiff strips status and server-populated metadata so only intended configuration is compared
+func normalizeForDiff(object *unstructured.Unstructured) map[string]interface{} {
+	content := object.DeepCopy().Object
+	delete(content, "status")
+	if metadata, ok := content["metadata"].(map[string]interface{}); ok {
+		for _, field := range noisyMetadataFields {
+			delete(metadata, field)
+		}
+		// Names may legitimately differ when comparing renamed copies
+		delete(metadata, "name")
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			for _, annotation := range noisyAnnotations {
+				delete(annotations, annotation)
+			}
+			if len(annotations) == 0 {
+				delete(metadata, "annotations")
+			}
+		}
+	}
+	return content
+}
+
+// diffEntry is one differing field between two objects
+type diffEntry struct {
+	Path  string
+	Left  interface{}
+	Right interface{}
+	// OnlyIn is "left" or "right" when the field exists on one side only
+	OnlyIn string
+}
+
+// diffObjects returns the differing leaf fields of two objects, sorted by path
+func diffObjects(left, right map[string]interface{}) []diffEntry {
+	leftFields := make(map[string]interface{})
+	rightFields := make(map[string]interface{})
+	flattenFields("", left, leftFields)
+	flattenFields("", right, rightFields)
+
+	var entries []diffEntry
+	for path, leftValue := range leftFields {
+		rightValue, ok := rightFields[path]
+		switch {
+		case !ok:
+			entries = append(entries, diffEntry{Path: path, Left: leftValue, OnlyIn: "left"})
+		case fmt.Sprint(leftValue) != fmt.Sprint(rightValue):
+			entries = append(entries, diffEntry{Path: path, Left: leftValue, Right: rightValue})
+		}
+	}
+	for path, rightValue := range rightFields {
+		if _, ok := leftFields[path]; !ok {
+			entries = append(entries, diffEntry{Path: path, Right: rightValue, OnlyIn: "right"})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries
+}
+
+// flattenFields records every leaf value under a dotted path. List items with a name (containers, env
+// vars, ports, volumes) are keyed by that name so reordering doesn't show up as a difference
+func flattenFields(prefix string, value interface{}, out map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			out[prefix] = "{}"
+			return
+		}
+		for key, child := range v {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			flattenFields(path, child, out)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			out[prefix] = "[]"
+			return
+		}
+		for i, item := range v {
+			key := fmt.Sprintf("%d", i)
+			if named, ok := item.(map[string]interface{}); ok {
+				if name, ok := named["name"].(string); ok && name != "" {
+					key = "name=" + name
+				}
+			}
+			flattenFields(fmt.Sprintf("%s[%s]", prefix, key), item, out)
+		}
+	default:
+		out[prefix] = v
+	}
+}
+
+// formatResourceDiff renders diff entries as compact lines for the model
+func formatResourceDiff(resource, leftLabel, rightLabel string, entries []diffEntry) string {
+	if len(entries) == 0 {
+		return fmt.Sprintf("No configuration differences between %s %s and %s (status and server-managed metadata ignored)",
+			resource, leftLabel, rightLabel)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d configuration differences between %s %s (left) and %s (right), status and server-managed metadata ignored:\n\n",
+		len(entries), resource, leftLabel, rightLabel)
+	for i, entry := range entries {
+		if i == maxDiffEntries {
+			fmt.Fprintf(&b, "... %d more differences omitted\n", len(entries)-maxDiffEntries)
+			break
+		}
+		switch entry.OnlyIn {
+		case "left":
+			fmt.Fprintf(&b, "- %s: %s (only in %s)\n", entry.Path, formatDiffValue(entry.Left), leftLabel)
+		case "right":
+			fmt.Fprintf(&b, "+ %s: %s (only in %s)\n", entry.Path, formatDiffValue(entry.Right), rightLabel)
+		default:
+			fmt.Fprintf(&b, "~ %s: %s -> %s\n", entry.Path, formatDiffValue(entry.Left), formatDiffValue(entry.Right))
+		}
+	}
+	return b.String()
+}
+
+// formatDiffValue renders a leaf value as JSON so strings and numbers are distinguishable
+func formatDiffValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,163 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"kube-sherlock/internal/kubernetes"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// Caps on ConfigMap contents returned by get_configmap so large config blobs don't flood the prompt
+const (
+	maxConfigValueBytes = 4 * 1024
+	maxConfigTotalBytes = 32 * 1024
+)
+
+// configMapEntry is a single key of a ConfigMap
+type configMapEntry struct {
+	Key       string `json:"key"`
+	Value     string `json:"value,omitempty"`
+	Size      int    `json:"size"`
+	Binary    bool   `json:"binary,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+	// Omitted is set when the total cap was reached before this key
+	Omitted bool `json:"omitted,omitempty"`
+}
+
+// configMapSummary describes a ConfigMap's keys and values
+type configMapSummary struct {
+	Name      string           `json:"name"`
+	Namespace string           `json:"namespace"`
+	Immutable bool             `json:"immutable,omitempty"`
+	Entries   []configMapEntry `json:"entries"`
+}
+
+// getConfigMap returns a ConfigMap's keys and values, truncating long values
+func (m *MCPService) getConfigMap(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+	name := getStringParam(args, "configMapName", "")
+	key := getStringParam(args, "key", "")
+
+	if name == "" {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "ConfigMap name is required",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: configmap name is required", ErrInvalidArguments)
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	configMap, err := m.k8sService.GetConfigMap(ctx, namespace, name)
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error getting configmap: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+
+	summary := summarizeConfigMap(configMap, key)
+	if key != "" && len(summary.Entries) == 0 {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("ConfigMap '%s' in namespace '%s' has no key '%s'. Keys: %v",
+					name, namespace, key, configMapKeys(configMap)),
+			}},
+		}, nil
+	}
+
+	summaryData, _ := json.MarshalIndent(summary, "", "  ")
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: fmt.Sprintf("ConfigMap '%s' in namespace '%s' (values over %d bytes are truncated):\n\n%s",
+				name, namespace, maxConfigValueBytes, string(summaryData)),
+		}},
+	}, nil
+}
+
+// summarizeConfigMap lists a ConfigMap's keys in order with their values, applying the per-value and
+// total caps. Binary values are reported by size only. A non-empty key selects a single entry
+func summarizeConfigMap(configMap *v1.ConfigMap, key string) configMapSummary {
+	summary := configMapSummary{
+		Name:      configMap.Name,
+		Namespace: configMap.Namespace,
+		Immutable: configMap.Immutable != nil && *configMap.Immutable,
+		Entries:   []configMapEntry{},
+	}
+
+	total := 0
+	for _, k := range configMapKeys(configMap) {
+		if key != "" && k != key {
+			continue
+		}
+
+		if data, ok := configMap.BinaryData[k]; ok {
+			summary.Entries = append(summary.Entries, configMapEntry{Key: k, Size: len(data), Binary: true})
+			continue
+		}
+
+		value := configMap.Data[k]
+		entry := configMapEntry{Key: k, Size: len(value)}
+		if total >= maxConfigTotalBytes {
+			entry.Omitted = true
+			summary.Entries = append(summary.Entries, entry)
+			continue
+		}
+
+		limit := maxConfigValueBytes
+		if remaining := maxConfigTotalBytes - total; remaining < limit {
+			limit = remaining
+		}
+		entry.Value, entry.Truncated = truncateUTF8(value, limit)
+		total += len(entry.Value)
+		summary.Entries = append(summary.Entries, entry)
+	}
+
+	return summary
+}
+
+// configMapKeys returns the sorted keys of a ConfigMap's data and binaryData
+func configMapKeys(configMap *v1.ConfigMap) []string {
+	keys := make([]string, 0, len(configMap.Data)+len(configMap.BinaryData))
+	for k := range configMap.Data {
+		keys = append(keys, k)
+	}
+	for k := range configMap.BinaryData {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// truncateUTF8 cuts value to at most limit bytes without splitting a multi-byte character
+func truncateUTF8(value string, limit int) (string, bool) {
+	if len(value) <= limit {
+		return value, false
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return value[:cut], true
+}
@@ -0,0 +1,252 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"kube-sherlock/internal/kubernetes"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// connectivityReport is a static sanity check of whether a pod can serve traffic on a port
+type connectivityReport struct {
+	Pod            string                `json:"pod"`
+	Namespace      string                `json:"namespace"`
+	PodPhase       string                `json:"podPhase"`
+	PodReady       bool                  `json:"podReady"`
+	NotReadyReason string                `json:"notReadyReason,omitempty"`
+	Port           string                `json:"port,omitempty"`
+	PortDeclared   bool                  `json:"portDeclared"`
+	DeclaredIn     string                `json:"declaredInContainer,omitempty"`
+	DeclaredPorts  []string              `json:"declaredPorts"`
+	Services       []serviceConnectivity `json:"services"`
+	Findings       []string              `json:"findings"`
+	StaticOnly     bool                  `json:"staticCheckOnly"`
+}
+
+// serviceConnectivity describes how a service that selects the pod maps onto its ports
+type serviceConnectivity struct {
+	Name            string `json:"name"`
+	SelectsPod      bool   `json:"selectsPod"`
+	ServicePort     int32  `json:"servicePort"`
+	TargetPort      string `json:"targetPort"`
+	TargetPortFound bool   `json:"targetPortFound"`
+}
+
+// checkPodConnectivity cross-references pod readiness, declared container ports and service target ports
+func (m *MCPService) checkPodConnectivity(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+	podName := getStringParam(args, "podName", "")
+	serviceName := getStringParam(args, "serviceName", "")
+	port := getPortParam(args, "port")
+
+	if podName == "" {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Pod name is required for a connectivity check",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: pod name is required", ErrInvalidArguments)
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	pod, err := m.k8sService.GetPod(ctx, namespace, podName)
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error getting pod: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+
+	// Services to cross-reference: the named one, or every service selecting the pod
+	var services []v1.Service
+	if serviceName != "" {
+		service, err := m.k8sService.GetService(ctx, namespace, serviceName)
+		if err != nil {
+			return &ToolResult{
+				Content: []ToolContent{{
+					Type: "text",
+					Text: fmt.Sprintf("Error getting service: %v", err),
+				}},
+				IsError: true,
+			}, err
+		}
+		services = append(services, *service)
+	} else {
+		resources, err := m.gather(ctx, []string{"services"}, namespace, "")
+		if err == nil {
+			if list, ok := resources.Resources["services"].(*v1.ServiceList); ok {
+				for _, service := range list.Items {
+					if serviceSelectsPod(&service, pod) {
+						services = append(services, service)
+					}
+				}
+			}
+		}
+	}
+
+	report := buildConnectivityReport(pod, port, services)
+	reportData, _ := json.MarshalIndent(report, "", "  ")
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: fmt.Sprintf("Static connectivity check for pod '%s' in namespace '%s' (no network dial performed):\n\n%s",
+				podName, namespace, string(reportData)),
+		}},
+	}, nil
+}
+
+// buildConnectivityReport evaluates readiness and port wiring for a pod and the services in front of it
+func buildConnectivityReport(pod *v1.Pod, port intstr.IntOrString, services []v1.Service) connectivityReport {
+	report := connectivityReport{
+		Pod:           pod.Name,
+		Namespace:     pod.Namespace,
+		PodPhase:      string(pod.Status.Phase),
+		DeclaredPorts: []string{},
+		Services:      []serviceConnectivity{},
+		Findings:      []string{},
+		StaticOnly:    true,
+	}
+	if port.String() != "0" {
+		report.Port = port.String()
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			report.PodReady = condition.Status == v1.ConditionTrue
+			if !report.PodReady {
+				report.NotReadyReason = condition.Reason
+			}
+		}
+	}
+	if !report.PodReady {
+		report.Findings = append(report.Findings,
+			"Pod is not Ready, so it is excluded from service endpoints and will not receive service traffic")
+	}
+
+	for _, container := range pod.Spec.Containers {
+		for _, containerPort := range container.Ports {
+			declared := fmt.Sprintf("%s:%d/%s", container.Name, containerPort.ContainerPort, containerPort.Protocol)
+			if containerPort.Name != "" {
+				declared += " (" + containerPort.Name + ")"
+			}
+			report.DeclaredPorts = append(report.DeclaredPorts, declared)
+		}
+	}
+
+	if report.Port != "" {
+		if containerName, ok := findContainerPort(pod, port); ok {
+			report.PortDeclared = true
+			report.DeclaredIn = containerName
+		} else {
+			report.Findings = append(report.Findings, fmt.Sprintf(
+				"Port %s is not declared in any container spec; the process may still listen on it, but named targetPorts cannot resolve to it", report.Port))
+		}
+	}
+
+	if len(services) == 0 {
+		report.Findings = append(report.Findings, "No service in the namespace selects this pod")
+	}
+
+	for i := range services {
+		service := &services[i]
+		selects := serviceSelectsPod(service, pod)
+		if !selects {
+			report.Findings = append(report.Findings, fmt.Sprintf(
+				"Service '%s' selector does not match the pod's labels, so the pod will never be one of its endpoints", service.Name))
+		}
+
+		for _, servicePort := range service.Spec.Ports {
+			targetPort := servicePort.TargetPort
+			if targetPort.String() == "0" || targetPort.String() == "" {
+				targetPort = intstr.FromInt32(servicePort.Port)
+			}
+
+			_, found := findContainerPort(pod, targetPort)
+			// Numeric target ports work even when undeclared; named ones must resolve
+			if targetPort.Type == intstr.Int && !found {
+				report.Findings = append(report.Findings, fmt.Sprintf(
+					"Service '%s' port %d targets port %s, which no container declares; verify the application listens there",
+					service.Name, servicePort.Port, targetPort.String()))
+			} else if targetPort.Type == intstr.String && !found {
+				report.Findings = append(report.Findings, fmt.Sprintf(
+					"Service '%s' port %d targets named port '%s', which no container declares; traffic cannot be routed",
+					service.Name, servicePort.Port, targetPort.String()))
+			}
+
+			report.Services = append(report.Services, serviceConnectivity{
+				Name:            service.Name,
+				SelectsPod:      selects,
+				ServicePort:     servicePort.Port,
+				TargetPort:      targetPort.String(),
+				TargetPortFound: found,
+			})
+		}
+	}
+
+	if len(report.Findings) == 0 {
+		report.Findings = append(report.Findings, "No static connectivity problems found")
+	}
+
+	return report
+}
+
+// findContainerPort looks up a numeric or named port among the pod's containers
+func findContainerPort(pod *v1.Pod, port intstr.IntOrString) (string, bool) {
+	for _, container := range pod.Spec.Containers {
+		for _, containerPort := range container.Ports {
+			if port.Type == intstr.Int && containerPort.ContainerPort == port.IntVal {
+				return container.Name, true
+			}
+			if port.Type == intstr.String && containerPort.Name == port.StrVal {
+				return container.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// serviceSelectsPod reports whether a service's selector matches the pod's labels
+func serviceSelectsPod(service *v1.Service, pod *v1.Pod) bool {
+	if len(service.Spec.Selector) == 0 {
+		return false
+	}
+	return labels.SelectorFromSet(service.Spec.Selector).Matches(labels.Set(pod.Labels))
+}
+
+// getPortParam reads a port given either as a number or as a name
+func getPortParam(args map[string]interface{}, key string) intstr.IntOrString {
+	if val, ok := args[key]; ok {
+		switch v := val.(type) {
+		case float64:
+			return intstr.FromInt32(int32(v))
+		case int:
+			return intstr.FromInt32(int32(v))
+		case string:
+			if n, err := strconv.Atoi(v); err == nil {
+				return intstr.FromInt32(int32(n))
+			}
+			return intstr.FromString(v)
+		}
+	}
+	return intstr.FromInt32(0)
+}
@@ -0,0 +1,203 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"kube-sherlock/internal/kubernetes"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// Container kinds reported by get_pod_containers
+const (
+	containerKindInit      = "init"
+	containerKindSidecar   = "sidecar"
+	containerKindMain      = "main"
+	containerKindEphemeral = "ephemeral"
+)
+
+// containerSummary describes the state of a single container in a pod
+type containerSummary struct {
+	Name                  string `json:"name"`
+	Kind                  string `json:"kind"`
+	Image                 string `json:"image"`
+	State                 string `json:"state"`
+	Reason                string `json:"reason,omitempty"`
+	Message               string `json:"message,omitempty"`
+	ExitCode              *int32 `json:"exitCode,omitempty"`
+	Ready                 bool   `json:"ready"`
+	RestartCount          int32  `json:"restartCount"`
+	LastTerminationReason string `json:"lastTerminationReason,omitempty"`
+	LastTerminationExit   *int32 `json:"lastTerminationExitCode,omitempty"`
+}
+
+// podContainersSummary groups a pod's containers by kind
+type podContainersSummary struct {
+	Pod                 string             `json:"pod"`
+	Namespace           string             `json:"namespace"`
+	Phase               string             `json:"phase"`
+	InitStatus          string             `json:"initStatus"`
+	InitContainers      []containerSummary `json:"initContainers"`
+	Containers          []containerSummary `json:"containers"`
+	EphemeralContainers []containerSummary `json:"ephemeralContainers"`
+}
+
+// getPodContainers reports init, sidecar, main and ephemeral container states for a pod
+func (m *MCPService) getPodContainers(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+	podName := getStringParam(args, "podName", "")
+
+	if podName == "" {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Pod name is required for getting container status",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: pod name is required", ErrInvalidArguments)
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	pod, err := m.k8sService.GetPod(ctx, namespace, podName)
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error getting pod: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+
+	summary := summarizePodContainers(pod)
+	summaryData, _ := json.MarshalIndent(summary, "", "  ")
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: fmt.Sprintf("Container status for pod '%s' in namespace '%s' (init status: %s):\n\n%s",
+				podName, namespace, summary.InitStatus, string(summaryData)),
+		}},
+	}, nil
+}
+
+// summarizePodContainers builds a per-kind container summary from a pod's spec and status
+func summarizePodContainers(pod *v1.Pod) podContainersSummary {
+	initStatuses := statusesByName(pod.Status.InitContainerStatuses)
+	mainStatuses := statusesByName(pod.Status.ContainerStatuses)
+	ephemeralStatuses := statusesByName(pod.Status.EphemeralContainerStatuses)
+
+	summary := podContainersSummary{
+		Pod:                 pod.Name,
+		Namespace:           pod.Namespace,
+		Phase:               string(pod.Status.Phase),
+		InitContainers:      []containerSummary{},
+		Containers:          []containerSummary{},
+		EphemeralContainers: []containerSummary{},
+	}
+
+	// Sidecars are init containers that keep running; they don't need to complete
+	initRequired, initCompleted := 0, 0
+	initFailed := ""
+	for _, container := range pod.Spec.InitContainers {
+		kind := containerKindInit
+		if container.RestartPolicy != nil && *container.RestartPolicy == v1.ContainerRestartPolicyAlways {
+			kind = containerKindSidecar
+		}
+		cs := summarizeContainer(container.Name, kind, container.Image, initStatuses[container.Name])
+		summary.InitContainers = append(summary.InitContainers, cs)
+
+		if kind == containerKindInit {
+			initRequired++
+			if cs.State == "terminated" && cs.ExitCode != nil && *cs.ExitCode == 0 {
+				initCompleted++
+			} else if initFailed == "" && (cs.Reason == "Error" || cs.Reason == "CrashLoopBackOff" ||
+				(cs.State == "terminated" && cs.ExitCode != nil && *cs.ExitCode != 0)) {
+				initFailed = cs.Reason
+			}
+		}
+	}
+
+	for _, container := range pod.Spec.Containers {
+		summary.Containers = append(summary.Containers,
+			summarizeContainer(container.Name, containerKindMain, container.Image, mainStatuses[container.Name]))
+	}
+
+	for _, container := range pod.Spec.EphemeralContainers {
+		summary.EphemeralContainers = append(summary.EphemeralContainers,
+			summarizeContainer(container.Name, containerKindEphemeral, container.Image, ephemeralStatuses[container.Name]))
+	}
+
+	switch {
+	case initRequired == 0:
+		summary.InitStatus = "no init containers"
+	case initFailed != "":
+		summary.InitStatus = fmt.Sprintf("Init:%s (%d/%d completed)", initFailed, initCompleted, initRequired)
+	case initCompleted == initRequired:
+		summary.InitStatus = fmt.Sprintf("completed (%d/%d)", initCompleted, initRequired)
+	default:
+		summary.InitStatus = fmt.Sprintf("Init:%d/%d", initCompleted, initRequired)
+	}
+
+	return summary
+}
+
+// summarizeContainer describes a container's current and last state
+func summarizeContainer(name, kind, image string, status *v1.ContainerStatus) containerSummary {
+	cs := containerSummary{
+		Name:  name,
+		Kind:  kind,
+		Image: image,
+		State: "unknown",
+	}
+	if status == nil {
+		cs.State = "not started"
+		return cs
+	}
+
+	cs.Ready = status.Ready
+	cs.RestartCount = status.RestartCount
+
+	switch {
+	case status.State.Waiting != nil:
+		cs.State = "waiting"
+		cs.Reason = status.State.Waiting.Reason
+		cs.Message = status.State.Waiting.Message
+	case status.State.Running != nil:
+		cs.State = "running"
+	case status.State.Terminated != nil:
+		cs.State = "terminated"
+		cs.Reason = status.State.Terminated.Reason
+		cs.Message = status.State.Terminated.Message
+		exitCode := status.State.Terminated.ExitCode
+		cs.ExitCode = &exitCode
+	}
+
+	if last := status.LastTerminationState.Terminated; last != nil {
+		cs.LastTerminationReason = last.Reason
+		exitCode := last.ExitCode
+		cs.LastTerminationExit = &exitCode
+	}
+
+	return cs
+}
+
+// statusesByName indexes container statuses by container name
+func statusesByName(statuses []v1.ContainerStatus) map[string]*v1.ContainerStatus {
+	byName := make(map[string]*v1.ContainerStatus, len(statuses))
+	for i := range statuses {
+		byName[statuses[i].Name] = &statuses[i]
+	}
+	return byName
+}
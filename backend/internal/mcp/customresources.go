@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"kube-sherlock/internal/kubernetes"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// getCustomResources lists arbitrary resources such as CRD instances through the dynamic client
+func (m *MCPService) getCustomResources(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	group := getStringParam(args, "group", "")
+	version := getStringParam(args, "version", "")
+	resource := getStringParam(args, "resource", "")
+	namespace := getStringParam(args, "namespace", "")
+	labelSelector := getStringParam(args, "labelSelector", "")
+
+	if version == "" || resource == "" {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Both version and resource are required for listing custom resources",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: version and resource are required", ErrInvalidArguments)
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	gvr := schema.GroupVersionResource{Group: group, Version: version, Resource: resource}
+	list, err := m.k8sService.ListCustomResources(ctx, gvr, namespace, labelSelector)
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error listing %s: %v", gvr.String(), err),
+			}},
+			IsError: true,
+		}, err
+	}
+
+	kubernetes.MinimizeList(list)
+
+	scope := fmt.Sprintf("namespace '%s'", namespace)
+	if namespace == "" {
+		scope = "all namespaces"
+	}
+
+	listData, _ := json.MarshalIndent(list.Items, "", "  ")
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: fmt.Sprintf("%d %s in %s:\n\n%s", len(list.Items), gvr.GroupResource().String(), scope, string(listData)),
+		}},
+	}, nil
+}
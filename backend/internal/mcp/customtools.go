@@ -0,0 +1,232 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"kube-sherlock/internal/kubernetes"
+
+	"go.uber.org/zap"
+)
+
+// customToolName restricts custom tool and parameter names to what the model reliably reproduces
+var customToolName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// CustomTool defines a read-only tool from configuration. It gathers ResourceTypes from the namespace
+// and with the label selector rendered from its templates, so teams can offer tailored views such as
+// get_payments_pods without code changes
+type CustomTool struct {
+	Name          string   `mapstructure:"name"`
+	Description   string   `mapstructure:"description"`
+	ResourceTypes []string `mapstructure:"resource_types"`
+	// Namespace and LabelSelector are text/template strings over the tool's parameters, such as
+	// "{{.team}}-prod" or "app={{.app}}". An empty namespace uses the default namespace
+	Namespace     string                `mapstructure:"namespace"`
+	LabelSelector string                `mapstructure:"label_selector"`
+	Parameters    []CustomToolParameter `mapstructure:"parameters"`
+}
+
+// CustomToolParameter is a string argument of a custom tool
+type CustomToolParameter struct {
+	Name        string `mapstructure:"name"`
+	Description string `mapstructure:"description"`
+	Default     string `mapstructure:"default"`
+	Required    bool   `mapstructure:"required"`
+}
+
+// customTool is a validated custom tool with its templates parsed
+type customTool struct {
+	CustomTool
+	namespace     *template.Template
+	labelSelector *template.Template
+}
+
+// WithCustomTools registers tools defined in configuration alongside the built-in ones. Invalid
+// definitions, and ones that reuse a built-in tool's name, are logged and skipped
+func WithCustomTools(tools ...CustomTool) Option {
+	return func(m *MCPService) {
+		m.customToolDefinitions = tools
+	}
+}
+
+// registerCustomTools validates and registers the configured custom tools
+func (m *MCPService) registerCustomTools() {
+	for _, definition := range m.customToolDefinitions {
+		tool, err := newCustomTool(definition)
+		if err == nil {
+			if _, exists := m.tools[definition.Name]; exists {
+				err = fmt.Errorf("a tool named %s already exists", definition.Name)
+			}
+		}
+		if err != nil {
+			m.logger.Warn("Skipping invalid custom tool", zap.String("tool", definition.Name), zap.Error(err))
+			continue
+		}
+
+		properties := make(map[string]interface{}, len(definition.Parameters))
+		required := []string{}
+		for _, param := range definition.Parameters {
+			description := param.Description
+			if param.Default != "" {
+				description += fmt.Sprintf(" (default: %s)", param.Default)
+			}
+			properties[param.Name] = map[string]interface{}{
+				"type":        "string",
+				"description": strings.TrimSpace(description),
+			}
+			if param.Required {
+				required = append(required, param.Name)
+			}
+		}
+
+		description := definition.Description
+		if description == "" {
+			description = "Get " + strings.Join(definition.ResourceTypes, ", ")
+		}
+		m.tools[definition.Name] = Tool{
+			Name:        definition.Name,
+			Description: description,
+			InputSchema: ToolSchema{
+				Type:       "object",
+				Properties: properties,
+				Required:   required,
+			},
+		}
+		m.customTools[definition.Name] = tool
+		m.logger.Info("Registered custom tool", zap.String("tool", definition.Name), zap.Strings("types", definition.ResourceTypes))
+	}
+}
+
+// newCustomTool checks a custom tool definition and parses its templates. The templates are rendered
+// once with every parameter set so references to undeclared parameters are caught up front
+func newCustomTool(definition CustomTool) (*customTool, error) {
+	if !customToolName.MatchString(definition.Name) {
+		return nil, fmt.Errorf("name %q must be lowercase letters, digits and underscores", definition.Name)
+	}
+	if len(definition.ResourceTypes) == 0 {
+		return nil, fmt.Errorf("no resource_types given")
+	}
+	if err := kubernetes.ValidateResourceTypes(definition.ResourceTypes); err != nil {
+		return nil, err
+	}
+
+	sample := make(map[string]string, len(definition.Parameters))
+	for _, param := range definition.Parameters {
+		if !customToolName.MatchString(param.Name) {
+			return nil, fmt.Errorf("parameter name %q must be lowercase letters, digits and underscores", param.Name)
+		}
+		if _, duplicate := sample[param.Name]; duplicate {
+			return nil, fmt.Errorf("parameter %s is declared twice", param.Name)
+		}
+		sample[param.Name] = "x"
+	}
+
+	tool := &customTool{CustomTool: definition}
+	var err error
+	if tool.namespace, err = parseCustomTemplate("namespace", definition.Namespace, sample); err != nil {
+		return nil, err
+	}
+	if tool.labelSelector, err = parseCustomTemplate("label_selector", definition.LabelSelector, sample); err != nil {
+		return nil, err
+	}
+	return tool, nil
+}
+
+// parseCustomTemplate parses a custom tool template and renders it once with sample values
+func parseCustomTemplate(name, text string, sample map[string]string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	if err := tmpl.Execute(&strings.Builder{}, sample); err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// executeCustomTool renders a custom tool's namespace and label selector from its arguments and gathers its resources
+func (m *MCPService) executeCustomTool(ctx context.Context, tool *customTool, args map[string]interface{}) (*ToolResult, error) {
+	values := make(map[string]string, len(tool.Parameters))
+	for _, param := range tool.Parameters {
+		value := strings.TrimSpace(getStringParam(args, param.Name, param.Default))
+		if value == "" && param.Required {
+			return &ToolResult{
+				Content: []ToolContent{{
+					Type: "text",
+					Text: fmt.Sprintf("%s is required", param.Name),
+				}},
+				IsError: true,
+			}, fmt.Errorf("%w: %s is required", ErrInvalidArguments, param.Name)
+		}
+		values[param.Name] = value
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	var namespace, labelSelector strings.Builder
+	err := tool.namespace.Execute(&namespace, values)
+	if err == nil {
+		err = tool.labelSelector.Execute(&labelSelector, values)
+	}
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error rendering the namespace or label selector: %v", err),
+			}},
+			IsError: true,
+		}, fmt.Errorf("failed to render templates for %s: %w", tool.Name, err)
+	}
+	ns := strings.TrimSpace(namespace.String())
+	if ns == "" {
+		ns = m.k8sService.DefaultNamespace()
+	}
+	selector := strings.TrimSpace(labelSelector.String())
+
+	resources, err := m.gather(ctx, tool.ResourceTypes, ns, selector)
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error gathering resources: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+
+	failed := 0
+	for _, resourceType := range tool.ResourceTypes {
+		if _, ok := resources.Resources[resourceType+"_error"]; ok {
+			failed++
+		}
+	}
+
+	header := fmt.Sprintf("%s in namespace '%s'", strings.Join(tool.ResourceTypes, ", "), ns)
+	if selector != "" {
+		header += fmt.Sprintf(" with selector '%s'", selector)
+	}
+	resourcesData, _ := json.MarshalIndent(resources.Resources, "", "  ")
+	result := &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: fmt.Sprintf("%s:\n\n%s", header, string(resourcesData)),
+		}},
+	}
+	if failed == len(tool.ResourceTypes) {
+		result.IsError = true
+		return result, fmt.Errorf("failed to gather %s", strings.Join(tool.ResourceTypes, ", "))
+	}
+	return result, nil
+}
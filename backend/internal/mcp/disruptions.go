@@ -0,0 +1,218 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"kube-sherlock/internal/kubernetes"
+
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// maxDisruptionBudgets caps the PodDisruptionBudgets listed by get_pod_disruption_budgets, blocking ones first
+const maxDisruptionBudgets = 100
+
+// disruptionBudgetSummary is the state of one PodDisruptionBudget
+type disruptionBudgetSummary struct {
+	Namespace      string `json:"namespace"`
+	Name           string `json:"name"`
+	Selector       string `json:"selector"`
+	MinAvailable   string `json:"minAvailable,omitempty"`
+	MaxUnavailable string `json:"maxUnavailable,omitempty"`
+	// ExpectedPods is how many pods the budget selects; CurrentHealthy of them are healthy and
+	// DesiredHealthy must stay healthy
+	ExpectedPods       int32 `json:"expectedPods"`
+	CurrentHealthy     int32 `json:"currentHealthy"`
+	DesiredHealthy     int32 `json:"desiredHealthy"`
+	DisruptionsAllowed int32 `json:"disruptionsAllowed"`
+	// Blocking means no pod it selects can be evicted right now, so drains and evictions wait
+	Blocking                   bool     `json:"blocking"`
+	UnhealthyPodEvictionPolicy string   `json:"unhealthyPodEvictionPolicy,omitempty"`
+	Problems                   []string `json:"problems,omitempty"`
+}
+
+// disruptionBudgetReport is the result of get_pod_disruption_budgets
+type disruptionBudgetReport struct {
+	Total    int                       `json:"total"`
+	Blocking int                       `json:"blocking"`
+	Budgets  []disruptionBudgetSummary `json:"budgets"`
+}
+
+// getPodDisruptionBudgets reports each PodDisruptionBudget's thresholds, healthy and desired pods and
+// whether it currently allows disruptions, flagging budgets that block every eviction
+func (m *MCPService) getPodDisruptionBudgets(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+	name := getStringParam(args, "name", "")
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	resources, err := m.gather(ctx, []string{"poddisruptionbudgets"}, namespace, "")
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error gathering PodDisruptionBudgets: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+	if msg, ok := resources.Resources["poddisruptionbudgets_error"].(string); ok {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error listing PodDisruptionBudgets: %s", msg),
+			}},
+			IsError: true,
+		}, fmt.Errorf("failed to list poddisruptionbudgets: %s", msg)
+	}
+
+	report := disruptionBudgetReport{Budgets: []disruptionBudgetSummary{}}
+	if budgets, ok := resources.Resources["poddisruptionbudgets"].(*policyv1.PodDisruptionBudgetList); ok {
+		for i := range budgets.Items {
+			if name != "" && budgets.Items[i].Name != name {
+				continue
+			}
+			summary := summarizeDisruptionBudget(&budgets.Items[i])
+			if summary.Blocking {
+				report.Blocking++
+			}
+			report.Budgets = append(report.Budgets, summary)
+		}
+	}
+
+	scope := fmt.Sprintf("namespace '%s'", namespace)
+	if namespace == kubernetes.AllNamespaces {
+		scope = "any namespace"
+	}
+	if name != "" && len(report.Budgets) == 0 {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("PodDisruptionBudget '%s' not found in %s", name, scope),
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: poddisruptionbudget %s/%s", kubernetes.ErrNotFound, namespace, name)
+	}
+	if len(report.Budgets) == 0 {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("No PodDisruptionBudgets in %s; evictions there are not limited by a budget", scope),
+			}},
+		}, nil
+	}
+
+	sort.SliceStable(report.Budgets, func(i, j int) bool {
+		a, b := report.Budgets[i], report.Budgets[j]
+		if a.Blocking != b.Blocking {
+			return a.Blocking
+		}
+		if len(a.Problems) != len(b.Problems) {
+			return len(a.Problems) > len(b.Problems)
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	report.Total = len(report.Budgets)
+	if report.Total > maxDisruptionBudgets {
+		report.Budgets = report.Budgets[:maxDisruptionBudgets]
+	}
+
+	reportData, _ := json.MarshalIndent(report, "", "  ")
+	text := fmt.Sprintf("PodDisruptionBudgets in %s (%d budgets, %d blocking all disruptions):\n\n%s",
+		scope, report.Total, report.Blocking, string(reportData))
+	if report.Total > maxDisruptionBudgets {
+		text += fmt.Sprintf("\n\nShowing %d of %d budgets", maxDisruptionBudgets, report.Total)
+	}
+	if len(resources.Metadata.Truncated) > 0 {
+		text += "\n\nThe list hit the cluster-wide list limit, so some budgets may be missing"
+	}
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: text,
+		}},
+	}, nil
+}
+
+// summarizeDisruptionBudget reports a budget's thresholds and status and explains why it blocks evictions
+func summarizeDisruptionBudget(budget *policyv1.PodDisruptionBudget) disruptionBudgetSummary {
+	status := budget.Status
+	summary := disruptionBudgetSummary{
+		Namespace:          budget.Namespace,
+		Name:               budget.Name,
+		Selector:           "<none>",
+		ExpectedPods:       status.ExpectedPods,
+		CurrentHealthy:     status.CurrentHealthy,
+		DesiredHealthy:     status.DesiredHealthy,
+		DisruptionsAllowed: status.DisruptionsAllowed,
+	}
+	if budget.Spec.Selector != nil {
+		summary.Selector = metav1.FormatLabelSelector(budget.Spec.Selector)
+	}
+	if budget.Spec.MinAvailable != nil {
+		summary.MinAvailable = budget.Spec.MinAvailable.String()
+	}
+	if budget.Spec.MaxUnavailable != nil {
+		summary.MaxUnavailable = budget.Spec.MaxUnavailable.String()
+	}
+	if budget.Spec.UnhealthyPodEvictionPolicy != nil {
+		summary.UnhealthyPodEvictionPolicy = string(*budget.Spec.UnhealthyPodEvictionPolicy)
+	}
+
+	if budget.Status.ObservedGeneration < budget.Generation {
+		summary.Problems = append(summary.Problems, "status is out of date; the disruption controller hasn't processed the latest spec yet")
+	}
+	if condition := meta.FindStatusCondition(status.Conditions, policyv1.DisruptionAllowedCondition); condition != nil &&
+		condition.Status == metav1.ConditionFalse && condition.Reason == policyv1.SyncFailedReason {
+		summary.Problems = append(summary.Problems, fmt.Sprintf("the disruption controller can't compute this budget: %s", condition.Message))
+	}
+
+	switch {
+	case status.ExpectedPods == 0:
+		summary.Problems = append(summary.Problems, "selects no pods; check that the selector matches the workload's pod labels")
+	case status.DisruptionsAllowed > 0:
+	case zeroDisruptionBudget(budget, status.ExpectedPods):
+		summary.Blocking = true
+		summary.Problems = append(summary.Problems, fmt.Sprintf("never allows a disruption: it requires all %d pods to stay healthy, so node drains hang until the budget is relaxed", status.ExpectedPods))
+	default:
+		summary.Blocking = true
+		summary.Problems = append(summary.Problems, fmt.Sprintf("blocking all disruptions: %d of %d pods healthy and %d must stay healthy; evictions wait until more pods are ready",
+			status.CurrentHealthy, status.ExpectedPods, status.DesiredHealthy))
+		if summary.UnhealthyPodEvictionPolicy != string(policyv1.AlwaysAllow) && status.CurrentHealthy < status.ExpectedPods {
+			summary.Problems = append(summary.Problems, "unhealthy pods can't be evicted either; unhealthyPodEvictionPolicy: AlwaysAllow would let drains remove them")
+		}
+	}
+	return summary
+}
+
+// zeroDisruptionBudget reports whether a budget is configured so that it can never allow a disruption,
+// with maxUnavailable 0 or 0%, or minAvailable equal to the number of pods or 100%
+func zeroDisruptionBudget(budget *policyv1.PodDisruptionBudget, expectedPods int32) bool {
+	if maxUnavailable := budget.Spec.MaxUnavailable; maxUnavailable != nil {
+		return maxUnavailable.String() == "0" || maxUnavailable.String() == "0%"
+	}
+	if minAvailable := budget.Spec.MinAvailable; minAvailable != nil {
+		if minAvailable.String() == "100%" {
+			return true
+		}
+		return minAvailable.Type == intstr.Int && minAvailable.IntVal >= expectedPods
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+)
+
+// serviceEndpointSummary correlates a service with the endpoints currently backing it
+type serviceEndpointSummary struct {
+	Service           string            `json:"service"`
+	Type              string            `json:"type"`
+	ClusterIP         string            `json:"clusterIP,omitempty"`
+	Selector          map[string]string `json:"selector,omitempty"`
+	Ports             []string          `json:"ports"`
+	ReadyEndpoints    []string          `json:"readyEndpoints"`
+	NotReadyEndpoints []string          `json:"notReadyEndpoints"`
+	Warning           string            `json:"warning,omitempty"`
+}
+
+// summarizeServiceEndpoints resolves each service's ready and not-ready endpoint addresses from its EndpointSlices
+func summarizeServiceEndpoints(services *v1.ServiceList, slices *discoveryv1.EndpointSliceList) []serviceEndpointSummary {
+	slicesByService := make(map[string][]discoveryv1.EndpointSlice)
+	if slices != nil {
+		for _, slice := range slices.Items {
+			name := slice.Labels[discoveryv1.LabelServiceName]
+			slicesByService[name] = append(slicesByService[name], slice)
+		}
+	}
+
+	summaries := make([]serviceEndpointSummary, 0, len(services.Items))
+	for _, service := range services.Items {
+		summary := serviceEndpointSummary{
+			Service:           service.Name,
+			Type:              string(service.Spec.Type),
+			ClusterIP:         service.Spec.ClusterIP,
+			Selector:          service.Spec.Selector,
+			Ports:             []string{},
+			ReadyEndpoints:    []string{},
+			NotReadyEndpoints: []string{},
+		}
+		for _, port := range service.Spec.Ports {
+			summary.Ports = append(summary.Ports, fmt.Sprintf("%d->%s/%s", port.Port, port.TargetPort.String(), port.Protocol))
+		}
+
+		for _, slice := range slicesByService[service.Name] {
+			for _, endpoint := range slice.Endpoints {
+				target := ""
+				if endpoint.TargetRef != nil {
+					target = fmt.Sprintf(" (%s/%s)", endpoint.TargetRef.Kind, endpoint.TargetRef.Name)
+				}
+				// A nil Ready condition means ready per the EndpointSlice API
+				ready := endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+				for _, address := range endpoint.Addresses {
+					if ready {
+						summary.ReadyEndpoints = append(summary.ReadyEndpoints, address+target)
+					} else {
+						summary.NotReadyEndpoints = append(summary.NotReadyEndpoints, address+target)
+					}
+				}
+			}
+		}
+
+		switch {
+		case service.Spec.Type == v1.ServiceTypeExternalName:
+			// ExternalName services resolve via DNS and never have endpoints
+		case len(service.Spec.Selector) == 0:
+			summary.Warning = "Service has no selector; endpoints must be managed manually"
+		case len(summary.ReadyEndpoints) == 0 && len(summary.NotReadyEndpoints) > 0:
+			summary.Warning = "Service has NO READY endpoints: all backing pods are not ready"
+		case len(summary.ReadyEndpoints) == 0:
+			summary.Warning = "Service has NO endpoints: its selector matches no pods"
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries
+}
@@ -0,0 +1,20 @@
+package mcp
+
+import "errors"
+
+// Sentinel errors returned (wrapped) by ExecuteTool so callers can use errors.Is. Cluster
+// failures are reported with the kubernetes package's sentinels
+var (
+	// ErrToolNotFound means no tool is registered under the requested name
+	ErrToolNotFound = errors.New("tool not found")
+	// ErrToolNotImplemented means the tool is registered but has no implementation
+	ErrToolNotImplemented = errors.New("tool not implemented")
+	// ErrInvalidArguments means required tool arguments were missing or malformed
+	ErrInvalidArguments = errors.New("invalid tool arguments")
+	// ErrCommandNotAllowed means exec_in_pod was asked to run a program missing from the allow-list
+	ErrCommandNotAllowed = errors.New("command not allowed")
+	// ErrAdminRequired means an admin-only tool was requested without the admin token
+	ErrAdminRequired = errors.New("tool requires the admin token")
+	// ErrToolTimeout means the tool didn't finish within its configured timeout
+	ErrToolTimeout = errors.New("tool timed out")
+)
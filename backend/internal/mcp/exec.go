@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"kube-sherlock/internal/kubernetes"
+
+	"go.uber.org/zap"
+)
+
+// execTimeout bounds a single exec_in_pod command so commands like `tail -f` can't hang a query
+const execTimeout = 30 * time.Second
+
+// maxExecOutputBytes caps the stdout and the stderr kept from an exec_in_pod command
+const maxExecOutputBytes = 64 * 1024
+
+// DefaultExecCommands are read-only programs exec_in_pod may run when no allow-list is configured.
+// None of them can start another program or, like date and hostname, change state given arguments
+var DefaultExecCommands = []string{
+	"cat", "ls", "printenv", "df", "ps", "id", "uname", "head", "wc", "stat", "nslookup",
+}
+
+// WithExec registers the exec_in_pod tool when enabled, restricted to the allowed programs (DefaultExecCommands
+// when empty). A program is matched by the command's first word exactly, so "cat" does not allow "/bin/cat"
+func WithExec(enabled bool, allowedCommands []string) Option {
+	return func(m *MCPService) {
+		if !enabled {
+			return
+		}
+		m.execCommands = DefaultExecCommands
+		if len(allowedCommands) > 0 {
+			m.execCommands = allowedCommands
+		}
+	}
+}
+
+// execInPod runs an allow-listed command in a container and returns its output and exit code
+func (m *MCPService) execInPod(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+	podName := getStringParam(args, "podName", "")
+	containerName := getStringParam(args, "containerName", "")
+	command := strings.Fields(getStringParam(args, "command", ""))
+
+	if podName == "" || len(command) == 0 {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "podName and command are required",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: podName and command are required", ErrInvalidArguments)
+	}
+	if !slices.Contains(m.execCommands, command[0]) {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Command %q is not allowed. Allowed programs: %s", command[0], strings.Join(m.execCommands, ", ")),
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: %s", ErrCommandNotAllowed, command[0])
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	// Every exec is logged so the commands run in containers can be audited
+	m.log(ctx).Info("Executing command in pod",
+		zap.String("namespace", namespace),
+		zap.String("pod", podName),
+		zap.String("container", containerName),
+		zap.Strings("command", command))
+
+	execCtx, cancel := context.WithTimeout(ctx, execTimeout)
+	defer cancel()
+	result, err := m.k8sService.ExecInPod(execCtx, namespace, podName, containerName, command, maxExecOutputBytes)
+	if err != nil {
+		if execCtx.Err() != nil && ctx.Err() == nil {
+			err = fmt.Errorf("command did not finish within %s: %w", execTimeout, err)
+		}
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error executing command in pod: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+
+	resultData, _ := json.MarshalIndent(result, "", "  ")
+	text := fmt.Sprintf("Output of %q in pod '%s' in namespace '%s':\n\n%s",
+		strings.Join(command, " "), podName, namespace, string(resultData))
+	if result.Truncated {
+		text += fmt.Sprintf("\n\nNote: output truncated to the first %d bytes", maxExecOutputBytes)
+	}
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: text,
+		}},
+	}, nil
+}
@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"kube-sherlock/internal/kubernetes"
+)
+
+// explainResource returns a single named object of any kind, minimized, with its most recent events, so the
+// analysis can explain what the object is for, its status and anything anomalous about it
+func (m *MCPService) explainResource(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	kind := getStringParam(args, "kind", "")
+	name := getStringParam(args, "name", "")
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+
+	if kind == "" || name == "" {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Both kind and name are required",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: kind and name are required", ErrInvalidArguments)
+	}
+	if namespace == kubernetes.AllNamespaces {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "namespace must name the resource's namespace, not \"*\"",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: namespace must not be \"*\"", ErrInvalidArguments)
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	details, err := m.k8sService.GetResourceDetails(ctx, kind, namespace, name)
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error getting %s '%s': %v", kind, name, err),
+			}},
+			IsError: true,
+		}, err
+	}
+
+	detailsData, _ := json.MarshalIndent(details, "", "  ")
+	text := fmt.Sprintf("%s with its %d most recent events. Explain what it is for, its current status and any anomalies:\n\n%s",
+		details.String(), len(details.Events), string(detailsData))
+	if details.EventsError != "" {
+		text += fmt.Sprintf("\n\nEvents could not be listed: %s", details.EventsError)
+	}
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: text,
+		}},
+	}, nil
+}
@@ -0,0 +1,323 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"kube-sherlock/internal/kubernetes"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Issue severities reported by get_cluster_health_summary, most urgent first
+const (
+	severityCritical = "critical"
+	severityWarning  = "warning"
+	severityInfo     = "info"
+)
+
+// maxIssueMessageLen caps the event message quoted in an issue
+const maxIssueMessageLen = 300
+
+// severityRank orders issues by severity
+var severityRank = map[string]int{
+	severityCritical: 0,
+	severityWarning:  1,
+	severityInfo:     2,
+}
+
+// criticalWaitingReasons are container waiting reasons that won't resolve without intervention
+var criticalWaitingReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"RunContainerError":          true,
+}
+
+// clusterIssue is a single problem found by the cluster health sweep
+type clusterIssue struct {
+	Severity  string `json:"severity"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Controller is the top-level owner of a pod, such as Deployment/api
+	Controller string `json:"controller,omitempty"`
+	Problem    string `json:"problem"`
+	Count      int32  `json:"count,omitempty"`
+}
+
+// clusterHealthSummary is the bounded, prioritized result of a cluster health sweep
+type clusterHealthSummary struct {
+	PodsChecked        int            `json:"podsChecked"`
+	DeploymentsChecked int            `json:"deploymentsChecked"`
+	IssueCounts        map[string]int `json:"issueCounts"`
+	TotalIssues        int            `json:"totalIssues"`
+	Issues             []clusterIssue `json:"issues"`
+	// Truncated lists resource types that hit the all-namespaces list limit, so some issues may be missing
+	Truncated []string `json:"truncated,omitempty"`
+}
+
+// getClusterHealthSummary sweeps all permitted namespaces for failing pods, deployments with unavailable
+// replicas and recent Warning events, returning the most urgent problems first
+func (m *MCPService) getClusterHealthSummary(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	limit := getIntParam(args, "limit", 20)
+	eventMinutes := getIntParam(args, "eventMinutes", 60)
+
+	if limit <= 0 || limit > 100 {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "limit must be between 1 and 100",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: limit must be between 1 and 100", ErrInvalidArguments)
+	}
+	if eventMinutes <= 0 {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "eventMinutes must be positive",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: eventMinutes must be positive", ErrInvalidArguments)
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	resourceTypes := []string{"pods", "deployments", "events"}
+	resources, err := m.gather(ctx, resourceTypes, kubernetes.AllNamespaces, "")
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error gathering cluster resources: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+
+	summary := clusterHealthSummary{
+		IssueCounts: map[string]int{},
+		Truncated:   resources.Metadata.Truncated,
+	}
+	var issues []clusterIssue
+
+	if pods, ok := resources.Resources["pods"].(*v1.PodList); ok {
+		summary.PodsChecked = len(pods.Items)
+		resolver := m.k8sService.NewOwnerResolver()
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if issue, ok := podIssue(pod); ok {
+				issue.Controller = resolver.Controller(ctx, pod.Namespace, pod.OwnerReferences).String()
+				issues = append(issues, issue)
+			}
+		}
+	}
+	if deployments, ok := resources.Resources["deployments"].(*appsv1.DeploymentList); ok {
+		summary.DeploymentsChecked = len(deployments.Items)
+		for i := range deployments.Items {
+			if issue, ok := deploymentIssue(&deployments.Items[i]); ok {
+				issues = append(issues, issue)
+			}
+		}
+	}
+	if events, ok := resources.Resources["events"].(*v1.EventList); ok {
+		since := time.Now().Add(-time.Duration(eventMinutes) * time.Minute)
+		issues = append(issues, warningEventIssues(events, since)...)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if severityRank[a.Severity] != severityRank[b.Severity] {
+			return severityRank[a.Severity] < severityRank[b.Severity]
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	for _, issue := range issues {
+		summary.IssueCounts[issue.Severity]++
+	}
+	summary.TotalIssues = len(issues)
+	if int64(len(issues)) > limit {
+		issues = issues[:limit]
+	}
+	summary.Issues = issues
+	if summary.Issues == nil {
+		summary.Issues = []clusterIssue{}
+	}
+
+	var gatherErrors []string
+	for _, resourceType := range resourceTypes {
+		if msg, ok := resources.Resources[resourceType+"_error"].(string); ok {
+			gatherErrors = append(gatherErrors, fmt.Sprintf("%s: %s", resourceType, msg))
+		}
+	}
+
+	summaryData, _ := json.MarshalIndent(summary, "", "  ")
+	text := fmt.Sprintf("Cluster health summary (%d of %d issues, most urgent first):\n\n%s",
+		len(summary.Issues), summary.TotalIssues, string(summaryData))
+	if len(gatherErrors) > 0 {
+		text += fmt.Sprintf("\n\nErrors:\n%s", strings.Join(gatherErrors, "\n"))
+	}
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: text,
+		}},
+	}, nil
+}
+
+// podIssue describes what is wrong with a pod that is not Running and ready or Succeeded
+func podIssue(pod *v1.Pod) (clusterIssue, bool) {
+	if kubernetes.IsPodHealthy(pod) {
+		return clusterIssue{}, false
+	}
+
+	issue := clusterIssue{
+		Severity:  severityWarning,
+		Kind:      "Pod",
+		Namespace: pod.Namespace,
+		Name:      pod.Name,
+	}
+
+	var problems []string
+	notReady := 0
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		issue.Count += status.RestartCount
+		if !status.Ready {
+			notReady++
+		}
+		if waiting := status.State.Waiting; waiting != nil && waiting.Reason != "" && waiting.Reason != "ContainerCreating" && waiting.Reason != "PodInitializing" {
+			problem := fmt.Sprintf("container %s: %s", status.Name, waiting.Reason)
+			if last := status.LastTerminationState.Terminated; last != nil && last.Reason != "" {
+				problem += fmt.Sprintf(" (last exit: %s, code %d)", last.Reason, last.ExitCode)
+			}
+			problems = append(problems, problem)
+			if criticalWaitingReasons[waiting.Reason] {
+				issue.Severity = severityCritical
+			}
+		}
+	}
+
+	switch pod.Status.Phase {
+	case v1.PodFailed:
+		reason := pod.Status.Reason
+		if reason == "" {
+			reason = "Failed"
+		}
+		problems = append([]string{reason}, problems...)
+		// Evicted pods linger after the problem has passed; other failures need attention
+		if reason != "Evicted" {
+			issue.Severity = severityCritical
+		}
+	case v1.PodUnknown:
+		problems = append([]string{"pod status Unknown (node may be unreachable)"}, problems...)
+		issue.Severity = severityCritical
+	case v1.PodPending:
+		prefix := "Pending"
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == v1.PodScheduled && condition.Status == v1.ConditionFalse {
+				prefix = "Pending: unschedulable"
+			}
+		}
+		problems = append([]string{prefix}, problems...)
+	case v1.PodRunning:
+		if len(problems) == 0 {
+			problems = append(problems, fmt.Sprintf("%d of %d containers not ready", notReady, len(pod.Status.ContainerStatuses)))
+		}
+	}
+
+	issue.Problem = strings.Join(problems, "; ")
+	return issue, true
+}
+
+// deploymentIssue reports a deployment with unavailable replicas; none available is critical
+func deploymentIssue(deployment *appsv1.Deployment) (clusterIssue, bool) {
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	available := deployment.Status.AvailableReplicas
+	if desired == 0 || available >= desired {
+		return clusterIssue{}, false
+	}
+
+	issue := clusterIssue{
+		Severity:  severityWarning,
+		Kind:      "Deployment",
+		Namespace: deployment.Namespace,
+		Name:      deployment.Name,
+		Problem:   fmt.Sprintf("%d of %d replicas available", available, desired),
+		Count:     desired - available,
+	}
+	if available == 0 {
+		issue.Severity = severityCritical
+	}
+	return issue, true
+}
+
+// warningEventIssues groups Warning events seen since the cutoff by object and reason
+func warningEventIssues(events *v1.EventList, since time.Time) []clusterIssue {
+	grouped := make(map[string]*clusterIssue)
+	var order []string
+	for i := range events.Items {
+		event := &events.Items[i]
+		if event.Type != v1.EventTypeWarning || kubernetes.EventTime(event).Time.Before(since) {
+			continue
+		}
+
+		object := event.InvolvedObject
+		key := strings.Join([]string{object.Namespace, object.Kind, object.Name, event.Reason}, "/")
+		count := event.Count
+		if count == 0 {
+			count = 1
+		}
+		if issue, ok := grouped[key]; ok {
+			issue.Count += count
+			continue
+		}
+		message, truncated := truncateUTF8(event.Message, maxIssueMessageLen)
+		if truncated {
+			message += "..."
+		}
+		grouped[key] = &clusterIssue{
+			Severity:  severityInfo,
+			Kind:      "Event",
+			Namespace: object.Namespace,
+			Name:      object.Kind + "/" + object.Name,
+			Problem:   event.Reason + ": " + message,
+			Count:     count,
+		}
+		order = append(order, key)
+	}
+
+	issues := make([]clusterIssue, 0, len(order))
+	for _, key := range order {
+		issues = append(issues, *grouped[key])
+	}
+	return issues
+}
@@ -0,0 +1,368 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"kube-sherlock/internal/kubernetes"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// maxImageContainers bounds how many containers diagnose_image_pulls reports
+const maxImageContainers = 50
+
+// imagePullWaitingReasons are the container waiting reasons the kubelet sets when it can't get an image
+var imagePullWaitingReasons = map[string]bool{
+	"ErrImagePull":        true,
+	"ImagePullBackOff":    true,
+	"InvalidImageName":    true,
+	"ErrImageNeverPull":   true,
+	"RegistryUnavailable": true,
+	"ImageInspectError":   true,
+}
+
+// Likely causes of a failed image pull reported by diagnose_image_pulls
+const (
+	pullCauseNotFound     = "image or tag not found"
+	pullCauseUnauthorized = "registry authentication failed"
+	pullCauseUnreachable  = "registry unreachable"
+	pullCauseInvalidName  = "invalid image name"
+	pullCauseNeverPull    = "image not on the node and imagePullPolicy is Never"
+	pullCauseRateLimited  = "registry rate limit"
+)
+
+// pullCausePatterns map substrings of kubelet and registry error messages to a likely cause, checked in order
+var pullCausePatterns = []struct {
+	substrings []string
+	cause      string
+}{
+	{[]string{"toomanyrequests", "rate limit"}, pullCauseRateLimited},
+	{[]string{"unauthorized", "authentication required", "access denied", "denied:", "403 forbidden", "no basic auth credentials"}, pullCauseUnauthorized},
+	{[]string{"not found", "manifest unknown", "does not exist", "name unknown"}, pullCauseNotFound},
+	{[]string{"no such host", "i/o timeout", "connection refused", "dial tcp", "tls handshake timeout", "context deadline exceeded"}, pullCauseUnreachable},
+	{[]string{"invalidimagename", "invalid reference format"}, pullCauseInvalidName},
+	{[]string{"errimageneverpull", "never pull"}, pullCauseNeverPull},
+}
+
+// pullSecretWrongType is the status of an imagePullSecret that exists but holds no registry credentials
+const pullSecretWrongType = "not a registry credential"
+
+// pullSecretStatus is whether an imagePullSecret of a pod exists and holds registry credentials
+type pullSecretStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// containerImageReport describes a container's image and whether the kubelet could pull it
+type containerImageReport struct {
+	Pod             string `json:"pod"`
+	Container       string `json:"container"`
+	Init            bool   `json:"init,omitempty"`
+	Image           string `json:"image"`
+	ImagePullPolicy string `json:"imagePullPolicy"`
+	// ImageID is the digest the image resolved to, set once it was pulled
+	ImageID        string             `json:"imageID,omitempty"`
+	Failing        bool               `json:"failing"`
+	WaitingReason  string             `json:"waitingReason,omitempty"`
+	WaitingMessage string             `json:"waitingMessage,omitempty"`
+	FailedPulls    int32              `json:"failedPulls,omitempty"`
+	LastPullError  string             `json:"lastPullError,omitempty"`
+	LastPullErrAt  string             `json:"lastPullErrorAt,omitempty"`
+	LikelyCause    string             `json:"likelyCause,omitempty"`
+	PullSecrets    []pullSecretStatus `json:"pullSecrets,omitempty"`
+	Warnings       []string           `json:"warnings,omitempty"`
+}
+
+// pullFailures aggregates the kubelet's Failed pull events for one container
+type pullFailures struct {
+	count   int32
+	message string
+	last    time.Time
+}
+
+// containerKey identifies a container of a pod
+type containerKey struct {
+	pod       types.NamespacedName
+	container string
+}
+
+// diagnoseImagePulls reports the image and pull policy of each container of matching pods, with image pull
+// waiting reasons, the matching Failed events and the state of the pods' imagePullSecrets, failing pulls first
+func (m *MCPService) diagnoseImagePulls(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+	labelSelector := getStringParam(args, "labelSelector", "")
+	podName := getStringParam(args, "podName", "")
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	resources, err := m.gather(ctx, []string{"pods"}, namespace, labelSelector)
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error gathering pods: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+	if msg, ok := resources.Resources["pods_error"].(string); ok {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error listing pods: %s", msg),
+			}},
+			IsError: true,
+		}, fmt.Errorf("failed to list pods: %s", msg)
+	}
+
+	// Events carry no pod labels, so they are matched to the pods by name and container field path
+	eventResources, err := m.gather(ctx, []string{"events"}, namespace, "")
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error gathering events: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+	events, _ := eventResources.Resources["events"].(*v1.EventList)
+	failures := indexPullEvents(events)
+
+	var reports []containerImageReport
+	secrets := make(map[string]pullSecretStatus)
+	if pods, ok := resources.Resources["pods"].(*v1.PodList); ok {
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if podName != "" && pod.Name != podName {
+				continue
+			}
+			podReports := podImageReports(pod, failures)
+			// Pull secrets are only looked up for pods that can't pull, to keep API calls down
+			var pullSecrets []pullSecretStatus
+			for j := range podReports {
+				if !podReports[j].Failing {
+					continue
+				}
+				if pullSecrets == nil {
+					pullSecrets = m.checkPullSecrets(ctx, pod, secrets)
+				}
+				podReports[j].PullSecrets = pullSecrets
+				if len(pullSecrets) == 0 && podReports[j].LikelyCause == pullCauseUnauthorized {
+					podReports[j].Warnings = append(podReports[j].Warnings, "the pod has no imagePullSecrets; a private registry needs one on the pod or its service account")
+				}
+			}
+			reports = append(reports, podReports...)
+		}
+	}
+	if len(reports) == 0 {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("No matching pods in namespace '%s'", namespace),
+			}},
+		}, nil
+	}
+
+	total, failing := len(reports), 0
+	for _, report := range reports {
+		if report.Failing {
+			failing++
+		}
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].Failing && !reports[j].Failing
+	})
+
+	text := ""
+	if total > maxImageContainers {
+		text = fmt.Sprintf("Showing %d of %d containers; narrow the query with labelSelector or podName.\n\n", maxImageContainers, total)
+		reports = reports[:maxImageContainers]
+	}
+	reportData, _ := json.MarshalIndent(reports, "", "  ")
+	text = fmt.Sprintf("Image pull diagnostics for namespace '%s' (%d of %d containers failing to pull):\n\n%s%s",
+		namespace, failing, total, text, string(reportData))
+	if msg, ok := eventResources.Resources["events_error"].(string); ok {
+		text += fmt.Sprintf("\n\nErrors:\nevents: %s (pull error messages could not be checked)", msg)
+	}
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: text,
+		}},
+	}, nil
+}
+
+// indexPullEvents aggregates the kubelet's Failed events about pulling images per container
+func indexPullEvents(events *v1.EventList) map[containerKey]*pullFailures {
+	failures := make(map[containerKey]*pullFailures)
+	if events == nil {
+		return failures
+	}
+
+	for i := range events.Items {
+		event := &events.Items[i]
+		if event.InvolvedObject.Kind != "Pod" || event.Reason != "Failed" || !isPullMessage(event.Message) {
+			continue
+		}
+		key := containerKey{
+			pod:       types.NamespacedName{Namespace: event.InvolvedObject.Namespace, Name: event.InvolvedObject.Name},
+			container: fieldPathContainer(event.InvolvedObject.FieldPath),
+		}
+		entry, ok := failures[key]
+		if !ok {
+			entry = &pullFailures{}
+			failures[key] = entry
+		}
+		count := event.Count
+		if count == 0 {
+			count = 1
+		}
+		entry.count += count
+		// Prefer the detailed "Failed to pull image" message over the bare ErrImagePull that follows it
+		t := kubernetes.EventTime(event).Time
+		detailed := strings.HasPrefix(event.Message, "Failed to pull")
+		entryDetailed := strings.HasPrefix(entry.message, "Failed to pull")
+		if !ok || (detailed && !entryDetailed) || (detailed == entryDetailed && t.After(entry.last)) {
+			entry.last = t
+			entry.message = event.Message
+		}
+	}
+	return failures
+}
+
+// isPullMessage reports whether a Failed event is about getting an image rather than starting the container
+func isPullMessage(message string) bool {
+	lower := strings.ToLower(message)
+	return strings.Contains(lower, "pull") || strings.Contains(lower, "errimage") || strings.Contains(lower, "invalidimagename")
+}
+
+// podImageReports builds an image report for each init and regular container in a pod
+func podImageReports(pod *v1.Pod, failures map[containerKey]*pullFailures) []containerImageReport {
+	podKey := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+	initStatuses := statusesByName(pod.Status.InitContainerStatuses)
+	statuses := statusesByName(pod.Status.ContainerStatuses)
+
+	reports := make([]containerImageReport, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers))
+	for i := range pod.Spec.InitContainers {
+		container := &pod.Spec.InitContainers[i]
+		reports = append(reports, containerImage(pod.Name, container, true, initStatuses[container.Name], failures[containerKey{pod: podKey, container: container.Name}]))
+	}
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		reports = append(reports, containerImage(pod.Name, container, false, statuses[container.Name], failures[containerKey{pod: podKey, container: container.Name}]))
+	}
+	return reports
+}
+
+// containerImage describes one container's image, pull state and the likely cause of a failed pull
+func containerImage(podName string, container *v1.Container, init bool, status *v1.ContainerStatus, failure *pullFailures) containerImageReport {
+	report := containerImageReport{
+		Pod:             podName,
+		Container:       container.Name,
+		Init:            init,
+		Image:           container.Image,
+		ImagePullPolicy: string(container.ImagePullPolicy),
+	}
+	if status != nil {
+		report.ImageID = status.ImageID
+		if waiting := status.State.Waiting; waiting != nil && imagePullWaitingReasons[waiting.Reason] {
+			report.Failing = true
+			report.WaitingReason = waiting.Reason
+			report.WaitingMessage = waiting.Message
+		}
+	}
+	// Old events of a container that has since pulled its image aren't reported as failures
+	if failure != nil && (report.Failing || report.ImageID == "") {
+		report.Failing = true
+		report.FailedPulls = failure.count
+		report.LastPullError = failure.message
+		if !failure.last.IsZero() {
+			report.LastPullErrAt = failure.last.UTC().Format(time.RFC3339)
+		}
+	}
+	if report.Failing {
+		report.LikelyCause = pullCause(report.WaitingReason, report.WaitingMessage, report.LastPullError)
+	}
+	report.Warnings = imageWarnings(container)
+	return report
+}
+
+// pullCause guesses why a pull failed from the waiting reason and error messages
+func pullCause(reason string, messages ...string) string {
+	text := strings.ToLower(reason + " " + strings.Join(messages, " "))
+	for _, pattern := range pullCausePatterns {
+		for _, substring := range pattern.substrings {
+			if strings.Contains(text, substring) {
+				return pattern.cause
+			}
+		}
+	}
+	return ""
+}
+
+// imageWarnings flags image references that make pulls unpredictable
+func imageWarnings(container *v1.Container) []string {
+	var warnings []string
+	image := container.Image
+	name := image[strings.LastIndex(image, "/")+1:]
+	switch {
+	case strings.Contains(image, "@"):
+		// Pinned by digest
+	case !strings.Contains(name, ":"):
+		warnings = append(warnings, "the image has no tag, so :latest is pulled")
+	case strings.HasSuffix(name, ":latest") && container.ImagePullPolicy == v1.PullIfNotPresent:
+		warnings = append(warnings, ":latest with imagePullPolicy IfNotPresent keeps whatever version a node pulled first")
+	}
+	return warnings
+}
+
+// checkPullSecrets reports whether each of a pod's imagePullSecrets exists and is a registry credential,
+// caching lookups in secrets by namespace and name
+func (m *MCPService) checkPullSecrets(ctx context.Context, pod *v1.Pod, secrets map[string]pullSecretStatus) []pullSecretStatus {
+	return m.checkPullSecretRefs(ctx, pod.Namespace, pod.Spec.ImagePullSecrets, secrets)
+}
+
+// checkPullSecretRefs reports whether each referenced secret in namespace exists and is a registry
+// credential, caching lookups in secrets by namespace and name
+func (m *MCPService) checkPullSecretRefs(ctx context.Context, namespace string, refs []v1.LocalObjectReference, secrets map[string]pullSecretStatus) []pullSecretStatus {
+	statuses := []pullSecretStatus{}
+	for _, ref := range refs {
+		key := namespace + "/" + ref.Name
+		status, ok := secrets[key]
+		if !ok {
+			status = pullSecretStatus{Name: ref.Name, Status: secretRefOK}
+			secret, err := m.k8sService.GetSecretMetadata(ctx, namespace, ref.Name)
+			switch {
+			case errors.Is(err, kubernetes.ErrNotFound):
+				status.Status = secretRefNotFound
+			case err != nil:
+				status.Status = secretRefUnavailable
+				status.Detail = err.Error()
+			case secret.Type != v1.SecretTypeDockerConfigJson && secret.Type != v1.SecretTypeDockercfg:
+				status.Status = pullSecretWrongType
+				status.Detail = fmt.Sprintf("secret type is %s, not %s", secret.Type, v1.SecretTypeDockerConfigJson)
+			}
+			secrets[key] = status
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
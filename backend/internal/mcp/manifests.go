@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"kube-sherlock/internal/kubernetes"
+)
+
+// validateManifest validates a YAML manifest with a server-side dry-run apply of each document and
+// reports the validation errors, without changing anything in the cluster
+func (m *MCPService) validateManifest(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	manifest := getStringParam(args, "manifest", "")
+
+	if manifest == "" {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "manifest is required",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: manifest is required", ErrInvalidArguments)
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	results, err := m.k8sService.ValidateManifest(ctx, manifest)
+	if err != nil {
+		if errors.Is(err, kubernetes.ErrInvalidManifest) {
+			err = fmt.Errorf("%w: %w", ErrInvalidArguments, err)
+		}
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error validating manifest: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+
+	invalid := 0
+	for _, result := range results {
+		if !result.Valid {
+			invalid++
+		}
+	}
+	resultsData, _ := json.MarshalIndent(results, "", "  ")
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: fmt.Sprintf("Server-side dry-run validation of %d manifest documents (%d invalid):\n\n%s", len(results), invalid, string(resultsData)),
+		}},
+	}, nil
+}
@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"sort"
+
+	"go.uber.org/zap"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// defaultMaxItems caps the items a list tool returns when neither the tool nor mcp.max_items sets a cap
+const defaultMaxItems = 50
+
+// defaultToolMaxItems are the built-in caps of tools whose lists are worth returning at greater length
+var defaultToolMaxItems = map[string]int{
+	"get_recent_events": maxRecentEvents,
+}
+
+// maxItemsTools are the tools whose output is capped by maxItems
+var maxItemsTools = map[string]bool{
+	"get_recent_events": true,
+	"get_pod_health":    true,
+}
+
+// WithMaxItems caps the items list tools such as get_recent_events and get_pod_health return, keeping the
+// most relevant. defaultMax applies to tools without a built-in or configured cap; overrides set the cap of
+// individual tools by name. Non-positive values keep the built-in caps
+func WithMaxItems(defaultMax int, overrides map[string]int) Option {
+	return func(m *MCPService) {
+		if defaultMax > 0 {
+			m.defaultMaxItems = defaultMax
+		}
+		for name, max := range overrides {
+			if max > 0 {
+				m.toolMaxItems[name] = max
+			}
+		}
+	}
+}
+
+// maxItems returns the most items the named tool may return
+func (m *MCPService) maxItems(name string) int {
+	if max, ok := m.toolMaxItems[name]; ok {
+		return max
+	}
+	return m.defaultMaxItems
+}
+
+// warnUnknownToolMaxItems logs configured caps for tools that don't cap their output, which usually means a
+// misspelled tool name
+func (m *MCPService) warnUnknownToolMaxItems() {
+	for name := range m.toolMaxItems {
+		if !maxItemsTools[name] {
+			m.logger.Warn("Ignoring max items for MCP tool that doesn't cap its output", zap.String("tool", name))
+		}
+	}
+}
+
+// mostProblematicPods returns at most max of pods, most problematic first: critical issues, then warnings,
+// then healthy pods, each ordered by restarts. The result is a new list, so pods itself is left untouched
+func mostProblematicPods(pods *v1.PodList, max int) *v1.PodList {
+	type rankedPod struct {
+		pod      v1.Pod
+		rank     int
+		restarts int32
+	}
+	ranked := make([]rankedPod, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		entry := rankedPod{pod: pod, rank: len(severityRank)}
+		if issue, ok := podIssue(&pod); ok {
+			entry.rank = severityRank[issue.Severity]
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			entry.restarts += status.RestartCount
+		}
+		ranked = append(ranked, entry)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].rank != ranked[j].rank {
+			return ranked[i].rank < ranked[j].rank
+		}
+		if ranked[i].restarts != ranked[j].restarts {
+			return ranked[i].restarts > ranked[j].restarts
+		}
+		return ranked[i].pod.Name < ranked[j].pod.Name
+	})
+
+	if len(ranked) > max {
+		ranked = ranked[:max]
+	}
+	kept := &v1.PodList{TypeMeta: pods.TypeMeta, ListMeta: pods.ListMeta, Items: make([]v1.Pod, 0, len(ranked))}
+	for _, entry := range ranked {
+		kept.Items = append(kept.Items, entry.pod)
+	}
+	return kept
+}
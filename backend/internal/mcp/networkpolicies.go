@@ -0,0 +1,325 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"kube-sherlock/internal/kubernetes"
+
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// maxNetworkPolicyPods caps the pods reported by get_network_policies
+const maxNetworkPolicyPods = 50
+
+// networkPolicyRule is one ingress or egress rule in readable form
+type networkPolicyRule struct {
+	Peers []string `json:"peers"`
+	Ports []string `json:"ports"`
+}
+
+// networkPolicySummary describes what a NetworkPolicy selects and allows
+type networkPolicySummary struct {
+	Name        string              `json:"name"`
+	PodSelector string              `json:"podSelector"`
+	PolicyTypes []string            `json:"policyTypes"`
+	Ingress     []networkPolicyRule `json:"ingress,omitempty"`
+	Egress      []networkPolicyRule `json:"egress,omitempty"`
+	// DefaultDeny lists the directions the policy isolates without allowing anything
+	DefaultDeny []string `json:"defaultDeny,omitempty"`
+}
+
+// podNetworkPolicies describes how NetworkPolicies restrict a single pod's traffic
+type podNetworkPolicies struct {
+	Pod      string   `json:"pod"`
+	Policies []string `json:"policies"`
+	// IngressIsolated and EgressIsolated mean only traffic allowed by a selecting policy's rules gets through
+	IngressIsolated     bool     `json:"ingressIsolated"`
+	EgressIsolated      bool     `json:"egressIsolated"`
+	DefaultDenyPolicies []string `json:"defaultDenyPolicies,omitempty"`
+	Warnings            []string `json:"warnings,omitempty"`
+}
+
+// networkPolicyReport is the result of get_network_policies
+type networkPolicyReport struct {
+	Namespace string                 `json:"namespace"`
+	Pods      []podNetworkPolicies   `json:"pods"`
+	Policies  []networkPolicySummary `json:"policies"`
+}
+
+// getNetworkPolicies reports which NetworkPolicies select the given pods and summarizes the traffic
+// they allow, flagging pods whose ingress or egress is denied outright
+func (m *MCPService) getNetworkPolicies(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+	podName := getStringParam(args, "podName", "")
+	labelSelector := getStringParam(args, "labelSelector", "")
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	resources, err := m.k8sService.Gather(ctx, kubernetes.GatherOptions{
+		ResourceTypes:  []string{"pods", "networkpolicies"},
+		Namespace:      namespace,
+		LabelSelectors: map[string]string{"pods": labelSelector},
+		Minimize:       true,
+	})
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error gathering network policies: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+	for _, resourceType := range []string{"pods", "networkpolicies"} {
+		if msg, ok := resources.Resources[resourceType+"_error"].(string); ok {
+			return &ToolResult{
+				Content: []ToolContent{{
+					Type: "text",
+					Text: fmt.Sprintf("Error listing %s: %s", resourceType, msg),
+				}},
+				IsError: true,
+			}, fmt.Errorf("failed to list %s: %s", resourceType, msg)
+		}
+	}
+
+	var pods []*v1.Pod
+	if podList, ok := resources.Resources["pods"].(*v1.PodList); ok {
+		for i := range podList.Items {
+			if podName == "" || podList.Items[i].Name == podName {
+				pods = append(pods, &podList.Items[i])
+			}
+		}
+	}
+	if podName != "" && len(pods) == 0 {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Pod '%s' not found in namespace '%s'", podName, namespace),
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: pod %s/%s", kubernetes.ErrNotFound, namespace, podName)
+	}
+
+	policyList, _ := resources.Resources["networkpolicies"].(*networkingv1.NetworkPolicyList)
+	if policyList == nil || len(policyList.Items) == 0 {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("No NetworkPolicies in namespace '%s'; its pods' traffic is not restricted by NetworkPolicy", namespace),
+			}},
+		}, nil
+	}
+
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	totalPods := len(pods)
+	if totalPods > maxNetworkPolicyPods {
+		pods = pods[:maxNetworkPolicyPods]
+	}
+
+	report := networkPolicyReport{Namespace: namespace, Pods: []podNetworkPolicies{}, Policies: []networkPolicySummary{}}
+	relevant := make(map[string]bool)
+	for _, pod := range pods {
+		podReport := podNetworkPolicies{Pod: pod.Name, Policies: []string{}}
+		var ingressRules, egressRules int
+		allowsDNS := false
+		for i := range policyList.Items {
+			policy := &policyList.Items[i]
+			selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.PodSelector)
+			if err != nil || !selector.Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+			relevant[policy.Name] = true
+			podReport.Policies = append(podReport.Policies, policy.Name)
+
+			ingress, egress := policyDirections(policy)
+			if ingress {
+				podReport.IngressIsolated = true
+				ingressRules += len(policy.Spec.Ingress)
+			}
+			if egress {
+				podReport.EgressIsolated = true
+				egressRules += len(policy.Spec.Egress)
+				for _, rule := range policy.Spec.Egress {
+					allowsDNS = allowsDNS || ruleAllowsDNS(rule.Ports)
+				}
+			}
+			if (ingress && len(policy.Spec.Ingress) == 0) || (egress && len(policy.Spec.Egress) == 0) {
+				podReport.DefaultDenyPolicies = append(podReport.DefaultDenyPolicies, policy.Name)
+			}
+		}
+
+		if podReport.IngressIsolated && ingressRules == 0 {
+			podReport.Warnings = append(podReport.Warnings, "all ingress is denied: the selecting policies allow no incoming traffic")
+		}
+		if podReport.EgressIsolated && egressRules == 0 {
+			podReport.Warnings = append(podReport.Warnings, "all egress is denied, including DNS: the selecting policies allow no outgoing traffic")
+		} else if podReport.EgressIsolated && !allowsDNS {
+			podReport.Warnings = append(podReport.Warnings, "egress is restricted and no rule allows DNS (port 53), so name lookups will fail")
+		}
+		report.Pods = append(report.Pods, podReport)
+	}
+
+	for i := range policyList.Items {
+		policy := &policyList.Items[i]
+		// With no pods in scope every policy is shown; otherwise only those selecting a reported pod
+		if len(pods) > 0 && !relevant[policy.Name] {
+			continue
+		}
+		report.Policies = append(report.Policies, summarizeNetworkPolicy(policy))
+	}
+
+	reportData, _ := json.MarshalIndent(report, "", "  ")
+	text := fmt.Sprintf("NetworkPolicies affecting %d pod(s) in namespace '%s':\n\n%s", len(report.Pods), namespace, string(reportData))
+	if totalPods > len(pods) {
+		text += fmt.Sprintf("\n\nOnly the first %d of %d pods are listed; use podName or labelSelector to narrow the query", len(pods), totalPods)
+	}
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: text,
+		}},
+	}, nil
+}
+
+// policyDirections reports whether a policy isolates ingress and egress. Without explicit policyTypes a
+// policy always affects ingress, and egress only when it has egress rules
+func policyDirections(policy *networkingv1.NetworkPolicy) (ingress, egress bool) {
+	if len(policy.Spec.PolicyTypes) == 0 {
+		return true, len(policy.Spec.Egress) > 0
+	}
+	for _, policyType := range policy.Spec.PolicyTypes {
+		switch policyType {
+		case networkingv1.PolicyTypeIngress:
+			ingress = true
+		case networkingv1.PolicyTypeEgress:
+			egress = true
+		}
+	}
+	return ingress, egress
+}
+
+// summarizeNetworkPolicy renders a policy's selector and rules as readable strings
+func summarizeNetworkPolicy(policy *networkingv1.NetworkPolicy) networkPolicySummary {
+	summary := networkPolicySummary{
+		Name:        policy.Name,
+		PodSelector: describeSelector(&policy.Spec.PodSelector, "all pods"),
+	}
+
+	ingress, egress := policyDirections(policy)
+	if ingress {
+		summary.PolicyTypes = append(summary.PolicyTypes, string(networkingv1.PolicyTypeIngress))
+		if len(policy.Spec.Ingress) == 0 {
+			summary.DefaultDeny = append(summary.DefaultDeny, "ingress")
+		}
+	}
+	if egress {
+		summary.PolicyTypes = append(summary.PolicyTypes, string(networkingv1.PolicyTypeEgress))
+		if len(policy.Spec.Egress) == 0 {
+			summary.DefaultDeny = append(summary.DefaultDeny, "egress")
+		}
+	}
+
+	for _, rule := range policy.Spec.Ingress {
+		summary.Ingress = append(summary.Ingress, networkPolicyRule{
+			Peers: describePeers(rule.From, "all sources"),
+			Ports: describePorts(rule.Ports),
+		})
+	}
+	for _, rule := range policy.Spec.Egress {
+		summary.Egress = append(summary.Egress, networkPolicyRule{
+			Peers: describePeers(rule.To, "all destinations"),
+			Ports: describePorts(rule.Ports),
+		})
+	}
+	return summary
+}
+
+// describePeers renders the peers of a rule; a rule without peers matches everything
+func describePeers(peers []networkingv1.NetworkPolicyPeer, all string) []string {
+	if len(peers) == 0 {
+		return []string{all}
+	}
+	described := make([]string, 0, len(peers))
+	for _, peer := range peers {
+		switch {
+		case peer.IPBlock != nil:
+			block := "ipBlock " + peer.IPBlock.CIDR
+			if len(peer.IPBlock.Except) > 0 {
+				block += " except " + strings.Join(peer.IPBlock.Except, ", ")
+			}
+			described = append(described, block)
+		case peer.NamespaceSelector != nil && peer.PodSelector != nil:
+			described = append(described, fmt.Sprintf("pods (%s) in namespaces (%s)",
+				describeSelector(peer.PodSelector, "all"), describeSelector(peer.NamespaceSelector, "all")))
+		case peer.NamespaceSelector != nil:
+			described = append(described, fmt.Sprintf("all pods in namespaces (%s)", describeSelector(peer.NamespaceSelector, "all")))
+		case peer.PodSelector != nil:
+			described = append(described, fmt.Sprintf("pods (%s) in the policy's namespace", describeSelector(peer.PodSelector, "all")))
+		}
+	}
+	return described
+}
+
+// describePorts renders the ports of a rule; a rule without ports matches every port
+func describePorts(ports []networkingv1.NetworkPolicyPort) []string {
+	if len(ports) == 0 {
+		return []string{"all ports"}
+	}
+	described := make([]string, 0, len(ports))
+	for _, port := range ports {
+		protocol := "TCP"
+		if port.Protocol != nil {
+			protocol = string(*port.Protocol)
+		}
+		switch {
+		case port.Port == nil:
+			described = append(described, protocol+"/all")
+		case port.EndPort != nil:
+			described = append(described, fmt.Sprintf("%s/%s-%d", protocol, port.Port.String(), *port.EndPort))
+		default:
+			described = append(described, protocol+"/"+port.Port.String())
+		}
+	}
+	return described
+}
+
+// ruleAllowsDNS reports whether an egress rule's ports include DNS; a rule without ports allows every port
+func ruleAllowsDNS(ports []networkingv1.NetworkPolicyPort) bool {
+	if len(ports) == 0 {
+		return true
+	}
+	for _, port := range ports {
+		if port.Port == nil || port.Port.IntValue() == 53 || strings.EqualFold(port.Port.String(), "dns") {
+			return true
+		}
+		if port.EndPort != nil && port.Port.IntValue() <= 53 && *port.EndPort >= 53 {
+			return true
+		}
+	}
+	return false
+}
+
+// describeSelector renders a label selector, naming an empty one with empty
+func describeSelector(selector *metav1.LabelSelector, empty string) string {
+	formatted := metav1.FormatLabelSelector(selector)
+	if formatted == "" || formatted == "<none>" {
+		return empty
+	}
+	return formatted
+}
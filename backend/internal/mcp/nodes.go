@@ -0,0 +1,228 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"kube-sherlock/internal/kubernetes"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxNodePods caps the pods listed by get_node_pods, unhealthy ones first
+const maxNodePods = 100
+
+// nodeSummary is the state of the node get_node_pods reports on
+type nodeSummary struct {
+	Name          string `json:"name"`
+	Ready         string `json:"ready"`
+	Unschedulable bool   `json:"unschedulable,omitempty"`
+	// Conditions lists the conditions other than Ready that are not False, such as MemoryPressure=True
+	Conditions     []string          `json:"conditions,omitempty"`
+	Taints         []string          `json:"taints,omitempty"`
+	KubeletVersion string            `json:"kubeletVersion,omitempty"`
+	Allocatable    map[string]string `json:"allocatable,omitempty"`
+}
+
+// nodePod is one pod scheduled to the node
+type nodePod struct {
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	Phase      string `json:"phase"`
+	Ready      string `json:"ready"`
+	Restarts   int32  `json:"restarts"`
+	Controller string `json:"controller,omitempty"`
+	Healthy    bool   `json:"healthy"`
+	Problem    string `json:"problem,omitempty"`
+	Severity   string `json:"severity,omitempty"`
+}
+
+// nodePodsReport is the result of get_node_pods
+type nodePodsReport struct {
+	// Node is omitted when the node couldn't be read; NodeError then says why
+	Node      *nodeSummary `json:"node,omitempty"`
+	NodeError string       `json:"nodeError,omitempty"`
+	Total     int          `json:"total"`
+	Unhealthy int          `json:"unhealthy"`
+	Pods      []nodePod    `json:"pods"`
+}
+
+// getNodePods lists the pods scheduled to a node, selected with a spec.nodeName field selector, together
+// with the node's conditions and taints, so "what's running on node X and is any of it unhealthy" is one call
+func (m *MCPService) getNodePods(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	nodeName := getStringParam(args, "nodeName", "")
+	namespace := getStringParam(args, "namespace", kubernetes.AllNamespaces)
+
+	if nodeName == "" {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "nodeName is required",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: nodeName is required", ErrInvalidArguments)
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	report := nodePodsReport{Pods: []nodePod{}}
+	node, err := m.k8sService.GetNode(ctx, nodeName)
+	switch {
+	case errors.Is(err, kubernetes.ErrNotFound):
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Node '%s' not found", nodeName),
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: node %s", kubernetes.ErrNotFound, nodeName)
+	case err != nil:
+		// Reading nodes needs cluster-scoped access; the pods can still be listed without it
+		report.NodeError = err.Error()
+	default:
+		report.Node = summarizeNode(node)
+	}
+
+	resources, err := m.k8sService.Gather(ctx, kubernetes.GatherOptions{
+		ResourceTypes:  []string{"pods"},
+		Namespace:      namespace,
+		FieldSelectors: map[string]string{"pods": "spec.nodeName=" + nodeName},
+		Minimize:       true,
+	})
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error gathering pods on node %s: %v", nodeName, err),
+			}},
+			IsError: true,
+		}, err
+	}
+	if msg, ok := resources.Resources["pods_error"].(string); ok {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error listing pods on node %s: %s", nodeName, msg),
+			}},
+			IsError: true,
+		}, fmt.Errorf("failed to list pods on node %s: %s", nodeName, msg)
+	}
+
+	if pods, ok := resources.Resources["pods"].(*v1.PodList); ok {
+		for i := range pods.Items {
+			pod := summarizeNodePod(&pods.Items[i])
+			if !pod.Healthy {
+				report.Unhealthy++
+			}
+			report.Pods = append(report.Pods, pod)
+		}
+	}
+	sort.SliceStable(report.Pods, func(i, j int) bool {
+		if report.Pods[i].Healthy != report.Pods[j].Healthy {
+			return !report.Pods[i].Healthy
+		}
+		if report.Pods[i].Namespace != report.Pods[j].Namespace {
+			return report.Pods[i].Namespace < report.Pods[j].Namespace
+		}
+		return report.Pods[i].Name < report.Pods[j].Name
+	})
+	report.Total = len(report.Pods)
+	if report.Total > maxNodePods {
+		report.Pods = report.Pods[:maxNodePods]
+	}
+
+	header := fmt.Sprintf("Pods on node '%s' (%d pods, %d unhealthy)", nodeName, report.Total, report.Unhealthy)
+	if namespace != kubernetes.AllNamespaces {
+		header = fmt.Sprintf("Pods on node '%s' in namespace '%s' (%d pods, %d unhealthy)", nodeName, namespace, report.Total, report.Unhealthy)
+	}
+	reportData, _ := json.MarshalIndent(report, "", "  ")
+	text := fmt.Sprintf("%s:\n\n%s", header, string(reportData))
+	if report.Total > maxNodePods {
+		text += fmt.Sprintf("\n\nShowing %d of %d pods", maxNodePods, report.Total)
+	}
+	if len(resources.Metadata.Truncated) > 0 {
+		text += "\n\nThe pod list hit the cluster-wide list limit, so some pods on the node may be missing"
+	}
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: text,
+		}},
+	}, nil
+}
+
+// summarizeNode reports a node's readiness, abnormal conditions, taints and allocatable resources
+func summarizeNode(node *v1.Node) *nodeSummary {
+	summary := &nodeSummary{
+		Name:           node.Name,
+		Ready:          string(v1.ConditionUnknown),
+		Unschedulable:  node.Spec.Unschedulable,
+		KubeletVersion: node.Status.NodeInfo.KubeletVersion,
+		Allocatable:    map[string]string{},
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			summary.Ready = string(condition.Status)
+			if condition.Status != v1.ConditionTrue && condition.Reason != "" {
+				summary.Ready += " (" + condition.Reason + ")"
+			}
+			continue
+		}
+		if condition.Status != v1.ConditionFalse {
+			summary.Conditions = append(summary.Conditions, fmt.Sprintf("%s=%s", condition.Type, condition.Status))
+		}
+	}
+	for _, taint := range node.Spec.Taints {
+		value := taint.Key
+		if taint.Value != "" {
+			value += "=" + taint.Value
+		}
+		summary.Taints = append(summary.Taints, value+":"+string(taint.Effect))
+	}
+	for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory, v1.ResourcePods} {
+		if quantity, ok := node.Status.Allocatable[name]; ok {
+			summary.Allocatable[string(name)] = quantity.String()
+		}
+	}
+	return summary
+}
+
+// summarizeNodePod reports a pod's status and, when it is unhealthy, what is wrong with it
+func summarizeNodePod(pod *v1.Pod) nodePod {
+	summary := nodePod{
+		Namespace: pod.Namespace,
+		Name:      pod.Name,
+		Phase:     string(pod.Status.Phase),
+		Healthy:   true,
+	}
+	ready := 0
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Ready {
+			ready++
+		}
+		summary.Restarts += status.RestartCount
+	}
+	summary.Ready = fmt.Sprintf("%d/%d", ready, len(pod.Spec.Containers))
+	if owner := metav1.GetControllerOf(pod); owner != nil {
+		summary.Controller = owner.Kind + "/" + owner.Name
+	}
+	if issue, unhealthy := podIssue(pod); unhealthy {
+		summary.Healthy = false
+		summary.Problem = issue.Problem
+		summary.Severity = issue.Severity
+	}
+	return summary
+}
@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"context"
+	"sort"
+
+	"kube-sherlock/internal/kubernetes"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// controllerPods counts the pods of one controller and names the unhealthy ones
+type controllerPods struct {
+	// Controller is the top-level owner, such as Deployment/api; empty for pods without one
+	Controller    string   `json:"controller"`
+	Namespace     string   `json:"namespace"`
+	Pods          int      `json:"pods"`
+	Unhealthy     int      `json:"unhealthy"`
+	UnhealthyPods []string `json:"unhealthyPods,omitempty"`
+}
+
+// podsByController groups pods by their top-level controller, controllers with the most unhealthy pods first
+func podsByController(ctx context.Context, resolver *kubernetes.OwnerResolver, pods *v1.PodList) []controllerPods {
+	groups := make(map[[2]string]*controllerPods)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		controller := resolver.Controller(ctx, pod.Namespace, pod.OwnerReferences).String()
+		key := [2]string{pod.Namespace, controller}
+		group, ok := groups[key]
+		if !ok {
+			group = &controllerPods{Controller: controller, Namespace: pod.Namespace}
+			groups[key] = group
+		}
+		group.Pods++
+		if !kubernetes.IsPodHealthy(pod) {
+			group.Unhealthy++
+			group.UnhealthyPods = append(group.UnhealthyPods, pod.Name)
+		}
+	}
+
+	result := make([]controllerPods, 0, len(groups))
+	for _, group := range groups {
+		if group.Controller == "" {
+			group.Controller = "(none)"
+		}
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Unhealthy != b.Unhealthy {
+			return a.Unhealthy > b.Unhealthy
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Controller < b.Controller
+	})
+	return result
+}
@@ -0,0 +1,199 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Models don't always send arguments with the type the schema declares: numbers arrive for string fields,
+// strings for booleans and comma-separated or half-written JSON for arrays. The getters below accept any
+// compatible form and fall back to the default for the rest; warnArgumentTypes logs each mismatch
+
+// getStringParam returns a string argument, formatting numbers and booleans as strings
+func getStringParam(args map[string]interface{}, key, defaultValue string) string {
+	if str, ok := stringValue(args[key]); ok {
+		return str
+	}
+	return defaultValue
+}
+
+// getIntParam returns an integer argument, truncating fractions and parsing numeric strings
+func getIntParam(args map[string]interface{}, key string, defaultValue int64) int64 {
+	switch v := args[key].(type) {
+	case float64:
+		return int64(v)
+	case int:
+		return int64(v)
+	case int64:
+		return v
+	case string:
+		text := strings.TrimSpace(v)
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n
+		}
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return int64(f)
+		}
+	}
+	return defaultValue
+}
+
+// getBoolParam returns a boolean argument. Strings such as "true", "1" or "yes" and the numbers 0 and 1
+// are accepted too
+func getBoolParam(args map[string]interface{}, key string, defaultValue bool) bool {
+	switch v := args[key].(type) {
+	case bool:
+		return v
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "t", "1", "yes", "y", "on":
+			return true
+		case "false", "f", "0", "no", "n", "off":
+			return false
+		}
+	case float64:
+		if v == 0 || v == 1 {
+			return v == 1
+		}
+	case int:
+		if v == 0 || v == 1 {
+			return v == 1
+		}
+	case int64:
+		if v == 0 || v == 1 {
+			return v == 1
+		}
+	}
+	return defaultValue
+}
+
+// getStringSliceParam returns a list argument. Besides arrays, it accepts a single value, a
+// comma-separated string and a JSON array in a string, including one cut off before its closing
+// bracket. Empty items are dropped; an argument with no items returns the default
+func getStringSliceParam(args map[string]interface{}, key string, defaultValue []string) []string {
+	var items []string
+	switch v := args[key].(type) {
+	case []string:
+		items = v
+	case []interface{}:
+		for _, item := range v {
+			if str, ok := stringValue(item); ok {
+				items = append(items, str)
+			}
+		}
+	case string:
+		items = splitListString(v)
+	default:
+		if str, ok := stringValue(v); ok {
+			items = []string{str}
+		}
+	}
+
+	var result []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	if len(result) == 0 {
+		return defaultValue
+	}
+	return result
+}
+
+// stringValue converts a scalar argument to a string; lists, objects and nil are not converted
+func stringValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int:
+		return strconv.Itoa(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// splitListString splits a string holding a list: a JSON array, possibly missing its end, or comma-separated values
+func splitListString(text string) []string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "[") {
+		return strings.Split(text, ",")
+	}
+
+	var items []interface{}
+	if err := json.Unmarshal([]byte(text), &items); err == nil {
+		var result []string
+		for _, item := range items {
+			if str, ok := stringValue(item); ok {
+				result = append(result, str)
+			}
+		}
+		return result
+	}
+
+	// A truncated or malformed array: keep the items, unquoted, dropping a last string that was cut off
+	truncated := !strings.HasSuffix(text, "]")
+	parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(text, "["), "]"), ",")
+	var result []string
+	for i, item := range parts {
+		item = strings.TrimSpace(item)
+		if unquoted, err := strconv.Unquote(item); err == nil {
+			item = unquoted
+		} else if truncated && i == len(parts)-1 && strings.HasPrefix(item, `"`) {
+			continue
+		} else {
+			item = strings.Trim(item, `"'`)
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+// argumentType names the JSON type of a decoded argument as a schema would
+func argumentType(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case float64, int, int64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}, []string:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case nil:
+		return "null"
+	}
+	return "unknown"
+}
+
+// warnArgumentTypes logs each argument whose type differs from the type the tool's schema declares. The
+// getters coerce such arguments where they can, so the call goes ahead either way
+func (m *MCPService) warnArgumentTypes(ctx context.Context, tool Tool, args map[string]interface{}) {
+	for key, value := range args {
+		property, ok := tool.InputSchema.Properties[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		expected, _ := property["type"].(string)
+		actual := argumentType(value)
+		if expected == "" || expected == actual || (expected == "integer" && actual == "number") {
+			continue
+		}
+		m.log(ctx).Warn("Coercing MCP tool argument to its declared type",
+			zap.String("tool", tool.Name),
+			zap.String("argument", key),
+			zap.String("expected", expected),
+			zap.String("actual", actual))
+	}
+}
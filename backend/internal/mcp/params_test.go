@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetStringParam(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "string", value: "shop", want: "shop"},
+		{name: "whole number", value: float64(8080), want: "8080"},
+		{name: "fraction", value: 1.5, want: "1.5"},
+		{name: "int", value: 42, want: "42"},
+		{name: "int64", value: int64(-7), want: "-7"},
+		{name: "bool", value: true, want: "true"},
+		{name: "empty string", value: "", want: ""},
+		{name: "missing", value: nil, want: "default"},
+		{name: "array", value: []interface{}{"a"}, want: "default"},
+		{name: "object", value: map[string]interface{}{"a": "b"}, want: "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]interface{}{}
+			if tt.value != nil {
+				args["key"] = tt.value
+			}
+			if got := getStringParam(args, "key", "default"); got != tt.want {
+				t.Errorf("getStringParam(%#v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBoolParam(t *testing.T) {
+	tests := []struct {
+		name         string
+		value        interface{}
+		defaultValue bool
+		want         bool
+	}{
+		{name: "true", value: true, want: true},
+		{name: "false", value: false, defaultValue: true, want: false},
+		{name: "string true", value: "true", want: true},
+		{name: "string TRUE with spaces", value: "  TRUE ", want: true},
+		{name: "string yes", value: "yes", want: true},
+		{name: "string on", value: "on", want: true},
+		{name: "string 1", value: "1", want: true},
+		{name: "string false", value: "false", defaultValue: true, want: false},
+		{name: "string no", value: "No", defaultValue: true, want: false},
+		{name: "string 0", value: "0", defaultValue: true, want: false},
+		{name: "number 1", value: float64(1), want: true},
+		{name: "number 0", value: float64(0), defaultValue: true, want: false},
+		{name: "int 1", value: 1, want: true},
+		{name: "int64 0", value: int64(0), defaultValue: true, want: false},
+		{name: "unrecognized string", value: "maybe", defaultValue: true, want: true},
+		{name: "number other than 0 or 1", value: float64(2), want: false},
+		{name: "array", value: []interface{}{true}, defaultValue: true, want: true},
+		{name: "missing", value: nil, defaultValue: true, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]interface{}{}
+			if tt.value != nil {
+				args["key"] = tt.value
+			}
+			if got := getBoolParam(args, "key", tt.defaultValue); got != tt.want {
+				t.Errorf("getBoolParam(%#v, default %v) = %v, want %v", tt.value, tt.defaultValue, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetStringSliceParam(t *testing.T) {
+	defaultValue := []string{"default"}
+	tests := []struct {
+		name  string
+		value interface{}
+		want  []string
+	}{
+		{name: "string slice", value: []string{"pods", "events"}, want: []string{"pods", "events"}},
+		{name: "array", value: []interface{}{"pods", "events"}, want: []string{"pods", "events"}},
+		{name: "array of mixed scalars", value: []interface{}{"pods", float64(3), true}, want: []string{"pods", "3", "true"}},
+		{name: "array skipping nested values", value: []interface{}{"pods", map[string]interface{}{}, nil}, want: []string{"pods"}},
+		{name: "comma-separated string", value: "pods,events", want: []string{"pods", "events"}},
+		{name: "comma-separated string with spaces and empty items", value: " pods , ,events, ", want: []string{"pods", "events"}},
+		{name: "single string", value: "pods", want: []string{"pods"}},
+		{name: "JSON array in a string", value: `["pods", "events"]`, want: []string{"pods", "events"}},
+		{name: "truncated JSON array", value: `["pods", "events", "depl`, want: []string{"pods", "events"}},
+		{name: "JSON array missing its bracket", value: `["pods", "events"`, want: []string{"pods", "events"}},
+		{name: "single number", value: float64(5), want: []string{"5"}},
+		{name: "empty string", value: "", want: defaultValue},
+		{name: "empty array", value: []interface{}{}, want: defaultValue},
+		{name: "object", value: map[string]interface{}{"a": "b"}, want: defaultValue},
+		{name: "missing", value: nil, want: defaultValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]interface{}{}
+			if tt.value != nil {
+				args["key"] = tt.value
+			}
+			if got := getStringSliceParam(args, "key", defaultValue); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getStringSliceParam(%#v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
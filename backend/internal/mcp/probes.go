@@ -0,0 +1,394 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"kube-sherlock/internal/kubernetes"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// maxProbeContainers bounds how many containers diagnose_probes reports
+const maxProbeContainers = 50
+
+// Probe types reported by diagnose_probes, matching the kubelet's event messages
+const (
+	probeLiveness  = "liveness"
+	probeReadiness = "readiness"
+	probeStartup   = "startup"
+)
+
+// probeSummary describes one probe and whether it has been failing
+type probeSummary struct {
+	Type                string   `json:"type"`
+	Check               string   `json:"check"`
+	InitialDelaySeconds int32    `json:"initialDelaySeconds"`
+	PeriodSeconds       int32    `json:"periodSeconds"`
+	TimeoutSeconds      int32    `json:"timeoutSeconds"`
+	FailureThreshold    int32    `json:"failureThreshold"`
+	Firing              bool     `json:"firing"`
+	Failures            int32    `json:"recentFailures,omitempty"`
+	LastFailure         string   `json:"lastFailure,omitempty"`
+	LastFailureAt       string   `json:"lastFailureAt,omitempty"`
+	Warnings            []string `json:"warnings,omitempty"`
+}
+
+// containerProbeReport lists a container's probes alongside its readiness and restart history
+type containerProbeReport struct {
+	Pod                   string         `json:"pod"`
+	Controller            string         `json:"controller,omitempty"`
+	Container             string         `json:"container"`
+	Ready                 bool           `json:"ready"`
+	RestartCount          int32          `json:"restartCount"`
+	LastTerminationReason string         `json:"lastTerminationReason,omitempty"`
+	LastTerminationExit   *int32         `json:"lastTerminationExitCode,omitempty"`
+	KilledByLiveness      int32          `json:"killedByLivenessProbe,omitempty"`
+	Probes                []probeSummary `json:"probes"`
+	MissingProbes         []string       `json:"missingProbes,omitempty"`
+}
+
+// probeFailures aggregates the kubelet's Unhealthy events for one probe of one container
+type probeFailures struct {
+	count   int32
+	message string
+	last    time.Time
+}
+
+// diagnoseProbes reports probe configuration for matching pods, cross-referenced with probe failure
+// events and restarts, with firing probes listed first
+func (m *MCPService) diagnoseProbes(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+	labelSelector := getStringParam(args, "labelSelector", "")
+	podName := getStringParam(args, "podName", "")
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	resources, err := m.gather(ctx, []string{"pods"}, namespace, labelSelector)
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error gathering pods: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+	if msg, ok := resources.Resources["pods_error"].(string); ok {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error listing pods: %s", msg),
+			}},
+			IsError: true,
+		}, fmt.Errorf("failed to list pods: %s", msg)
+	}
+
+	// Events carry no pod labels, so they are matched to the pods by name and container field path
+	eventResources, err := m.gather(ctx, []string{"events"}, namespace, "")
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error gathering events: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+
+	events, _ := eventResources.Resources["events"].(*v1.EventList)
+	failures, kills := indexProbeEvents(events)
+
+	var reports []containerProbeReport
+	if pods, ok := resources.Resources["pods"].(*v1.PodList); ok {
+		resolver := m.k8sService.NewOwnerResolver()
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if podName != "" && pod.Name != podName {
+				continue
+			}
+			controller := resolver.Controller(ctx, pod.Namespace, pod.OwnerReferences).String()
+			for _, report := range podProbeReports(pod, failures, kills) {
+				report.Controller = controller
+				reports = append(reports, report)
+			}
+		}
+	}
+	if len(reports) == 0 {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("No matching pods in namespace '%s'", namespace),
+			}},
+		}, nil
+	}
+
+	// Firing probes first, then containers that restarted
+	sort.SliceStable(reports, func(i, j int) bool {
+		fi, fj := reports[i].firing(), reports[j].firing()
+		if fi != fj {
+			return fi
+		}
+		return reports[i].RestartCount > reports[j].RestartCount
+	})
+
+	text := ""
+	if len(reports) > maxProbeContainers {
+		text = fmt.Sprintf("Showing %d of %d containers; narrow the query with labelSelector or podName.\n\n", maxProbeContainers, len(reports))
+		reports = reports[:maxProbeContainers]
+	}
+	reportData, _ := json.MarshalIndent(reports, "", "  ")
+	text = fmt.Sprintf("Probe diagnostics for namespace '%s':\n\n%s%s", namespace, text, string(reportData))
+	if msg, ok := eventResources.Resources["events_error"].(string); ok {
+		text += fmt.Sprintf("\n\nErrors:\nevents: %s (probe failures could not be checked)", msg)
+	}
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: text,
+		}},
+	}, nil
+}
+
+// firing reports whether any of the container's probes has recent failures
+func (r containerProbeReport) firing() bool {
+	for _, probe := range r.Probes {
+		if probe.Firing {
+			return true
+		}
+	}
+	return false
+}
+
+// probeEventKey identifies a probe of a container in a pod
+type probeEventKey struct {
+	pod       types.NamespacedName
+	container string
+	probe     string
+}
+
+// indexProbeEvents aggregates Unhealthy events per probe and counts liveness restarts per container
+func indexProbeEvents(events *v1.EventList) (map[probeEventKey]*probeFailures, map[probeEventKey]int32) {
+	failures := make(map[probeEventKey]*probeFailures)
+	kills := make(map[probeEventKey]int32)
+	if events == nil {
+		return failures, kills
+	}
+
+	for i := range events.Items {
+		event := &events.Items[i]
+		if event.InvolvedObject.Kind != "Pod" {
+			continue
+		}
+		key := probeEventKey{
+			pod:       types.NamespacedName{Namespace: event.InvolvedObject.Namespace, Name: event.InvolvedObject.Name},
+			container: fieldPathContainer(event.InvolvedObject.FieldPath),
+		}
+		count := event.Count
+		if count == 0 {
+			count = 1
+		}
+
+		switch {
+		case event.Reason == "Unhealthy":
+			key.probe = probeTypeFromMessage(event.Message)
+			if key.probe == "" {
+				continue
+			}
+			entry, ok := failures[key]
+			if !ok {
+				entry = &probeFailures{}
+				failures[key] = entry
+			}
+			entry.count += count
+			if t := kubernetes.EventTime(event).Time; !ok || t.After(entry.last) {
+				entry.last = t
+				entry.message = event.Message
+			}
+		case event.Reason == "Killing" && strings.Contains(event.Message, "failed liveness probe"):
+			key.probe = probeLiveness
+			kills[key] += count
+		}
+	}
+	return failures, kills
+}
+
+// fieldPathContainer extracts the container name from an event field path such as spec.containers{app}
+func fieldPathContainer(fieldPath string) string {
+	start := strings.Index(fieldPath, "{")
+	end := strings.LastIndex(fieldPath, "}")
+	if start == -1 || end <= start {
+		return ""
+	}
+	return fieldPath[start+1 : end]
+}
+
+// probeTypeFromMessage returns the probe named by a kubelet message like "Liveness probe failed: ..."
+func probeTypeFromMessage(message string) string {
+	lower := strings.ToLower(message)
+	for _, probe := range []string{probeLiveness, probeReadiness, probeStartup} {
+		if strings.HasPrefix(lower, probe+" probe") {
+			return probe
+		}
+	}
+	return ""
+}
+
+// podProbeReports builds a probe report for each container in a pod
+func podProbeReports(pod *v1.Pod, failures map[probeEventKey]*probeFailures, kills map[probeEventKey]int32) []containerProbeReport {
+	statuses := statusesByName(pod.Status.ContainerStatuses)
+	podKey := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+
+	reports := make([]containerProbeReport, 0, len(pod.Spec.Containers))
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		report := containerProbeReport{
+			Pod:              pod.Name,
+			Container:        container.Name,
+			KilledByLiveness: kills[probeEventKey{pod: podKey, container: container.Name, probe: probeLiveness}],
+			Probes:           []probeSummary{},
+		}
+		if status := statuses[container.Name]; status != nil {
+			report.Ready = status.Ready
+			report.RestartCount = status.RestartCount
+			if last := status.LastTerminationState.Terminated; last != nil {
+				report.LastTerminationReason = last.Reason
+				exitCode := last.ExitCode
+				report.LastTerminationExit = &exitCode
+			}
+		}
+
+		probes := []struct {
+			name  string
+			probe *v1.Probe
+		}{
+			{probeStartup, container.StartupProbe},
+			{probeLiveness, container.LivenessProbe},
+			{probeReadiness, container.ReadinessProbe},
+		}
+		for _, p := range probes {
+			if p.probe == nil {
+				if p.name != probeStartup {
+					report.MissingProbes = append(report.MissingProbes, p.name)
+				}
+				continue
+			}
+			summary := summarizeProbe(p.name, p.probe)
+			if failure := failures[probeEventKey{pod: podKey, container: container.Name, probe: p.name}]; failure != nil {
+				summary.Firing = true
+				summary.Failures = failure.count
+				summary.LastFailure = failure.message
+				if !failure.last.IsZero() {
+					summary.LastFailureAt = failure.last.UTC().Format(time.RFC3339)
+				}
+			}
+			summary.Warnings = probeWarnings(&summary, p.probe, container, report)
+			report.Probes = append(report.Probes, summary)
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// summarizeProbe describes a probe's check and timing
+func summarizeProbe(probeType string, probe *v1.Probe) probeSummary {
+	summary := probeSummary{
+		Type:                probeType,
+		InitialDelaySeconds: probe.InitialDelaySeconds,
+		PeriodSeconds:       probe.PeriodSeconds,
+		TimeoutSeconds:      probe.TimeoutSeconds,
+		FailureThreshold:    probe.FailureThreshold,
+	}
+
+	handler := probe.ProbeHandler
+	switch {
+	case handler.HTTPGet != nil:
+		scheme := string(handler.HTTPGet.Scheme)
+		if scheme == "" {
+			scheme = "HTTP"
+		}
+		summary.Check = fmt.Sprintf("%s GET :%s%s", scheme, handler.HTTPGet.Port.String(), handler.HTTPGet.Path)
+	case handler.TCPSocket != nil:
+		summary.Check = fmt.Sprintf("TCP :%s", handler.TCPSocket.Port.String())
+	case handler.GRPC != nil:
+		summary.Check = fmt.Sprintf("gRPC :%d", handler.GRPC.Port)
+	case handler.Exec != nil:
+		summary.Check = "exec: " + strings.Join(handler.Exec.Command, " ")
+	default:
+		summary.Check = "unknown"
+	}
+	return summary
+}
+
+// probeWarnings flags common probe misconfigurations, taking the container's restart history into account
+func probeWarnings(probe *probeSummary, spec *v1.Probe, container *v1.Container, report containerProbeReport) []string {
+	var warnings []string
+
+	lastFailure := strings.ToLower(probe.LastFailure)
+	if probe.Firing && probe.TimeoutSeconds <= 1 &&
+		(strings.Contains(lastFailure, "timeout") || strings.Contains(lastFailure, "deadline exceeded")) {
+		warnings = append(warnings, fmt.Sprintf("probe times out after %ds; the endpoint may just be slow, consider raising timeoutSeconds", probe.TimeoutSeconds))
+	}
+	if strings.Contains(lastFailure, "connection refused") {
+		warnings = append(warnings, "connection refused: nothing is listening on the probed port yet, or the port is wrong")
+	}
+	if strings.Contains(lastFailure, "statuscode: 404") {
+		warnings = append(warnings, "endpoint returned 404: the probe path is probably wrong")
+	}
+
+	if probe.Type == probeLiveness {
+		window := probe.PeriodSeconds * probe.FailureThreshold
+		if window > 0 && window < 10 {
+			warnings = append(warnings, fmt.Sprintf("container is restarted after only %ds of failed checks (periodSeconds × failureThreshold)", window))
+		}
+		if container.StartupProbe == nil && probe.InitialDelaySeconds == 0 && report.RestartCount > 0 {
+			warnings = append(warnings, "liveness probe starts immediately and there is no startupProbe; a slow-starting app is killed before it is up")
+		}
+		if report.KilledByLiveness > 0 {
+			warnings = append(warnings, fmt.Sprintf("kubelet restarted the container %d time(s) after this probe failed", report.KilledByLiveness))
+		}
+	}
+
+	if port := undefinedProbePort(spec, container); port != "" {
+		warnings = append(warnings, fmt.Sprintf("probe uses port name %q, which no container port defines", port))
+	}
+	return warnings
+}
+
+// undefinedProbePort returns the named port an HTTP or TCP probe targets when the container defines no
+// port with that name
+func undefinedProbePort(probe *v1.Probe, container *v1.Container) string {
+	var port intstr.IntOrString
+	switch {
+	case probe.HTTPGet != nil:
+		port = probe.HTTPGet.Port
+	case probe.TCPSocket != nil:
+		port = probe.TCPSocket.Port
+	default:
+		return ""
+	}
+	if port.Type != intstr.String {
+		return ""
+	}
+	for _, containerPort := range container.Ports {
+		if containerPort.Name == port.StrVal {
+			return ""
+		}
+	}
+	return port.StrVal
+}
@@ -0,0 +1,163 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"kube-sherlock/internal/kubernetes"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// pullSecretWiringReport is the result of check_image_pull_secrets
+type pullSecretWiringReport struct {
+	Pod            string `json:"pod,omitempty"`
+	ServiceAccount string `json:"serviceAccount"`
+	// ServiceAccountFound is false when the service account doesn't exist or couldn't be read
+	ServiceAccountFound bool `json:"serviceAccountFound"`
+	// PodPullSecrets are the imagePullSecrets in the pod spec, including those copied from the service
+	// account when the pod was created
+	PodPullSecrets            []pullSecretStatus `json:"podPullSecrets,omitempty"`
+	ServiceAccountPullSecrets []pullSecretStatus `json:"serviceAccountPullSecrets"`
+	Problems                  []string           `json:"problems,omitempty"`
+}
+
+// checkImagePullSecrets reports how a pod or service account is wired to registry credentials: the pod's
+// service account, the imagePullSecrets on both, and whether each secret exists and holds registry
+// credentials. Secret contents are never read into the result
+func (m *MCPService) checkImagePullSecrets(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+	podName := getStringParam(args, "podName", "")
+	serviceAccountName := getStringParam(args, "serviceAccount", "")
+
+	if podName == "" && serviceAccountName == "" {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Either podName or serviceAccount is required",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: podName or serviceAccount is required", ErrInvalidArguments)
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	report := pullSecretWiringReport{ServiceAccountPullSecrets: []pullSecretStatus{}}
+	secrets := make(map[string]pullSecretStatus)
+
+	var pod *v1.Pod
+	if podName != "" {
+		var err error
+		pod, err = m.k8sService.GetPod(ctx, namespace, podName)
+		if err != nil {
+			return &ToolResult{
+				Content: []ToolContent{{
+					Type: "text",
+					Text: fmt.Sprintf("Error getting pod: %v", err),
+				}},
+				IsError: true,
+			}, err
+		}
+		report.Pod = pod.Name
+		// A pod always runs as its own service account, whatever was asked for
+		serviceAccountName = pod.Spec.ServiceAccountName
+		if serviceAccountName == "" {
+			serviceAccountName = "default"
+		}
+		report.PodPullSecrets = m.checkPullSecrets(ctx, pod, secrets)
+	}
+	report.ServiceAccount = serviceAccountName
+
+	serviceAccount, err := m.k8sService.GetServiceAccount(ctx, namespace, serviceAccountName)
+	switch {
+	case errors.Is(err, kubernetes.ErrNotFound):
+		report.Problems = append(report.Problems, fmt.Sprintf("service account '%s' does not exist; new pods using it are rejected, so create it or fix serviceAccountName", serviceAccountName))
+	case err != nil && pod == nil:
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error getting service account: %v", err),
+			}},
+			IsError: true,
+		}, err
+	case err != nil:
+		report.Problems = append(report.Problems, fmt.Sprintf("service account '%s' could not be checked: %v", serviceAccountName, err))
+	default:
+		report.ServiceAccountFound = true
+		report.ServiceAccountPullSecrets = m.checkPullSecretRefs(ctx, namespace, serviceAccount.ImagePullSecrets, secrets)
+	}
+
+	report.Problems = append(report.Problems, pullSecretProblems(report)...)
+
+	subject := fmt.Sprintf("pod '%s' (service account '%s')", podName, serviceAccountName)
+	if pod == nil {
+		subject = fmt.Sprintf("service account '%s'", serviceAccountName)
+	}
+	reportData, _ := json.MarshalIndent(report, "", "  ")
+	text := fmt.Sprintf("Image pull secrets for %s in namespace '%s' (%d problems):\n\n%s",
+		subject, namespace, len(report.Problems), string(reportData))
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: text,
+		}},
+	}, nil
+}
+
+// pullSecretProblems flags pull secrets that are missing or hold no registry credentials, service account
+// pull secrets a pod doesn't have, and pods or service accounts with no pull secrets at all
+func pullSecretProblems(report pullSecretWiringReport) []string {
+	var problems []string
+	onPod := make(map[string]bool, len(report.PodPullSecrets))
+	for _, secret := range report.PodPullSecrets {
+		onPod[secret.Name] = true
+		if problem := pullSecretProblem("the pod", secret); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+	for _, secret := range report.ServiceAccountPullSecrets {
+		// The pod's own entry already reports a broken secret both reference
+		if onPod[secret.Name] {
+			continue
+		}
+		if problem := pullSecretProblem("the service account", secret); problem != "" {
+			problems = append(problems, problem)
+		}
+		if report.Pod != "" {
+			problems = append(problems, fmt.Sprintf("imagePullSecret '%s' is on the service account but not the pod; service account pull secrets are only copied into pods when they are created, so recreate the pod to use it", secret.Name))
+		}
+	}
+
+	switch {
+	case report.Pod != "" && len(report.PodPullSecrets) == 0 && len(report.ServiceAccountPullSecrets) == 0:
+		problems = append(problems, "neither the pod nor its service account has imagePullSecrets; pulls from a private registry only work if the nodes have credentials for it")
+	case report.Pod == "" && report.ServiceAccountFound && len(report.ServiceAccountPullSecrets) == 0:
+		problems = append(problems, "the service account has no imagePullSecrets; pods using it need their own to pull from a private registry")
+	}
+	return problems
+}
+
+// pullSecretProblem describes what is wrong with a pull secret referenced by owner, or returns an empty string
+func pullSecretProblem(owner string, secret pullSecretStatus) string {
+	switch secret.Status {
+	case secretRefOK:
+		return ""
+	case secretRefNotFound:
+		return fmt.Sprintf("imagePullSecret '%s' on %s does not exist; the kubelet pulls without it", secret.Name, owner)
+	case pullSecretWrongType:
+		return fmt.Sprintf("imagePullSecret '%s' on %s is not a registry credential (%s); create it with kubectl create secret docker-registry", secret.Name, owner, secret.Detail)
+	default:
+		return fmt.Sprintf("imagePullSecret '%s' on %s could not be checked: %s", secret.Name, owner, secret.Detail)
+	}
+}
@@ -0,0 +1,272 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"kube-sherlock/internal/kubernetes"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// nearQuotaPercent is the usage at which a quota resource is flagged as nearly exhausted
+const nearQuotaPercent = 90
+
+// Quota usage states reported by get_resource_quotas
+const (
+	quotaOK        = "ok"
+	quotaNear      = "near"
+	quotaExhausted = "exhausted"
+)
+
+// quotaUsage compares one resource's used amount with its hard limit
+type quotaUsage struct {
+	Resource string `json:"resource"`
+	Hard     string `json:"hard"`
+	Used     string `json:"used"`
+	Percent  int    `json:"percent"`
+	Status   string `json:"status"`
+}
+
+// quotaReport summarizes a ResourceQuota
+type quotaReport struct {
+	Name   string       `json:"name"`
+	Scopes []string     `json:"scopes,omitempty"`
+	Usage  []quotaUsage `json:"usage"`
+}
+
+// limitRangeLimit is the constraint a LimitRange places on one resource of one object type
+type limitRangeLimit struct {
+	Type                 string `json:"type"`
+	Resource             string `json:"resource"`
+	Default              string `json:"default,omitempty"`
+	DefaultRequest       string `json:"defaultRequest,omitempty"`
+	Min                  string `json:"min,omitempty"`
+	Max                  string `json:"max,omitempty"`
+	MaxLimitRequestRatio string `json:"maxLimitRequestRatio,omitempty"`
+}
+
+// limitRangeReport summarizes a LimitRange
+type limitRangeReport struct {
+	Name   string            `json:"name"`
+	Limits []limitRangeLimit `json:"limits"`
+}
+
+// resourceQuotasReport is the result of get_resource_quotas
+type resourceQuotasReport struct {
+	Namespace   string             `json:"namespace"`
+	Quotas      []quotaReport      `json:"quotas"`
+	LimitRanges []limitRangeReport `json:"limitRanges"`
+	Warnings    []string           `json:"warnings,omitempty"`
+}
+
+// getResourceQuotas reports each ResourceQuota's hard limits against current usage and the defaults and
+// bounds set by LimitRanges, flagging quotas that are nearly or fully used
+func (m *MCPService) getResourceQuotas(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	resourceTypes := []string{"resourcequotas", "limitranges"}
+	resources, err := m.gather(ctx, resourceTypes, namespace, "")
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error gathering resource quotas: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+	for _, resourceType := range resourceTypes {
+		if msg, ok := resources.Resources[resourceType+"_error"].(string); ok {
+			return &ToolResult{
+				Content: []ToolContent{{
+					Type: "text",
+					Text: fmt.Sprintf("Error listing %s: %s", resourceType, msg),
+				}},
+				IsError: true,
+			}, fmt.Errorf("failed to list %s: %s", resourceType, msg)
+		}
+	}
+
+	quotas, _ := resources.Resources["resourcequotas"].(*v1.ResourceQuotaList)
+	limitRanges, _ := resources.Resources["limitranges"].(*v1.LimitRangeList)
+	if (quotas == nil || len(quotas.Items) == 0) && (limitRanges == nil || len(limitRanges.Items) == 0) {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("No ResourceQuotas or LimitRanges in namespace '%s'; resource usage there is not limited by quota", namespace),
+			}},
+		}, nil
+	}
+
+	report := resourceQuotasReport{Namespace: namespace, Quotas: []quotaReport{}, LimitRanges: []limitRangeReport{}}
+	containerDefaults := make(map[v1.ResourceName]bool)
+	if limitRanges != nil {
+		for i := range limitRanges.Items {
+			summary := summarizeLimitRange(&limitRanges.Items[i])
+			for _, limit := range summary.Limits {
+				if limit.Type == string(v1.LimitTypeContainer) && (limit.Default != "" || limit.DefaultRequest != "") {
+					containerDefaults[v1.ResourceName(limit.Resource)] = true
+				}
+			}
+			report.LimitRanges = append(report.LimitRanges, summary)
+		}
+	}
+	if quotas != nil {
+		for i := range quotas.Items {
+			quota := &quotas.Items[i]
+			summary := summarizeQuota(quota)
+			for _, usage := range summary.Usage {
+				switch usage.Status {
+				case quotaExhausted:
+					report.Warnings = append(report.Warnings, fmt.Sprintf("ResourceQuota %s: %s is exhausted (%s of %s used); new objects that need more are rejected",
+						quota.Name, usage.Resource, usage.Used, usage.Hard))
+				case quotaNear:
+					report.Warnings = append(report.Warnings, fmt.Sprintf("ResourceQuota %s: %s is at %d%% (%s of %s used)",
+						quota.Name, usage.Resource, usage.Percent, usage.Used, usage.Hard))
+				}
+			}
+			report.Warnings = append(report.Warnings, missingDefaultWarnings(quota, containerDefaults)...)
+			report.Quotas = append(report.Quotas, summary)
+		}
+	}
+
+	reportData, _ := json.MarshalIndent(report, "", "  ")
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: fmt.Sprintf("Resource quotas and limit ranges in namespace '%s' (%d quotas, %d limit ranges, %d warnings):\n\n%s",
+				namespace, len(report.Quotas), len(report.LimitRanges), len(report.Warnings), string(reportData)),
+		}},
+	}, nil
+}
+
+// summarizeQuota compares each hard limit of a quota with its usage, sorted by resource name
+func summarizeQuota(quota *v1.ResourceQuota) quotaReport {
+	summary := quotaReport{Name: quota.Name, Usage: []quotaUsage{}}
+	for _, scope := range quota.Spec.Scopes {
+		summary.Scopes = append(summary.Scopes, string(scope))
+	}
+
+	// Status.Hard is what the quota controller enforces; Spec.Hard is used until it has synced
+	hard := quota.Status.Hard
+	if len(hard) == 0 {
+		hard = quota.Spec.Hard
+	}
+	for name, limit := range hard {
+		used := quota.Status.Used[name]
+		summary.Usage = append(summary.Usage, quotaUsage{
+			Resource: string(name),
+			Hard:     limit.String(),
+			Used:     used.String(),
+			Percent:  quotaPercent(used, limit),
+			Status:   quotaStatus(used, limit),
+		})
+	}
+	sort.Slice(summary.Usage, func(i, j int) bool {
+		return summary.Usage[i].Resource < summary.Usage[j].Resource
+	})
+	return summary
+}
+
+// quotaPercent returns used as a percentage of hard, or 100 for a zero hard limit
+func quotaPercent(used, hard resource.Quantity) int {
+	if hard.IsZero() {
+		return 100
+	}
+	return int(used.AsApproximateFloat64() / hard.AsApproximateFloat64() * 100)
+}
+
+// quotaStatus classifies usage of a quota resource; a zero hard limit allows nothing and counts as exhausted
+func quotaStatus(used, hard resource.Quantity) string {
+	switch {
+	case used.Cmp(hard) >= 0:
+		return quotaExhausted
+	case quotaPercent(used, hard) >= nearQuotaPercent:
+		return quotaNear
+	default:
+		return quotaOK
+	}
+}
+
+// missingDefaultWarnings flags compute resources a quota tracks that no LimitRange defaults. Pods that
+// don't set them on every container are rejected by the quota admission check
+func missingDefaultWarnings(quota *v1.ResourceQuota, containerDefaults map[v1.ResourceName]bool) []string {
+	var warnings []string
+	for name := range quota.Spec.Hard {
+		var tracked v1.ResourceName
+		kind := "request"
+		switch name {
+		case v1.ResourceCPU, v1.ResourceRequestsCPU:
+			tracked = v1.ResourceCPU
+		case v1.ResourceMemory, v1.ResourceRequestsMemory:
+			tracked = v1.ResourceMemory
+		case v1.ResourceLimitsCPU:
+			tracked, kind = v1.ResourceCPU, "limit"
+		case v1.ResourceLimitsMemory:
+			tracked, kind = v1.ResourceMemory, "limit"
+		default:
+			continue
+		}
+		if !containerDefaults[tracked] {
+			warnings = append(warnings, fmt.Sprintf("ResourceQuota %s tracks %s and no LimitRange sets a default; every container must set a %s %s or its pod is rejected",
+				quota.Name, name, tracked, kind))
+		}
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// summarizeLimitRange flattens a LimitRange into one entry per object type and resource
+func summarizeLimitRange(limitRange *v1.LimitRange) limitRangeReport {
+	summary := limitRangeReport{Name: limitRange.Name, Limits: []limitRangeLimit{}}
+	for _, item := range limitRange.Spec.Limits {
+		var names []string
+		seen := make(map[v1.ResourceName]bool)
+		for _, list := range []v1.ResourceList{item.Default, item.DefaultRequest, item.Min, item.Max, item.MaxLimitRequestRatio} {
+			for name := range list {
+				if !seen[name] {
+					seen[name] = true
+					names = append(names, string(name))
+				}
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			resourceName := v1.ResourceName(name)
+			summary.Limits = append(summary.Limits, limitRangeLimit{
+				Type:                 string(item.Type),
+				Resource:             name,
+				Default:              quantityString(item.Default, resourceName),
+				DefaultRequest:       quantityString(item.DefaultRequest, resourceName),
+				Min:                  quantityString(item.Min, resourceName),
+				Max:                  quantityString(item.Max, resourceName),
+				MaxLimitRequestRatio: quantityString(item.MaxLimitRequestRatio, resourceName),
+			})
+		}
+	}
+	return summary
+}
+
+// quantityString returns the named quantity from a resource list, or an empty string when it isn't set
+func quantityString(list v1.ResourceList, name v1.ResourceName) string {
+	quantity, ok := list[name]
+	if !ok {
+		return ""
+	}
+	return quantity.String()
+}
@@ -0,0 +1,282 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"kube-sherlock/internal/kubernetes"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// rbacBindingSummary describes a binding and the rules it grants
+type rbacBindingSummary struct {
+	Kind      string   `json:"kind"`
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace,omitempty"`
+	RoleRef   string   `json:"roleRef"`
+	Subjects  []string `json:"subjects"`
+	Rules     []string `json:"rules,omitempty"`
+}
+
+// accessCheckResult reports the outcome of an access review
+type accessCheckResult struct {
+	Subject         string `json:"subject"`
+	Verb            string `json:"verb"`
+	Resource        string `json:"resource"`
+	Namespace       string `json:"namespace"`
+	Allowed         bool   `json:"allowed"`
+	Denied          bool   `json:"denied,omitempty"`
+	Reason          string `json:"reason,omitempty"`
+	EvaluationError string `json:"evaluationError,omitempty"`
+}
+
+// getRBACStatus lists the RBAC bindings relevant to a namespace or service account and optionally checks a permission
+func (m *MCPService) getRBACStatus(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+	serviceAccount := getStringParam(args, "serviceAccount", "")
+	verb := getStringParam(args, "verb", "")
+	resource := getStringParam(args, "resource", "")
+
+	if (verb == "") != (resource == "") {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Both verb and resource are required to check a permission",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: verb and resource must be given together", ErrInvalidArguments)
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	subject := "the kube-sherlock credentials"
+	if serviceAccount != "" {
+		subject = fmt.Sprintf("service account '%s'", serviceAccount)
+	}
+
+	var sections []string
+
+	// Answer the specific question first; it is usually what the caller needs
+	if verb != "" {
+		check := kubernetes.AccessCheck{
+			Namespace:      namespace,
+			ServiceAccount: serviceAccount,
+			Verb:           verb,
+			Group:          getStringParam(args, "group", ""),
+			Resource:       resource,
+			Subresource:    getStringParam(args, "subresource", ""),
+			Name:           getStringParam(args, "resourceName", ""),
+		}
+		status, err := m.k8sService.CheckAccess(ctx, check)
+		if err != nil {
+			return &ToolResult{
+				Content: []ToolContent{{
+					Type: "text",
+					Text: fmt.Sprintf("Error checking access: %v", err),
+				}},
+				IsError: true,
+			}, err
+		}
+
+		result := accessCheckResult{
+			Subject:         subject,
+			Verb:            verb,
+			Resource:        formatGroupResource(check.Group, resource),
+			Namespace:       namespace,
+			Allowed:         status.Allowed,
+			Denied:          status.Denied,
+			Reason:          status.Reason,
+			EvaluationError: status.EvaluationError,
+		}
+		if check.Subresource != "" {
+			result.Resource += "/" + check.Subresource
+		}
+		resultData, _ := json.MarshalIndent(result, "", "  ")
+
+		verdict := "ALLOWED"
+		if !status.Allowed {
+			verdict = "NOT ALLOWED"
+		}
+		sections = append(sections, fmt.Sprintf("Access check: %s to %s %s in namespace '%s' is %s\n%s",
+			subject, verb, result.Resource, namespace, verdict, string(resultData)))
+	}
+
+	bindings, err := m.rbacBindings(ctx, namespace, serviceAccount)
+	if err != nil {
+		// Reading RBAC objects needs more permissions than an access review; keep the review result if we have one
+		if len(sections) == 0 {
+			return &ToolResult{
+				Content: []ToolContent{{
+					Type: "text",
+					Text: fmt.Sprintf("Error listing RBAC bindings: %v", err),
+				}},
+				IsError: true,
+			}, err
+		}
+		sections = append(sections, fmt.Sprintf("Bindings could not be listed: %v", err))
+	} else {
+		bindingsData, _ := json.MarshalIndent(bindings, "", "  ")
+		scope := fmt.Sprintf("RoleBindings in namespace '%s'", namespace)
+		if serviceAccount != "" {
+			scope = fmt.Sprintf("RoleBindings and ClusterRoleBindings granting %s in namespace '%s'", subject, namespace)
+		}
+		sections = append(sections, fmt.Sprintf("%d %s:\n%s", len(bindings), scope, string(bindingsData)))
+	}
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: fmt.Sprintf("RBAC status for %s in namespace '%s':\n\n%s", subject, namespace, strings.Join(sections, "\n\n")),
+		}},
+	}, nil
+}
+
+// rbacBindings summarizes the bindings in namespace, limited to those that apply to serviceAccount when one is given
+func (m *MCPService) rbacBindings(ctx context.Context, namespace, serviceAccount string) ([]rbacBindingSummary, error) {
+	roleBindings, err := m.k8sService.ListRoleBindings(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := m.k8sService.ListRoles(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	roleRules := make(map[string][]rbacv1.PolicyRule, len(roles.Items))
+	for _, role := range roles.Items {
+		roleRules[role.Name] = role.Rules
+	}
+
+	// ClusterRoles are fetched lazily since only referenced ones matter
+	clusterRoleRules := make(map[string][]rbacv1.PolicyRule)
+	rulesFor := func(ref rbacv1.RoleRef) []string {
+		if ref.Kind == "Role" {
+			return formatPolicyRules(roleRules[ref.Name])
+		}
+		rules, ok := clusterRoleRules[ref.Name]
+		if !ok {
+			if role, err := m.k8sService.GetClusterRole(ctx, ref.Name); err == nil {
+				rules = role.Rules
+			}
+			clusterRoleRules[ref.Name] = rules
+		}
+		return formatPolicyRules(rules)
+	}
+
+	summaries := []rbacBindingSummary{}
+	for _, binding := range roleBindings.Items {
+		if serviceAccount != "" && !subjectsMatchServiceAccount(binding.Subjects, namespace, serviceAccount) {
+			continue
+		}
+		summaries = append(summaries, rbacBindingSummary{
+			Kind:      "RoleBinding",
+			Name:      binding.Name,
+			Namespace: binding.Namespace,
+			RoleRef:   binding.RoleRef.Kind + "/" + binding.RoleRef.Name,
+			Subjects:  formatSubjects(binding.Subjects),
+			Rules:     rulesFor(binding.RoleRef),
+		})
+	}
+
+	// Cluster-wide bindings are only relevant, and only a manageable number, for a specific subject
+	if serviceAccount != "" {
+		clusterBindings, err := m.k8sService.ListClusterRoleBindings(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, binding := range clusterBindings.Items {
+			if !subjectsMatchServiceAccount(binding.Subjects, namespace, serviceAccount) {
+				continue
+			}
+			summaries = append(summaries, rbacBindingSummary{
+				Kind:     "ClusterRoleBinding",
+				Name:     binding.Name,
+				RoleRef:  binding.RoleRef.Kind + "/" + binding.RoleRef.Name,
+				Subjects: formatSubjects(binding.Subjects),
+				Rules:    rulesFor(binding.RoleRef),
+			})
+		}
+	}
+
+	return summaries, nil
+}
+
+// subjectsMatchServiceAccount reports whether any subject names the service account or one of its groups
+func subjectsMatchServiceAccount(subjects []rbacv1.Subject, namespace, serviceAccount string) bool {
+	groups := kubernetes.ServiceAccountGroups(namespace)
+	for _, subject := range subjects {
+		switch subject.Kind {
+		case rbacv1.ServiceAccountKind:
+			if subject.Name == serviceAccount && subject.Namespace == namespace {
+				return true
+			}
+		case rbacv1.UserKind:
+			if subject.Name == kubernetes.ServiceAccountUsername(namespace, serviceAccount) {
+				return true
+			}
+		case rbacv1.GroupKind:
+			for _, group := range groups {
+				if subject.Name == group {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// formatSubjects renders binding subjects as Kind/namespace/name strings
+func formatSubjects(subjects []rbacv1.Subject) []string {
+	formatted := make([]string, 0, len(subjects))
+	for _, subject := range subjects {
+		if subject.Namespace != "" {
+			formatted = append(formatted, fmt.Sprintf("%s/%s/%s", subject.Kind, subject.Namespace, subject.Name))
+		} else {
+			formatted = append(formatted, fmt.Sprintf("%s/%s", subject.Kind, subject.Name))
+		}
+	}
+	return formatted
+}
+
+// formatPolicyRules renders policy rules as compact "verbs on resources" strings
+func formatPolicyRules(rules []rbacv1.PolicyRule) []string {
+	formatted := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		verbs := strings.Join(rule.Verbs, ",")
+		if len(rule.NonResourceURLs) > 0 {
+			formatted = append(formatted, fmt.Sprintf("%s on %s", verbs, strings.Join(rule.NonResourceURLs, ",")))
+			continue
+		}
+
+		var resources []string
+		for _, resource := range rule.Resources {
+			for _, group := range rule.APIGroups {
+				resources = append(resources, formatGroupResource(group, resource))
+			}
+		}
+		text := fmt.Sprintf("%s on %s", verbs, strings.Join(resources, ","))
+		if len(rule.ResourceNames) > 0 {
+			text += fmt.Sprintf(" (names: %s)", strings.Join(rule.ResourceNames, ","))
+		}
+		formatted = append(formatted, text)
+	}
+	return formatted
+}
+
+// formatGroupResource renders a resource qualified by its API group, omitting the core group
+func formatGroupResource(group, resource string) string {
+	if group == "" {
+		return resource
+	}
+	return group + "/" + resource
+}
@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"kube-sherlock/internal/kubernetes"
+)
+
+// getRelatedResources returns the graph of resources connected to one object: its owners, the objects it
+// owns and the ConfigMaps, Secrets, claims and service account its pod spec references
+func (m *MCPService) getRelatedResources(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	kind := getStringParam(args, "kind", "")
+	name := getStringParam(args, "name", "")
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+	includeObjects := getBoolParam(args, "includeObjects", true)
+
+	if kind == "" || name == "" {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Both kind and name are required",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: kind and name are required", ErrInvalidArguments)
+	}
+	if namespace == kubernetes.AllNamespaces {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "namespace must name the resource's namespace, not \"*\"",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: namespace must not be \"*\"", ErrInvalidArguments)
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	related, err := m.k8sService.RelatedResources(ctx, kind, namespace, name, includeObjects)
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error getting resources related to %s '%s': %v", kind, name, err),
+			}},
+			IsError: true,
+		}, err
+	}
+
+	var missing []string
+	for _, resource := range related.Resources {
+		if resource.Missing {
+			missing = append(missing, resource.String())
+		}
+	}
+
+	relatedData, _ := json.MarshalIndent(related, "", "  ")
+	text := fmt.Sprintf("Resources related to %s (%d resources, %d relations):\n\n%s",
+		related.Root.String(), len(related.Resources), len(related.Edges), string(relatedData))
+	if len(missing) > 0 {
+		text += fmt.Sprintf("\n\nReferenced but missing: %s", strings.Join(missing, ", "))
+	}
+	if related.Truncated {
+		text += "\n\nThe graph was cut at its size limits; query a more specific resource for the rest"
+	}
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: text,
+		}},
+	}, nil
+}
@@ -0,0 +1,240 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"kube-sherlock/internal/kubernetes"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Annotations the deployment controller and kubectl set on a Deployment's ReplicaSets
+const (
+	revisionAnnotation    = "deployment.kubernetes.io/revision"
+	changeCauseAnnotation = "kubernetes.io/change-cause"
+)
+
+// maxRolloutRevisions caps the revisions reported by get_rollout_history, newest first
+const maxRolloutRevisions = 10
+
+// rolloutRevision is one revision of a Deployment, backed by a ReplicaSet
+type rolloutRevision struct {
+	Revision    int64             `json:"revision"`
+	ReplicaSet  string            `json:"replicaSet"`
+	Created     string            `json:"created"`
+	ChangeCause string            `json:"changeCause,omitempty"`
+	Images      map[string]string `json:"images"`
+	Replicas    int32             `json:"replicas"`
+	Ready       int32             `json:"ready"`
+	Available   int32             `json:"available"`
+}
+
+// imageChange is a container whose image differs between the previous and current revision
+type imageChange struct {
+	Container string `json:"container"`
+	Previous  string `json:"previous,omitempty"`
+	Current   string `json:"current,omitempty"`
+}
+
+// rolloutHistoryReport is the result of get_rollout_history
+type rolloutHistoryReport struct {
+	Deployment       string            `json:"deployment"`
+	Namespace        string            `json:"namespace"`
+	CurrentRevision  int64             `json:"currentRevision"`
+	PreviousRevision int64             `json:"previousRevision,omitempty"`
+	RolloutStatus    string            `json:"rolloutStatus"`
+	RolloutMessage   string            `json:"rolloutMessage,omitempty"`
+	Conditions       []string          `json:"conditions,omitempty"`
+	ImageChanges     []imageChange     `json:"imageChanges,omitempty"`
+	Revisions        []rolloutRevision `json:"revisions"`
+	// Failing is set when the latest revision stalled or can't create its pods
+	Failing    bool   `json:"failing"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// getRolloutHistory reports a Deployment's revisions from its ReplicaSets, the image change between the
+// current and previous revision, and the rollout status, suggesting a rollback when the latest revision fails
+func (m *MCPService) getRolloutHistory(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+	deploymentName := getStringParam(args, "deploymentName", "")
+
+	if deploymentName == "" {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "deploymentName is required",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: deploymentName is required", ErrInvalidArguments)
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	// Not minimized: ReplicaSets are matched to the Deployment by owner UID, and the rollout status
+	// compares the Deployment's generation with the observed one
+	resourceTypes := []string{"deployments", "replicasets"}
+	resources, err := m.k8sService.Gather(ctx, kubernetes.GatherOptions{
+		ResourceTypes: resourceTypes,
+		Namespace:     namespace,
+	})
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error gathering rollout history: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+	for _, resourceType := range resourceTypes {
+		if msg, ok := resources.Resources[resourceType+"_error"].(string); ok {
+			return &ToolResult{
+				Content: []ToolContent{{
+					Type: "text",
+					Text: fmt.Sprintf("Error listing %s: %s", resourceType, msg),
+				}},
+				IsError: true,
+			}, fmt.Errorf("failed to list %s: %s", resourceType, msg)
+		}
+	}
+
+	var deployment *appsv1.Deployment
+	if deployments, ok := resources.Resources["deployments"].(*appsv1.DeploymentList); ok {
+		for i := range deployments.Items {
+			if deployments.Items[i].Name == deploymentName {
+				deployment = &deployments.Items[i]
+			}
+		}
+	}
+	if deployment == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Deployment '%s' not found in namespace '%s'", deploymentName, namespace),
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: deployment %s/%s", kubernetes.ErrNotFound, namespace, deploymentName)
+	}
+
+	replicaSets, _ := resources.Resources["replicasets"].(*appsv1.ReplicaSetList)
+	report := rolloutHistory(deployment, replicaSets)
+	total := len(report.Revisions)
+	if total > maxRolloutRevisions {
+		report.Revisions = report.Revisions[:maxRolloutRevisions]
+	}
+
+	reportData, _ := json.MarshalIndent(report, "", "  ")
+	text := fmt.Sprintf("Rollout history of deployment '%s' in namespace '%s' (%d revisions, current %d, %s):\n\n%s",
+		deploymentName, namespace, total, report.CurrentRevision, report.RolloutStatus, string(reportData))
+	if total > maxRolloutRevisions {
+		text += fmt.Sprintf("\n\nOnly the newest %d of %d revisions are listed", maxRolloutRevisions, total)
+	}
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: text,
+		}},
+	}, nil
+}
+
+// rolloutHistory builds the revision history of a Deployment from the ReplicaSets it controls
+func rolloutHistory(deployment *appsv1.Deployment, replicaSets *appsv1.ReplicaSetList) rolloutHistoryReport {
+	status := deploymentStatus(deployment)
+	report := rolloutHistoryReport{
+		Deployment:     deployment.Name,
+		Namespace:      deployment.Namespace,
+		RolloutStatus:  status.RolloutStatus,
+		RolloutMessage: status.RolloutMessage,
+		Conditions:     status.Conditions,
+		Revisions:      []rolloutRevision{},
+	}
+
+	if replicaSets != nil {
+		for i := range replicaSets.Items {
+			replicaSet := &replicaSets.Items[i]
+			owner := metav1.GetControllerOf(replicaSet)
+			if owner == nil || owner.Kind != "Deployment" || owner.UID != deployment.UID {
+				continue
+			}
+			report.Revisions = append(report.Revisions, replicaSetRevision(replicaSet))
+		}
+	}
+	sort.Slice(report.Revisions, func(i, j int) bool {
+		return report.Revisions[i].Revision > report.Revisions[j].Revision
+	})
+
+	if len(report.Revisions) > 0 {
+		report.CurrentRevision = report.Revisions[0].Revision
+	}
+	if len(report.Revisions) > 1 {
+		report.PreviousRevision = report.Revisions[1].Revision
+		report.ImageChanges = imageChanges(report.Revisions[1].Images, report.Revisions[0].Images)
+	}
+
+	report.Failing = status.RolloutStatus == rolloutStalled
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentReplicaFailure && condition.Status == v1.ConditionTrue {
+			report.Failing = true
+		}
+	}
+	if report.Failing && report.PreviousRevision > 0 {
+		report.Suggestion = fmt.Sprintf("The latest revision %d is failing; to roll back to revision %d run `kubectl rollout undo deployment/%s -n %s --to-revision=%d`",
+			report.CurrentRevision, report.PreviousRevision, deployment.Name, deployment.Namespace, report.PreviousRevision)
+	}
+	return report
+}
+
+// replicaSetRevision describes the revision a ReplicaSet holds
+func replicaSetRevision(replicaSet *appsv1.ReplicaSet) rolloutRevision {
+	revision, _ := strconv.ParseInt(replicaSet.Annotations[revisionAnnotation], 10, 64)
+	images := make(map[string]string, len(replicaSet.Spec.Template.Spec.Containers))
+	for _, container := range replicaSet.Spec.Template.Spec.Containers {
+		images[container.Name] = container.Image
+	}
+	return rolloutRevision{
+		Revision:    revision,
+		ReplicaSet:  replicaSet.Name,
+		Created:     replicaSet.CreationTimestamp.UTC().Format(time.RFC3339),
+		ChangeCause: replicaSet.Annotations[changeCauseAnnotation],
+		Images:      images,
+		Replicas:    replicaSet.Status.Replicas,
+		Ready:       replicaSet.Status.ReadyReplicas,
+		Available:   replicaSet.Status.AvailableReplicas,
+	}
+}
+
+// imageChanges lists the containers whose image differs between two revisions, sorted by container name.
+// Containers only in one revision have an empty image on the other side
+func imageChanges(previous, current map[string]string) []imageChange {
+	var changes []imageChange
+	for container, image := range current {
+		if previous[container] != image {
+			changes = append(changes, imageChange{Container: container, Previous: previous[container], Current: image})
+		}
+	}
+	for container, image := range previous {
+		if _, ok := current[container]; !ok {
+			changes = append(changes, imageChange{Container: container, Previous: image})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Container < changes[j].Container
+	})
+	return changes
+}
@@ -0,0 +1,314 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"kube-sherlock/internal/kubernetes"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// failedSchedulingReason is the event reason the scheduler records when it cannot place a pod
+const failedSchedulingReason = "FailedScheduling"
+
+var (
+	// schedulerNodeCount matches the "0/3 nodes are available" prefix of scheduler messages
+	schedulerNodeCount = regexp.MustCompile(`(\d+)/(\d+) nodes are available`)
+	// schedulerPredicate matches one "<count> <reason>" entry of a scheduler message
+	schedulerPredicate = regexp.MustCompile(`^(\d+) (.+)$`)
+	// schedulerTaint extracts the taint from an "untolerated taint {key: value}" entry
+	schedulerTaint = regexp.MustCompile(`taints? \{([^}]*)\}`)
+	// schedulerInsufficient extracts the resource from an "Insufficient <resource>" entry
+	schedulerInsufficient = regexp.MustCompile(`(?i)insufficient ([\w./-]+)`)
+)
+
+// pendingPodDiagnosis explains why a single Pending pod has not started
+type pendingPodDiagnosis struct {
+	Pod              string   `json:"pod"`
+	Controller       string   `json:"controller,omitempty"`
+	Pending          string   `json:"pendingFor,omitempty"`
+	Scheduled        bool     `json:"scheduled"`
+	Reason           string   `json:"reason"`
+	Causes           []string `json:"causes,omitempty"`
+	SchedulerMessage string   `json:"schedulerMessage,omitempty"`
+	LastAttempt      string   `json:"lastAttempt,omitempty"`
+}
+
+// diagnosePendingPods explains why each Pending pod in a namespace hasn't been scheduled or started
+func (m *MCPService) diagnosePendingPods(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+	labelSelector := getStringParam(args, "labelSelector", "")
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	resources, err := m.gather(ctx, []string{"pods"}, namespace, labelSelector)
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error gathering pods: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+	if msg, ok := resources.Resources["pods_error"].(string); ok {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error listing pods: %s", msg),
+			}},
+			IsError: true,
+		}, fmt.Errorf("failed to list pods: %s", msg)
+	}
+
+	var pending []*v1.Pod
+	if pods, ok := resources.Resources["pods"].(*v1.PodList); ok {
+		for i := range pods.Items {
+			if pods.Items[i].Status.Phase == v1.PodPending {
+				pending = append(pending, &pods.Items[i])
+			}
+		}
+	}
+	if len(pending) == 0 {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("No Pending pods in namespace '%s'", namespace),
+			}},
+		}, nil
+	}
+
+	// Events are matched to the pods by name since minimized pods carry no UID
+	events, err := m.gather(ctx, []string{"events"}, namespace, "")
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error gathering events: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+	eventList, _ := events.Resources["events"].(*v1.EventList)
+	schedulingEvents := latestFailedScheduling(eventList)
+
+	now := time.Now()
+	resolver := m.k8sService.NewOwnerResolver()
+	diagnoses := make([]pendingPodDiagnosis, 0, len(pending))
+	for _, pod := range pending {
+		event := schedulingEvents[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}]
+		diagnosis := diagnosePendingPod(pod, event, now)
+		diagnosis.Controller = resolver.Controller(ctx, pod.Namespace, pod.OwnerReferences).String()
+		diagnoses = append(diagnoses, diagnosis)
+	}
+	sort.Slice(diagnoses, func(i, j int) bool {
+		return diagnoses[i].Pod < diagnoses[j].Pod
+	})
+
+	diagnosesData, _ := json.MarshalIndent(diagnoses, "", "  ")
+	text := fmt.Sprintf("%d Pending pod(s) in namespace '%s':\n\n%s", len(diagnoses), namespace, string(diagnosesData))
+	if msg, ok := events.Resources["events_error"].(string); ok {
+		text += fmt.Sprintf("\n\nErrors:\nevents: %s", msg)
+	}
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: text,
+		}},
+	}, nil
+}
+
+// latestFailedScheduling returns the most recent FailedScheduling event for each pod
+func latestFailedScheduling(events *v1.EventList) map[types.NamespacedName]*v1.Event {
+	latest := make(map[types.NamespacedName]*v1.Event)
+	if events == nil {
+		return latest
+	}
+	for i := range events.Items {
+		event := &events.Items[i]
+		if event.Reason != failedSchedulingReason || event.InvolvedObject.Kind != "Pod" {
+			continue
+		}
+		key := types.NamespacedName{Namespace: event.InvolvedObject.Namespace, Name: event.InvolvedObject.Name}
+		if current, ok := latest[key]; !ok || kubernetes.EventTime(event).After(kubernetes.EventTime(current).Time) {
+			latest[key] = event
+		}
+	}
+	return latest
+}
+
+// diagnosePendingPod combines the PodScheduled condition, the latest FailedScheduling event and
+// container states into a concise reason
+func diagnosePendingPod(pod *v1.Pod, event *v1.Event, now time.Time) pendingPodDiagnosis {
+	diagnosis := pendingPodDiagnosis{Pod: pod.Name}
+	if !pod.CreationTimestamp.IsZero() {
+		diagnosis.Pending = now.Sub(pod.CreationTimestamp.Time).Round(time.Second).String()
+	}
+
+	var scheduledCondition *v1.PodCondition
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == v1.PodScheduled {
+			scheduledCondition = &pod.Status.Conditions[i]
+		}
+	}
+	diagnosis.Scheduled = pod.Spec.NodeName != "" ||
+		(scheduledCondition != nil && scheduledCondition.Status == v1.ConditionTrue)
+
+	if diagnosis.Scheduled {
+		diagnosis.Reason = fmt.Sprintf("scheduled to %s; %s", pod.Spec.NodeName, waitingContainers(pod))
+		return diagnosis
+	}
+
+	message := ""
+	if scheduledCondition != nil {
+		message = scheduledCondition.Message
+	}
+	if event != nil {
+		// The event is usually more recent than the condition
+		message = event.Message
+		if t := kubernetes.EventTime(event); !t.IsZero() {
+			diagnosis.LastAttempt = t.UTC().Format(time.RFC3339)
+		}
+	}
+	diagnosis.SchedulerMessage = message
+
+	switch {
+	case message == "" && pod.Spec.SchedulerName != "" && pod.Spec.SchedulerName != v1.DefaultSchedulerName:
+		diagnosis.Reason = fmt.Sprintf("not yet scheduled; uses scheduler %q, check that it is running", pod.Spec.SchedulerName)
+	case message == "":
+		diagnosis.Reason = "not yet scheduled and the scheduler has reported no reason; check that the scheduler is running"
+	default:
+		diagnosis.Causes = schedulingCauses(message)
+		diagnosis.Reason = "unschedulable"
+		if match := schedulerNodeCount.FindStringSubmatch(message); match != nil {
+			diagnosis.Reason = fmt.Sprintf("unschedulable: %s of %s nodes available", match[1], match[2])
+		}
+		if len(diagnosis.Causes) > 0 {
+			diagnosis.Reason += ": " + strings.Join(diagnosis.Causes, ", ")
+		}
+	}
+	return diagnosis
+}
+
+// schedulingCauses turns the per-node reasons of a scheduler message into short cause descriptions
+func schedulingCauses(message string) []string {
+	details := message
+	if idx := strings.Index(details, "available:"); idx != -1 {
+		details = details[idx+len("available:"):]
+	}
+	// Preemption details follow the node reasons and repeat them
+	if idx := strings.Index(details, "preemption:"); idx != -1 {
+		details = details[:idx]
+	}
+	details = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(details), "."))
+
+	var causes []string
+	for _, entry := range splitOutsideBraces(details) {
+		entry = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(entry), "."))
+		if entry == "" {
+			continue
+		}
+		count := ""
+		if match := schedulerPredicate.FindStringSubmatch(entry); match != nil {
+			count, entry = match[1], match[2]
+		}
+		cause := classifySchedulingReason(entry)
+		if count != "" {
+			nodes := "nodes"
+			if n, _ := strconv.Atoi(count); n == 1 {
+				nodes = "node"
+			}
+			cause = fmt.Sprintf("%s (%s %s)", cause, count, nodes)
+		}
+		causes = append(causes, cause)
+	}
+	return causes
+}
+
+// classifySchedulingReason names the common scheduler predicates in plain words
+func classifySchedulingReason(reason string) string {
+	lower := strings.ToLower(reason)
+	switch {
+	case strings.Contains(lower, "insufficient"):
+		if match := schedulerInsufficient.FindStringSubmatch(reason); match != nil {
+			return "insufficient " + match[1]
+		}
+		return "insufficient resources"
+	case strings.Contains(lower, "taint"):
+		if match := schedulerTaint.FindStringSubmatch(reason); match != nil {
+			return fmt.Sprintf("untolerated taint {%s}", match[1])
+		}
+		return "untolerated taint"
+	case strings.Contains(lower, "volume node affinity"):
+		return "volume is in a different zone or node (volume node affinity conflict)"
+	case strings.Contains(lower, "node affinity"), strings.Contains(lower, "node selector"):
+		return "node affinity/nodeSelector mismatch"
+	case strings.Contains(lower, "anti-affinity"), strings.Contains(lower, "pod affinity"):
+		return "pod affinity/anti-affinity rules not satisfied"
+	case strings.Contains(lower, "persistentvolumeclaim"):
+		return "PersistentVolumeClaim not bound"
+	case strings.Contains(lower, "topology spread"):
+		return "topology spread constraints not satisfied"
+	case strings.Contains(lower, "too many pods"):
+		return "node pod limit reached"
+	case strings.Contains(lower, "free ports"):
+		return "requested host port already in use"
+	case strings.Contains(lower, "were unschedulable"):
+		return "node cordoned (unschedulable)"
+	}
+	return reason
+}
+
+// splitOutsideBraces splits a scheduler message on commas that are not inside a taint's braces
+func splitOutsideBraces(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// waitingContainers describes why a scheduled but Pending pod's containers haven't started
+func waitingContainers(pod *v1.Pod) string {
+	var waiting []string
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason != "" {
+			waiting = append(waiting, fmt.Sprintf("%s: %s", status.Name, status.State.Waiting.Reason))
+		}
+	}
+	if len(waiting) == 0 {
+		return "waiting for containers to be created"
+	}
+	return "containers waiting (" + strings.Join(waiting, ", ") + ")"
+}
@@ -0,0 +1,355 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"kube-sherlock/internal/kubernetes"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Components of the namespace health score, in the order they are reported
+const (
+	scoreReadyPods     = "ready_pods"
+	scoreDeployments   = "deployments"
+	scoreWarningEvents = "warning_events"
+	scoreRestarts      = "restarts"
+)
+
+// scoreComponents lists the health score components in report order
+var scoreComponents = []string{scoreReadyPods, scoreDeployments, scoreWarningEvents, scoreRestarts}
+
+// defaultHealthScoreWeights weight pod readiness and deployment availability above the noisier signals
+var defaultHealthScoreWeights = map[string]float64{
+	scoreReadyPods:     40,
+	scoreDeployments:   30,
+	scoreWarningEvents: 15,
+	scoreRestarts:      15,
+}
+
+// warningEventCap is the number of recent Warning events at which the warning_events component reaches zero
+const warningEventCap = 20
+
+// maxDetractors caps the detractors get_namespace_health_score may return
+const maxDetractors = 50
+
+// WithHealthScoreWeights overrides the weights of get_namespace_health_score components by name:
+// ready_pods, deployments, warning_events and restarts. Weights are relative; zero leaves a component
+// out. Negative weights and unknown names are ignored
+func WithHealthScoreWeights(weights map[string]float64) Option {
+	return func(m *MCPService) {
+		for name, weight := range weights {
+			if _, ok := defaultHealthScoreWeights[name]; !ok || weight < 0 {
+				m.logger.Warn("Ignoring invalid health score weight", zap.String("component", name), zap.Float64("weight", weight))
+				continue
+			}
+			m.healthScoreWeights[name] = weight
+		}
+	}
+}
+
+// scoreComponent is one input to the health score, scored from 0 to 100
+type scoreComponent struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+	Score  float64 `json:"score"`
+	Detail string  `json:"detail"`
+}
+
+// scoreDetractor is an object that lowered the health score and by how many points
+type scoreDetractor struct {
+	Component string  `json:"component"`
+	Kind      string  `json:"kind"`
+	Name      string  `json:"name"`
+	Problem   string  `json:"problem"`
+	Impact    float64 `json:"impact"`
+}
+
+// namespaceHealthScore is the result of get_namespace_health_score
+type namespaceHealthScore struct {
+	Namespace  string           `json:"namespace"`
+	Score      int              `json:"score"`
+	Components []scoreComponent `json:"components"`
+	Detractors []scoreDetractor `json:"detractors"`
+	// Truncated lists resource types that hit the list limit, so the score may be based on partial data
+	Truncated []string `json:"truncated,omitempty"`
+}
+
+// healthScoreFormula describes the score for the tool description, with the configured weights
+func (m *MCPService) healthScoreFormula() string {
+	weights := make([]string, 0, len(scoreComponents))
+	for _, name := range scoreComponents {
+		weights = append(weights, fmt.Sprintf("%s %g", name, m.healthScoreWeights[name]))
+	}
+	return fmt.Sprintf("The score is the weighted average (weights: %s) of four component scores from 0 to 100: "+
+		"ready_pods is the share of pods, excluding completed ones, that are Running and ready; deployments is the share of deployments with all desired replicas available; "+
+		"warning_events falls linearly from 100 to 0 as Warning events in the window go from 0 to %d; restarts is the share of pods without a container restart in the window. "+
+		"A component with nothing to measure scores 100", strings.Join(weights, ", "), warningEventCap)
+}
+
+// getNamespaceHealthScore scores a namespace's health from 0 to 100 and lists the objects that cost it the most points
+func (m *MCPService) getNamespaceHealthScore(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+	eventMinutes := getIntParam(args, "eventMinutes", 60)
+	limit := getIntParam(args, "limit", 10)
+
+	if namespace == kubernetes.AllNamespaces {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "namespace must name a single namespace; use get_cluster_health_summary for the whole cluster",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: namespace must name a single namespace", ErrInvalidArguments)
+	}
+	if eventMinutes <= 0 {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "eventMinutes must be positive",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: eventMinutes must be positive", ErrInvalidArguments)
+	}
+	if limit <= 0 || limit > maxDetractors {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("limit must be between 1 and %d", maxDetractors),
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidArguments, maxDetractors)
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	resourceTypes := []string{"pods", "deployments", "events"}
+	resources, err := m.gather(ctx, resourceTypes, namespace, "")
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error gathering resources in namespace %s: %v", namespace, err),
+			}},
+			IsError: true,
+		}, err
+	}
+
+	// The score would be meaningless without the resources it is computed from
+	var gatherErrors []string
+	for _, resourceType := range resourceTypes {
+		if msg, ok := resources.Resources[resourceType+"_error"].(string); ok {
+			gatherErrors = append(gatherErrors, fmt.Sprintf("%s: %s", resourceType, msg))
+		}
+	}
+	if len(gatherErrors) > 0 {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error gathering resources in namespace %s:\n%s", namespace, strings.Join(gatherErrors, "\n")),
+			}},
+			IsError: true,
+		}, fmt.Errorf("failed to gather resources in namespace %s: %s", namespace, strings.Join(gatherErrors, "; "))
+	}
+
+	pods, _ := resources.Resources["pods"].(*v1.PodList)
+	deployments, _ := resources.Resources["deployments"].(*appsv1.DeploymentList)
+	events, _ := resources.Resources["events"].(*v1.EventList)
+	since := time.Now().Add(-time.Duration(eventMinutes) * time.Minute)
+
+	result := scoreNamespace(pods, deployments, events, since, m.healthScoreWeights)
+	result.Namespace = namespace
+	result.Truncated = resources.Metadata.Truncated
+	totalDetractors := len(result.Detractors)
+	if int64(totalDetractors) > limit {
+		result.Detractors = result.Detractors[:limit]
+	}
+
+	resultData, _ := json.MarshalIndent(result, "", "  ")
+	text := fmt.Sprintf("Health score for namespace '%s': %d/100 (Warning events and restarts from the last %d minutes):\n\n%s",
+		namespace, result.Score, eventMinutes, string(resultData))
+	if totalDetractors > len(result.Detractors) {
+		text += fmt.Sprintf("\n\nShowing %d of %d detractors", len(result.Detractors), totalDetractors)
+	}
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: text,
+		}},
+	}, nil
+}
+
+// scoreNamespace computes the health score from a namespace's resources. Each detractor's impact is the
+// number of points it cost the overall score, so the impacts add up to 100 minus the score
+func scoreNamespace(pods *v1.PodList, deployments *appsv1.DeploymentList, events *v1.EventList, since time.Time, weights map[string]float64) namespaceHealthScore {
+	var totalWeight float64
+	for _, name := range scoreComponents {
+		totalWeight += weights[name]
+	}
+	// points is how much of the overall score a component's full range is worth
+	points := func(name string) float64 {
+		if totalWeight == 0 {
+			return 0
+		}
+		return 100 * weights[name] / totalWeight
+	}
+
+	result := namespaceHealthScore{Detractors: []scoreDetractor{}}
+	scores := map[string]float64{}
+	details := map[string]string{}
+
+	var scoredPods []*v1.Pod
+	if pods != nil {
+		for i := range pods.Items {
+			if pods.Items[i].Status.Phase != v1.PodSucceeded {
+				scoredPods = append(scoredPods, &pods.Items[i])
+			}
+		}
+	}
+	unready, restarted := 0, 0
+	for _, pod := range scoredPods {
+		if issue, unhealthy := podIssue(pod); unhealthy {
+			unready++
+			result.Detractors = append(result.Detractors, scoreDetractor{
+				Component: scoreReadyPods,
+				Kind:      "Pod",
+				Name:      pod.Name,
+				Problem:   issue.Problem,
+				Impact:    points(scoreReadyPods) / float64(len(scoredPods)),
+			})
+		}
+		if restarts := recentRestarts(pod, since); restarts > 0 {
+			restarted++
+			result.Detractors = append(result.Detractors, scoreDetractor{
+				Component: scoreRestarts,
+				Kind:      "Pod",
+				Name:      pod.Name,
+				Problem:   fmt.Sprintf("restarted recently (%d restarts in total)", restarts),
+				Impact:    points(scoreRestarts) / float64(len(scoredPods)),
+			})
+		}
+	}
+	scores[scoreReadyPods] = shareScore(len(scoredPods)-unready, len(scoredPods))
+	details[scoreReadyPods] = fmt.Sprintf("%d of %d pods ready", len(scoredPods)-unready, len(scoredPods))
+	scores[scoreRestarts] = shareScore(len(scoredPods)-restarted, len(scoredPods))
+	details[scoreRestarts] = fmt.Sprintf("%d of %d pods restarted in the window", restarted, len(scoredPods))
+
+	deploymentCount, unavailable := 0, 0
+	if deployments != nil {
+		deploymentCount = len(deployments.Items)
+		for i := range deployments.Items {
+			if issue, ok := deploymentIssue(&deployments.Items[i]); ok {
+				unavailable++
+				result.Detractors = append(result.Detractors, scoreDetractor{
+					Component: scoreDeployments,
+					Kind:      "Deployment",
+					Name:      issue.Name,
+					Problem:   issue.Problem,
+					Impact:    points(scoreDeployments) / float64(deploymentCount),
+				})
+			}
+		}
+	}
+	scores[scoreDeployments] = shareScore(deploymentCount-unavailable, deploymentCount)
+	details[scoreDeployments] = fmt.Sprintf("%d of %d deployments at desired replicas", deploymentCount-unavailable, deploymentCount)
+
+	var warnings int32
+	var eventIssues []clusterIssue
+	if events != nil {
+		eventIssues = warningEventIssues(events, since)
+		for _, issue := range eventIssues {
+			warnings += issue.Count
+		}
+	}
+	penalty := math.Min(float64(warnings), warningEventCap) / warningEventCap
+	scores[scoreWarningEvents] = 100 * (1 - penalty)
+	details[scoreWarningEvents] = fmt.Sprintf("%d Warning events in the window", warnings)
+	for _, issue := range eventIssues {
+		result.Detractors = append(result.Detractors, scoreDetractor{
+			Component: scoreWarningEvents,
+			Kind:      "Event",
+			Name:      issue.Name,
+			Problem:   issue.Problem,
+			Impact:    points(scoreWarningEvents) * penalty * float64(issue.Count) / float64(warnings),
+		})
+	}
+
+	var score float64
+	for _, name := range scoreComponents {
+		score += scores[name] * points(name) / 100
+		result.Components = append(result.Components, scoreComponent{
+			Name:   name,
+			Weight: weights[name],
+			Score:  roundScore(scores[name]),
+			Detail: details[name],
+		})
+	}
+	if totalWeight == 0 {
+		score = 100
+	}
+	result.Score = int(math.Round(score))
+
+	// Objects in components weighted zero didn't cost any points
+	detractors := result.Detractors[:0]
+	for _, detractor := range result.Detractors {
+		if detractor.Impact > 0 {
+			detractors = append(detractors, detractor)
+		}
+	}
+	result.Detractors = detractors
+	sort.SliceStable(result.Detractors, func(i, j int) bool {
+		return result.Detractors[i].Impact > result.Detractors[j].Impact
+	})
+	for i := range result.Detractors {
+		result.Detractors[i].Impact = roundScore(result.Detractors[i].Impact)
+	}
+	return result
+}
+
+// recentRestarts returns a pod's total container restarts if any container last terminated after since,
+// and zero otherwise
+func recentRestarts(pod *v1.Pod, since time.Time) int32 {
+	var total int32
+	recent := false
+	for _, status := range pod.Status.ContainerStatuses {
+		total += status.RestartCount
+		if last := status.LastTerminationState.Terminated; last != nil && status.RestartCount > 0 && last.FinishedAt.Time.After(since) {
+			recent = true
+		}
+	}
+	if !recent {
+		return 0
+	}
+	return total
+}
+
+// shareScore scores good out of total from 0 to 100; nothing to measure scores 100
+func shareScore(good, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return 100 * float64(good) / float64(total)
+}
+
+// roundScore rounds to one decimal place
+func roundScore(value float64) float64 {
+	return math.Round(value*10) / 10
+}
@@ -0,0 +1,242 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"kube-sherlock/internal/kubernetes"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// Results of checking a pod's reference to a secret
+const (
+	secretRefOK          = "ok"
+	secretRefMissingKey  = "missing key"
+	secretRefNotFound    = "secret not found"
+	secretRefUnavailable = "could not be checked"
+)
+
+// secretReference is a place in a pod spec that uses a secret, or one key of it
+type secretReference struct {
+	Secret string `json:"secret"`
+	// Key is empty when the whole secret is used, as with envFrom or an unfiltered volume
+	Key      string `json:"key,omitempty"`
+	Source   string `json:"source"`
+	Optional bool   `json:"optional,omitempty"`
+	Status   string `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// secretMetadataReport is the output of get_secret_metadata
+type secretMetadataReport struct {
+	Secrets    []kubernetes.SecretMetadata `json:"secrets"`
+	References []secretReference           `json:"references,omitempty"`
+}
+
+// getSecretMetadata reports secret types and key names, and checks the secret keys a pod references.
+// Secret values are never returned
+func (m *MCPService) getSecretMetadata(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+	secretName := getStringParam(args, "secretName", "")
+	podName := getStringParam(args, "podName", "")
+
+	if secretName == "" && podName == "" {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Either secretName or podName is required",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: secretName or podName is required", ErrInvalidArguments)
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	var references []secretReference
+	if podName != "" {
+		pod, err := m.k8sService.GetPod(ctx, namespace, podName)
+		if err != nil {
+			return &ToolResult{
+				Content: []ToolContent{{
+					Type: "text",
+					Text: fmt.Sprintf("Error getting pod: %v", err),
+				}},
+				IsError: true,
+			}, err
+		}
+		for _, ref := range podSecretReferences(pod) {
+			if secretName == "" || ref.Secret == secretName {
+				references = append(references, ref)
+			}
+		}
+	}
+
+	// Look up the named secret and every secret the pod references
+	names := []string{}
+	seen := make(map[string]bool)
+	if secretName != "" {
+		names = append(names, secretName)
+		seen[secretName] = true
+	}
+	for _, ref := range references {
+		if !seen[ref.Secret] {
+			seen[ref.Secret] = true
+			names = append(names, ref.Secret)
+		}
+	}
+
+	report := secretMetadataReport{Secrets: []kubernetes.SecretMetadata{}}
+	metadata := make(map[string]*kubernetes.SecretMetadata)
+	lookupErrors := make(map[string]error)
+	for _, name := range names {
+		secret, err := m.k8sService.GetSecretMetadata(ctx, namespace, name)
+		if err != nil {
+			lookupErrors[name] = err
+			continue
+		}
+		metadata[name] = secret
+		report.Secrets = append(report.Secrets, *secret)
+	}
+
+	// A named secret that can't be read is an error unless it's reported on the pod's references
+	if len(references) == 0 {
+		if err := lookupErrors[secretName]; err != nil {
+			return &ToolResult{
+				Content: []ToolContent{{
+					Type: "text",
+					Text: fmt.Sprintf("Error getting secret: %v", err),
+				}},
+				IsError: true,
+			}, err
+		}
+	}
+
+	for i := range references {
+		checkSecretReference(&references[i], metadata[references[i].Secret], lookupErrors[references[i].Secret])
+	}
+	report.References = references
+
+	reportData, _ := json.MarshalIndent(report, "", "  ")
+	text := fmt.Sprintf("Secret metadata in namespace '%s' (values are never shown):\n\n%s", namespace, string(reportData))
+	if podName != "" && len(references) == 0 {
+		text = fmt.Sprintf("Pod '%s' does not reference %s.\n\n%s", podName, secretOrSecrets(secretName), text)
+	}
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: text,
+		}},
+	}, nil
+}
+
+// checkSecretReference sets the status of a reference from the referenced secret's metadata or lookup error
+func checkSecretReference(ref *secretReference, secret *kubernetes.SecretMetadata, lookupErr error) {
+	switch {
+	case errors.Is(lookupErr, kubernetes.ErrNotFound):
+		ref.Status = secretRefNotFound
+		if ref.Optional {
+			ref.Detail = "reference is optional, so the pod can still start"
+		}
+	case lookupErr != nil:
+		ref.Status = secretRefUnavailable
+		ref.Detail = lookupErr.Error()
+	case ref.Key == "":
+		ref.Status = secretRefOK
+	default:
+		i := sort.SearchStrings(secret.Keys, ref.Key)
+		if i < len(secret.Keys) && secret.Keys[i] == ref.Key {
+			ref.Status = secretRefOK
+			return
+		}
+		ref.Status = secretRefMissingKey
+		if ref.Optional {
+			ref.Detail = "reference is optional, so the pod can still start"
+		}
+	}
+}
+
+// podSecretReferences lists every secret, and secret key, used by a pod's containers, volumes and image pulls
+func podSecretReferences(pod *v1.Pod) []secretReference {
+	var refs []secretReference
+	optional := func(o *bool) bool { return o != nil && *o }
+
+	containers := append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, container := range containers {
+		for _, env := range container.Env {
+			if env.ValueFrom == nil || env.ValueFrom.SecretKeyRef == nil {
+				continue
+			}
+			ref := env.ValueFrom.SecretKeyRef
+			refs = append(refs, secretReference{
+				Secret:   ref.Name,
+				Key:      ref.Key,
+				Source:   fmt.Sprintf("env %s in container %s", env.Name, container.Name),
+				Optional: optional(ref.Optional),
+			})
+		}
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef == nil {
+				continue
+			}
+			refs = append(refs, secretReference{
+				Secret:   envFrom.SecretRef.Name,
+				Source:   fmt.Sprintf("envFrom in container %s", container.Name),
+				Optional: optional(envFrom.SecretRef.Optional),
+			})
+		}
+	}
+
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Secret != nil {
+			refs = append(refs, secretVolumeReferences(volume.Secret.SecretName, volume.Secret.Items,
+				fmt.Sprintf("volume %s", volume.Name), optional(volume.Secret.Optional))...)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil {
+					refs = append(refs, secretVolumeReferences(source.Secret.Name, source.Secret.Items,
+						fmt.Sprintf("projected volume %s", volume.Name), optional(source.Secret.Optional))...)
+				}
+			}
+		}
+	}
+
+	for _, pullSecret := range pod.Spec.ImagePullSecrets {
+		refs = append(refs, secretReference{Secret: pullSecret.Name, Source: "imagePullSecrets"})
+	}
+
+	return refs
+}
+
+// secretVolumeReferences lists the keys a secret volume projects, or the whole secret when no items are given
+func secretVolumeReferences(secretName string, items []v1.KeyToPath, source string, optional bool) []secretReference {
+	if len(items) == 0 {
+		return []secretReference{{Secret: secretName, Source: source, Optional: optional}}
+	}
+	refs := make([]secretReference, 0, len(items))
+	for _, item := range items {
+		refs = append(refs, secretReference{Secret: secretName, Key: item.Key, Source: source, Optional: optional})
+	}
+	return refs
+}
+
+// secretOrSecrets names the secret being checked, or any secret when none was given
+func secretOrSecrets(secretName string) string {
+	if secretName == "" {
+		return "any secrets"
+	}
+	return fmt.Sprintf("secret '%s'", secretName)
+}
@@ -4,10 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"kube-sherlock/internal/kubernetes"
+	"kube-sherlock/internal/logging"
+	"kube-sherlock/internal/metrics"
 
 	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 )
 
 // Tool represents an MCP tool that can be executed
@@ -42,148 +49,931 @@ type ToolRequest struct {
 	Arguments map[string]interface{} `json:"arguments"`
 }
 
+// Default caps on pod log output so a single request can't blow past the model's context window
+const (
+	defaultMaxLogLines = 1000
+	defaultMaxLogBytes = 256 * 1024
+)
+
+// maxRecentEvents is the built-in cap on the events get_recent_events returns; defaultRecentEvents are
+// returned when the AI doesn't ask for a number
+const (
+	maxRecentEvents     = 200
+	defaultRecentEvents = 50
+)
+
 // MCPService handles Model Context Protocol operations
 type MCPService struct {
-	k8sService *kubernetes.Service
-	logger     *zap.Logger
-	tools      map[string]Tool
+	k8sService  *kubernetes.Service
+	logger      *zap.Logger
+	tools       map[string]Tool
+	maxLogLines int64
+	maxLogBytes int64
+	// execCommands are the programs exec_in_pod may run; the tool is only registered when it is non-empty
+	execCommands []string
+	// customToolDefinitions are registered by registerCustomTools into tools and customTools
+	customToolDefinitions []CustomTool
+	customTools           map[string]*customTool
+	// toolTimeouts are per-tool limits on ExecuteTool; other tools get defaultToolTimeout
+	toolTimeouts       map[string]time.Duration
+	defaultToolTimeout time.Duration
+	// healthScoreWeights are the relative weights of the get_namespace_health_score components
+	healthScoreWeights map[string]float64
+	// toolMaxItems are per-tool caps on list output; other tools get defaultMaxItems
+	toolMaxItems    map[string]int
+	defaultMaxItems int
+	// restrictAdminTools hides and refuses adminOnlyTools for requests not marked with WithAdmin
+	restrictAdminTools bool
+	// toolsJSON caches the indented JSON of ListTools for prompts, and publicToolsJSON the same without the
+	// admin-only tools; refresh them whenever tools change
+	toolsJSON       string
+	publicToolsJSON string
+}
+
+// Option configures optional MCP service behavior
+type Option func(*MCPService)
+
+// WithLogLimits caps the number of lines and bytes returned by get_pod_logs. Non-positive values keep the defaults
+func WithLogLimits(maxLines, maxBytes int64) Option {
+	return func(m *MCPService) {
+		if maxLines > 0 {
+			m.maxLogLines = maxLines
+		}
+		if maxBytes > 0 {
+			m.maxLogBytes = maxBytes
+		}
+	}
 }
 
 // NewMCPService creates a new MCP service
-func NewMCPService(k8sService *kubernetes.Service, logger *zap.Logger) *MCPService {
+func NewMCPService(k8sService *kubernetes.Service, logger *zap.Logger, opts ...Option) *MCPService {
 	mcp := &MCPService{
-		k8sService: k8sService,
-		logger:     logger,
-		tools:      make(map[string]Tool),
+		k8sService:  k8sService,
+		logger:      logger,
+		tools:       make(map[string]Tool),
+		customTools: make(map[string]*customTool),
+		maxLogLines: defaultMaxLogLines,
+		maxLogBytes: defaultMaxLogBytes,
+
+		toolTimeouts:       make(map[string]time.Duration, len(defaultToolTimeouts)),
+		defaultToolTimeout: defaultToolTimeout,
+		toolMaxItems:       make(map[string]int, len(defaultToolMaxItems)),
+		defaultMaxItems:    defaultMaxItems,
+	}
+	for name, timeout := range defaultToolTimeouts {
+		mcp.toolTimeouts[name] = timeout
+	}
+	for name, max := range defaultToolMaxItems {
+		mcp.toolMaxItems[name] = max
+	}
+	mcp.healthScoreWeights = make(map[string]float64, len(defaultHealthScoreWeights))
+	for name, weight := range defaultHealthScoreWeights {
+		mcp.healthScoreWeights[name] = weight
+	}
+	for _, opt := range opts {
+		opt(mcp)
+	}
+
+	// Register built-in tools
+	mcp.registerTools()
+	mcp.registerCustomTools()
+	mcp.warnUnknownToolTimeouts()
+	mcp.warnUnknownToolMaxItems()
+	mcp.refreshToolsJSON()
+	return mcp
+}
+
+// log returns the request-scoped logger from ctx, falling back to the service logger
+func (m *MCPService) log(ctx context.Context) *zap.Logger {
+	return logging.FromContext(ctx, m.logger)
+}
+
+// registerTools registers all available MCP tools
+func (m *MCPService) registerTools() {
+	// Get pod health tool
+	m.tools["get_pod_health"] = Tool{
+		Name:        "get_pod_health",
+		Description: fmt.Sprintf("Get the health status of pods in a namespace. Returns at most %d pods, the most problematic first", m.maxItems("get_pod_health")),
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Kubernetes namespace to check (default: the kubeconfig context's namespace, \"*\" for all namespaces)",
+				},
+				"labelSelector": map[string]interface{}{
+					"type":        "string",
+					"description": "Label selector to filter pods (optional)",
+				},
+				"newerThan": map[string]interface{}{
+					"type":        "string",
+					"description": "Only pods created within this duration, e.g. 10m or 2h (optional)",
+				},
+				"olderThan": map[string]interface{}{
+					"type":        "string",
+					"description": "Only pods created at least this long ago, e.g. 1h (optional)",
+				},
+			},
+			Required: []string{},
+		},
+	}
+
+	// Get deployment status tool
+	m.tools["get_deployment_status"] = Tool{
+		Name:        "get_deployment_status",
+		Description: "Get the status of deployments in a namespace",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Kubernetes namespace to check (default: the kubeconfig context's namespace)",
+				},
+				"deploymentName": map[string]interface{}{
+					"type":        "string",
+					"description": "Specific deployment name (optional)",
+				},
+			},
+			Required: []string{},
+		},
+	}
+
+	// Get service endpoints tool
+	m.tools["get_service_endpoints"] = Tool{
+		Name:        "get_service_endpoints",
+		Description: "Get services in a namespace with their resolved endpoint addresses and ready/not-ready state. Flags services with no endpoints, the most common cause of a service not working",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Kubernetes namespace to check (default: the kubeconfig context's namespace)",
+				},
+				"serviceName": map[string]interface{}{
+					"type":        "string",
+					"description": "Specific service name (optional)",
+				},
+			},
+			Required: []string{},
+		},
+	}
+
+	// Get recent events tool
+	m.tools["get_recent_events"] = Tool{
+		Name:        "get_recent_events",
+		Description: "Get recent Kubernetes events in a namespace, newest first. Returns Warning events from the last hour by default; set type to Normal or all, or widen sinceMinutes, for more",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Kubernetes namespace to check (default: the kubeconfig context's namespace, \"*\" for all namespaces)",
+				},
+				"resourceName": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter events for specific resource (optional)",
+				},
+				"type": map[string]interface{}{
+					"type":        "string",
+					"description": "Event type to return: Warning, Normal or all (default: Warning)",
+				},
+				"sinceMinutes": map[string]interface{}{
+					"type":        "number",
+					"description": "Only return events seen in the last N minutes (default: 60)",
+				},
+				"limit": map[string]interface{}{
+					"type":        "number",
+					"description": fmt.Sprintf("Maximum number of events to return, newest first (default: %d, max: %d)", min(defaultRecentEvents, m.maxItems("get_recent_events")), m.maxItems("get_recent_events")),
+				},
+				"newerThan": map[string]interface{}{
+					"type":        "string",
+					"description": "Only events created within this duration, e.g. 10m or 2h (optional)",
+				},
+				"olderThan": map[string]interface{}{
+					"type":        "string",
+					"description": "Only events created at least this long ago, e.g. 1h (optional)",
+				},
+			},
+			Required: []string{},
+		},
+	}
+
+	// Get namespaces tool
+	m.tools["get_namespaces"] = Tool{
+		Name:        "get_namespaces",
+		Description: "List all namespaces in the cluster with their status. Use this when the user doesn't know which namespace a problem is in",
+		InputSchema: ToolSchema{
+			Type:       "object",
+			Properties: map[string]interface{}{},
+			Required:   []string{},
+		},
+	}
+
+	// Get pod logs tool
+	m.tools["get_pod_logs"] = Tool{
+		Name:        "get_pod_logs",
+		Description: "Get logs from a specific pod",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Kubernetes namespace (default: the kubeconfig context's namespace)",
+				},
+				"podName": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the pod to get logs from",
+				},
+				"containerName": map[string]interface{}{
+					"type":        "string",
+					"description": "Container name (optional)",
+				},
+				"lines": map[string]interface{}{
+					"type":        "number",
+					"description": "Number of lines to retrieve (default: 100, capped by the server's mcp.max_log_lines)",
+				},
+			},
+			Required: []string{"podName"},
+		},
+	}
+
+	// Get pod containers tool
+	m.tools["get_pod_containers"] = Tool{
+		Name:        "get_pod_containers",
+		Description: "Get per-container status for a pod, separating init containers, sidecars, main containers and ephemeral debug containers, with restart counts and termination reasons. Use this for pods stuck in Init:Error or Init:CrashLoopBackOff",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Kubernetes namespace (default: the kubeconfig context's namespace)",
+				},
+				"podName": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the pod to inspect",
+				},
+			},
+			Required: []string{"podName"},
+		},
+	}
+
+	// Get custom resources tool
+	m.tools["get_custom_resources"] = Tool{
+		Name:        "get_custom_resources",
+		Description: "List arbitrary Kubernetes resources by group/version/resource, including custom resources from operators such as cert-manager (group cert-manager.io, version v1, resource certificates), Istio or ArgoCD. List the CRDs themselves with group apiextensions.k8s.io, version v1, resource customresourcedefinitions",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"group": map[string]interface{}{
+					"type":        "string",
+					"description": "API group (empty for the core group)",
+				},
+				"version": map[string]interface{}{
+					"type":        "string",
+					"description": "API version, e.g. v1",
+				},
+				"resource": map[string]interface{}{
+					"type":        "string",
+					"description": "Plural resource name, e.g. certificates",
+				},
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Kubernetes namespace (optional; omit for all namespaces or cluster-scoped resources)",
+				},
+				"labelSelector": map[string]interface{}{
+					"type":        "string",
+					"description": "Label selector to filter resources (optional)",
+				},
+			},
+			Required: []string{"version", "resource"},
+		},
+	}
+
+	// Check pod connectivity tool
+	m.tools["check_pod_connectivity"] = Tool{
+		Name:        "check_pod_connectivity",
+		Description: "Static connectivity sanity check for a pod (no network dial): reports whether the pod is Ready, whether a port is declared in its container spec, and whether services selecting the pod have target ports that resolve. Use this to explain why a service has no endpoints or traffic doesn't reach a pod",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Kubernetes namespace (default: the kubeconfig context's namespace)",
+				},
+				"podName": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the pod to check",
+				},
+				"port": map[string]interface{}{
+					"type":        "string",
+					"description": "Port number or named port to check (optional)",
+				},
+				"serviceName": map[string]interface{}{
+					"type":        "string",
+					"description": "Service to cross-reference (optional; defaults to all services selecting the pod)",
+				},
+			},
+			Required: []string{"podName"},
+		},
+	}
+
+	// Get RBAC status tool
+	m.tools["get_rbac_status"] = Tool{
+		Name:        "get_rbac_status",
+		Description: "Diagnose Forbidden errors: list the RoleBindings (and, for a service account, ClusterRoleBindings) that apply in a namespace with the rules they grant, and optionally check whether a verb on a resource is permitted using an access review",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Kubernetes namespace (default: the kubeconfig context's namespace)",
+				},
+				"serviceAccount": map[string]interface{}{
+					"type":        "string",
+					"description": "Service account to inspect, e.g. the pod's spec.serviceAccountName (optional; without it the tool's own credentials are checked)",
+				},
+				"verb": map[string]interface{}{
+					"type":        "string",
+					"description": "Verb to check, e.g. get, list, watch, create (optional; requires resource)",
+				},
+				"resource": map[string]interface{}{
+					"type":        "string",
+					"description": "Resource to check, e.g. configmaps, pods, deployments (optional; requires verb)",
+				},
+				"group": map[string]interface{}{
+					"type":        "string",
+					"description": "API group of the resource, e.g. apps (default: core group)",
+				},
+				"subresource": map[string]interface{}{
+					"type":        "string",
+					"description": "Subresource to check, e.g. log or exec (optional)",
+				},
+				"resourceName": map[string]interface{}{
+					"type":        "string",
+					"description": "Specific object name to check (optional)",
+				},
+			},
+			Required: []string{},
+		},
+	}
+
+	// Get workload status tool
+	m.tools["get_workload_status"] = Tool{
+		Name:        "get_workload_status",
+		Description: "Get rollout status of Deployments, StatefulSets and DaemonSets: desired/current/ready/updated/available replicas, update strategy, StatefulSet partition, revisions and volume claim templates, and DaemonSet nodes scheduled vs. desired",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Kubernetes namespace to check (default: the kubeconfig context's namespace, \"*\" for all namespaces)",
+				},
+				"kind": map[string]interface{}{
+					"type":        "string",
+					"description": "Workload kind: deployment, statefulset, daemonset or all (default: all)",
+				},
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Specific workload name (optional)",
+				},
+			},
+			Required: []string{},
+		},
+	}
+
+	// Compare resources tool
+	m.tools["compare_resources"] = Tool{
+		Name:        "compare_resources",
+		Description: "Diff the configuration of a resource across two namespaces (e.g. staging vs. prod): image tags, env vars, resource limits, replica counts. Status and server-managed metadata are ignored. Use this when something works in one environment but not another",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"kind": map[string]interface{}{
+					"type":        "string",
+					"description": "deployment, statefulset, daemonset, service, configmap, ingress, or group/version/resource",
+				},
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the resource in namespace",
+				},
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "First namespace (left side of the diff)",
+				},
+				"otherNamespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Second namespace (right side of the diff)",
+				},
+				"otherName": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the resource in otherNamespace (default: same as name)",
+				},
+			},
+			Required: []string{"kind", "name", "namespace", "otherNamespace"},
+		},
+	}
+
+	// Top pods tool
+	m.tools["get_top_pods"] = Tool{
+		Name:        "get_top_pods",
+		Description: "List the pods using the most CPU or memory (like kubectl top pods), with usage compared to requests and limits and the node each pod runs on. Use this to find noisy neighbors, pods near their memory limit, or CPU throttling. Requires metrics-server",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Kubernetes namespace (default: the kubeconfig context's namespace, \"*\" for all namespaces)",
+				},
+				"sortBy": map[string]interface{}{
+					"type":        "string",
+					"description": "Sort by cpu or memory usage (default: cpu)",
+				},
+				"limit": map[string]interface{}{
+					"type":        "number",
+					"description": "Number of pods to return (default: 10, max: 50)",
+				},
+			},
+		},
+	}
+
+	// ConfigMap contents tool
+	m.tools["get_configmap"] = Tool{
+		Name:        "get_configmap",
+		Description: "Get the keys and values of a ConfigMap to spot misconfiguration such as a wrong connection string, URL or feature flag. Long values are truncated and binary values are reported by size only",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Kubernetes namespace (default: the kubeconfig context's namespace)",
+				},
+				"configMapName": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the ConfigMap",
+				},
+				"key": map[string]interface{}{
+					"type":        "string",
+					"description": "Return only this key (optional)",
+				},
+			},
+			Required: []string{"configMapName"},
+		},
+	}
+
+	// Secret metadata tool
+	m.tools["get_secret_metadata"] = Tool{
+		Name:        "get_secret_metadata",
+		Description: "Get a secret's type and key names (never values), and check whether the secret keys a pod references through env, envFrom, volumes or imagePullSecrets actually exist. Use this for errors like CreateContainerConfigError or 'couldn't find key X in Secret'",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Kubernetes namespace (default: the kubeconfig context's namespace)",
+				},
+				"secretName": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the secret (optional when podName is given)",
+				},
+				"podName": map[string]interface{}{
+					"type":        "string",
+					"description": "Check this pod's secret references; with secretName, only references to that secret",
+				},
+			},
+		},
+	}
+
+	// Application overview tool
+	m.tools["get_application_overview"] = Tool{
+		Name:        "get_application_overview",
+		Description: "Get a correlated view of everything matching a label selector such as app=my-app: each Deployment with its ReplicaSets, their pods, and the recent events for each, linked through ownerReferences. Use this instead of separate deployment, pod and event calls when asked about an application",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Kubernetes namespace (default: the kubeconfig context's namespace)",
+				},
+				"labelSelector": map[string]interface{}{
+					"type":        "string",
+					"description": "Label selector for the application, e.g. app=my-app",
+				},
+			},
+			Required: []string{"labelSelector"},
+		},
+	}
+
+	// Pending pod diagnostics tool
+	m.tools["diagnose_pending_pods"] = Tool{
+		Name:        "diagnose_pending_pods",
+		Description: "Explain why pods are stuck in Pending. For each Pending pod, combines the PodScheduled condition and the latest FailedScheduling event into a concise reason such as insufficient cpu/memory, node affinity mismatch, untolerated taints or unbound PersistentVolumeClaims, and reports waiting containers for pods that were scheduled",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Kubernetes namespace (default: the kubeconfig context's namespace)",
+				},
+				"labelSelector": map[string]interface{}{
+					"type":        "string",
+					"description": "Only check pods matching this label selector",
+				},
+			},
+			Required: []string{},
+		},
+	}
+
+	// Probe diagnostics tool
+	m.tools["diagnose_probes"] = Tool{
+		Name:        "diagnose_probes",
+		Description: "Show each container's liveness, readiness and startup probe configuration (check, delay, period, timeout, failure threshold) cross-referenced with recent probe failure events and restarts, with firing probes first and common misconfigurations flagged. Use this for restart loops or pods that never become ready",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Kubernetes namespace (default: the kubeconfig context's namespace)",
+				},
+				"labelSelector": map[string]interface{}{
+					"type":        "string",
+					"description": "Only check pods matching this label selector",
+				},
+				"podName": map[string]interface{}{
+					"type":        "string",
+					"description": "Only check this pod",
+				},
+			},
+			Required: []string{},
+		},
+	}
+
+	// Cluster health summary tool
+	m.tools["get_cluster_health_summary"] = Tool{
+		Name:        "get_cluster_health_summary",
+		Description: "Triage the whole cluster in one call: scans all permitted namespaces for pods that are not Running and ready or Succeeded, deployments with unavailable replicas, and recent Warning events, and returns a prioritized list of problems (critical, warning, info). Use this as the starting point for 'what's broken right now'",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"limit": map[string]interface{}{
+					"type":        "number",
+					"description": "Maximum number of issues to return (default: 20, max: 100)",
+				},
+				"eventMinutes": map[string]interface{}{
+					"type":        "number",
+					"description": "Only include Warning events from the last this many minutes (default: 60)",
+				},
+			},
+			Required: []string{},
+		},
+	}
+
+	// Change detection tool
+	m.tools["detect_changes"] = Tool{
+		Name:        "detect_changes",
+		Description: "Gather resources twice, a few seconds apart, and report which objects were added, removed, modified (with the changed fields) or recreated under the same name in between. Use this to catch churn that a single snapshot misses, such as pods being replaced or configmaps being rewritten while an issue is flapping",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Kubernetes namespace to watch (default: the kubeconfig context's namespace, \"*\" for all namespaces)",
+				},
+				"resourceTypes": map[string]interface{}{
+					"type":        "string",
+					"description": "Comma-separated resource types to compare, e.g. \"pods,configmaps\" (default: pods,deployments,replicasets,configmaps)",
+				},
+				"labelSelector": map[string]interface{}{
+					"type":        "string",
+					"description": "Label selector to filter resources (optional)",
+				},
+				"intervalSeconds": map[string]interface{}{
+					"type":        "number",
+					"description": "Seconds to wait between the two snapshots (default: 15, max: 45)",
+				},
+			},
+			Required: []string{},
+		},
 	}
 
-	// Register built-in tools
-	mcp.registerTools()
-	return mcp
-}
+	// Exec tool, only offered when enabled in the configuration
+	if len(m.execCommands) > 0 {
+		m.tools["exec_in_pod"] = Tool{
+			Name:        "exec_in_pod",
+			Description: fmt.Sprintf("Run a read-only diagnostic command inside a container and return its stdout, stderr and exit code, e.g. to read a mounted config file or list a data directory. Only these programs are allowed: %s. Arguments are split on spaces; there is no shell, so pipes, redirects and globs don't work", strings.Join(m.execCommands, ", ")),
+			InputSchema: ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"namespace": map[string]interface{}{
+						"type":        "string",
+						"description": "Kubernetes namespace of the pod (default: the kubeconfig context's namespace)",
+					},
+					"podName": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the pod",
+					},
+					"containerName": map[string]interface{}{
+						"type":        "string",
+						"description": "Container to run the command in (optional for single-container pods)",
+					},
+					"command": map[string]interface{}{
+						"type":        "string",
+						"description": "Command line to run, e.g. \"cat /etc/app/config.yaml\" or \"ls -la /data\"",
+					},
+				},
+				Required: []string{"podName", "command"},
+			},
+		}
+	}
 
-// registerTools registers all available MCP tools
-func (m *MCPService) registerTools() {
-	// Get pod health tool
-	m.tools["get_pod_health"] = Tool{
-		Name:        "get_pod_health",
-		Description: "Get the health status of pods in a namespace",
+	// Network policy tool
+	m.tools["get_network_policies"] = Tool{
+		Name:        "get_network_policies",
+		Description: "Find the NetworkPolicies that select a pod (or the pods matching a selector) and summarize the ingress and egress they allow: peers (pods, namespaces, IP blocks) and ports. Flags pods selected by a default-deny policy, pods with all ingress or egress denied, and egress rules that block DNS. Use this when a pod can't reach, or can't be reached by, another service",
 		InputSchema: ToolSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
 				"namespace": map[string]interface{}{
 					"type":        "string",
-					"description": "Kubernetes namespace to check (default: default)",
+					"description": "Kubernetes namespace of the pods (default: the kubeconfig context's namespace)",
+				},
+				"podName": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of a single pod to check (optional)",
 				},
 				"labelSelector": map[string]interface{}{
 					"type":        "string",
-					"description": "Label selector to filter pods (optional)",
+					"description": "Label selector for the pods to check (optional; default: all pods in the namespace)",
 				},
 			},
 			Required: []string{},
 		},
 	}
 
-	// Get deployment status tool
-	m.tools["get_deployment_status"] = Tool{
-		Name:        "get_deployment_status",
-		Description: "Get the status of deployments in a namespace",
+	// Resource quota tool
+	m.tools["get_resource_quotas"] = Tool{
+		Name:        "get_resource_quotas",
+		Description: "Report each ResourceQuota's hard limits against current usage and the defaults, minimums and maximums set by LimitRanges in a namespace. Flags quota resources that are nearly (90%+) or fully used, and quotas on CPU or memory that no LimitRange provides defaults for. Use this when pods fail to create with 'exceeded quota' or 'must specify limits', or get unexpected default requests and limits",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Kubernetes namespace to check (default: the kubeconfig context's namespace)",
+				},
+			},
+			Required: []string{},
+		},
+	}
+
+	// Rollout history tool
+	m.tools["get_rollout_history"] = Tool{
+		Name:        "get_rollout_history",
+		Description: "Report a deployment's revision history from its ReplicaSets (revision, creation time, change cause, images, ready replicas), the image change between the current and previous revision, and the rollout status with the Progressing, Available and ReplicaFailure conditions. Suggests `kubectl rollout undo` when the latest revision is failing. Use this to correlate an incident with a recent deployment change",
 		InputSchema: ToolSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
 				"namespace": map[string]interface{}{
 					"type":        "string",
-					"description": "Kubernetes namespace to check (default: default)",
+					"description": "Kubernetes namespace of the deployment (default: the kubeconfig context's namespace)",
 				},
 				"deploymentName": map[string]interface{}{
 					"type":        "string",
-					"description": "Specific deployment name (optional)",
+					"description": "Name of the deployment",
 				},
 			},
-			Required: []string{},
+			Required: []string{"deploymentName"},
 		},
 	}
 
-	// Get service endpoints tool
-	m.tools["get_service_endpoints"] = Tool{
-		Name:        "get_service_endpoints",
-		Description: "Get the endpoints and status of services in a namespace",
+	// Image pull diagnostics tool
+	m.tools["diagnose_image_pulls"] = Tool{
+		Name:        "diagnose_image_pulls",
+		Description: "Report each container's image, imagePullPolicy and resolved image ID for matching pods, with image pull waiting reasons (ErrImagePull, ImagePullBackOff, InvalidImageName), the kubelet's Failed pull events, a likely cause (image or tag not found, registry authentication failed, registry unreachable, rate limit) and whether the pod's imagePullSecrets exist. Failing containers are listed first. Use this for ImagePullBackOff or ErrImagePull",
 		InputSchema: ToolSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
 				"namespace": map[string]interface{}{
 					"type":        "string",
-					"description": "Kubernetes namespace to check (default: default)",
+					"description": "Kubernetes namespace (default: the kubeconfig context's namespace)",
 				},
-				"serviceName": map[string]interface{}{
+				"labelSelector": map[string]interface{}{
 					"type":        "string",
-					"description": "Specific service name (optional)",
+					"description": "Only check pods matching this label selector",
+				},
+				"podName": map[string]interface{}{
+					"type":        "string",
+					"description": "Only check this pod",
 				},
 			},
 			Required: []string{},
 		},
 	}
 
-	// Get recent events tool
-	m.tools["get_recent_events"] = Tool{
-		Name:        "get_recent_events",
-		Description: "Get recent Kubernetes events in a namespace",
+	// Node pods tool
+	m.tools["get_node_pods"] = Tool{
+		Name:        "get_node_pods",
+		Description: "List the pods scheduled to a node (selected by spec.nodeName) with their phase, ready containers, restarts, controller and, for unhealthy pods, what is wrong; unhealthy pods are listed first. Also reports the node's Ready status, other non-False conditions such as MemoryPressure or DiskPressure, whether it is cordoned, its taints and allocatable resources. Use this to answer what is running on a node and whether any of it is unhealthy",
 		InputSchema: ToolSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
+				"nodeName": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the node",
+				},
 				"namespace": map[string]interface{}{
 					"type":        "string",
-					"description": "Kubernetes namespace to check (default: default)",
+					"description": "Only list pods in this namespace (default: all namespaces)",
 				},
-				"resourceName": map[string]interface{}{
+			},
+			Required: []string{"nodeName"},
+		},
+	}
+
+	// Manifest validation tool
+	m.tools["validate_manifest"] = Tool{
+		Name:        "validate_manifest",
+		Description: "Validate a YAML or JSON manifest, with one or more documents, before it is applied. Each document is checked with a server-side dry-run apply, which validates it against the cluster's API schema, rejects unknown fields and runs admission webhooks without changing anything. Reports per document whether it is valid, the step that failed (parse, mapping, namespace, dry-run) and the errors. Use this when the user shares a manifest and asks whether it will apply or why it is rejected",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"manifest": map[string]interface{}{
 					"type":        "string",
-					"description": "Filter events for specific resource (optional)",
+					"description": "The manifest YAML, with documents separated by ---",
+				},
+			},
+			Required: []string{"manifest"},
+		},
+	}
+
+	// PodDisruptionBudget tool
+	m.tools["get_pod_disruption_budgets"] = Tool{
+		Name:        "get_pod_disruption_budgets",
+		Description: "Report each PodDisruptionBudget's minAvailable or maxUnavailable, selector, healthy versus desired pods and how many disruptions are currently allowed, flagging budgets that block all evictions and explaining why: too few healthy pods, or a budget such as maxUnavailable: 0 that can never be satisfied. Use this when a node drain, cluster upgrade or eviction is stuck",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Kubernetes namespace to check (default: the kubeconfig context's namespace, \"*\" for all namespaces)",
+				},
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Only report this PodDisruptionBudget (optional)",
 				},
 			},
 			Required: []string{},
 		},
 	}
 
-	// Get pod logs tool
-	m.tools["get_pod_logs"] = Tool{
-		Name:        "get_pod_logs",
-		Description: "Get logs from a specific pod",
+	// Namespace health score tool
+	m.tools["get_namespace_health_score"] = Tool{
+		Name:        "get_namespace_health_score",
+		Description: "Score a namespace's health from 0 (broken) to 100 (healthy) and list the top detractors, the pods, deployments and Warning events that cost it the most points. Use this for a quick quantitative answer to 'how healthy is this namespace' or to compare namespaces. " + m.healthScoreFormula(),
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Kubernetes namespace to score (default: the kubeconfig context's namespace)",
+				},
+				"eventMinutes": map[string]interface{}{
+					"type":        "number",
+					"description": "Window, in minutes, for counting Warning events and container restarts (default: 60)",
+				},
+				"limit": map[string]interface{}{
+					"type":        "number",
+					"description": fmt.Sprintf("Maximum number of detractors to return (default: 10, max: %d)", maxDetractors),
+				},
+			},
+			Required: []string{},
+		},
+	}
+
+	// Image pull secret wiring tool
+	m.tools["check_image_pull_secrets"] = Tool{
+		Name:        "check_image_pull_secrets",
+		Description: "Check how a pod or service account is wired to private registry credentials: the pod's service account, the imagePullSecrets on the pod and the service account, and whether each secret exists and is of type kubernetes.io/dockerconfigjson. Flags missing or wrong-type secrets and service account pull secrets a pod was created without. Secret contents are never read. Use this for ImagePullBackOff or 'unauthorized' pulls from a private registry",
 		InputSchema: ToolSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
 				"namespace": map[string]interface{}{
 					"type":        "string",
-					"description": "Kubernetes namespace (default: default)",
+					"description": "Kubernetes namespace (default: the kubeconfig context's namespace)",
 				},
 				"podName": map[string]interface{}{
 					"type":        "string",
-					"description": "Name of the pod to get logs from",
+					"description": "Pod to check, together with its service account",
 				},
-				"containerName": map[string]interface{}{
+				"serviceAccount": map[string]interface{}{
 					"type":        "string",
-					"description": "Container name (optional)",
+					"description": "Service account to check when no podName is given",
 				},
-				"lines": map[string]interface{}{
-					"type":        "number",
-					"description": "Number of lines to retrieve (default: 100)",
+			},
+			Required: []string{},
+		},
+	}
+
+	// Related resources tool
+	m.tools["get_related_resources"] = Tool{
+		Name:        "get_related_resources",
+		Description: "Get the family of resources connected to one resource, as a graph: its owners up the ownerReferences chain (e.g. pod -> ReplicaSet -> Deployment), what it owns (a Deployment's ReplicaSets and their pods), and the ConfigMaps, Secrets, PersistentVolumeClaims (with their PersistentVolume and StorageClass) and service account its pod spec references. Referenced objects that don't exist are flagged as missing. Objects are minimized and redacted; Secrets only show their type and key names. Use this to get full context from a single starting resource instead of several separate lookups",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"kind": map[string]interface{}{
+					"type":        "string",
+					"description": "Kind of the starting resource, e.g. Pod, Deployment, StatefulSet, CronJob; resource and short names such as deploy also work",
+				},
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the starting resource",
+				},
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Namespace of the starting resource (default: the kubeconfig context's namespace)",
+				},
+				"includeObjects": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Include each related object's spec and status, not just the graph (default: true)",
 				},
 			},
-			Required: []string{"podName"},
+			Required: []string{"kind", "name"},
+		},
+	}
+
+	// Explain resource tool
+	m.tools["explain_resource"] = Tool{
+		Name:        "explain_resource",
+		Description: "Get one named resource of any kind, minimized and redacted, with its most recent events, to explain what it is for, its current status and any anomalies. Use this when the user names a resource and asks what it is or whether it is healthy",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"kind": map[string]interface{}{
+					"type":        "string",
+					"description": "Kind of the resource, e.g. Pod, Deployment, Ingress, Certificate; resource and short names such as svc also work",
+				},
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the resource",
+				},
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Namespace of the resource (default: the kubeconfig context's namespace; ignored for cluster-scoped kinds)",
+				},
+			},
+			Required: []string{"kind", "name"},
 		},
 	}
 }
 
-// ListTools returns all available tools
+// ListTools returns all available tools, sorted by name
 func (m *MCPService) ListTools() []Tool {
 	tools := make([]Tool, 0, len(m.tools))
 	for _, tool := range m.tools {
 		tools = append(tools, tool)
 	}
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].Name < tools[j].Name
+	})
 	return tools
 }
 
-// ExecuteTool executes a specific tool with given arguments
-func (m *MCPService) ExecuteTool(ctx context.Context, request ToolRequest) (*ToolResult, error) {
+// ToolsJSON returns the tools the request with ctx may run as indented JSON: ListTools, less the admin-only
+// tools when they are reserved for admins. It is marshaled once when tools are registered, so every prompt
+// that embeds it gets the same bytes
+func (m *MCPService) ToolsJSON(ctx context.Context) string {
+	if m.restrictAdminTools && !isAdmin(ctx) {
+		return m.publicToolsJSON
+	}
+	return m.toolsJSON
+}
+
+// refreshToolsJSON re-marshals the cached ToolsJSON after the registered tools change
+func (m *MCPService) refreshToolsJSON() {
+	tools := m.ListTools()
+	data, err := json.MarshalIndent(tools, "", "  ")
+	if err != nil {
+		m.logger.Error("Failed to marshal tool list", zap.Error(err))
+		return
+	}
+	m.toolsJSON = string(data)
+
+	public := make([]Tool, 0, len(tools))
+	for _, tool := range tools {
+		if !adminOnlyTools[tool.Name] {
+			public = append(public, tool)
+		}
+	}
+	data, err = json.MarshalIndent(public, "", "  ")
+	if err != nil {
+		m.logger.Error("Failed to marshal tool list", zap.Error(err))
+		return
+	}
+	m.publicToolsJSON = string(data)
+}
+
+// ExecuteTool executes a specific tool with given arguments, within the tool's timeout
+func (m *MCPService) ExecuteTool(ctx context.Context, request ToolRequest) (result *ToolResult, err error) {
 	_, exists := m.tools[request.Name]
 	if !exists {
 		return &ToolResult{
@@ -192,12 +982,40 @@ func (m *MCPService) ExecuteTool(ctx context.Context, request ToolRequest) (*Too
 				Text: fmt.Sprintf("Unknown tool: %s", request.Name),
 			}},
 			IsError: true,
-		}, fmt.Errorf("unknown tool: %s", request.Name)
+		}, fmt.Errorf("%w: %s", ErrToolNotFound, request.Name)
+	}
+	if !m.toolAllowed(ctx, request.Name) {
+		m.log(ctx).Warn("Refusing admin-only MCP tool", zap.String("tool", request.Name))
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Tool %s is only available to admins", request.Name),
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: %s", ErrAdminRequired, request.Name)
 	}
 
-	m.logger.Info("Executing MCP tool",
+	// Only registered tools are recorded, so arbitrary names can't inflate metric cardinality
+	start := time.Now()
+	defer func() {
+		metrics.ObserveToolExecution(request.Name, err != nil || (result != nil && result.IsError), time.Since(start))
+	}()
+
+	m.log(ctx).Info("Executing MCP tool",
 		zap.String("tool", request.Name),
 		zap.Any("arguments", request.Arguments))
+	m.warnArgumentTypes(ctx, m.tools[request.Name], request.Arguments)
+
+	return m.runWithTimeout(ctx, request.Name, func(ctx context.Context) (*ToolResult, error) {
+		return m.dispatch(ctx, request)
+	})
+}
+
+// dispatch runs a registered tool
+func (m *MCPService) dispatch(ctx context.Context, request ToolRequest) (*ToolResult, error) {
+	if tool, ok := m.customTools[request.Name]; ok {
+		return m.executeCustomTool(ctx, tool, request.Arguments)
+	}
 
 	switch request.Name {
 	case "get_pod_health":
@@ -210,6 +1028,60 @@ func (m *MCPService) ExecuteTool(ctx context.Context, request ToolRequest) (*Too
 		return m.getRecentEvents(ctx, request.Arguments)
 	case "get_pod_logs":
 		return m.getPodLogs(ctx, request.Arguments)
+	case "get_namespaces":
+		return m.getNamespaces(ctx, request.Arguments)
+	case "get_pod_containers":
+		return m.getPodContainers(ctx, request.Arguments)
+	case "get_custom_resources":
+		return m.getCustomResources(ctx, request.Arguments)
+	case "check_pod_connectivity":
+		return m.checkPodConnectivity(ctx, request.Arguments)
+	case "get_rbac_status":
+		return m.getRBACStatus(ctx, request.Arguments)
+	case "get_workload_status":
+		return m.getWorkloadStatus(ctx, request.Arguments)
+	case "compare_resources":
+		return m.compareResources(ctx, request.Arguments)
+	case "get_top_pods":
+		return m.getTopPods(ctx, request.Arguments)
+	case "get_configmap":
+		return m.getConfigMap(ctx, request.Arguments)
+	case "get_secret_metadata":
+		return m.getSecretMetadata(ctx, request.Arguments)
+	case "get_application_overview":
+		return m.getApplicationOverview(ctx, request.Arguments)
+	case "diagnose_pending_pods":
+		return m.diagnosePendingPods(ctx, request.Arguments)
+	case "diagnose_probes":
+		return m.diagnoseProbes(ctx, request.Arguments)
+	case "get_cluster_health_summary":
+		return m.getClusterHealthSummary(ctx, request.Arguments)
+	case "detect_changes":
+		return m.detectChanges(ctx, request.Arguments)
+	case "exec_in_pod":
+		return m.execInPod(ctx, request.Arguments)
+	case "get_network_policies":
+		return m.getNetworkPolicies(ctx, request.Arguments)
+	case "get_resource_quotas":
+		return m.getResourceQuotas(ctx, request.Arguments)
+	case "get_rollout_history":
+		return m.getRolloutHistory(ctx, request.Arguments)
+	case "diagnose_image_pulls":
+		return m.diagnoseImagePulls(ctx, request.Arguments)
+	case "get_node_pods":
+		return m.getNodePods(ctx, request.Arguments)
+	case "validate_manifest":
+		return m.validateManifest(ctx, request.Arguments)
+	case "get_pod_disruption_budgets":
+		return m.getPodDisruptionBudgets(ctx, request.Arguments)
+	case "get_namespace_health_score":
+		return m.getNamespaceHealthScore(ctx, request.Arguments)
+	case "check_image_pull_secrets":
+		return m.checkImagePullSecrets(ctx, request.Arguments)
+	case "get_related_resources":
+		return m.getRelatedResources(ctx, request.Arguments)
+	case "explain_resource":
+		return m.explainResource(ctx, request.Arguments)
 	default:
 		return &ToolResult{
 			Content: []ToolContent{{
@@ -217,39 +1089,34 @@ func (m *MCPService) ExecuteTool(ctx context.Context, request ToolRequest) (*Too
 				Text: fmt.Sprintf("Tool %s is registered but not implemented", request.Name),
 			}},
 			IsError: true,
-		}, fmt.Errorf("tool not implemented: %s", request.Name)
-	}
-}
-
-// Helper function to get string parameter with default
-func getStringParam(args map[string]interface{}, key, defaultValue string) string {
-	if val, ok := args[key]; ok {
-		if str, ok := val.(string); ok {
-			return str
-		}
+		}, fmt.Errorf("%w: %s", ErrToolNotImplemented, request.Name)
 	}
-	return defaultValue
 }
 
-// Helper function to get int parameter with default
-func getIntParam(args map[string]interface{}, key string, defaultValue int64) int64 {
-	if val, ok := args[key]; ok {
-		switch v := val.(type) {
-		case float64:
-			return int64(v)
-		case int:
-			return int64(v)
-		case int64:
-			return v
-		}
-	}
-	return defaultValue
+// gather lists resources for a tool, minimized since tool output is sent to the model
+func (m *MCPService) gather(ctx context.Context, resourceTypes []string, namespace, labelSelector string) (*kubernetes.GatherResourcesResponse, error) {
+	return m.k8sService.Gather(ctx, kubernetes.GatherOptions{
+		ResourceTypes: resourceTypes,
+		Namespace:     namespace,
+		LabelSelector: labelSelector,
+		Minimize:      true,
+	})
 }
 
 // getPodHealth retrieves pod health information
 func (m *MCPService) getPodHealth(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
-	namespace := getStringParam(args, "namespace", "default")
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
 	labelSelector := getStringParam(args, "labelSelector", "")
+	newerThan, olderThan, err := getAgeFilter(args)
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: err.Error(),
+			}},
+			IsError: true,
+		}, err
+	}
 
 	if m.k8sService == nil {
 		return &ToolResult{
@@ -258,11 +1125,18 @@ func (m *MCPService) getPodHealth(ctx context.Context, args map[string]interface
 				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
 			}},
 			IsError: true,
-		}, fmt.Errorf("kubernetes service not available")
+		}, kubernetes.ErrClusterUnavailable
 	}
 
 	// Gather pod information
-	resources, err := m.k8sService.GatherResources(ctx, []string{"pods"}, namespace, labelSelector)
+	resources, err := m.k8sService.Gather(ctx, kubernetes.GatherOptions{
+		ResourceTypes: []string{"pods"},
+		Namespace:     namespace,
+		LabelSelector: labelSelector,
+		Minimize:      true,
+		NewerThan:     newerThan,
+		OlderThan:     olderThan,
+	})
 	if err != nil {
 		return &ToolResult{
 			Content: []ToolContent{{
@@ -273,20 +1147,45 @@ func (m *MCPService) getPodHealth(ctx context.Context, args map[string]interface
 		}, err
 	}
 
-	// Format the response
-	podsData, _ := json.MarshalIndent(resources.Resources["pods"], "", "  ")
+	// Format the response; the controller summary covers every pod, the pod list only the most problematic
+	podsOutput := resources.Resources["pods"]
+	text := fmt.Sprintf("Pod health information for namespace '%s'%s:\n\n", namespace, ageFilterText(newerThan, olderThan))
+	var omittedNote string
+	if pods, ok := resources.Resources["pods"].(*v1.PodList); ok && len(pods.Items) > 0 {
+		controllersData, _ := json.MarshalIndent(podsByController(ctx, m.k8sService.NewOwnerResolver(), pods), "", "  ")
+		text += fmt.Sprintf("Pods by controller:\n%s\n\nPods:\n", string(controllersData))
+
+		if maxPods := m.maxItems("get_pod_health"); len(pods.Items) > maxPods {
+			kept := mostProblematicPods(pods, maxPods)
+			omittedHealthy := 0
+			for i := range pods.Items {
+				if kubernetes.IsPodHealthy(&pods.Items[i]) {
+					omittedHealthy++
+				}
+			}
+			for i := range kept.Items {
+				if kubernetes.IsPodHealthy(&kept.Items[i]) {
+					omittedHealthy--
+				}
+			}
+			omittedNote = fmt.Sprintf("\n\nShowing the %d most problematic of %d pods; %d omitted (%d of them healthy). Filter by labelSelector to see the rest",
+				len(kept.Items), len(pods.Items), len(pods.Items)-len(kept.Items), omittedHealthy)
+			podsOutput = kept
+		}
+	}
+	podsData, _ := json.MarshalIndent(podsOutput, "", "  ")
 
 	return &ToolResult{
 		Content: []ToolContent{{
 			Type: "text",
-			Text: fmt.Sprintf("Pod health information for namespace '%s':\n\n%s", namespace, string(podsData)),
+			Text: text + string(podsData) + omittedNote,
 		}},
 	}, nil
 }
 
 // getDeploymentStatus retrieves deployment status information
 func (m *MCPService) getDeploymentStatus(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
-	namespace := getStringParam(args, "namespace", "default")
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
 	deploymentName := getStringParam(args, "deploymentName", "")
 
 	if m.k8sService == nil {
@@ -296,7 +1195,7 @@ func (m *MCPService) getDeploymentStatus(ctx context.Context, args map[string]in
 				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
 			}},
 			IsError: true,
-		}, fmt.Errorf("kubernetes service not available")
+		}, kubernetes.ErrClusterUnavailable
 	}
 
 	labelSelector := ""
@@ -304,7 +1203,7 @@ func (m *MCPService) getDeploymentStatus(ctx context.Context, args map[string]in
 		labelSelector = fmt.Sprintf("app=%s", deploymentName)
 	}
 
-	resources, err := m.k8sService.GatherResources(ctx, []string{"deployments"}, namespace, labelSelector)
+	resources, err := m.gather(ctx, []string{"deployments"}, namespace, labelSelector)
 	if err != nil {
 		return &ToolResult{
 			Content: []ToolContent{{
@@ -327,7 +1226,7 @@ func (m *MCPService) getDeploymentStatus(ctx context.Context, args map[string]in
 
 // getServiceEndpoints retrieves service endpoint information
 func (m *MCPService) getServiceEndpoints(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
-	namespace := getStringParam(args, "namespace", "default")
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
 	serviceName := getStringParam(args, "serviceName", "")
 
 	if m.k8sService == nil {
@@ -337,7 +1236,7 @@ func (m *MCPService) getServiceEndpoints(ctx context.Context, args map[string]in
 				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
 			}},
 			IsError: true,
-		}, fmt.Errorf("kubernetes service not available")
+		}, kubernetes.ErrClusterUnavailable
 	}
 
 	labelSelector := ""
@@ -345,7 +1244,19 @@ func (m *MCPService) getServiceEndpoints(ctx context.Context, args map[string]in
 		labelSelector = fmt.Sprintf("app=%s", serviceName)
 	}
 
-	resources, err := m.k8sService.GatherResources(ctx, []string{"services"}, namespace, labelSelector)
+	// EndpointSlices are labeled with their service name rather than the service's own labels
+	endpointSliceSelector := ""
+	if serviceName != "" {
+		endpointSliceSelector = fmt.Sprintf("%s=%s", discoveryv1.LabelServiceName, serviceName)
+	}
+
+	resources, err := m.k8sService.Gather(ctx, kubernetes.GatherOptions{
+		ResourceTypes:  []string{"services", "endpointslices"},
+		Namespace:      namespace,
+		LabelSelector:  labelSelector,
+		LabelSelectors: map[string]string{"endpointslices": endpointSliceSelector},
+		Minimize:       true,
+	})
 	if err != nil {
 		return &ToolResult{
 			Content: []ToolContent{{
@@ -358,35 +1269,98 @@ func (m *MCPService) getServiceEndpoints(ctx context.Context, args map[string]in
 
 	servicesData, _ := json.MarshalIndent(resources.Resources["services"], "", "  ")
 
+	endpointsText := "Endpoint data unavailable"
+	if services, ok := resources.Resources["services"].(*v1.ServiceList); ok {
+		slices, _ := resources.Resources["endpointslices"].(*discoveryv1.EndpointSliceList)
+		endpointsData, _ := json.MarshalIndent(summarizeServiceEndpoints(services, slices), "", "  ")
+		endpointsText = string(endpointsData)
+	}
+
 	return &ToolResult{
 		Content: []ToolContent{{
 			Type: "text",
-			Text: fmt.Sprintf("Service endpoints for namespace '%s':\n\n%s", namespace, string(servicesData)),
+			Text: fmt.Sprintf("Service endpoints for namespace '%s':\n\n%s\n\nServices:\n%s", namespace, endpointsText, string(servicesData)),
 		}},
 	}, nil
 }
 
 // getRecentEvents retrieves recent Kubernetes events
 func (m *MCPService) getRecentEvents(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
-	namespace := getStringParam(args, "namespace", "default")
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
 	resourceName := getStringParam(args, "resourceName", "")
+	eventType := getStringParam(args, "type", v1.EventTypeWarning)
+	sinceMinutes := getIntParam(args, "sinceMinutes", 60)
+	maxEvents := m.maxItems("get_recent_events")
+	limit := getIntParam(args, "limit", int64(min(defaultRecentEvents, maxEvents)))
 
-	if m.k8sService == nil {
+	switch {
+	case strings.EqualFold(eventType, v1.EventTypeWarning):
+		eventType = v1.EventTypeWarning
+	case strings.EqualFold(eventType, v1.EventTypeNormal):
+		eventType = v1.EventTypeNormal
+	case strings.EqualFold(eventType, "all"):
+		eventType = ""
+	default:
 		return &ToolResult{
 			Content: []ToolContent{{
 				Type: "text",
-				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+				Text: "type must be Warning, Normal or all",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: type must be Warning, Normal or all", ErrInvalidArguments)
+	}
+	if sinceMinutes <= 0 {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "sinceMinutes must be positive",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: sinceMinutes must be positive", ErrInvalidArguments)
+	}
+	if limit <= 0 {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "limit must be positive",
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: limit must be positive", ErrInvalidArguments)
+	}
+	// Clamp oversized requests to the configured cap rather than failing them
+	var notes []string
+	if limit > int64(maxEvents) {
+		notes = append(notes, fmt.Sprintf("requested %d events, clamped to the maximum of %d", limit, maxEvents))
+		limit = int64(maxEvents)
+	}
+	newerThan, olderThan, err := getAgeFilter(args)
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: err.Error(),
 			}},
 			IsError: true,
-		}, fmt.Errorf("kubernetes service not available")
+		}, err
 	}
 
-	labelSelector := ""
-	if resourceName != "" {
-		labelSelector = fmt.Sprintf("involvedObject.name=%s", resourceName)
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
 	}
 
-	resources, err := m.k8sService.GatherResources(ctx, []string{"events"}, namespace, labelSelector)
+	resources, err := m.k8sService.Gather(ctx, kubernetes.GatherOptions{
+		ResourceTypes: []string{"events"},
+		Namespace:     namespace,
+		Minimize:      true,
+		NewerThan:     newerThan,
+		OlderThan:     olderThan,
+	})
 	if err != nil {
 		return &ToolResult{
 			Content: []ToolContent{{
@@ -396,20 +1370,72 @@ func (m *MCPService) getRecentEvents(ctx context.Context, args map[string]interf
 			IsError: true,
 		}, err
 	}
+	if msg, ok := resources.Resources["events_error"].(string); ok {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error listing events: %s", msg),
+			}},
+			IsError: true,
+		}, fmt.Errorf("failed to list events: %s", msg)
+	}
+
+	// Events can't be label-selected by type, last-seen time or involved object, so they are filtered here
+	since := time.Now().Add(-time.Duration(sinceMinutes) * time.Minute)
+	events := []v1.Event{}
+	if list, ok := resources.Resources["events"].(*v1.EventList); ok {
+		for _, event := range list.Items {
+			if eventType != "" && event.Type != eventType {
+				continue
+			}
+			if resourceName != "" && event.InvolvedObject.Name != resourceName {
+				continue
+			}
+			if kubernetes.EventTime(&event).Time.Before(since) {
+				continue
+			}
+			events = append(events, event)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return kubernetes.EventTime(&events[i]).After(kubernetes.EventTime(&events[j]).Time)
+	})
+
+	matched := len(events)
+	if int64(matched) > limit {
+		events = events[:limit]
+	}
+
+	filter := "all types"
+	if eventType != "" {
+		filter = eventType
+	}
+	if resourceName != "" {
+		filter += fmt.Sprintf(" for %s", resourceName)
+	}
+	filter += ageFilterText(newerThan, olderThan)
+	eventsData, _ := json.MarshalIndent(events, "", "  ")
+	if omitted := matched - len(events); omitted > 0 {
+		notes = append(notes, fmt.Sprintf("%d older events omitted; narrow sinceMinutes, type or resourceName to see them", omitted))
+	}
 
-	eventsData, _ := json.MarshalIndent(resources.Resources["events"], "", "  ")
+	text := fmt.Sprintf("Recent events for namespace '%s' (%s, last %d minutes; %d of %d, newest first):\n\n%s",
+		namespace, filter, sinceMinutes, len(events), matched, string(eventsData))
+	if len(notes) > 0 {
+		text += fmt.Sprintf("\n\nNote: %s", strings.Join(notes, "; "))
+	}
 
 	return &ToolResult{
 		Content: []ToolContent{{
 			Type: "text",
-			Text: fmt.Sprintf("Recent events for namespace '%s':\n\n%s", namespace, string(eventsData)),
+			Text: text,
 		}},
 	}, nil
 }
 
 // getPodLogs retrieves logs from a specific pod
 func (m *MCPService) getPodLogs(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
-	namespace := getStringParam(args, "namespace", "default")
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
 	podName := getStringParam(args, "podName", "")
 	containerName := getStringParam(args, "containerName", "")
 	lines := getIntParam(args, "lines", 100)
@@ -421,7 +1447,7 @@ func (m *MCPService) getPodLogs(ctx context.Context, args map[string]interface{}
 				Text: "Pod name is required for getting logs",
 			}},
 			IsError: true,
-		}, fmt.Errorf("pod name is required")
+		}, fmt.Errorf("%w: pod name is required", ErrInvalidArguments)
 	}
 
 	if m.k8sService == nil {
@@ -431,10 +1457,17 @@ func (m *MCPService) getPodLogs(ctx context.Context, args map[string]interface{}
 				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
 			}},
 			IsError: true,
-		}, fmt.Errorf("kubernetes service not available")
+		}, kubernetes.ErrClusterUnavailable
 	}
 
-	logs, err := m.k8sService.GetPodLogs(ctx, namespace, podName, containerName, lines)
+	// Clamp oversized requests; a non-positive value would otherwise fetch the whole log
+	var notes []string
+	if lines <= 0 || lines > m.maxLogLines {
+		notes = append(notes, fmt.Sprintf("requested %d lines, clamped to the server maximum of %d", lines, m.maxLogLines))
+		lines = m.maxLogLines
+	}
+
+	logs, truncated, err := m.k8sService.GetPodLogs(ctx, namespace, podName, containerName, lines, m.maxLogBytes)
 	if err != nil {
 		return &ToolResult{
 			Content: []ToolContent{{
@@ -445,11 +1478,73 @@ func (m *MCPService) getPodLogs(ctx context.Context, args map[string]interface{}
 		}, err
 	}
 
+	if truncated {
+		notes = append(notes, fmt.Sprintf("output truncated to the most recent %d bytes", m.maxLogBytes))
+	}
+
+	header := fmt.Sprintf("Logs for pod '%s' in namespace '%s' (last %d lines)", podName, namespace, lines)
+	if len(notes) > 0 {
+		m.log(ctx).Info("Pod log output limited",
+			zap.String("pod", podName),
+			zap.String("namespace", namespace),
+			zap.Strings("notes", notes))
+		header += fmt.Sprintf("\nNote: %s", strings.Join(notes, "; "))
+	}
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: fmt.Sprintf("%s:\n\n%s", header, logs),
+		}},
+	}, nil
+}
+
+// getNamespaces lists the namespaces in the cluster
+func (m *MCPService) getNamespaces(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	namespaces, err := m.k8sService.ListNamespaces(ctx)
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error listing namespaces: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+
+	type namespaceSummary struct {
+		Name    string            `json:"name"`
+		Status  string            `json:"status"`
+		Created string            `json:"created"`
+		Labels  map[string]string `json:"labels,omitempty"`
+	}
+
+	summaries := make([]namespaceSummary, 0, len(namespaces.Items))
+	for _, ns := range namespaces.Items {
+		summaries = append(summaries, namespaceSummary{
+			Name:    ns.Name,
+			Status:  string(ns.Status.Phase),
+			Created: ns.CreationTimestamp.UTC().Format(time.RFC3339),
+			Labels:  ns.Labels,
+		})
+	}
+
+	namespacesData, _ := json.MarshalIndent(summaries, "", "  ")
+
 	return &ToolResult{
 		Content: []ToolContent{{
 			Type: "text",
-			Text: fmt.Sprintf("Logs for pod '%s' in namespace '%s' (last %d lines):\n\n%s",
-				podName, namespace, lines, logs),
+			Text: fmt.Sprintf("Namespaces in the cluster (%d):\n\n%s", len(summaries), string(namespacesData)),
 		}},
 	}, nil
 }
@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultToolTimeout bounds a tool without its own entry in defaultToolTimeouts
+const defaultToolTimeout = 30 * time.Second
+
+// defaultToolTimeouts are the limits of tools that routinely take longer than defaultToolTimeout: those that
+// read logs, run commands, gather many resource types or wait between snapshots. detect_changes waits up to
+// maxChangeInterval seconds on its own
+var defaultToolTimeouts = map[string]time.Duration{
+	"get_pod_logs":               60 * time.Second,
+	"exec_in_pod":                execTimeout + 15*time.Second,
+	"get_application_overview":   60 * time.Second,
+	"get_cluster_health_summary": 60 * time.Second,
+	"diagnose_image_pulls":       60 * time.Second,
+	"validate_manifest":          60 * time.Second,
+	"detect_changes":             maxChangeInterval*time.Second + 60*time.Second,
+}
+
+// WithToolTimeouts sets the time a tool may run before ExecuteTool gives up on it. defaultTimeout applies
+// to tools without a built-in or configured limit; overrides set the limit of individual tools by name.
+// Non-positive values keep the built-in limits
+func WithToolTimeouts(defaultTimeout time.Duration, overrides map[string]time.Duration) Option {
+	return func(m *MCPService) {
+		if defaultTimeout > 0 {
+			m.defaultToolTimeout = defaultTimeout
+		}
+		for name, timeout := range overrides {
+			if timeout > 0 {
+				m.toolTimeouts[name] = timeout
+			}
+		}
+	}
+}
+
+// toolTimeout returns the time the named tool may run
+func (m *MCPService) toolTimeout(name string) time.Duration {
+	if timeout, ok := m.toolTimeouts[name]; ok {
+		return timeout
+	}
+	return m.defaultToolTimeout
+}
+
+// warnUnknownToolTimeouts logs configured timeouts for tools that aren't registered, which usually
+// means a misspelled tool name
+func (m *MCPService) warnUnknownToolTimeouts() {
+	for name := range m.toolTimeouts {
+		if _, ok := m.tools[name]; !ok && defaultToolTimeouts[name] == 0 {
+			m.logger.Warn("Ignoring timeout for unknown MCP tool", zap.String("tool", name))
+		}
+	}
+}
+
+// runWithTimeout runs a tool under its timeout. When the tool's own deadline passes while ctx is still
+// live, the result says which tool timed out and after how long, so the model can retry or move on
+func (m *MCPService) runWithTimeout(ctx context.Context, name string, run func(context.Context) (*ToolResult, error)) (*ToolResult, error) {
+	timeout := m.toolTimeout(name)
+	toolCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result, err := run(toolCtx)
+	if ctx.Err() == nil && errors.Is(toolCtx.Err(), context.DeadlineExceeded) && (err != nil || result == nil || result.IsError) {
+		m.log(ctx).Warn("MCP tool timed out",
+			zap.String("tool", name),
+			zap.Duration("timeout", timeout))
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Tool %s timed out after %s. Try narrowing it to a namespace or fewer resources", name, timeout),
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: %s after %s", ErrToolTimeout, name, timeout)
+	}
+	return result, err
+}
@@ -0,0 +1,198 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"kube-sherlock/internal/kubernetes"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Limits on the number of pods returned by get_top_pods
+const (
+	defaultTopPods = 10
+	maxTopPods     = 50
+)
+
+// podTopEntry reports a pod's usage against its requests and limits
+type podTopEntry struct {
+	Namespace string        `json:"namespace"`
+	Pod       string        `json:"pod"`
+	Node      string        `json:"node,omitempty"`
+	CPU       resourceUsage `json:"cpu"`
+	Memory    resourceUsage `json:"memory"`
+
+	// raw usage used for sorting: millicores and bytes
+	cpuMillis   int64
+	memoryBytes int64
+}
+
+// resourceUsage describes usage of one resource relative to the pod's requests and limits
+type resourceUsage struct {
+	Usage            string   `json:"usage"`
+	Request          string   `json:"request,omitempty"`
+	Limit            string   `json:"limit,omitempty"`
+	PercentOfRequest *float64 `json:"percentOfRequest,omitempty"`
+	PercentOfLimit   *float64 `json:"percentOfLimit,omitempty"`
+}
+
+// getTopPods lists the pods using the most CPU or memory, like kubectl top pods
+func (m *MCPService) getTopPods(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+	sortBy := strings.ToLower(getStringParam(args, "sortBy", "cpu"))
+	limit := getIntParam(args, "limit", defaultTopPods)
+
+	if sortBy != "cpu" && sortBy != "memory" {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Unsupported sortBy '%s': use cpu or memory", sortBy),
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: unsupported sortBy %s", ErrInvalidArguments, sortBy)
+	}
+	if limit <= 0 {
+		limit = defaultTopPods
+	} else if limit > maxTopPods {
+		limit = maxTopPods
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	metricsNamespace := namespace
+	if namespace == kubernetes.AllNamespaces {
+		metricsNamespace = ""
+	}
+	usages, err := m.k8sService.ListPodUsage(ctx, metricsNamespace)
+	if err != nil {
+		text := fmt.Sprintf("Error getting pod metrics: %v", err)
+		if errors.Is(err, kubernetes.ErrMetricsUnavailable) {
+			text = "Pod metrics are unavailable. get_top_pods requires metrics-server to be installed in the cluster."
+		}
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: text,
+			}},
+			IsError: true,
+		}, err
+	}
+
+	// Pod specs supply the node and requests/limits; usage is still reported if they can't be listed
+	pods := make(map[string]*v1.Pod)
+	resources, err := m.gather(ctx, []string{"pods"}, namespace, "")
+	if err == nil {
+		if podList, ok := resources.Resources["pods"].(*v1.PodList); ok {
+			for i := range podList.Items {
+				pod := &podList.Items[i]
+				pods[pod.Namespace+"/"+pod.Name] = pod
+			}
+		}
+	}
+
+	entries := make([]podTopEntry, 0, len(usages))
+	for _, usage := range usages {
+		entries = append(entries, topEntry(usage, pods[usage.Namespace+"/"+usage.Name]))
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if sortBy == "memory" && a.memoryBytes != b.memoryBytes {
+			return a.memoryBytes > b.memoryBytes
+		}
+		if sortBy == "cpu" && a.cpuMillis != b.cpuMillis {
+			return a.cpuMillis > b.cpuMillis
+		}
+		return a.Namespace+"/"+a.Pod < b.Namespace+"/"+b.Pod
+	})
+
+	total := len(entries)
+	if int64(len(entries)) > limit {
+		entries = entries[:limit]
+	}
+
+	entriesData, _ := json.MarshalIndent(entries, "", "  ")
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: fmt.Sprintf("Top %d of %d pods by %s in namespace '%s':\n\n%s",
+				len(entries), total, sortBy, namespace, string(entriesData)),
+		}},
+	}, nil
+}
+
+// topEntry combines a pod's measured usage with the requests and limits from its spec, when known
+func topEntry(usage kubernetes.PodUsage, pod *v1.Pod) podTopEntry {
+	entry := podTopEntry{
+		Namespace:   usage.Namespace,
+		Pod:         usage.Name,
+		CPU:         resourceUsage{Usage: usage.CPU.String()},
+		Memory:      resourceUsage{Usage: usage.Memory.String()},
+		cpuMillis:   usage.CPU.MilliValue(),
+		memoryBytes: usage.Memory.Value(),
+	}
+	if pod == nil {
+		return entry
+	}
+
+	entry.Node = pod.Spec.NodeName
+	requests, limits := podRequestsAndLimits(pod)
+	entry.CPU = compareUsage(usage.CPU, requests[v1.ResourceCPU], limits[v1.ResourceCPU])
+	entry.Memory = compareUsage(usage.Memory, requests[v1.ResourceMemory], limits[v1.ResourceMemory])
+	return entry
+}
+
+// podRequestsAndLimits sums the requests and limits of a pod's app containers
+func podRequestsAndLimits(pod *v1.Pod) (v1.ResourceList, v1.ResourceList) {
+	requests, limits := v1.ResourceList{}, v1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		addResources(requests, container.Resources.Requests)
+		addResources(limits, container.Resources.Limits)
+	}
+	return requests, limits
+}
+
+// addResources adds each quantity in src to dst
+func addResources(dst, src v1.ResourceList) {
+	for name, quantity := range src {
+		total := dst[name]
+		total.Add(quantity)
+		dst[name] = total
+	}
+}
+
+// compareUsage reports usage as a percentage of the request and limit, when they are set
+func compareUsage(usage, request, limit resource.Quantity) resourceUsage {
+	result := resourceUsage{Usage: usage.String()}
+	if !request.IsZero() {
+		result.Request = request.String()
+		result.PercentOfRequest = percentOf(usage, request)
+	}
+	if !limit.IsZero() {
+		result.Limit = limit.String()
+		result.PercentOfLimit = percentOf(usage, limit)
+	}
+	return result
+}
+
+// percentOf returns usage as a percentage of total, rounded to one decimal place
+func percentOf(usage, total resource.Quantity) *float64 {
+	percent := math.Round(float64(usage.MilliValue())/float64(total.MilliValue())*1000) / 10
+	return &percent
+}
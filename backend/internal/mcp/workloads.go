@@ -0,0 +1,310 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"kube-sherlock/internal/kubernetes"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Rollout states reported by get_workload_status
+const (
+	rolloutComplete    = "complete"
+	rolloutProgressing = "progressing"
+	rolloutStalled     = "stalled"
+	rolloutPending     = "pending"
+)
+
+// workloadKinds maps the kind argument of get_workload_status to the resource types it gathers
+var workloadKinds = map[string][]string{
+	"deployment":  {"deployments"},
+	"statefulset": {"statefulsets"},
+	"daemonset":   {"daemonsets"},
+	"all":         {"deployments", "statefulsets", "daemonsets"},
+}
+
+// workloadStatus summarizes the rollout state of a Deployment, StatefulSet or DaemonSet
+type workloadStatus struct {
+	Kind           string `json:"kind"`
+	Name           string `json:"name"`
+	Namespace      string `json:"namespace"`
+	RolloutStatus  string `json:"rolloutStatus"`
+	RolloutMessage string `json:"rolloutMessage,omitempty"`
+	Desired        int32  `json:"desired"`
+	Current        int32  `json:"current"`
+	Ready          int32  `json:"ready"`
+	Updated        int32  `json:"updated"`
+	Available      int32  `json:"available"`
+	UpdateStrategy string `json:"updateStrategy"`
+
+	// StatefulSet details
+	Partition            *int32   `json:"partition,omitempty"`
+	CurrentRevision      string   `json:"currentRevision,omitempty"`
+	UpdateRevision       string   `json:"updateRevision,omitempty"`
+	PodManagementPolicy  string   `json:"podManagementPolicy,omitempty"`
+	VolumeClaimTemplates []string `json:"volumeClaimTemplates,omitempty"`
+
+	// DaemonSet details
+	NodesMisscheduled int32 `json:"nodesMisscheduled,omitempty"`
+
+	Conditions []string `json:"conditions,omitempty"`
+}
+
+// getWorkloadStatus reports rollout status for Deployments, StatefulSets and DaemonSets
+func (m *MCPService) getWorkloadStatus(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	namespace := getStringParam(args, "namespace", m.k8sService.DefaultNamespace())
+	kind := strings.ToLower(getStringParam(args, "kind", "all"))
+	name := getStringParam(args, "name", "")
+
+	resourceTypes, ok := workloadKinds[kind]
+	if !ok {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Unsupported workload kind '%s': use deployment, statefulset, daemonset or all", kind),
+			}},
+			IsError: true,
+		}, fmt.Errorf("%w: unsupported workload kind %s", ErrInvalidArguments, kind)
+	}
+
+	if m.k8sService == nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: "Kubernetes service not available. Please ensure cluster connectivity.",
+			}},
+			IsError: true,
+		}, kubernetes.ErrClusterUnavailable
+	}
+
+	resources, err := m.gather(ctx, resourceTypes, namespace, "")
+	if err != nil {
+		return &ToolResult{
+			Content: []ToolContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error gathering workload information: %v", err),
+			}},
+			IsError: true,
+		}, err
+	}
+
+	statuses := []workloadStatus{}
+	if deployments, ok := resources.Resources["deployments"].(*appsv1.DeploymentList); ok {
+		for i := range deployments.Items {
+			statuses = append(statuses, deploymentStatus(&deployments.Items[i]))
+		}
+	}
+	if statefulSets, ok := resources.Resources["statefulsets"].(*appsv1.StatefulSetList); ok {
+		for i := range statefulSets.Items {
+			statuses = append(statuses, statefulSetStatus(&statefulSets.Items[i]))
+		}
+	}
+	if daemonSets, ok := resources.Resources["daemonsets"].(*appsv1.DaemonSetList); ok {
+		for i := range daemonSets.Items {
+			statuses = append(statuses, daemonSetStatus(&daemonSets.Items[i]))
+		}
+	}
+
+	if name != "" {
+		filtered := []workloadStatus{}
+		for _, status := range statuses {
+			if status.Name == name {
+				filtered = append(filtered, status)
+			}
+		}
+		statuses = filtered
+	}
+
+	var gatherErrors []string
+	for _, resourceType := range resourceTypes {
+		if msg, ok := resources.Resources[resourceType+"_error"].(string); ok {
+			gatherErrors = append(gatherErrors, fmt.Sprintf("%s: %s", resourceType, msg))
+		}
+	}
+
+	statusData, _ := json.MarshalIndent(statuses, "", "  ")
+	text := fmt.Sprintf("Workload status for namespace '%s':\n\n%s", namespace, string(statusData))
+	if len(gatherErrors) > 0 {
+		text += fmt.Sprintf("\n\nErrors:\n%s", strings.Join(gatherErrors, "\n"))
+	}
+
+	return &ToolResult{
+		Content: []ToolContent{{
+			Type: "text",
+			Text: text,
+		}},
+	}, nil
+}
+
+// deploymentStatus derives rollout status the way kubectl rollout status does
+func deploymentStatus(deployment *appsv1.Deployment) workloadStatus {
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	status := workloadStatus{
+		Kind:           "Deployment",
+		Name:           deployment.Name,
+		Namespace:      deployment.Namespace,
+		Desired:        desired,
+		Current:        deployment.Status.Replicas,
+		Ready:          deployment.Status.ReadyReplicas,
+		Updated:        deployment.Status.UpdatedReplicas,
+		Available:      deployment.Status.AvailableReplicas,
+		UpdateStrategy: string(deployment.Spec.Strategy.Type),
+	}
+
+	progressDeadlineExceeded := false
+	for _, condition := range deployment.Status.Conditions {
+		status.Conditions = append(status.Conditions, formatCondition(string(condition.Type), condition.Status, condition.Reason, condition.Message))
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			progressDeadlineExceeded = true
+		}
+	}
+
+	switch {
+	case deployment.Status.ObservedGeneration < deployment.Generation:
+		status.RolloutStatus, status.RolloutMessage = rolloutPending, "waiting for the controller to observe the latest spec"
+	case progressDeadlineExceeded:
+		status.RolloutStatus, status.RolloutMessage = rolloutStalled, "progress deadline exceeded"
+	case status.Updated < desired:
+		status.RolloutStatus = rolloutProgressing
+		status.RolloutMessage = fmt.Sprintf("%d of %d replicas updated", status.Updated, desired)
+	case status.Current > status.Updated:
+		status.RolloutStatus = rolloutProgressing
+		status.RolloutMessage = fmt.Sprintf("%d old replicas pending termination", status.Current-status.Updated)
+	case status.Available < status.Updated:
+		status.RolloutStatus = rolloutProgressing
+		status.RolloutMessage = fmt.Sprintf("%d of %d updated replicas available", status.Available, status.Updated)
+	default:
+		status.RolloutStatus = rolloutComplete
+	}
+	return status
+}
+
+// statefulSetStatus derives rollout status including partitioned and OnDelete updates
+func statefulSetStatus(statefulSet *appsv1.StatefulSet) workloadStatus {
+	desired := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		desired = *statefulSet.Spec.Replicas
+	}
+	status := workloadStatus{
+		Kind:                "StatefulSet",
+		Name:                statefulSet.Name,
+		Namespace:           statefulSet.Namespace,
+		Desired:             desired,
+		Current:             statefulSet.Status.Replicas,
+		Ready:               statefulSet.Status.ReadyReplicas,
+		Updated:             statefulSet.Status.UpdatedReplicas,
+		Available:           statefulSet.Status.AvailableReplicas,
+		UpdateStrategy:      string(statefulSet.Spec.UpdateStrategy.Type),
+		CurrentRevision:     statefulSet.Status.CurrentRevision,
+		UpdateRevision:      statefulSet.Status.UpdateRevision,
+		PodManagementPolicy: string(statefulSet.Spec.PodManagementPolicy),
+	}
+	if rollingUpdate := statefulSet.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil {
+		status.Partition = rollingUpdate.Partition
+	}
+	for _, template := range statefulSet.Spec.VolumeClaimTemplates {
+		claim := template.Name
+		if storage, ok := template.Spec.Resources.Requests[v1.ResourceStorage]; ok {
+			claim += " (" + storage.String()
+			if template.Spec.StorageClassName != nil {
+				claim += ", storageClass " + *template.Spec.StorageClassName
+			}
+			claim += ")"
+		}
+		status.VolumeClaimTemplates = append(status.VolumeClaimTemplates, claim)
+	}
+	for _, condition := range statefulSet.Status.Conditions {
+		status.Conditions = append(status.Conditions, formatCondition(string(condition.Type), condition.Status, condition.Reason, condition.Message))
+	}
+
+	// Pods with ordinals below the partition intentionally stay on the old revision
+	partition := int32(0)
+	if status.Partition != nil {
+		partition = *status.Partition
+	}
+	expectedUpdated := desired - partition
+	if expectedUpdated < 0 {
+		expectedUpdated = 0
+	}
+
+	switch {
+	case statefulSet.Status.ObservedGeneration < statefulSet.Generation:
+		status.RolloutStatus, status.RolloutMessage = rolloutPending, "waiting for the controller to observe the latest spec"
+	case statefulSet.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType && status.CurrentRevision != status.UpdateRevision:
+		status.RolloutStatus = rolloutPending
+		status.RolloutMessage = "OnDelete strategy: pods pick up the new revision only when deleted"
+	case status.Updated < expectedUpdated:
+		status.RolloutStatus = rolloutProgressing
+		status.RolloutMessage = fmt.Sprintf("%d of %d replicas updated (pods update one ordinal at a time, highest first)", status.Updated, expectedUpdated)
+	case status.Ready < desired:
+		status.RolloutStatus = rolloutProgressing
+		status.RolloutMessage = fmt.Sprintf("%d of %d replicas ready; an unready lower ordinal blocks higher ones with OrderedReady", status.Ready, desired)
+	case partition > 0:
+		status.RolloutStatus = rolloutComplete
+		status.RolloutMessage = fmt.Sprintf("partitioned rollout: ordinals >= %d updated, lower ordinals stay on revision %s", partition, status.CurrentRevision)
+	default:
+		status.RolloutStatus = rolloutComplete
+	}
+	return status
+}
+
+// daemonSetStatus derives rollout status from node scheduling counts
+func daemonSetStatus(daemonSet *appsv1.DaemonSet) workloadStatus {
+	status := workloadStatus{
+		Kind:              "DaemonSet",
+		Name:              daemonSet.Name,
+		Namespace:         daemonSet.Namespace,
+		Desired:           daemonSet.Status.DesiredNumberScheduled,
+		Current:           daemonSet.Status.CurrentNumberScheduled,
+		Ready:             daemonSet.Status.NumberReady,
+		Updated:           daemonSet.Status.UpdatedNumberScheduled,
+		Available:         daemonSet.Status.NumberAvailable,
+		UpdateStrategy:    string(daemonSet.Spec.UpdateStrategy.Type),
+		NodesMisscheduled: daemonSet.Status.NumberMisscheduled,
+	}
+	for _, condition := range daemonSet.Status.Conditions {
+		status.Conditions = append(status.Conditions, formatCondition(string(condition.Type), condition.Status, condition.Reason, condition.Message))
+	}
+
+	switch {
+	case daemonSet.Status.ObservedGeneration < daemonSet.Generation:
+		status.RolloutStatus, status.RolloutMessage = rolloutPending, "waiting for the controller to observe the latest spec"
+	case status.Desired == 0:
+		status.RolloutStatus, status.RolloutMessage = rolloutComplete, "no nodes match the node selector, affinity and tolerations"
+	case status.Current < status.Desired:
+		status.RolloutStatus = rolloutProgressing
+		status.RolloutMessage = fmt.Sprintf("scheduled on %d of %d nodes", status.Current, status.Desired)
+	case daemonSet.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType && status.Updated < status.Desired:
+		status.RolloutStatus = rolloutPending
+		status.RolloutMessage = "OnDelete strategy: pods pick up the new template only when deleted"
+	case status.Updated < status.Desired:
+		status.RolloutStatus = rolloutProgressing
+		status.RolloutMessage = fmt.Sprintf("%d of %d nodes updated", status.Updated, status.Desired)
+	case status.Available < status.Desired:
+		status.RolloutStatus = rolloutProgressing
+		status.RolloutMessage = fmt.Sprintf("available on %d of %d nodes", status.Available, status.Desired)
+	default:
+		status.RolloutStatus = rolloutComplete
+	}
+	return status
+}
+
+// formatCondition renders a workload condition as a single line
+func formatCondition(conditionType string, status v1.ConditionStatus, reason, message string) string {
+	text := fmt.Sprintf("%s=%s", conditionType, status)
+	if reason != "" {
+		text += " (" + reason + ")"
+	}
+	if message != "" {
+		text += ": " + message
+	}
+	return text
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "kube_sherlock"
+
+// durationBuckets covers fast cluster reads through multi-step AI queries
+var durationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled, by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency, by method and route.",
+		Buckets:   durationBuckets,
+	}, []string{"method", "route"})
+
+	toolExecutions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mcp_tool_executions_total",
+		Help:      "MCP tool executions, by tool and result.",
+	}, []string{"tool", "result"})
+
+	toolDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mcp_tool_duration_seconds",
+		Help:      "MCP tool execution latency, by tool.",
+		Buckets:   durationBuckets,
+	}, []string{"tool"})
+
+	aiRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ai_requests_total",
+		Help:      "Gemini generate requests, by operation and result.",
+	}, []string{"operation", "result"})
+
+	aiDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "ai_request_duration_seconds",
+		Help:      "Gemini generate request latency, by operation.",
+		Buckets:   durationBuckets,
+	}, []string{"operation"})
+
+	aiTokens = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ai_tokens_total",
+		Help:      "Gemini tokens consumed, by operation and type (prompt or completion).",
+	}, []string{"operation", "type"})
+
+	aiWaiting = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ai_requests_waiting",
+		Help:      "Gemini requests queued for a free slot under gemini.max_concurrent_requests.",
+	})
+)
+
+// Handler returns the HTTP handler serving metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveHTTPRequest records a handled HTTP request. route should be the route pattern, not the raw path
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveToolExecution records a single MCP tool execution
+func ObserveToolExecution(tool string, failed bool, duration time.Duration) {
+	toolExecutions.WithLabelValues(tool, result(failed)).Inc()
+	toolDuration.WithLabelValues(tool).Observe(duration.Seconds())
+}
+
+// ObserveAIRequest records a single Gemini generate request
+func ObserveAIRequest(operation string, failed bool, duration time.Duration) {
+	aiRequests.WithLabelValues(operation, result(failed)).Inc()
+	aiDuration.WithLabelValues(operation).Observe(duration.Seconds())
+}
+
+// AddAITokens records the tokens consumed by a Gemini generate request
+func AddAITokens(operation string, promptTokens, completionTokens int32) {
+	aiTokens.WithLabelValues(operation, "prompt").Add(float64(promptTokens))
+	aiTokens.WithLabelValues(operation, "completion").Add(float64(completionTokens))
+}
+
+// AddAIRequestsWaiting adjusts the number of Gemini requests queued for a free slot by delta
+func AddAIRequestsWaiting(delta int) {
+	aiWaiting.Add(float64(delta))
+}
+
+// result converts a failure flag into a result label value
+func result(failed bool) string {
+	if failed {
+		return "error"
+	}
+	return "success"
+}